@@ -0,0 +1,93 @@
+package actor
+
+import "time"
+
+// actorTimer 是由进程管理的命名计时器。
+type actorTimer struct {
+	msg      any
+	interval time.Duration
+	opts     []RepeatOptFunc
+	// repeater 为 nil 表示计时器已暂停（actor 正在重启）。
+	repeater *SendRepeater
+}
+
+func (t *actorTimer) start(c *Context) {
+	sr := newSendRepeater(c.engine, c.pid, c.pid, t.msg, t.interval, t.opts)
+	t.repeater = &sr
+}
+
+func (t *actorTimer) stop() {
+	if t.repeater != nil {
+		t.repeater.Stop()
+		t.repeater = nil
+	}
+}
+
+// StartTimer 启动一个由进程管理的命名计时器，每隔 interval 向当前 actor 自身投递 msg。
+// 与 SendRepeat 返回的 SendRepeater 不同，计时器不属于接收器实例：actor 崩溃重启后
+// 计时器会自动重新计时，actor 停止时自动取消。启动同名的计时器会替换原来的计时器。
+func (c *Context) StartTimer(name string, msg any, interval time.Duration, opts ...RepeatOptFunc) {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	if c.timers == nil {
+		c.timers = make(map[string]*actorTimer)
+	}
+	if t, ok := c.timers[name]; ok {
+		t.stop()
+	}
+	t := &actorTimer{msg: msg, interval: interval, opts: opts}
+	t.start(c)
+	c.timers[name] = t
+}
+
+// CancelTimer 取消给定名称的计时器。计时器不存在时返回 false。
+func (c *Context) CancelTimer(name string) bool {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	t, ok := c.timers[name]
+	if !ok {
+		return false
+	}
+	t.stop()
+	delete(c.timers, name)
+	return true
+}
+
+// HasTimer 报告给定名称的计时器是否存在。
+func (c *Context) HasTimer(name string) bool {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	_, ok := c.timers[name]
+	return ok
+}
+
+// pauseTimers 在 actor 重启期间暂停所有计时器。
+func (c *Context) pauseTimers() {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	for _, t := range c.timers {
+		t.stop()
+	}
+}
+
+// resumeTimers 重新启动所有被暂停的计时器，已经在运行的计时器（例如在 Started 中
+// 重新启动的）保持不变。
+func (c *Context) resumeTimers() {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	for _, t := range c.timers {
+		if t.repeater == nil {
+			t.start(c)
+		}
+	}
+}
+
+// stopTimers 在 actor 停止时取消所有计时器。
+func (c *Context) stopTimers() {
+	c.timerMu.Lock()
+	defer c.timerMu.Unlock()
+	for _, t := range c.timers {
+		t.stop()
+	}
+	c.timers = nil
+}
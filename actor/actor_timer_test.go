@@ -0,0 +1,82 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timerTick struct{}
+
+// timerReceiver 统计收到的 timerTick，第一次收到时崩溃。
+type timerReceiver struct {
+	n       int
+	ticks   chan int
+	crashed *bool
+}
+
+func (r *timerReceiver) Receive(c *Context) {
+	switch c.Message().(type) {
+	case string:
+		c.StartTimer("tick", timerTick{}, 5*time.Millisecond)
+	case timerTick:
+		r.n++
+		r.ticks <- r.n
+		if !*r.crashed {
+			*r.crashed = true
+			panic("boom")
+		}
+	}
+}
+
+func TestStartTimerSurvivesRestart(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	ticks := make(chan int, 16)
+	crashed := false
+	pid := e.Spawn(func() Receiver {
+		return &timerReceiver{ticks: ticks, crashed: &crashed}
+	}, "timer", WithRestartDelay(0))
+	e.Send(pid, "arm")
+
+	assert.Equal(t, 1, <-ticks)
+	// 重启后的新接收器实例继续收到计时器消息。
+	assert.Equal(t, 1, <-ticks)
+	assert.Equal(t, 2, <-ticks)
+
+	deadLetters := make(chan DeadLetterEvent, 16)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if dl, ok := event.(DeadLetterEvent); ok {
+			deadLetters <- dl
+		}
+	})
+	defer unsubscribe()
+	<-e.Stop(pid).Done()
+	select {
+	case dl := <-deadLetters:
+		t.Fatalf("停止后计时器仍在发送: %v", dl.Message)
+	case <-time.After(30 * time.Millisecond):
+	}
+}
+
+func TestCancelTimer(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			c.StartTimer("tick", timerTick{}, time.Hour)
+			assert.True(t, c.HasTimer("tick"))
+			assert.True(t, c.CancelTimer("tick"))
+			assert.False(t, c.HasTimer("tick"))
+			assert.False(t, c.CancelTimer("tick"))
+			close(done)
+		}
+	}, "timer")
+	e.Send(pid, "arm")
+	<-done
+}
@@ -0,0 +1,74 @@
+package actor
+
+import (
+	"strings"
+	"sync"
+)
+
+// autoSpawnKind 是通过 RegisterAutoSpawn 注册的可按需创建的 actor 类型。
+type autoSpawnKind struct {
+	producer Producer
+	opts     []OptFunc
+}
+
+// autoSpawner 保存按需创建的 actor 类型。
+type autoSpawner struct {
+	mu    sync.RWMutex
+	kinds map[string]autoSpawnKind
+	// spawning 为每个正在创建的 ID 保存一个锁，避免并发发送重复创建同一个 actor。
+	spawning sync.Map
+}
+
+// RegisterAutoSpawn 注册一个按需创建的 actor 类型：向本地一个尚未注册的
+// "kind/id" PID 发送消息时，引擎会用给定的 Producer 和选项创建 ID 为 id 的 actor，
+// 然后投递消息，而不是产生死信。这为本地 actor 提供了与集群激活相同的虚拟 actor 语义。
+//
+// 只有顶层 actor 会被按需创建，子 actor 的 PID（"kind/id/child/..."）以及
+// Stop/Poison 等控制消息不会触发创建。
+func (e *Engine) RegisterAutoSpawn(kind string, p Producer, opts ...OptFunc) {
+	e.autoSpawner.mu.Lock()
+	defer e.autoSpawner.mu.Unlock()
+	if e.autoSpawner.kinds == nil {
+		e.autoSpawner.kinds = make(map[string]autoSpawnKind)
+	}
+	e.autoSpawner.kinds[kind] = autoSpawnKind{producer: p, opts: opts}
+}
+
+// UnregisterAutoSpawn 取消按需创建。已经创建的 actor 不受影响。
+func (e *Engine) UnregisterAutoSpawn(kind string) {
+	e.autoSpawner.mu.Lock()
+	defer e.autoSpawner.mu.Unlock()
+	delete(e.autoSpawner.kinds, kind)
+}
+
+// autoSpawn 在 pid 属于按需创建的类型时创建它并返回对应的进程，否则返回 nil。
+func (e *Engine) autoSpawn(pid *PID, msg any) Processer {
+	switch msg.(type) {
+	case poisonPill, *Watch, *Unwatch:
+		return nil
+	}
+	kind, id, ok := strings.Cut(pid.ID, pidSeparator)
+	if !ok || id == "" || strings.Contains(id, pidSeparator) {
+		return nil
+	}
+	e.autoSpawner.mu.RLock()
+	k, ok := e.autoSpawner.kinds[kind]
+	e.autoSpawner.mu.RUnlock()
+	if !ok || e.shuttingDown.Load() {
+		return nil
+	}
+
+	v, _ := e.autoSpawner.spawning.LoadOrStore(pid.ID, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	defer func() {
+		mu.Unlock()
+		e.autoSpawner.spawning.Delete(pid.ID)
+	}()
+	if proc := e.Registry.get(pid); proc != nil {
+		return proc
+	}
+	opts := append(append([]OptFunc{}, k.opts...), WithID(id))
+	e.Spawn(k.producer, kind, opts...)
+	return e.Registry.get(pid)
+}
@@ -0,0 +1,54 @@
+package actor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSpawn(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterRetention(10))
+	require.NoError(t, err)
+	var spawned atomic.Int32
+	e.RegisterAutoSpawn("session", newFuncReceiver(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			spawned.Add(1)
+		case string:
+			c.Respond(c.PID().ID + ":" + msg)
+		}
+	}), WithInboxSize(16))
+
+	pid := NewPID(e.Address(), "session/42")
+	resp, err := e.Request(pid, "hello", time.Second).Result()
+	require.NoError(t, err)
+	require.Equal(t, "session/42:hello", resp)
+	require.Equal(t, pid, e.GetPID("session", "42"))
+
+	// 并发发送只会创建一次。
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Send(NewPID(e.Address(), "session/7"), "hi")
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), spawned.Load())
+
+	// 停止消息、子 actor 和未注册的类型不会触发创建。
+	<-e.Stop(NewPID(e.Address(), "session/99")).Done()
+	e.Send(NewPID(e.Address(), "session/1/child"), "hi")
+	e.Send(NewPID(e.Address(), "other/1"), "hi")
+	require.Nil(t, e.GetPID("session", "99"))
+	require.Equal(t, int32(2), spawned.Load())
+	require.Equal(t, 2, e.Deadletters().Len())
+
+	e.UnregisterAutoSpawn("session")
+	e.Send(NewPID(e.Address(), "session/100"), "hi")
+	require.Nil(t, e.GetPID("session", "100"))
+}
@@ -0,0 +1,72 @@
+package actor
+
+// inboxLener 由能够报告消息数量的收件箱实现。
+type inboxLener interface {
+	Len() int
+}
+
+// WithBacklogThreshold 设置收件箱积压告警的阈值，覆盖引擎级别的阈值：
+// 待处理的消息数量达到 n 时，在事件流上广播 ActorInboxBacklogEvent。
+// 积压回落到阈值以下之后，再次达到阈值时会重新广播。
+func WithBacklogThreshold(n int) OptFunc {
+	return func(opts *Opts) {
+		opts.BacklogThreshold = n
+	}
+}
+
+// WithInboxBacklogThreshold 为引擎中所有非系统 actor 设置收件箱积压告警的阈值，
+// 使运维人员能够尽早发现处理速度跟不上的 actor。默认不告警。
+func (config EngineConfig) WithInboxBacklogThreshold(n int) EngineConfig {
+	config.backlogThreshold = n
+	return config
+}
+
+// inboxLen 返回收件箱中等待处理的消息数量，收件箱不支持时返回 -1。
+func (p *process) inboxLen() int {
+	if in, ok := p.inbox.(inboxLener); ok {
+		return in.Len()
+	}
+	return -1
+}
+
+// checkBacklog 在处理一批消息之前检查积压，batch 是这一批的消息数量。
+// 只在积压越过阈值时广播一次，避免持续积压时事件泛滥。
+func (p *process) checkBacklog(batch int) {
+	if p.Opts.BacklogThreshold <= 0 {
+		return
+	}
+	n := p.inboxLen()
+	if n < 0 {
+		return
+	}
+	n += batch
+	if n < p.Opts.BacklogThreshold {
+		p.backlogged = false
+		return
+	}
+	if p.backlogged {
+		return
+	}
+	p.backlogged = true
+	p.context.engine.BroadcastEvent(ActorInboxBacklogEvent{
+		PID:       p.pid,
+		Len:       n,
+		Threshold: p.Opts.BacklogThreshold,
+		Timestamp: p.context.engine.clock.Now(),
+	})
+}
+
+// InboxLen 返回给定 PID 对应的本地 actor 收件箱中等待处理的消息数量。
+// actor 不存在或其收件箱不支持统计时返回 -1。
+func (e *Engine) InboxLen(pid *PID) int {
+	if p, ok := e.Registry.get(pid).(*process); ok {
+		return p.inboxLen()
+	}
+	return -1
+}
+
+// InboxLen 返回当前 actor 收件箱中等待处理的消息数量（不包括正在处理的这一批），
+// 收件箱不支持统计时返回 -1。
+func (c *Context) InboxLen() int {
+	return c.engine.InboxLen(c.pid)
+}
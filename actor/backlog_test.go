@@ -0,0 +1,94 @@
+package actor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboxBacklogEvent(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithInboxBacklogThreshold(5))
+	require.NoError(t, err)
+	events := make(chan ActorInboxBacklogEvent, 8)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(ActorInboxBacklogEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+
+	var (
+		release   = make(chan struct{})
+		blocked   = make(chan struct{})
+		processed atomic.Int32
+	)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			close(blocked)
+			<-release
+		case int:
+			processed.Add(1)
+		}
+	}, "busy")
+	e.Send(pid, "block")
+	<-blocked
+	for i := 0; i < 10; i++ {
+		e.Send(pid, i)
+	}
+	assert.Equal(t, 10, e.InboxLen(pid))
+	close(release)
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, pid, evt.PID)
+		assert.Equal(t, 10, evt.Len)
+		assert.Equal(t, 5, evt.Threshold)
+	case <-time.After(time.Second):
+		t.Fatal("expected ActorInboxBacklogEvent")
+	}
+	assert.Eventually(t, func() bool { return processed.Load() == 10 }, time.Second, time.Millisecond)
+	assert.Equal(t, 0, e.InboxLen(pid))
+	assert.Equal(t, -1, e.InboxLen(NewPID(LocalLookupAddr, "missing")))
+}
+
+func TestInboxLen(t *testing.T) {
+	for name, opt := range map[string]OptFunc{
+		"mpsc":     WithMPSCInbox(),
+		"priority": WithPriorityInbox(),
+		"bounded":  WithBoundedInbox(32, OverflowReject),
+	} {
+		t.Run(name, func(t *testing.T) {
+			e, err := NewEngine(NewEngineConfig())
+			require.NoError(t, err)
+			var (
+				release = make(chan struct{})
+				blocked = make(chan struct{})
+				lens    = make(chan int, 1)
+			)
+			pid := e.SpawnFunc(func(c *Context) {
+				switch c.Message().(type) {
+				case string:
+					close(blocked)
+					<-release
+				case int:
+					select {
+					case lens <- c.InboxLen():
+					default:
+					}
+				}
+			}, "busy", opt)
+			e.Send(pid, "block")
+			<-blocked
+			for i := 0; i < 3; i++ {
+				e.Send(pid, i)
+			}
+			assert.Equal(t, 3, e.InboxLen(pid))
+			close(release)
+			assert.Equal(t, 0, <-lens)
+		})
+	}
+}
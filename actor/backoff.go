@@ -0,0 +1,55 @@
+package actor
+
+import "time"
+
+// RestartBackoff 是重启的指数退避策略。第一次重启等待 Initial，之后每次乘以 Multiplier，
+// 最多等待 Max。如果距离上次重启已超过 ResetAfter，退避重新从 Initial 开始。
+type RestartBackoff struct {
+	Initial    time.Duration
+	Multiplier float64
+	Max        time.Duration
+	// ResetAfter 为 0 时退避不会被重置。
+	ResetAfter time.Duration
+}
+
+// WithRestartBackoff 使用指数退避代替固定的 RestartDelay，
+// 避免不断崩溃的 actor 以固定的频率冲击系统。
+//
+//	actor.WithRestartBackoff(actor.RestartBackoff{
+//		Initial:    100 * time.Millisecond,
+//		Multiplier: 2,
+//		Max:        30 * time.Second,
+//		ResetAfter: time.Minute,
+//	})
+func WithRestartBackoff(b RestartBackoff) OptFunc {
+	return func(opts *Opts) {
+		opts.RestartBackoff = &b
+	}
+}
+
+// next 根据上一次的等待时间和距离上次重启的时间计算本次的等待时间。
+func (b RestartBackoff) next(prev, sinceLast time.Duration) time.Duration {
+	if prev == 0 || (b.ResetAfter > 0 && sinceLast > b.ResetAfter) {
+		return b.Initial
+	}
+	mult := b.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := time.Duration(float64(prev) * mult)
+	if b.Max > 0 && d > b.Max {
+		d = b.Max
+	}
+	return d
+}
+
+// nextRestartDelay 返回本次重启前需要等待的时间。
+func (p *process) nextRestartDelay() time.Duration {
+	if p.RestartBackoff == nil {
+		return p.Opts.RestartDelay
+	}
+	now := p.context.engine.clock.Now()
+	p.restartDelay = p.RestartBackoff.next(p.restartDelay, now.Sub(p.lastRestart))
+	p.lastRestart = now
+	return p.restartDelay
+}
@@ -0,0 +1,72 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartBackoffNext(t *testing.T) {
+	b := RestartBackoff{
+		Initial:    10 * time.Millisecond,
+		Multiplier: 2,
+		Max:        50 * time.Millisecond,
+		ResetAfter: time.Second,
+	}
+	var (
+		d      time.Duration
+		delays []time.Duration
+	)
+	for i := 0; i < 5; i++ {
+		d = b.next(d, 0)
+		delays = append(delays, d)
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+		50 * time.Millisecond,
+	}, delays)
+	assert.Equal(t, 10*time.Millisecond, b.next(d, 2*time.Second))
+}
+
+func TestRestartBackoff(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		mu     sync.Mutex
+		starts []time.Time
+		wg     sync.WaitGroup
+	)
+	wg.Add(4)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+			wg.Done()
+		case string:
+			panic("boom")
+		}
+	}, "crasher", WithMaxRestarts(5), WithRestartBackoff(RestartBackoff{
+		Initial:    5 * time.Millisecond,
+		Multiplier: 4,
+		Max:        time.Second,
+	}))
+	for i := 0; i < 3; i++ {
+		e.Send(pid, "crash")
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 重启间隔依次约为 5ms、20ms、80ms。
+	assert.GreaterOrEqual(t, starts[1].Sub(starts[0]), 5*time.Millisecond)
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[1]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, starts[3].Sub(starts[2]), 80*time.Millisecond)
+}
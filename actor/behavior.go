@@ -0,0 +1,26 @@
+package actor
+
+// Become 将给定的 ReceiveFunc 压入行为栈，之后的用户消息都由它处理，
+// 直到调用 Unbecome 或再次调用 Become。生命周期消息（Initialized、Started、Stopped）
+// 始终交给 Receiver 处理。actor 重启后行为栈会被清空。
+func (c *Context) Become(f ReceiveFunc) {
+	c.behaviors = append(c.behaviors, f)
+}
+
+// Unbecome 弹出行为栈顶的行为，恢复到上一个行为。
+// 当行为栈为空时，消息重新由 Receiver 处理。
+func (c *Context) Unbecome() {
+	if len(c.behaviors) == 0 {
+		return
+	}
+	c.behaviors[len(c.behaviors)-1] = nil
+	c.behaviors = c.behaviors[:len(c.behaviors)-1]
+}
+
+// behavior 返回当前用于处理用户消息的 ReceiveFunc。
+func (c *Context) behavior() ReceiveFunc {
+	if n := len(c.behaviors); n > 0 {
+		return c.behaviors[n-1]
+	}
+	return c.receiver.Receive
+}
@@ -0,0 +1,65 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type door struct{}
+
+func (d *door) Receive(c *Context) {
+	switch c.Message() {
+	case "state":
+		c.Respond("closed")
+	case "open":
+		c.Become(d.opened)
+	}
+}
+
+func (d *door) opened(c *Context) {
+	switch c.Message() {
+	case "state":
+		c.Respond("opened")
+	case "lock":
+		c.Become(d.locked)
+	case "close":
+		c.Unbecome()
+	}
+}
+
+func (d *door) locked(c *Context) {
+	switch c.Message() {
+	case "state":
+		c.Respond("locked")
+	case "unlock":
+		c.Unbecome()
+	}
+}
+
+func TestBecomeUnbecome(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.Spawn(func() Receiver { return &door{} }, "door")
+
+	state := func() string {
+		resp, err := e.Request(pid, "state", time.Second).Result()
+		require.NoError(t, err)
+		return resp.(string)
+	}
+
+	assert.Equal(t, "closed", state())
+	e.Send(pid, "open")
+	assert.Equal(t, "opened", state())
+	e.Send(pid, "lock")
+	assert.Equal(t, "locked", state())
+	e.Send(pid, "unlock")
+	assert.Equal(t, "opened", state())
+	e.Send(pid, "close")
+	assert.Equal(t, "closed", state())
+	// 空栈上的 Unbecome 不做任何事情。
+	e.Send(pid, "close")
+	assert.Equal(t, "closed", state())
+}
@@ -0,0 +1,218 @@
+package actor
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/TAnNbR/Distributed-framework/ringbuffer"
+)
+
+// OverflowPolicy 决定有界收件箱已满时如何处理新消息。
+type OverflowPolicy int
+
+const (
+	// OverflowBlock 阻塞发送者，直到收件箱有空闲位置。
+	// 注意：actor 不应向自己发送消息，否则可能造成死锁。
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest 丢弃收件箱中最旧的用户消息以腾出位置，被丢弃的消息交给死信处理。
+	OverflowDropOldest
+	// OverflowDropNewest 丢弃新到达的消息，被丢弃的消息交给死信处理。
+	OverflowDropNewest
+	// OverflowReject 拒绝新到达的消息，并在事件流上广播 MailboxFullEvent。
+	OverflowReject
+)
+
+// overflowNotifier 由需要在拒绝或丢弃消息时通知引擎的收件箱实现。
+type overflowNotifier interface {
+	setOverflowHandler(onReject, onDrop func(Envelope))
+}
+
+// controlEnvelope 是排队的系统消息。after 是它之前发送的用户消息数量，
+// 这些用户消息被处理或丢弃之后才投递它，系统消息因此保持与用户消息的相对顺序。
+type controlEnvelope struct {
+	env   Envelope
+	after uint64
+}
+
+// BoundedInbox 是容量固定的收件箱，满时按照 OverflowPolicy 处理新消息。
+// 系统消息（如 poisonPill）保存在单独的队列中，不计入容量，也不会被丢弃。
+type BoundedInbox struct {
+	mu       sync.Mutex
+	notFull  *sync.Cond
+	rb       *ringbuffer.RingBuffer[Envelope]
+	control  []controlEnvelope
+	pushed   uint64 // 已加入的用户消息数量
+	taken    uint64 // 已取出或丢弃的用户消息数量
+	capacity int64
+	policy   OverflowPolicy
+	onReject func(Envelope)
+	// onDrop 接收 OverflowDropOldest 和 OverflowDropNewest 丢弃的消息。
+	onDrop     func(Envelope)
+	closed     atomic.Bool
+	proc       Processer
+	scheduler  Scheduler
+	procStatus int32
+}
+
+// NewBoundedInbox 创建一个容量为 size 的有界收件箱。
+func NewBoundedInbox(size int, policy OverflowPolicy) *BoundedInbox {
+	if size <= 0 {
+		size = defaultInboxSize
+	}
+	in := &BoundedInbox{
+		rb:         ringbuffer.New[Envelope](int64(size)),
+		capacity:   int64(size),
+		policy:     policy,
+		scheduler:  NewScheduler(defaultThroughput),
+		procStatus: stopped,
+	}
+	in.notFull = sync.NewCond(&in.mu)
+	return in
+}
+
+func (in *BoundedInbox) setOverflowHandler(onReject, onDrop func(Envelope)) {
+	in.onReject = onReject
+	in.onDrop = onDrop
+}
+
+// setScheduler 替换收件箱的调度器。
+func (in *BoundedInbox) setScheduler(s Scheduler) {
+	in.scheduler = s
+}
+
+// Send 向收件箱发送消息。收件箱已满时按照 OverflowPolicy 处理，丢弃的消息交给死信处理。
+func (in *BoundedInbox) Send(msg Envelope) {
+	in.mu.Lock()
+	if _, ok := msg.Msg.(poisonPill); ok || isSystemMessage(msg.Msg) {
+		in.control = append(in.control, controlEnvelope{env: msg, after: in.pushed})
+		in.mu.Unlock()
+		in.schedule()
+		return
+	}
+	var (
+		dropped Envelope
+		drop    bool
+	)
+	for in.rb.Len() >= in.capacity {
+		switch in.policy {
+		case OverflowDropOldest:
+			dropped, drop = in.rb.Pop()
+			in.taken++
+		case OverflowDropNewest:
+			in.mu.Unlock()
+			in.drop(msg)
+			return
+		case OverflowReject:
+			in.mu.Unlock()
+			if in.onReject != nil {
+				in.onReject(msg)
+			}
+			return
+		default:
+			// 收件箱已停止时不再阻塞发送者。
+			if in.closed.Load() {
+				in.mu.Unlock()
+				return
+			}
+			in.notFull.Wait()
+		}
+	}
+	in.rb.Push(msg)
+	in.pushed++
+	in.mu.Unlock()
+	if drop {
+		in.drop(dropped)
+	}
+	in.schedule()
+}
+
+func (in *BoundedInbox) drop(msg Envelope) {
+	if in.onDrop != nil {
+		in.onDrop(msg)
+	}
+}
+
+// Len 返回收件箱中的消息数量，包括系统消息。
+func (in *BoundedInbox) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return int(in.rb.Len()) + len(in.control)
+}
+
+func (in *BoundedInbox) schedule() {
+	if atomic.CompareAndSwapInt32(&in.procStatus, idle, running) {
+		in.scheduler.Schedule(in.process)
+	}
+}
+
+func (in *BoundedInbox) process() {
+	in.run()
+	if atomic.CompareAndSwapInt32(&in.procStatus, running, idle) && in.Len() > 0 {
+		in.schedule()
+	}
+}
+
+func (in *BoundedInbox) run() {
+	batch := getBatch()
+	msgs := *batch
+	defer func() { putBatch(batch, msgs) }()
+	i, t := 0, in.scheduler.Throughput()
+	for atomic.LoadInt32(&in.procStatus) != stopped {
+		if i > t {
+			i = 0
+			runtime.Gosched()
+		}
+		i++
+
+		msgs = in.take(msgs[:0], messageBatchSize)
+		if len(msgs) == 0 {
+			return
+		}
+		in.proc.Invoke(msgs)
+		clear(msgs)
+	}
+}
+
+// take 按发送顺序取出最多 n 条消息追加到 dst，并唤醒因收件箱已满而阻塞的发送者。
+func (in *BoundedInbox) take(dst []Envelope, n int) []Envelope {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for start := len(dst); len(dst)-start < n; {
+		if len(in.control) > 0 && in.control[0].after <= in.taken {
+			dst = append(dst, in.control[0].env)
+			in.control[0] = controlEnvelope{}
+			in.control = in.control[1:]
+			continue
+		}
+		env, ok := in.rb.Pop()
+		if !ok {
+			break
+		}
+		in.taken++
+		dst = append(dst, env)
+	}
+	if in.policy == OverflowBlock {
+		in.notFull.Broadcast()
+	}
+	return dst
+}
+
+// Start 启动收件箱。
+func (in *BoundedInbox) Start(proc Processer) {
+	if atomic.CompareAndSwapInt32(&in.procStatus, stopped, starting) {
+		in.proc = proc
+		atomic.SwapInt32(&in.procStatus, idle)
+		in.schedule()
+	}
+}
+
+// Stop 停止收件箱，并释放所有被阻塞的发送者。
+func (in *BoundedInbox) Stop() error {
+	atomic.StoreInt32(&in.procStatus, stopped)
+	in.closed.Store(true)
+	in.mu.Lock()
+	in.notFull.Broadcast()
+	in.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,180 @@
+package actor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectingProc(n int) (MockProcesser, func() []any) {
+	var (
+		mu        sync.Mutex
+		processed []any
+		done      = make(chan struct{})
+	)
+	proc := MockProcesser{
+		processFunc: func(envelopes []Envelope) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, e := range envelopes {
+				processed = append(processed, e.Msg)
+			}
+			if len(processed) == n {
+				close(done)
+			}
+		},
+	}
+	return proc, func() []any {
+		<-done
+		mu.Lock()
+		defer mu.Unlock()
+		return processed
+	}
+}
+
+func TestBoundedInboxDropOldest(t *testing.T) {
+	inbox := NewBoundedInbox(2, OverflowDropOldest)
+	for i := 0; i < 4; i++ {
+		inbox.Send(Envelope{Msg: i})
+	}
+	assert.Equal(t, 2, inbox.Len())
+	proc, wait := collectingProc(2)
+	inbox.Start(proc)
+	assert.Equal(t, []any{2, 3}, wait())
+}
+
+func TestBoundedInboxDropNewest(t *testing.T) {
+	inbox := NewBoundedInbox(2, OverflowDropNewest)
+	var dropped []any
+	inbox.setOverflowHandler(nil, func(env Envelope) {
+		dropped = append(dropped, env.Msg)
+	})
+	for i := 0; i < 4; i++ {
+		inbox.Send(Envelope{Msg: i})
+	}
+	assert.Equal(t, []any{2, 3}, dropped)
+	proc, wait := collectingProc(2)
+	inbox.Start(proc)
+	assert.Equal(t, []any{0, 1}, wait())
+}
+
+func TestBoundedInboxDropOldestKeepsControlMessages(t *testing.T) {
+	inbox := NewBoundedInbox(2, OverflowDropOldest)
+	var dropped []any
+	inbox.setOverflowHandler(nil, func(env Envelope) {
+		dropped = append(dropped, env.Msg)
+	})
+	pill := poisonPill{graceful: true}
+	inbox.Send(Envelope{Msg: 0})
+	inbox.Send(Envelope{Msg: pill})
+	for i := 1; i < 5; i++ {
+		inbox.Send(Envelope{Msg: i})
+	}
+	// 系统消息既不计入容量，也不会被丢弃，并且在它之前发送的用户消息之后投递。
+	assert.Equal(t, []any{0, 1, 2}, dropped)
+	assert.Equal(t, 3, inbox.Len())
+	proc, wait := collectingProc(3)
+	inbox.Start(proc)
+	assert.Equal(t, []any{pill, 3, 4}, wait())
+}
+
+func TestBoundedInboxPoisonNotEvicted(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var deadLetters atomic.Int32
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if _, ok := event.(DeadLetterEvent); ok {
+			deadLetters.Add(1)
+		}
+	})
+	defer unsubscribe()
+
+	release := make(chan struct{})
+	pid := e.SpawnFunc(func(c *Context) {
+		if c.Message() == 0 {
+			<-release
+		}
+	}, "bounded", WithBoundedInbox(2, OverflowDropOldest))
+	e.Send(pid, 0)
+	// 等待第一条消息被取出，actor 阻塞在它上面。
+	time.Sleep(10 * time.Millisecond)
+	done := e.Poison(pid)
+	for i := 1; i <= 10; i++ {
+		e.Send(pid, i)
+	}
+	close(release)
+	select {
+	case <-done.Done():
+	case <-time.After(time.Second):
+		t.Fatal("poison should complete after the inbox overflowed")
+	}
+	assert.Eventually(t, func() bool { return deadLetters.Load() >= 8 }, time.Second, 10*time.Millisecond)
+}
+
+func TestBoundedInboxReject(t *testing.T) {
+	inbox := NewBoundedInbox(1, OverflowReject)
+	var rejected []any
+	inbox.setOverflowHandler(func(env Envelope) {
+		rejected = append(rejected, env.Msg)
+	}, nil)
+	inbox.Send(Envelope{Msg: 1})
+	inbox.Send(Envelope{Msg: 2})
+	// 系统消息不受容量限制。
+	inbox.Send(Envelope{Msg: poisonPill{}})
+	assert.Equal(t, []any{2}, rejected)
+	assert.Equal(t, 2, inbox.Len())
+}
+
+func TestBoundedInboxBlock(t *testing.T) {
+	inbox := NewBoundedInbox(1, OverflowBlock)
+	inbox.Send(Envelope{Msg: 1})
+	sent := make(chan struct{})
+	go func() {
+		inbox.Send(Envelope{Msg: 2})
+		close(sent)
+	}()
+	select {
+	case <-sent:
+		t.Fatal("send should block while the inbox is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	proc, wait := collectingProc(2)
+	inbox.Start(proc)
+	<-sent
+	assert.Equal(t, []any{1, 2}, wait())
+}
+
+func TestMailboxFullEvent(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg      sync.WaitGroup
+		release = make(chan struct{})
+	)
+	wg.Add(1)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			c.Engine().Subscribe(c.PID())
+		case MailboxFullEvent:
+			assert.Equal(t, 3, msg.Message)
+			close(release)
+			wg.Done()
+		}
+	}, "listener")
+	pid := e.SpawnFunc(func(c *Context) {
+		if c.Message() == 1 {
+			<-release
+		}
+	}, "bounded", WithBoundedInbox(1, OverflowReject))
+	e.Send(pid, 1)
+	// 等待第一条消息被取出，使收件箱只容纳第二条消息。
+	time.Sleep(10 * time.Millisecond)
+	e.Send(pid, 2)
+	e.Send(pid, 3)
+	wg.Wait()
+}
@@ -0,0 +1,191 @@
+package actor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 是引擎的时间来源。SendAfter、SendAt、SendRepeat、接收超时以及重启延迟
+// 都通过它计时，测试中可以通过 EngineConfig.WithClock 注入 ManualClock，
+// 手动推进时间而不必真正等待。
+type Clock interface {
+	// Now 返回当前时间。
+	Now() time.Time
+	// AfterFunc 在 d 时间后调用 f。
+	AfterFunc(d time.Duration, f func()) Timer
+	// Sleep 阻塞 d 时间。
+	Sleep(d time.Duration)
+}
+
+// Timer 是 Clock.AfterFunc 返回的计时器。
+type Timer interface {
+	// Stop 阻止计时器触发。如果成功阻止返回 true，已经触发或已停止时返回 false。
+	Stop() bool
+	// Reset 使计时器在 d 时间后重新触发。如果计时器在调用前处于活动状态返回 true。
+	Reset(d time.Duration) bool
+}
+
+// realClock 是基于 time 包的 Clock。
+type realClock struct{}
+
+// RealClock 返回使用系统时间的 Clock，这是引擎的默认时钟。
+func RealClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// ManualClock 是只能手动推进的 Clock，用于测试依赖时间的 actor。
+// 计时器只在调用 Advance 或 Set 时触发，回调在调用者的 goroutine 中按到期顺序执行。
+type ManualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+	// changed 在计时器集合变化时关闭并替换，用于 BlockUntil。
+	changed chan struct{}
+}
+
+// NewManualClock 创建一个从 start 开始的 ManualClock。
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now 返回时钟的当前时间。
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc 注册一个在时钟推进 d 之后触发的计时器。
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) Timer {
+	t := &manualTimer{clock: c, f: f}
+	c.mu.Lock()
+	c.schedule(t, d)
+	c.mu.Unlock()
+	return t
+}
+
+// Sleep 阻塞直到时钟被推进 d。
+func (c *ManualClock) Sleep(d time.Duration) {
+	done := make(chan struct{})
+	c.AfterFunc(d, func() { close(done) })
+	<-done
+}
+
+// Advance 将时钟推进 d，并依次触发期间到期的计时器。
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set 将时钟设置为 t，并依次触发 t 之前到期的计时器。时钟不会倒退。
+func (c *ManualClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		if len(c.timers) == 0 || c.timers[0].when.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.when.After(c.now) {
+			c.now = timer.when
+		}
+		timer.active = false
+		c.notify()
+		c.mu.Unlock()
+		timer.f()
+	}
+}
+
+// Pending 返回尚未触发的计时器数量。
+func (c *ManualClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil 阻塞直到至少有 n 个尚未触发的计时器。
+// 用于等待 actor 在另一个 goroutine 中注册计时器之后再推进时钟。
+func (c *ManualClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		if len(c.timers) >= n {
+			c.mu.Unlock()
+			return
+		}
+		ch := c.changed
+		c.mu.Unlock()
+		<-ch
+	}
+}
+
+// schedule 按到期时间插入计时器，调用者必须持有锁。
+func (c *ManualClock) schedule(t *manualTimer, d time.Duration) {
+	t.when = c.now.Add(d)
+	t.active = true
+	i := sort.Search(len(c.timers), func(i int) bool {
+		return c.timers[i].when.After(t.when)
+	})
+	c.timers = append(c.timers, nil)
+	copy(c.timers[i+1:], c.timers[i:])
+	c.timers[i] = t
+	c.notify()
+}
+
+// remove 移除计时器，调用者必须持有锁。
+func (c *ManualClock) remove(t *manualTimer) bool {
+	if !t.active {
+		return false
+	}
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			break
+		}
+	}
+	t.active = false
+	c.notify()
+	return true
+}
+
+// notify 唤醒 BlockUntil，调用者必须持有锁。
+func (c *ManualClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// manualTimer 是 ManualClock 的计时器。
+type manualTimer struct {
+	clock  *ManualClock
+	f      func()
+	when   time.Time
+	active bool
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *manualTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.remove(t)
+	t.clock.schedule(t, d)
+	return active
+}
@@ -0,0 +1,82 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	var fired []int
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	stopped := clock.AfterFunc(time.Second, func() { fired = append(fired, -1) })
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+	require.Equal(t, 2, clock.Pending())
+
+	clock.Advance(1500 * time.Millisecond)
+	require.Equal(t, []int{1}, fired)
+	require.Equal(t, start.Add(1500*time.Millisecond), clock.Now())
+
+	clock.Advance(time.Second)
+	require.Equal(t, []int{1, 2}, fired)
+	require.Zero(t, clock.Pending())
+}
+
+func TestEngineManualClock(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock))
+	require.NoError(t, err)
+	ch := make(chan string, 10)
+	pid := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			ch <- msg
+		}
+	}, "clock")
+
+	e.SendAfter(pid, "after", time.Minute)
+	e.SendAt(pid, "at", clock.Now().Add(2*time.Minute))
+	clock.Advance(59 * time.Second)
+	require.Empty(t, ch)
+	clock.Advance(time.Second)
+	require.Equal(t, "after", <-ch)
+	clock.Advance(time.Minute)
+	require.Equal(t, "at", <-ch)
+
+	repeater := e.SendRepeat(pid, "tick", time.Hour)
+	defer repeater.Stop()
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		require.Equal(t, "tick", <-ch)
+	}
+}
+
+func TestManualClockRestartDelay(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock))
+	require.NoError(t, err)
+	started := make(chan struct{}, 10)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			started <- struct{}{}
+		case string:
+			panic("boom")
+		}
+	}, "restart", WithRestartDelay(time.Hour))
+	<-started
+	e.Send(pid, "crash")
+	clock.BlockUntil(1)
+	select {
+	case <-started:
+		t.Fatal("在时钟推进之前重启")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Hour)
+	<-started
+}
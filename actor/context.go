@@ -2,10 +2,10 @@ package actor
 
 import (
 	"context"
-	"log/slog"
 	"math"
 	"math/rand"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/TAnNbR/Distributed-framework/safemap"
@@ -20,6 +20,32 @@ type Context struct {
 	parentCtx *Context
 	children  *safemap.SafeMap[string, *PID]
 	context   context.Context
+	// supervisor 是该 actor 用于监督其子 actor 的策略。
+	supervisor SupervisorStrategy
+	// behaviors 是通过 Become 压入的行为栈，栈顶的行为处理用户消息。
+	behaviors []ReceiveFunc
+	// stash 是通过 Stash 暂存的消息。
+	stash []Envelope
+	// unstashed 是通过 UnstashAll 等待重新投递的消息。
+	unstashed []Envelope
+	// receiveTimeout 是接收超时时间，receiveTimer 在超时后投递 ReceiveTimeout。
+	receiveTimeout time.Duration
+	receiveTimer   Timer
+	// journal 是持久化事件的存储，persistSeq 是最后一条事件的序号。
+	journal    Journal
+	persistSeq uint64
+	// snapshotStore 是快照存储，snapshotEvery 为自动快照的事件间隔。
+	snapshotStore SnapshotStore
+	snapshotEvery uint64
+	// correlationID 是当前消息的关联 ID，发送和响应时自动传递。
+	correlationID string
+	// requestID 是当前消息作为远程请求的请求 ID，不是远程请求时为 0。
+	requestID uint64
+	// trace 是当前消息携带的追踪上下文，参见 Traced。
+	trace *TraceContext
+	// timers 是通过 StartTimer 启动的命名计时器，在重启后重新启动。
+	timerMu sync.Mutex
+	timers  map[string]*actorTimer
 }
 
 func newContext(ctx context.Context, e *Engine, pid *PID) *Context {
@@ -39,16 +65,35 @@ func (c *Context) Receiver() Receiver {
 	return c.receiver
 }
 
+// Request 参见 Engine.Request。在 Receive 中阻塞等待发给自身的请求会一直等到超时，
+// 此时会广播 BlockingSelfRequestEvent，应改用 RequestAsync。
 func (c *Context) Request(pid *PID, msg any, timeout time.Duration) *Response {
-	return c.engine.Request(pid, msg, timeout)
+	resp := c.engine.request(pid, msg, timeout, c.correlationID)
+	if pid.Equals(c.pid) {
+		resp.requester = c.pid
+	}
+	return resp
+}
+
+func (c *Context) RequestCtx(ctx context.Context, pid *PID, msg any) *Response {
+	return c.engine.RequestCtx(ctx, pid, msg)
+}
+
+// RequestWithRetry 参见 Engine.RequestWithRetry。它会阻塞当前 actor，
+// 在 Receive 中应谨慎使用。
+func (c *Context) RequestWithRetry(pid *PID, msg any, timeout time.Duration, retries int, backoff RestartBackoff) (any, error) {
+	return c.engine.RequestWithRetry(pid, msg, timeout, retries, backoff)
 }
 
 func (c *Context) Respond(msg any) {
 	if c.sender == nil {
-		slog.Warn("context got no sender", "func", "Respond", "pid", c.PID())
+		c.engine.logger.Warn("context got no sender", "func", "Respond", "pid", c.PID())
+		return
+	}
+	if c.engine.respondRemote(c.sender, msg, c.requestID) {
 		return
 	}
-	c.engine.Send(c.sender, msg)
+	c.engine.sendCorrelated(c.sender, msg, nil, c.correlationID)
 }
 
 func (c *Context) SpawnChild(p Producer, name string, opts ...OptFunc) *PID {
@@ -65,6 +110,10 @@ func (c *Context) SpawnChild(p Producer, name string, opts ...OptFunc) *PID {
 		options.ID = id
 	}
 	
+	if err := c.engine.interceptSpawn(&options); err != nil {
+		return c.engine.rejectSpawn(options, err)
+	}
+	
 	proc := newProcess(c.engine, options)
 	
 	proc.context.parentCtx = c
@@ -83,26 +132,15 @@ func (c *Context) SpawnChildFunc(f func(*Context), name string, opts ...OptFunc)
 }
 
 func (c *Context) Send(pid *PID, msg any) {
-	c.engine.SendWithSender(pid, msg, c.pid)
+	c.engine.sendCorrelated(pid, msg, c.pid, c.correlationID)
 }
 
-func (c *Context) SendRepeat(pid *PID, msg any, interval time.Duration) SendRepeater {
-	sr := SendRepeater{
-		engine:   c.engine,
-		self:     c.pid,
-		target:   pid.CloneVT(),
-		interval: interval,
-		msg:      msg,
-		cancelch: make(chan struct{}, 1),
-	}
-	
-	sr.start()
-	
-	return sr
+func (c *Context) SendRepeat(pid *PID, msg any, interval time.Duration, opts ...RepeatOptFunc) SendRepeater {
+	return newSendRepeater(c.engine, c.pid, pid, msg, interval, opts)
 }
 
 func (c *Context) Forward(pid *PID) {
-	c.engine.SendWithSender(pid, c.message, c.pid)
+	c.engine.sendCorrelated(pid, c.message, c.pid, c.correlationID)
 }
 
 func (c *Context) GetPID(id string) *PID {
@@ -115,6 +153,7 @@ func (c *Context) GetPID(id string) *PID {
 	return nil
 }
 
+// Parent 返回父 actor 的 PID，顶层 actor 返回 nil。
 func (c *Context) Parent() *PID {
 	if c.parentCtx != nil {
 		return c.parentCtx.pid
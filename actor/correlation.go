@@ -0,0 +1,21 @@
+package actor
+
+// CorrelationID 返回当前消息的关联 ID。关联 ID 标识一个跨越多个 actor 的逻辑请求：
+// 在 Receive 中调用 Send、Forward、Request、Respond 和 RespondErr 时，
+// 当前的关联 ID 会自动随消息传递，便于在日志和追踪中串联整条调用链。
+// 消息没有关联 ID 时返回空字符串。关联 ID 目前不会随远程消息传递。
+func (c *Context) CorrelationID() string {
+	return c.correlationID
+}
+
+// SetCorrelationID 设置当前 actor 在处理本条消息期间发出的消息所携带的关联 ID，
+// 用于由 actor 开启一个新的逻辑请求。处理下一条消息时将被该消息的关联 ID 替换。
+func (c *Context) SetCorrelationID(id string) {
+	c.correlationID = id
+}
+
+// SendWithCorrelationID 将携带给定关联 ID 的消息发送给给定的 PID，
+// 通常用于在系统入口（例如 HTTP 处理器）开启一个逻辑请求。
+func (e *Engine) SendWithCorrelationID(pid *PID, msg any, correlationID string) {
+	e.sendCorrelated(pid, msg, nil, correlationID)
+}
@@ -0,0 +1,50 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationIDPropagation(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	ids := make(chan string, 8)
+
+	target := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			ids <- "c:" + c.CorrelationID()
+			c.Respond(42)
+		}
+	}, "c")
+	b := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			ids <- "b:" + c.CorrelationID()
+			c.Request(target, "ping", time.Second).PipeTo(c.PID())
+		}
+	}, "b")
+	a := e.SpawnFunc(func(ctx *Context) {
+		switch ctx.Message().(type) {
+		case string:
+			ids <- "a:" + ctx.CorrelationID()
+			ctx.Forward(b)
+		case int:
+			// 没有关联 ID 的消息可以开启新的逻辑请求。
+			ids <- "a:" + ctx.CorrelationID()
+			ctx.SetCorrelationID("trace-2")
+			ctx.Send(b, "hello")
+		}
+	}, "a")
+
+	e.SendWithCorrelationID(a, "hello", "trace-1")
+	assert.Equal(t, "a:trace-1", <-ids)
+	assert.Equal(t, "b:trace-1", <-ids)
+	assert.Equal(t, "c:trace-1", <-ids)
+
+	e.Send(a, 1)
+	assert.Equal(t, "a:", <-ids)
+	assert.Equal(t, "b:trace-2", <-ids)
+	assert.Equal(t, "c:trace-2", <-ids)
+}
@@ -0,0 +1,60 @@
+package actor
+
+// CrashBufferPolicy 决定 actor 崩溃重启时如何处理尚未处理的消息。
+// 默认情况下，所有未处理的消息（包括暂存的消息）都会被缓冲并在重启后重新投递，
+// 而导致崩溃的那条消息会被跳过。被丢弃的消息将成为死信。
+type CrashBufferPolicy struct {
+	// MaxMessages 大于 0 时最多缓冲 N 条消息（保留最早的 N 条），其余的成为死信。
+	MaxMessages int
+	// RetryOffending 为 true 时，导致崩溃的消息也会在重启后重新投递。
+	// 对于由瞬时故障引起的崩溃有用，但确定性的"毒消息"会使 actor 反复崩溃直到达到 MaxRestarts。
+	RetryOffending bool
+	// DiscardOnRestart 为 true 时不缓冲任何消息，所有未处理的消息都成为死信。
+	DiscardOnRestart bool
+}
+
+// WithCrashBufferPolicy 设置 actor 崩溃重启时处理未处理消息的策略。
+func WithCrashBufferPolicy(policy CrashBufferPolicy) OptFunc {
+	return func(opts *Opts) {
+		opts.CrashBuffer = policy
+	}
+}
+
+// crashBuffer 根据 CrashBufferPolicy 返回重启后需要重新投递的消息，
+// 并将被丢弃的消息作为死信投递。offending 是导致崩溃的消息，没有时为 nil。
+// 引擎的控制消息（例如 Poison 发送的 poisonPill）总是被保留，不计入 MaxMessages。
+func (p *process) crashBuffer(pending, msgs []Envelope, offending *Envelope) []Envelope {
+	policy := p.Opts.CrashBuffer
+	buf := make([]Envelope, 0, len(pending)+len(msgs)+1)
+	if offending != nil && policy.RetryOffending && !isControlMessage(offending.Msg) {
+		buf = append(buf, *offending)
+	}
+	buf = append(buf, pending...)
+	buf = append(buf, msgs...)
+	if !policy.DiscardOnRestart && (policy.MaxMessages <= 0 || len(buf) <= policy.MaxMessages) {
+		return buf
+	}
+
+	kept, n := buf[:0], 0
+	for _, env := range buf {
+		switch {
+		case isControlMessage(env.Msg):
+			kept = append(kept, env)
+		case !policy.DiscardOnRestart && n < policy.MaxMessages:
+			kept = append(kept, env)
+			n++
+		default:
+			p.context.engine.deadLetter(p.pid, env.Msg, env.Sender)
+		}
+	}
+	return kept
+}
+
+// isControlMessage 判断给定的消息是否为引擎私有的控制消息。
+func isControlMessage(msg any) bool {
+	switch msg.(type) {
+	case poisonPill, supervisorRestart, escalatedFailure, *Watch, *Unwatch:
+		return true
+	}
+	return false
+}
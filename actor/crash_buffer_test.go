@@ -0,0 +1,102 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crashAfterBlock 创建一个 actor：第一条 "block" 消息阻塞直到 release 被关闭，
+// 使后续消息在同一批次中被处理；"crash" 消息在第一次收到时 panic。
+func crashAfterBlock(t *testing.T, e *Engine, policy CrashBufferPolicy) (pid *PID, release chan struct{}, got func() []any) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		received []any
+		crashed  bool
+		blocked  = make(chan struct{})
+	)
+	release = make(chan struct{})
+	pid = e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case string:
+			switch msg {
+			case "block":
+				close(blocked)
+				<-release
+				return
+			case "crash":
+				mu.Lock()
+				first := !crashed
+				crashed = true
+				mu.Unlock()
+				if first {
+					panic("boom")
+				}
+			}
+			mu.Lock()
+			received = append(received, msg)
+			mu.Unlock()
+		case int:
+			mu.Lock()
+			received = append(received, msg)
+			mu.Unlock()
+		}
+	}, "crashy", WithRestartDelay(0), WithCrashBufferPolicy(policy))
+	e.Send(pid, "block")
+	<-blocked
+	e.Send(pid, "crash")
+	for i := 1; i <= 5; i++ {
+		e.Send(pid, i)
+	}
+	return pid, release, func() []any {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]any(nil), received...)
+	}
+}
+
+func TestCrashBufferDefault(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid, release, got := crashAfterBlock(t, e, CrashBufferPolicy{})
+	close(release)
+	<-e.Poison(pid).Done()
+	assert.Equal(t, []any{1, 2, 3, 4, 5}, got())
+}
+
+func TestCrashBufferRetryOffending(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid, release, got := crashAfterBlock(t, e, CrashBufferPolicy{RetryOffending: true})
+	close(release)
+	<-e.Poison(pid).Done()
+	assert.Equal(t, []any{"crash", 1, 2, 3, 4, 5}, got())
+}
+
+func TestCrashBufferMaxMessages(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterRetention(10))
+	require.NoError(t, err)
+	pid, release, got := crashAfterBlock(t, e, CrashBufferPolicy{MaxMessages: 2})
+	close(release)
+	<-e.Poison(pid).Done()
+	assert.Equal(t, []any{1, 2}, got())
+	assert.Eventually(t, func() bool { return e.Deadletters().Len() == 3 }, time.Second, time.Millisecond)
+}
+
+func TestCrashBufferDiscardOnRestart(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterRetention(10))
+	require.NoError(t, err)
+	pid, release, got := crashAfterBlock(t, e, CrashBufferPolicy{DiscardOnRestart: true})
+	close(release)
+	<-e.Poison(pid).Done()
+	assert.Empty(t, got())
+	msgs := make([]any, 0, 5)
+	for _, evt := range e.Deadletters().Drain() {
+		msgs = append(msgs, evt.Message)
+	}
+	assert.Equal(t, []any{1, 2, 3, 4, 5}, msgs)
+}
@@ -0,0 +1,97 @@
+package actor
+
+import "sync"
+
+// deadLetter 处理无法投递的消息。如果配置了死信处理器，
+// 消息将以 DeadLetterEvent 的形式投递给它，否则通过事件流广播。
+func (e *Engine) deadLetter(target *PID, msg any, sender *PID) {
+	evt := DeadLetterEvent{
+		Target:  target,
+		Message: msg,
+		Sender:  sender,
+	}
+	e.deadLetters.add(evt)
+	// 死信处理器自身已停止时回退到广播，避免无限递归。
+	if e.deadLetterPID != nil && !target.Equals(e.deadLetterPID) && e.Registry.get(e.deadLetterPID) != nil {
+		e.SendLocal(e.deadLetterPID, evt, nil)
+		return
+	}
+	e.BroadcastEvent(evt)
+}
+
+// DeadLetter 把一条无法投递的消息交给死信处理，供远程等扩展模块使用。
+func (e *Engine) DeadLetter(target *PID, msg any, sender *PID) {
+	e.deadLetter(target, msg, sender)
+}
+
+// DeadLetters 保留最近 N 条无法投递的消息，以便目标 actor 出现后重新投递。
+// 通过 EngineConfig.WithDeadLetterRetention 启用，未启用时不保留任何消息。
+type DeadLetters struct {
+	engine *Engine
+	mu     sync.Mutex
+	size   int
+	// letters 按时间顺序保存死信，超过 size 时丢弃最旧的。
+	letters []DeadLetterEvent
+}
+
+func newDeadLetters(e *Engine, size int) *DeadLetters {
+	return &DeadLetters{
+		engine: e,
+		size:   size,
+	}
+}
+
+// add 保留一条死信。内部的 poisonPill 不会被保留。
+func (d *DeadLetters) add(evt DeadLetterEvent) {
+	if d.size <= 0 {
+		return
+	}
+	if _, ok := evt.Message.(poisonPill); ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.letters) == d.size {
+		d.letters = append(d.letters[:0], d.letters[1:]...)
+	}
+	d.letters = append(d.letters, evt)
+}
+
+// Len 返回当前保留的死信数量。
+func (d *DeadLetters) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.letters)
+}
+
+// Replay 将所有发往给定 PID 的死信按原顺序重新投递给它（保留原始发送者），
+// 并从保留缓冲区中移除。返回重新投递的消息数量。
+// 如果此时目标仍然不存在，这些消息会再次成为死信。
+func (d *DeadLetters) Replay(pid *PID) int {
+	d.mu.Lock()
+	var replay []DeadLetterEvent
+	kept := d.letters[:0]
+	for _, evt := range d.letters {
+		if evt.Target.Equals(pid) {
+			replay = append(replay, evt)
+			continue
+		}
+		kept = append(kept, evt)
+	}
+	d.letters = kept
+	d.mu.Unlock()
+
+	for _, evt := range replay {
+		d.engine.SendWithSender(pid, evt.Message, evt.Sender)
+	}
+	return len(replay)
+}
+
+// Drain 移除并返回所有保留的死信。
+func (d *DeadLetters) Drain() []DeadLetterEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letters := d.letters
+	d.letters = nil
+	return letters
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestDeadLetterCustom tests the custom deadletter handling.
@@ -27,6 +29,50 @@ func TestDeadLetterCustom(t *testing.T) {
 	wg.Wait()
 }
 
+func TestDeadLetterHandler(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		letters []DeadLetterEvent
+		wg      sync.WaitGroup
+	)
+	wg.Add(2)
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterHandler(newFuncReceiver(func(c *Context) {
+		if msg, ok := c.Message().(DeadLetterEvent); ok {
+			mu.Lock()
+			letters = append(letters, msg)
+			mu.Unlock()
+			wg.Done()
+		}
+	})))
+	require.NoError(t, err)
+
+	// 默认的事件流订阅者不应再收到死信。
+	broadcasted := make(chan struct{}, 2)
+	sub := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(DeadLetterEvent); ok {
+			broadcasted <- struct{}{}
+		}
+	}, "subscriber")
+	e.Subscribe(sub)
+
+	sender := NewPID(LocalLookupAddr, "sender")
+	missing := NewPID(LocalLookupAddr, "missing/1")
+	e.SendWithSender(missing, "hello", sender)
+	e.Send(missing, 42)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, letters, 2)
+	assert.Equal(t, DeadLetterEvent{Target: missing, Message: "hello", Sender: sender}, letters[0])
+	assert.Equal(t, 42, letters[1].Message)
+	select {
+	case <-broadcasted:
+		t.Fatal("死信不应通过事件流广播")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
 // SafeBuffer is a threadsafe buffer, used for testing the that the deadletters are logged.
 type SafeBuffer struct {
 	buf bytes.Buffer
@@ -51,3 +97,40 @@ func invalidPid() *PID {
 		ID:      "squirrel",
 	}
 }
+
+func TestDeadLetterRetentionReplay(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterRetention(3))
+	require.NoError(t, err)
+	target := NewPID(LocalLookupAddr, "worker/1")
+	other := NewPID(LocalLookupAddr, "other/1")
+	sender := NewPID(LocalLookupAddr, "sender/1")
+	for i := 0; i < 4; i++ {
+		e.SendWithSender(target, i, sender)
+	}
+	e.Send(other, "x")
+	// 只保留最近 3 条：2, 3, "x"。
+	assert.Equal(t, 3, e.Deadletters().Len())
+
+	received := make(chan Envelope, 2)
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			received <- Envelope{Msg: c.Message(), Sender: c.Sender()}
+		}
+	}, "worker", WithID("1"))
+	assert.Equal(t, 2, e.Deadletters().Replay(target))
+	assert.Equal(t, Envelope{Msg: 2, Sender: sender}, <-received)
+	assert.Equal(t, Envelope{Msg: 3, Sender: sender}, <-received)
+
+	letters := e.Deadletters().Drain()
+	require.Len(t, letters, 1)
+	assert.Equal(t, "x", letters[0].Message)
+	assert.Equal(t, 0, e.Deadletters().Len())
+}
+
+func TestDeadLetterRetentionDisabled(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	e.Send(invalidPid(), "bar")
+	assert.Equal(t, 0, e.Deadletters().Len())
+	assert.Equal(t, 0, e.Deadletters().Replay(invalidPid()))
+}
@@ -0,0 +1,79 @@
+package actor
+
+import "sync"
+
+// Watch 监视给定的 PID。当被监视的 actor 停止或因崩溃次数过多而永久停止时，
+// 当前 actor 将收到一条 *Terminated 消息。如果被监视的 actor 不存在，
+// 将立即收到 *Terminated。远程 PID 同样适用；如果远程节点变得不可达，
+// 所有对该节点上 actor 的监视都会收到 *Terminated。
+func (c *Context) Watch(pid *PID) {
+	if !c.engine.isLocalMessage(pid) {
+		c.engine.remoteWatches.add(pid, c.pid)
+	}
+	c.engine.SendWithSender(pid, &Watch{Watcher: c.pid}, c.pid)
+}
+
+// Unwatch 取消对给定 PID 的监视。
+func (c *Context) Unwatch(pid *PID) {
+	if !c.engine.isLocalMessage(pid) {
+		c.engine.remoteWatches.remove(pid, c.pid)
+	}
+	c.engine.SendWithSender(pid, &Unwatch{Watcher: c.pid}, c.pid)
+}
+
+// remoteWatch 是一条本地 actor 对远程 PID 的监视记录。
+type remoteWatch struct {
+	target  *PID
+	watcher *PID
+}
+
+// remoteWatches 记录本地 actor 对远程 PID 的监视，
+// 以便在远程节点不可达时通知监视者。
+type remoteWatches struct {
+	mu sync.Mutex
+	// byAddr 是远程地址到该地址上所有监视记录的映射。
+	byAddr map[string][]remoteWatch
+}
+
+func newRemoteWatches() *remoteWatches {
+	return &remoteWatches{
+		byAddr: make(map[string][]remoteWatch),
+	}
+}
+
+func (w *remoteWatches) add(target, watcher *PID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, rw := range w.byAddr[target.Address] {
+		if rw.target.Equals(target) && rw.watcher.Equals(watcher) {
+			return
+		}
+	}
+	w.byAddr[target.Address] = append(w.byAddr[target.Address], remoteWatch{target: target, watcher: watcher})
+}
+
+func (w *remoteWatches) remove(target, watcher *PID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watches := w.byAddr[target.Address]
+	for i, rw := range watches {
+		if rw.target.Equals(target) && rw.watcher.Equals(watcher) {
+			watches = append(watches[:i], watches[i+1:]...)
+			break
+		}
+	}
+	if len(watches) == 0 {
+		delete(w.byAddr, target.Address)
+		return
+	}
+	w.byAddr[target.Address] = watches
+}
+
+// take 移除并返回给定远程地址上的所有监视记录。
+func (w *remoteWatches) take(address string) []remoteWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	watches := w.byAddr[address]
+	delete(w.byAddr, address)
+	return watches
+}
@@ -0,0 +1,100 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchTerminatedOnPoison(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg      sync.WaitGroup
+		watched = e.SpawnFunc(func(c *Context) {}, "watched")
+	)
+	wg.Add(1)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.Watch(watched)
+			c.Send(c.PID(), "watching")
+		case string:
+			e.Poison(watched)
+		case *Terminated:
+			assert.True(t, watched.Equals(msg.PID))
+			wg.Done()
+		}
+	}, "watcher")
+	wg.Wait()
+}
+
+func TestWatchTerminatedOnMaxRestarts(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	watched := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			panic("boom")
+		}
+	}, "watched", WithMaxRestarts(0))
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.Watch(watched)
+			c.Send(watched, "crash")
+		case *Terminated:
+			assert.True(t, watched.Equals(msg.PID))
+			wg.Done()
+		}
+	}, "watcher")
+	wg.Wait()
+}
+
+func TestWatchNonExistingPID(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.Watch(invalidPid())
+		case *Terminated:
+			assert.True(t, invalidPid().Equals(msg.PID))
+			wg.Done()
+		}
+	}, "watcher")
+	wg.Wait()
+}
+
+func TestUnwatch(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		terminated = make(chan struct{}, 1)
+		unwatched  = make(chan struct{})
+	)
+	watched := e.SpawnFunc(func(c *Context) {}, "watched")
+	e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			c.Watch(watched)
+			c.Unwatch(watched)
+			close(unwatched)
+		case *Terminated:
+			terminated <- struct{}{}
+		}
+	}, "watcher")
+	<-unwatched
+	<-e.Poison(watched).Done()
+	select {
+	case <-terminated:
+		t.Fatal("unexpected Terminated after Unwatch")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,84 @@
+package actor
+
+import "sync"
+
+// WithDedicatedGoroutine 使 actor 拥有一个专用的、常驻的处理 goroutine，
+// 收件箱的每轮处理都在这个 goroutine 中执行，而不是每次调度都创建新的 goroutine
+// 或与其他 actor 共享工作池。适用于对尾延迟敏感的 actor（例如远程模块的流路由器），
+// 代价是 actor 存活期间始终占用一个 goroutine。actor 停止时该 goroutine 退出。
+// 设置后 WithScheduler 和引擎的默认调度器对该 actor 不再生效，WithThroughput 仍然有效。
+func WithDedicatedGoroutine() OptFunc {
+	return func(opts *Opts) {
+		opts.DedicatedGoroutine = true
+	}
+}
+
+// dedicatedScheduler 在一个专用的 goroutine 中执行调度的函数。
+// 收件箱同一时刻最多只有一次未完成的调度，因此容量为 1 的 channel 足够。
+type dedicatedScheduler struct {
+	throughput int
+	work       chan func()
+	done       chan struct{}
+	startOnce  sync.Once
+	stopOnce   sync.Once
+}
+
+// newDedicatedScheduler 创建一个专用调度器。goroutine 在第一次调度时才启动，
+// 因此从未启动的进程（例如 ID 重复）不会泄漏 goroutine。
+func newDedicatedScheduler(throughput int) *dedicatedScheduler {
+	if throughput <= 0 {
+		throughput = defaultThroughput
+	}
+	return &dedicatedScheduler{
+		throughput: throughput,
+		work:       make(chan func(), 1),
+		done:       make(chan struct{}),
+	}
+}
+
+// Schedule 将函数交给专用 goroutine 执行。调度器停止后函数将在新的 goroutine 中执行。
+func (s *dedicatedScheduler) Schedule(fn func()) {
+	s.startOnce.Do(func() {
+		go s.loop()
+	})
+	select {
+	case <-s.done:
+		go fn()
+		return
+	default:
+	}
+	select {
+	case s.work <- fn:
+	case <-s.done:
+		go fn()
+	}
+}
+
+// Throughput 返回调度器的吞吐量。
+func (s *dedicatedScheduler) Throughput() int {
+	return s.throughput
+}
+
+// stop 使专用 goroutine 退出。可以在专用 goroutine 中调用。
+func (s *dedicatedScheduler) stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *dedicatedScheduler) loop() {
+	for {
+		select {
+		case fn := <-s.work:
+			fn()
+		case <-s.done:
+			// 执行停止前已经入队的函数。
+			select {
+			case fn := <-s.work:
+				go fn()
+			default:
+			}
+			return
+		}
+	}
+}
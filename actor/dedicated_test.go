@@ -0,0 +1,60 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedicatedGoroutine(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	const n = 100
+	var wg sync.WaitGroup
+	wg.Add(n)
+	var got []int
+	pid := e.SpawnFunc(func(c *Context) {
+		if i, ok := c.Message().(int); ok {
+			got = append(got, i)
+			wg.Done()
+		}
+	}, "dedicated", WithDedicatedGoroutine(), WithThroughput(8))
+
+	p, ok := e.Registry.get(pid).(*process)
+	require.True(t, ok)
+	require.NotNil(t, p.dedicated)
+	assert.Equal(t, 8, p.dedicated.Throughput())
+
+	for i := 0; i < n; i++ {
+		e.Send(pid, i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		assert.Equal(t, i, got[i])
+	}
+
+	<-e.Poison(pid).Done()
+	select {
+	case <-p.dedicated.done:
+	case <-time.After(time.Second):
+		t.Fatal("专用 goroutine 未停止")
+	}
+}
+
+func TestDedicatedSchedulerStop(t *testing.T) {
+	s := newDedicatedScheduler(0)
+	require.Equal(t, defaultThroughput, s.Throughput())
+	ran := make(chan struct{})
+	s.Schedule(func() { close(ran) })
+	<-ran
+	s.stop()
+	s.stop()
+	// 停止后调度的函数仍然会被执行。
+	done := make(chan struct{})
+	s.Schedule(func() { close(done) })
+	<-done
+}
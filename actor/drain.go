@@ -0,0 +1,102 @@
+package actor
+
+import (
+	"math"
+
+	"github.com/TAnNbR/Distributed-framework/ringbuffer"
+)
+
+// WithDrainTo 在 actor 停止时把尚未处理的消息（暂存的消息、当前批次中剩余的消息
+// 以及收件箱中的消息）按原顺序转发给 successor，而不是丢弃或作为死信。
+// 这样在热替换 actor 实现时不会丢失消息。actor 因崩溃被停止时，崩溃缓冲的消息同样会被转发。
+// 转发保留原始的发送者和关联 ID，引擎内部的控制消息不会被转发。
+func WithDrainTo(successor *PID) OptFunc {
+	return func(opts *Opts) {
+		opts.DrainTo = successor
+	}
+}
+
+// inboxDrainer 由能够在停止后取出剩余消息的收件箱实现。
+// drain 可以与处理消息的 goroutine 并发调用，例如强制停止阻塞的 actor 时。
+type inboxDrainer interface {
+	drain(dst []Envelope) []Envelope
+}
+
+// handOff 把停止时尚未处理的消息转发给 DrainTo。pending 是当前批次中尚未处理的消息。
+func (p *process) handOff(stash, pending []Envelope) {
+	msgs := append(stash, pending...)
+	if in, ok := p.inbox.(inboxDrainer); ok {
+		msgs = in.drain(msgs)
+	}
+	e := p.context.engine
+	for _, env := range msgs {
+		if isControlMessage(env.Msg) {
+			p.releaseControl(env.Msg)
+			continue
+		}
+		e.sendCorrelated(p.DrainTo, env.Msg, env.Sender, env.CorrelationID)
+	}
+}
+
+// drainInbox 取出收件箱中剩余的所有消息并追加到 dst。
+func (p *process) drainInbox(dst []Envelope) []Envelope {
+	if in, ok := p.inbox.(inboxDrainer); ok {
+		return in.drain(dst)
+	}
+	return dst
+}
+
+// discard 处理进程终止后不会再被处理的消息：取消其中的 poisonPill，
+// 使所有等待 Poison(pid).Done() 的调用者返回，其余的用户消息作为死信投递。
+func (p *process) discard(msgs []Envelope) {
+	for _, env := range msgs {
+		if isControlMessage(env.Msg) {
+			p.releaseControl(env.Msg)
+			continue
+		}
+		p.context.engine.deadLetter(p.pid, env.Msg, env.Sender)
+	}
+}
+
+// releaseControl 处理进程终止后剩余的控制消息：取消 poisonPill 的 context，
+// 并通知迟到的监视者进程已经终止。
+func (p *process) releaseControl(msg any) {
+	switch m := msg.(type) {
+	case poisonPill:
+		if m.cancel != nil {
+			m.cancel()
+		}
+	case *Watch:
+		p.context.engine.Send(m.Watcher, &Terminated{PID: p.pid})
+	}
+}
+
+// drain 取出收件箱中剩余的所有消息并追加到 dst。
+func (in *Inbox) drain(dst []Envelope) []Envelope {
+	if rest, ok := in.rb.PopN(in.rb.Len()); ok {
+		dst = append(dst, rest...)
+	}
+	return dst
+}
+
+// drain 取出收件箱中剩余的所有消息并追加到 dst。
+func (in *MPSCInbox) drain(dst []Envelope) []Envelope {
+	in.consumer.Lock()
+	defer in.consumer.Unlock()
+	return in.queue.popN(dst, int(in.queue.len.Load()))
+}
+
+// drain 按优先级取出收件箱中剩余的所有消息并追加到 dst。
+func (in *PriorityInbox) drain(dst []Envelope) []Envelope {
+	for _, rb := range []*ringbuffer.RingBuffer[Envelope]{in.system, in.high, in.normal} {
+		if rest, ok := rb.PopN(rb.Len()); ok {
+			dst = append(dst, rest...)
+		}
+	}
+	return dst
+}
+
+// drain 按发送顺序取出收件箱中剩余的所有消息（包括系统消息）并追加到 dst。
+func (in *BoundedInbox) drain(dst []Envelope) []Envelope {
+	return in.take(dst, math.MaxInt)
+}
@@ -0,0 +1,63 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainTo(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	var (
+		mu  sync.Mutex
+		got []int
+		wg  sync.WaitGroup
+	)
+	wg.Add(3)
+	successor := e.SpawnFunc(func(c *Context) {
+		if i, ok := c.Message().(int); ok {
+			mu.Lock()
+			got = append(got, i)
+			mu.Unlock()
+			assert.Equal(t, "sender", c.Sender().ID)
+			wg.Done()
+		}
+	}, "successor")
+
+	gate := make(chan struct{})
+	blocked := make(chan struct{})
+	var processed []int
+	pid := e.SpawnFunc(func(c *Context) {
+		switch m := c.Message().(type) {
+		case string:
+			close(blocked)
+			<-gate
+		case int:
+			if m == 1 {
+				// 暂存的消息同样会被转发，并且排在最前面。
+				c.Stash()
+				return
+			}
+			processed = append(processed, m)
+		}
+	}, "old", WithDrainTo(successor))
+
+	sender := NewPID(e.Address(), "sender")
+	e.Send(pid, "block")
+	<-blocked
+	e.SendWithSender(pid, 1, sender)
+	e.SendWithSender(pid, 2, sender)
+	done := e.Stop(pid)
+	e.SendWithSender(pid, 3, sender)
+	e.SendWithSender(pid, 4, sender)
+	close(gate)
+	<-done.Done()
+	wg.Wait()
+
+	assert.Equal(t, []int{2}, processed)
+	assert.Equal(t, []int{1, 3, 4}, got)
+}
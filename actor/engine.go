@@ -2,11 +2,12 @@ package actor
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
+	"log/slog"
 	"math/rand"
-	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -34,11 +35,58 @@ type Engine struct {
 	address     string
 	remote      Remoter
 	eventStream *PID
+	// remoteWatches 记录本地 actor 对远程 PID 的监视。
+	remoteWatches *remoteWatches
+	// journal 是持久化 actor 默认使用的事件存储。
+	journal Journal
+	// snapshotStore 是持久化 actor 默认使用的快照存储。
+	snapshotStore SnapshotStore
+	// deadLetterPID 是自定义死信处理器的 PID，为 nil 时死信通过事件流广播。
+	deadLetterPID *PID
+	// deadLetters 保留最近的死信以便重新投递。
+	deadLetters *DeadLetters
+	// panicHandler 是 actor 崩溃时默认调用的钩子。
+	panicHandler PanicHandler
+	// scheduler 是未指定调度器的 actor 默认使用的调度器，为 nil 时使用 goroutine 调度器。
+	scheduler Scheduler
+	// autoSpawner 保存通过 RegisterAutoSpawn 注册的按需创建的 actor 类型。
+	autoSpawner autoSpawner
+	// clock 是引擎计时使用的时钟。
+	clock Clock
+	// globalMiddleware 作用于每个非系统 actor。
+	globalMiddleware []MiddlewareFunc
+	// spawnInterceptors 在每次创建非系统 actor 时调用。
+	spawnInterceptors []SpawnInterceptor
+	// slowThreshold 是非系统 actor 默认的慢 actor 检测阈值，为 0 时不检测。
+	slowThreshold time.Duration
+	// backlogThreshold 是非系统 actor 默认的收件箱积压告警阈值，为 0 时不告警。
+	backlogThreshold int
+	// logger 是引擎及其 actor、远程和集群模块使用的日志记录器。
+	logger *slog.Logger
+	// requests 记录请求的统计信息。
+	requests requestStats
+	// remoteRequests 记录向远程 PID 发起的、等待响应的请求。
+	remoteRequests *remoteRequests
+	// shuttingDown 在 Shutdown 开始后为 true，此后不再接受新的 actor。
+	shuttingDown atomic.Bool
 }
 
 // EngineConfig 保存引擎的配置信息。
 type EngineConfig struct {
-	remote Remoter // Remoter 是上面在本文件开头定义的接口类型
+	remote        Remoter       // Remoter 是上面在本文件开头定义的接口类型
+	journal       Journal       // 持久化 actor 默认使用的事件存储
+	snapshotStore SnapshotStore // 持久化 actor 默认使用的快照存储
+	deadLetter    Producer      // 自定义死信处理器
+	deadLetterCap int           // 保留的死信数量
+	panicHandler  PanicHandler  // actor 崩溃时默认调用的钩子
+	logger        *slog.Logger  // 引擎使用的日志记录器
+	scheduler     Scheduler     // actor 收件箱默认使用的调度器
+	clock         Clock         // 引擎计时使用的时钟
+
+	globalMiddleware  []MiddlewareFunc   // 作用于每个 actor 的中间件
+	spawnInterceptors []SpawnInterceptor // 每次创建 actor 时调用的拦截器
+	slowThreshold     time.Duration      // 慢 actor 检测的默认阈值
+	backlogThreshold  int                // 收件箱积压告警的默认阈值
 }
 
 // NewEngineConfig 返回一个新的默认 EngineConfig。
@@ -52,9 +100,93 @@ func (config EngineConfig) WithRemote(remote Remoter) EngineConfig {
 	return config
 }
 
+// WithJournal 设置持久化 actor 默认使用的事件存储。未设置时使用 MemoryJournal。
+func (config EngineConfig) WithJournal(j Journal) EngineConfig {
+	config.journal = j
+	return config
+}
+
+// WithSnapshotStore 设置持久化 actor 默认使用的快照存储。未设置时使用 MemorySnapshotStore。
+func (config EngineConfig) WithSnapshotStore(s SnapshotStore) EngineConfig {
+	config.snapshotStore = s
+	return config
+}
+
+// WithDeadLetterHandler 注册一个自定义的死信处理器。引擎会将其作为系统 actor 创建，
+// 之后每条无法投递的消息都会以 DeadLetterEvent 的形式投递给它，而不再通过事件流广播。
+// 处理器可以持久化、重新路由或统计这些消息，也可以自行调用 BroadcastEvent。
+func (config EngineConfig) WithDeadLetterHandler(p Producer) EngineConfig {
+	config.deadLetter = p
+	return config
+}
+
+// WithDeadLetterRetention 使引擎保留最近 n 条无法投递的消息，
+// 之后可以通过 Engine.Deadletters 重新投递或取出它们。
+func (config EngineConfig) WithDeadLetterRetention(n int) EngineConfig {
+	config.deadLetterCap = n
+	return config
+}
+
+// WithLogger 设置引擎使用的日志记录器。引擎内部的进程、事件流以及绑定到该引擎的
+// 远程和集群模块都会使用它记录日志。未设置时使用 slog.Default()。
+// 同一进程中的多个引擎（以及测试）可以借此独立地路由或屏蔽日志。
+func (config EngineConfig) WithLogger(l *slog.Logger) EngineConfig {
+	config.logger = l
+	return config
+}
+
+// WithScheduler 设置 actor 收件箱默认使用的调度器。actor 自身通过 WithScheduler
+// 或 WithThroughput 指定的调度器优先。
+func (config EngineConfig) WithScheduler(s Scheduler) EngineConfig {
+	config.scheduler = s
+	return config
+}
+
+// WithSynchronousDispatch 使引擎中的所有 actor 共享一个 SyncScheduler：
+// 消息在 Send 时立即在调用者的 goroutine 中处理，多个 actor 之间的交互按确定的
+// 顺序执行，适合编写不依赖等待和超时的单元测试。限制参见 SyncScheduler。
+func (config EngineConfig) WithSynchronousDispatch() EngineConfig {
+	config.scheduler = NewSyncScheduler()
+	return config
+}
+
+// WithClock 设置引擎计时使用的时钟，默认为 RealClock()。
+// 测试中可以传入 ManualClock，手动推进定时发送、接收超时和重启延迟。
+func (config EngineConfig) WithClock(c Clock) EngineConfig {
+	config.clock = c
+	return config
+}
+
 // NewEngine 根据给定的 EngineConfig 返回一个新的 Actor 引擎。
 func NewEngine(config EngineConfig) (*Engine, error) {
-	e := &Engine{}
+	e := &Engine{
+		remoteWatches: newRemoteWatches(),
+		journal:       config.journal,
+		snapshotStore: config.snapshotStore,
+		panicHandler:  config.panicHandler,
+		logger:        config.logger,
+		scheduler:     config.scheduler,
+		clock:         config.clock,
+
+		globalMiddleware:  config.globalMiddleware,
+		spawnInterceptors: config.spawnInterceptors,
+		slowThreshold:     config.slowThreshold,
+		backlogThreshold:  config.backlogThreshold,
+		remoteRequests:    newRemoteRequests(),
+	}
+	if e.clock == nil {
+		e.clock = RealClock()
+	}
+	if e.logger == nil {
+		e.logger = slog.Default()
+	}
+	if e.journal == nil {
+		e.journal = NewMemoryJournal()
+	}
+	if e.snapshotStore == nil {
+		e.snapshotStore = NewMemorySnapshotStore()
+	}
+	e.deadLetters = newDeadLetters(e, config.deadLetterCap)
 	e.Registry = newRegistry(e) // 需要初始化注册表，以便我们可以自定义死信处理
 	e.address = LocalLookupAddr
 	if config.remote != nil {
@@ -65,24 +197,27 @@ func NewEngine(config EngineConfig) (*Engine, error) {
 			return nil, fmt.Errorf("启动远程模块失败: %w", err)
 		}
 	}
-	e.eventStream = e.Spawn(newEventStream(), "eventstream")
+	e.eventStream = e.Spawn(newEventStream(), "eventstream", WithSystem())
+	if config.deadLetter != nil {
+		opts := DefaultOpts(config.deadLetter)
+		opts.Kind = "deadletter"
+		opts.ID = "1"
+		opts.System = true
+		proc := newProcess(e, opts)
+		// 先记录 PID 再启动，使处理器启动期间产生的死信也能投递给它。
+		e.deadLetterPID = proc.PID()
+		e.SpawnProc(proc)
+	}
 	return e, nil
 }
 
 // Spawn 创建一个由给定 Producer 生产的进程，可以通过 opts 进行配置。
+// 即使 actor 没有被注册（例如 ID 重复时只会广播 ActorDuplicateIdEvent），也会返回它的 PID，
+// 需要确认创建结果时请使用 SpawnChecked。
 func (e *Engine) Spawn(p Producer, kind string, opts ...OptFunc) *PID {
-	// 首先生成默认的 Option 配置，该配置会基于当前的 Producer（即要生成的 actor 实例/工厂）做初始化
-	// 调用 DefaultOpts(p)，将 Producer p 封装进 options 中（options.Producer = p 等），
-	// 以便后续 process 创建时能通过 Producer 生成具体的 Receiver（即 actor 实例/消息接受者）
-	options := DefaultOpts(p)
-	options.Kind = kind
-	for _, opt := range opts {
-		opt(&options)
-	}
-	// 检查是否有 ID，没有则生成一个
-	if len(options.ID) == 0 {
-		id := strconv.Itoa(rand.Intn(math.MaxInt))
-		options.ID = id
+	options := newOpts(p, kind, opts)
+	if err := e.interceptSpawn(&options); err != nil {
+		return e.rejectSpawn(options, err)
 	}
 	proc := newProcess(e, options)
 	return e.SpawnProc(proc)
@@ -95,11 +230,26 @@ func (e *Engine) SpawnFunc(f func(*Context), kind string, opts ...OptFunc) *PID
 
 // SpawnProc 创建给定的 Processer。这个函数在处理自定义创建的 Process 时很有用。
 // 可以参考 streamWriter 作为示例。
+// 引擎关闭期间，新的 actor 进程将被拒绝，返回的 PID 不会被注册。
 func (e *Engine) SpawnProc(p Processer) *PID {
+	if _, ok := p.(*process); ok && e.shuttingDown.Load() {
+		e.logger.Warn("引擎正在关闭，拒绝创建 actor", "pid", p.PID())
+		return p.PID()
+	}
 	e.Registry.add(p)
 	return p.PID()
 }
 
+// Clock 返回引擎计时使用的时钟。
+func (e *Engine) Clock() Clock {
+	return e.clock
+}
+
+// Logger 返回引擎使用的日志记录器。
+func (e *Engine) Logger() *slog.Logger {
+	return e.logger
+}
+
 // Address 返回 Actor 引擎的地址。当没有配置远程模块时，
 // 使用 "local" 地址，否则使用远程的监听地址。
 func (e *Engine) Address() string {
@@ -107,12 +257,43 @@ func (e *Engine) Address() string {
 }
 
 // Request 将给定的消息作为"请求"发送给给定的 PID，返回一个将来会解析的响应。
-// 调用 Response.Result() 将阻塞直到超时或响应被解析。
+// 调用 Response.Result() 将阻塞直到超时或响应被解析。超时由引擎的 Clock 计时，
+// 即使没有人调用 Result，到期时也会注销响应进程并广播 RequestTimeoutEvent。
+// 目标是远程 PID 时，Response 不注册到 Registry：请求消息携带请求 ID，
+// 远程的响应带回同一个 ID，按 ID 直接交给 Response。
 func (e *Engine) Request(pid *PID, msg any, timeout time.Duration) *Response {
+	return e.request(pid, msg, timeout, "")
+}
+
+// request 发起请求，请求消息携带给定的关联 ID。
+func (e *Engine) request(pid *PID, msg any, timeout time.Duration, correlationID string) *Response {
 	resp := NewResponse(e, timeout)
-	e.Registry.add(resp)
+	resp.track(pid, msg)
+	e.addResponse(pid, resp)
+	resp.armTimeout()
 
-	e.SendWithSender(pid, msg, resp.PID())
+	e.sendRequest(pid, msg, resp, correlationID)
+
+	return resp
+}
+
+// RequestCtx 与 Request 相同，但请求的生命周期由 ctx 决定：ctx 被取消或到期时，
+// Response.Result 立即返回，并且即使没有人调用 Result，响应进程也会被及时注销，
+// 避免被放弃的请求使注册表不断增长。
+func (e *Engine) RequestCtx(ctx context.Context, pid *PID, msg any) *Response {
+	resp := NewResponse(e, 0)
+	resp.ctx = ctx
+	resp.track(pid, msg)
+	e.addResponse(pid, resp)
+	resp.stopCleanup = context.AfterFunc(ctx, func() {
+		resp.unregister()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			resp.expire()
+		}
+		resp.finish()
+	})
+
+	e.sendRequest(pid, msg, resp, "")
 
 	return resp
 }
@@ -131,19 +312,30 @@ func (e *Engine) Send(pid *PID, msg any) {
 
 // BroadcastEvent 将给定的消息广播到事件流，通知所有订阅的 actor。
 func (e *Engine) BroadcastEvent(msg any) {
+	// 远程节点不可达时，通知所有监视该节点上 actor 的本地监视者。
+	if evt, ok := msg.(RemoteUnreachableEvent); ok {
+		for _, w := range e.remoteWatches.take(evt.ListenAddr) {
+			e.SendLocal(w.watcher, &Terminated{PID: w.target}, nil)
+		}
+	}
 	if e.eventStream != nil {
 		e.send(e.eventStream, msg, nil)
 	}
 }
 
 func (e *Engine) send(pid *PID, msg any, sender *PID) {
+	e.sendCorrelated(pid, msg, sender, "")
+}
+
+// sendCorrelated 发送携带关联 ID 的消息。远程消息目前不携带关联 ID。
+func (e *Engine) sendCorrelated(pid *PID, msg any, sender *PID, correlationID string) {
 	// TODO: 我们可能需要在这里记录日志。还没决定什么是合理的。
 	// 发送到死信还是作为事件？死信可能更合理，因为目标不可达。
 	if pid == nil {
 		return
 	}
 	if e.isLocalMessage(pid) {
-		e.SendLocal(pid, msg, sender)
+		e.sendLocal(pid, msg, sender, correlationID)
 		return
 	}
 	if e.remote == nil {
@@ -157,29 +349,108 @@ func (e *Engine) send(pid *PID, msg any, sender *PID) {
 // 如果你需要让一个 actor 定期唤醒，可以使用 SendRepeater。
 // 它通过 SendRepeat 方法启动，通过其 Stop() 方法停止。
 type SendRepeater struct {
-	engine   *Engine
-	self     *PID
-	target   *PID
-	msg      any
-	interval time.Duration
-	cancelch chan struct{}
+	engine       *Engine
+	self         *PID
+	target       *PID
+	msg          any
+	interval     time.Duration
+	initialDelay time.Duration
+	jitter       time.Duration
+	cancelch     chan struct{}
+	resetch      chan time.Duration
+}
+
+// RepeatOptFunc 是 SendRepeat 的配置选项函数。
+type RepeatOptFunc func(*SendRepeater)
+
+// WithInitialDelay 设置第一次发送前的延迟，默认等于发送间隔。
+func WithInitialDelay(d time.Duration) RepeatOptFunc {
+	return func(sr *SendRepeater) {
+		sr.initialDelay = d
+	}
+}
+
+// WithJitter 为每次发送增加 [0, d) 的随机延迟，
+// 避免大量重复发送器（例如整个集群的心跳）在同一时刻触发。
+func WithJitter(d time.Duration) RepeatOptFunc {
+	return func(sr *SendRepeater) {
+		sr.jitter = d
+	}
+}
+
+func newSendRepeater(e *Engine, self, target *PID, msg any, interval time.Duration, opts []RepeatOptFunc) SendRepeater {
+	sr := SendRepeater{
+		engine:       e,
+		self:         self,
+		target:       target.CloneVT(),
+		interval:     interval,
+		initialDelay: interval,
+		msg:          msg,
+		cancelch:     make(chan struct{}, 1),
+		resetch:      make(chan time.Duration),
+	}
+	for _, opt := range opts {
+		opt(&sr)
+	}
+	sr.start()
+	return sr
+}
+
+// withJitter 返回加上随机抖动后的延迟。
+func (sr SendRepeater) withJitter(d time.Duration) time.Duration {
+	if sr.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(sr.jitter)))
+	}
+	return d
 }
 
 func (sr SendRepeater) start() {
-	ticker := time.NewTicker(sr.interval)
+	var (
+		// fire 接收到期计时器的代数，已被替换的计时器的触发将被忽略。
+		fire = make(chan uint64, 1)
+		gen  uint64
+	)
+	arm := func(d time.Duration) Timer {
+		gen++
+		g := gen
+		return sr.engine.clock.AfterFunc(sr.withJitter(d), func() {
+			select {
+			case fire <- g:
+			case <-sr.cancelch:
+			}
+		})
+	}
+	timer := arm(sr.initialDelay)
 	go func() {
+		interval := sr.interval
 		for {
 			select {
-			case <-ticker.C:
+			case g := <-fire:
+				if g != gen {
+					continue
+				}
 				sr.engine.SendWithSender(sr.target, sr.msg, sr.self)
+				timer = arm(interval)
+			case interval = <-sr.resetch:
+				timer.Stop()
+				timer = arm(interval)
 			case <-sr.cancelch:
-				ticker.Stop()
+				timer.Stop()
 				return
 			}
 		}
 	}()
 }
 
+// Reset 修改发送间隔，下一次发送将在新的间隔之后进行。
+// 对已停止的 SendRepeater 调用 Reset 不会产生任何效果。
+func (sr SendRepeater) Reset(interval time.Duration) {
+	select {
+	case sr.resetch <- interval:
+	case <-sr.cancelch:
+	}
+}
+
 // Stop 停止重复发送消息。
 func (sr SendRepeater) Stop() {
 	close(sr.cancelch)
@@ -187,18 +458,8 @@ func (sr SendRepeater) Stop() {
 
 // SendRepeat 将给定的消息以给定的间隔发送给给定的 PID。
 // 返回一个 SendRepeater 结构体，可以通过调用 Stop() 来停止重复发送。
-func (e *Engine) SendRepeat(pid *PID, msg any, interval time.Duration) SendRepeater {
-	clonedPID := *pid.CloneVT()
-	sr := SendRepeater{
-		engine:   e,
-		self:     nil,
-		target:   &clonedPID,
-		interval: interval,
-		msg:      msg,
-		cancelch: make(chan struct{}, 1),
-	}
-	sr.start()
-	return sr
+func (e *Engine) SendRepeat(pid *PID, msg any, interval time.Duration, opts ...RepeatOptFunc) SendRepeater {
+	return newSendRepeater(e, nil, pid, msg, interval, opts)
 }
 
 // Stop 向与给定 PID 关联的进程发送非优雅的 poisonPill 消息。
@@ -227,13 +488,9 @@ func (e *Engine) sendPoisonPill(ctx context.Context, graceful bool, pid *PID) co
 		cancel:   cancel,
 		graceful: graceful,
 	}
-	// 死信 - 如果我们没有找到进程，我们将广播一个 DeadletterEvent
+	// 死信 - 如果我们没有找到进程，我们将投递一个 DeadletterEvent
 	if e.Registry.get(pid) == nil {
-		e.BroadcastEvent(DeadLetterEvent{
-			Target:  pid,
-			Message: pill,
-			Sender:  nil,
-		})
+		e.deadLetter(pid, pill, nil)
 		cancel()
 		return ctx
 	}
@@ -244,24 +501,89 @@ func (e *Engine) sendPoisonPill(ctx context.Context, graceful bool, pid *PID) co
 // SendLocal 将给定的消息发送给给定的 PID。如果在注册表中找不到接收者，
 // 消息将被发送到死信进程。如果没有注册死信进程，函数将 panic。
 func (e *Engine) SendLocal(pid *PID, msg any, sender *PID) {
+	e.sendLocal(pid, msg, sender, "")
+}
+
+// sendLocal 将携带关联 ID 的消息发送给本地进程。
+func (e *Engine) sendLocal(pid *PID, msg any, sender *PID, correlationID string) {
+	e.deliverLocal(pid, Envelope{Msg: msg, Sender: sender, CorrelationID: correlationID})
+}
+
+// deliverLocal 将 env 投递给本地进程。
+func (e *Engine) deliverLocal(pid *PID, env Envelope) {
+	var (
+		msg    = env.Msg
+		sender = env.Sender
+	)
 	proc := e.Registry.get(pid)
 	if proc == nil {
-		// 广播死信消息
-		e.BroadcastEvent(DeadLetterEvent{
-			Target:  pid,
-			Message: msg,
-			Sender:  sender,
-		})
+		if resp := e.remoteRequests.takePID(pid); resp != nil {
+			resp.Send(pid, msg, sender)
+			return
+		}
+		proc = e.autoSpawn(pid, msg)
+	}
+	if proc == nil {
+		// 事件流已停止（引擎已关闭），丢弃消息以避免死信事件的无限递归。
+		if pid.Equals(e.eventStream) {
+			return
+		}
+		// 监视一个不存在的 actor，立即通知监视者它已终止。
+		if w, ok := msg.(*Watch); ok {
+			e.Send(w.Watcher, &Terminated{PID: pid})
+			return
+		}
+		e.deadLetter(pid, msg, sender)
+		return
+	}
+	if p, ok := proc.(*process); ok && (env.CorrelationID != "" || env.RequestID != 0) {
+		p.inbox.Send(env)
 		return
 	}
 	proc.Send(pid, msg, sender)
 }
 
+// GetPID 返回与给定 kind 和 id 关联的本地 actor 的 PID，未找到时返回 nil。
+func (e *Engine) GetPID(kind, id string) *PID {
+	return e.Registry.GetPID(kind, id)
+}
+
+// Lookup 返回 ID 与给定模式匹配的所有本地 actor 的 PID，例如 engine.Lookup("player/*")。
+// 模式语法参见 Registry.Lookup。
+func (e *Engine) Lookup(pattern string) []*PID {
+	return e.Registry.Lookup(pattern)
+}
+
+// Deadletters 返回引擎保留的死信。
+func (e *Engine) Deadletters() *DeadLetters {
+	return e.deadLetters
+}
+
 // Subscribe 将给定的 PID 订阅到事件流。
 func (e *Engine) Subscribe(pid *PID) {
 	e.Send(e.eventStream, eventSub{pid: pid})
 }
 
+// SubscribeFunc 以回调函数的方式订阅事件流。引擎会创建一个轻量的 actor，
+// 对每个事件调用 fn（同一时刻只有一个调用），返回的函数用于取消订阅并停止该 actor。
+func (e *Engine) SubscribeFunc(fn func(event any)) (unsubscribe func()) {
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Initialized, Started, Stopped:
+		default:
+			fn(c.Message())
+		}
+	}, "eventsub")
+	e.Subscribe(pid)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.Unsubscribe(pid)
+			<-e.Poison(pid).Done()
+		})
+	}
+}
+
 // Unsubscribe 将给定的 PID 从事件流取消订阅。
 func (e *Engine) Unsubscribe(pid *PID) {
 	e.Send(e.eventStream, eventUnsub{pid: pid})
@@ -2,8 +2,11 @@ package actor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -83,6 +86,45 @@ func TestSendRepeat(t *testing.T) {
 	repeater.Stop()
 }
 
+func TestSendRepeatInitialDelay(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	ticks := make(chan time.Time, 10)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(tick); ok {
+			ticks <- time.Now()
+		}
+	}, "test")
+	start := time.Now()
+	repeater := e.SendRepeat(pid, tick{}, time.Hour, WithInitialDelay(time.Millisecond))
+	defer repeater.Stop()
+	first := <-ticks
+	assert.Less(t, first.Sub(start), time.Hour)
+}
+
+func TestSendRepeatReset(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	pid := e.Spawn(newTickReceiver(wg), "test")
+	repeater := e.SendRepeat(pid, tick{}, time.Hour, WithJitter(time.Millisecond))
+	repeater.Reset(time.Millisecond * 2)
+	wg.Wait()
+	repeater.Stop()
+	// 对已停止的 SendRepeater 调用 Reset 不应阻塞。
+	repeater.Reset(time.Millisecond)
+}
+
+func TestSendRepeatJitter(t *testing.T) {
+	sr := SendRepeater{jitter: 10 * time.Millisecond}
+	for i := 0; i < 100; i++ {
+		d := sr.withJitter(time.Second)
+		assert.GreaterOrEqual(t, d, time.Second)
+		assert.Less(t, d, time.Second+10*time.Millisecond)
+	}
+}
+
 func TestRestartsMaxRestarts(t *testing.T) {
 	e, err := NewEngine(NewEngineConfig())
 	require.NoError(t, err)
@@ -392,6 +434,165 @@ func TestRequestResponse(t *testing.T) {
 	})
 }
 
+func TestRespondErr(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	errNotFound := errors.New("not found")
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			c.RespondErr(fmt.Errorf("lookup: %w", errNotFound))
+		}
+	}, "lookup")
+
+	_, err = e.Request(pid, "key", time.Second).Result()
+	assert.ErrorIs(t, err, errNotFound)
+	assert.NotErrorIs(t, err, ErrRequestTimeout)
+
+	_, err = e.Request(e.SpawnFunc(func(*Context) {}, "silent"), "key", time.Millisecond).Result()
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRequestCtx(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	echo := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			c.Respond(msg)
+		}
+	}, "echo")
+	silent := e.SpawnFunc(func(*Context) {}, "silent")
+
+	t.Run("response", func(t *testing.T) {
+		resp := e.RequestCtx(context.Background(), echo, "foo")
+		res, err := resp.Result()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", res)
+		assert.Nil(t, e.Registry.get(resp.pid))
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := e.RequestCtx(ctx, silent, "foo")
+		go func() {
+			time.Sleep(time.Millisecond)
+			cancel()
+		}()
+		_, err := resp.Result()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrRequestTimeout)
+	})
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err := e.RequestCtx(ctx, silent, "foo").Result()
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+	})
+	t.Run("abandoned", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := e.RequestCtx(ctx, silent, "foo")
+		assert.NotNil(t, e.Registry.get(resp.pid))
+		cancel()
+		assert.Eventually(t, func() bool {
+			return e.Registry.get(resp.pid) == nil
+		}, time.Second, time.Millisecond)
+	})
+}
+
+func TestResponsePipeTo(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	responder := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok && msg == "ping" {
+			c.Respond("pong")
+		}
+	}, "responder")
+	silent := e.SpawnFunc(func(*Context) {}, "silent")
+
+	results := make(chan Envelope, 2)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.Request(responder, "ping", time.Second).PipeTo(c.PID())
+			c.Request(silent, "ping", time.Millisecond).PipeTo(c.PID())
+		case string, RequestFailure:
+			results <- Envelope{Msg: msg, Sender: c.Sender()}
+		}
+	}, "requester")
+
+	got := map[string]Envelope{}
+	for i := 0; i < 2; i++ {
+		env := <-results
+		got[fmt.Sprintf("%T", env.Msg)] = env
+	}
+	assert.Equal(t, Envelope{Msg: "pong", Sender: responder}, got["string"])
+	failure := got["actor.RequestFailure"].Msg.(RequestFailure)
+	assert.Equal(t, silent, failure.Target)
+	assert.ErrorIs(t, failure.Err, context.DeadlineExceeded)
+}
+
+func TestResponseCombinators(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	echo := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			c.Respond(msg)
+		}
+	}, "echo")
+	silent := e.SpawnFunc(func(*Context) {}, "silent")
+
+	t.Run("done", func(t *testing.T) {
+		resp := e.Request(echo, "foo", time.Second)
+		select {
+		case <-resp.Done():
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+		res, err := resp.Result()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", res)
+		// 重复调用 Result 得到相同的结果。
+		res, err = resp.Result()
+		require.NoError(t, err)
+		assert.Equal(t, "foo", res)
+	})
+	t.Run("then", func(t *testing.T) {
+		ch := make(chan any, 1)
+		e.Request(echo, "foo", time.Second).Then(func(v any, err error) {
+			assert.NoError(t, err)
+			ch <- v
+		})
+		assert.Equal(t, "foo", <-ch)
+	})
+	t.Run("map", func(t *testing.T) {
+		res, err := e.Request(echo, "foo", time.Second).Map(func(v any) (any, error) {
+			return v.(string) + "bar", nil
+		}).Result()
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", res)
+
+		called := false
+		_, err = e.Request(silent, "foo", time.Millisecond).Map(func(v any) (any, error) {
+			called = true
+			return v, nil
+		}).Result()
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+		assert.False(t, called)
+	})
+	t.Run("wait all", func(t *testing.T) {
+		results, err := WaitAll(
+			e.Request(echo, "a", time.Second),
+			e.Request(silent, "b", time.Millisecond),
+			e.Request(echo, "c", time.Second),
+		)
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+		assert.Equal(t, []any{"a", nil, "c"}, results)
+
+		results, err = WaitAll()
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
 func TestPoisonPillPrivate(t *testing.T) {
 	e, err := NewEngine(NewEngineConfig())
 	require.NoError(t, err)
@@ -426,6 +627,7 @@ func BenchmarkSendMessageLocal(b *testing.B) {
 
 	b.ResetTimer()
 	b.Run("send_message_local", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			e.Send(pid, pid)
 		}
@@ -480,3 +682,25 @@ func TestMultipleStops(t *testing.T) {
 		<-done
 	}
 }
+
+func TestEngineWithLogger(t *testing.T) {
+	buf := &SafeBuffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	e, err := NewEngine(NewEngineConfig().WithLogger(logger))
+	require.NoError(t, err)
+	assert.Equal(t, logger, e.Logger())
+
+	done := make(chan struct{})
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			c.Respond("nobody")
+			close(done)
+		}
+	}, "foo")
+	<-done
+	assert.Eventually(t, func() bool {
+		out := buf.String()
+		// 事件流和 actor 上下文都应使用注入的日志记录器。
+		return strings.Contains(out, "context got no sender") && strings.Contains(out, "Actor 已启动")
+	}, time.Second, time.Millisecond)
+}
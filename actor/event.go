@@ -98,6 +98,299 @@ type RemoteUnreachableEvent struct {
 	ListenAddr string
 }
 
+// RemoteReconnectedEvent 在不可达的远程重新建立连接后发布。
+// Replayed 是连接建立后从发件箱中补发的消息数，参见 remote.Config.WithOutbox。
+type RemoteReconnectedEvent struct {
+	ListenAddr string
+	Replayed   int
+}
+
+func (e RemoteReconnectedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelInfo, "远程已重新连接", []any{"remote", e.ListenAddr, "replayed", e.Replayed}
+}
+
+// RemoteBackpressureEvent 在发往 ListenAddr 的流写入器队列达到阈值时发布（Active 为 true），
+// 队列回落到阈值以下时再次发布（Active 为 false）。
+type RemoteBackpressureEvent struct {
+	ListenAddr string
+	QueueDepth int
+	Threshold  int
+	Active     bool
+}
+
+func (e RemoteBackpressureEvent) Log() (slog.Level, string, []any) {
+	if e.Active {
+		return slog.LevelWarn, "远程发送队列超过阈值", []any{"remote", e.ListenAddr, "depth", e.QueueDepth, "threshold", e.Threshold}
+	}
+	return slog.LevelInfo, "远程发送队列已恢复", []any{"remote", e.ListenAddr, "depth", e.QueueDepth, "threshold", e.Threshold}
+}
+
+// RemoteCircuitState 是远程地址熔断器的状态。
+type RemoteCircuitState int
+
+const (
+	// RemoteCircuitClosed 表示正常发送。
+	RemoteCircuitClosed RemoteCircuitState = iota
+	// RemoteCircuitOpen 表示连续失败过多，发往该地址的消息直接进入死信。
+	RemoteCircuitOpen
+	// RemoteCircuitHalfOpen 表示冷却期已过，正在尝试重新连接。
+	RemoteCircuitHalfOpen
+)
+
+func (s RemoteCircuitState) String() string {
+	switch s {
+	case RemoteCircuitClosed:
+		return "closed"
+	case RemoteCircuitOpen:
+		return "open"
+	case RemoteCircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// RemoteCircuitEvent 在远程地址的熔断器状态改变时发布。
+type RemoteCircuitEvent struct {
+	ListenAddr string
+	State      RemoteCircuitState
+	// Failures 是熔断器打开时累计的连续失败次数。
+	Failures int
+}
+
+func (e RemoteCircuitEvent) Log() (slog.Level, string, []any) {
+	level := slog.LevelInfo
+	if e.State == RemoteCircuitOpen {
+		level = slog.LevelWarn
+	}
+	return level, "远程熔断器状态改变", []any{"remote", e.ListenAddr, "state", e.State.String(), "failures", e.Failures}
+}
+
+// RemotePeerAuthenticatedEvent 在远程节点的 TLS 客户端证书通过验证后发布。
+// Identity 是对方证书的通用名称，没有通用名称时为第一个 URI 或 DNS 名称。
+type RemotePeerAuthenticatedEvent struct {
+	RemoteAddr string
+	Identity   string
+}
+
+func (e RemotePeerAuthenticatedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelDebug, "远程节点已通过验证", []any{"remote", e.RemoteAddr, "identity", e.Identity}
+}
+
+// RemoteAuthFailedEvent 在远程节点没有通过认证握手时发布，对应的连接随即被关闭。
+type RemoteAuthFailedEvent struct {
+	RemoteAddr string
+	Reason     string
+}
+
+func (e RemoteAuthFailedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "远程节点认证失败", []any{"remote", e.RemoteAddr, "reason", e.Reason}
+}
+
+// RemotePeerBlockedEvent 在来自远程节点的连接被 Remote.Block 或 PeerFilter 拒绝时发布，
+// 对应的连接随即被关闭。被 Block 拒绝时 Identity 为空。
+type RemotePeerBlockedEvent struct {
+	RemoteAddr string
+	Identity   string
+}
+
+func (e RemotePeerBlockedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "远程节点被屏蔽", []any{"remote", e.RemoteAddr, "identity", e.Identity}
+}
+
+// RemoteIncompatibleEvent 在协商握手发现远程节点的协议版本或能力不兼容时发布，对应的连接随即被关闭。
+type RemoteIncompatibleEvent struct {
+	RemoteAddr string
+	Reason     string
+}
+
+func (e RemoteIncompatibleEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelError, "远程节点不兼容", []any{"remote", e.RemoteAddr, "reason", e.Reason}
+}
+
+// RemoteMessageRejectedEvent 在远程收到的消息超过 MaxMessageSize 而被丢弃时发布，
+// 启用协商后，发送的消息超过对方的 MaxMessageSize 时也在发送端发布。
+// Sender 是消息的发送者，没有发送者时为 nil。
+type RemoteMessageRejectedEvent struct {
+	Sender   *PID
+	Target   *PID
+	TypeName string
+	Size     int
+}
+
+func (e RemoteMessageRejectedEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "远程消息过大，已丢弃", []any{"sender", e.Sender.GetID(), "target", e.Target.GetID(), "type", e.TypeName, "size", e.Size}
+}
+
+// RemoteDeserializationErrorEvent 在远程收到的消息无法反序列化（例如本节点没有注册它的类型，
+// 或者它的类型、目标、发送者索引越界）时发布，这条消息被丢弃，同一批次中的其他消息照常投递。Sender 是消息的发送者，没有发送者时为 nil。
+type RemoteDeserializationErrorEvent struct {
+	Sender   *PID
+	Target   *PID
+	TypeName string
+	Err      error
+}
+
+func (e RemoteDeserializationErrorEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelError, "远程消息反序列化失败，已丢弃", []any{"sender", e.Sender.GetID(), "target", e.Target.GetID(), "type", e.TypeName, "err", e.Err}
+}
+
+// RemoteIntegrityErrorEvent 在远程收到的消息的 HMAC 签名缺失或不匹配时发布（参见 remote.Config.WithHMAC），
+// 这条消息被丢弃。Sender 是消息声称的发送者，没有发送者时为 nil。
+type RemoteIntegrityErrorEvent struct {
+	Sender   *PID
+	Target   *PID
+	TypeName string
+}
+
+func (e RemoteIntegrityErrorEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelError, "远程消息签名验证失败，已丢弃", []any{"sender", e.Sender.GetID(), "target", e.Target.GetID(), "type", e.TypeName}
+}
+
+// RemoteExpiredEvent 在发往远程节点的消息超过 remote.Config.WithSendTTL 设置的有效期仍未写出时发布
+// （例如对方不可达或发送队列积压），这条消息被丢弃，而不是在重新连接后过时地投递。
+type RemoteExpiredEvent struct {
+	Target   *PID
+	Message  any
+	Sender   *PID
+	Deadline time.Time
+}
+
+func (e RemoteExpiredEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "远程消息已过期，已丢弃", []any{"target", e.Target.GetID(), "sender", e.Sender.GetID(), "deadline", e.Deadline}
+}
+
+// RemoteThrottledEvent 在远程节点发来的消息超过 WithInboundRateLimit 设置的速率时发布，
+// 每个 Envelope 最多发布一次。Peer 是入站连接的对端主机，Dropped 是被丢弃的消息数，
+// Delay 是读取该节点的连接被暂停的总时间。
+type RemoteThrottledEvent struct {
+	Peer    string
+	Dropped int
+	Delay   time.Duration
+}
+
+func (e RemoteThrottledEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "远程节点超过速率限制", []any{"peer", e.Peer, "dropped", e.Dropped, "delay", e.Delay}
+}
+
+// RemoteStatsEvent 由远程模块按照配置的间隔为每个远程节点发布，计数都是本次间隔内的增量。
+// Peer 是出站方向的远程监听地址；入站方向按消息发送者的地址统计。
+type RemoteStatsEvent struct {
+	Peer     string
+	Interval time.Duration
+	BytesIn  uint64
+	BytesOut uint64
+	// MessagesIn 和 MessagesOut 是收发的消息数。
+	MessagesIn  uint64
+	MessagesOut uint64
+	// SerializeErrors 是发送时序列化失败的消息数。
+	SerializeErrors uint64
+	// QueueDepth 是流写入器收件箱中等待发送的消息数。
+	QueueDepth int
+}
+
+// MessagesInPerSec 返回本次间隔内每秒收到的消息数。
+func (e RemoteStatsEvent) MessagesInPerSec() float64 {
+	return float64(e.MessagesIn) / e.Interval.Seconds()
+}
+
+// MessagesOutPerSec 返回本次间隔内每秒发送的消息数。
+func (e RemoteStatsEvent) MessagesOutPerSec() float64 {
+	return float64(e.MessagesOut) / e.Interval.Seconds()
+}
+
+func (e RemoteStatsEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelDebug, "远程链路统计", []any{
+		"peer", e.Peer,
+		"bytes_in", e.BytesIn, "bytes_out", e.BytesOut,
+		"msgs_in_per_sec", e.MessagesInPerSec(), "msgs_out_per_sec", e.MessagesOutPerSec(),
+		"serialize_errors", e.SerializeErrors, "queue_depth", e.QueueDepth,
+	}
+}
+
+// MailboxFullEvent 在有界收件箱已满、且溢出策略为 OverflowReject 时，
+// 为每条被拒绝的消息发布。
+type MailboxFullEvent struct {
+	Target  *PID
+	Message any
+	Sender  *PID
+}
+
+func (e MailboxFullEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "收件箱已满，消息被拒绝", []any{"target", e.Target.GetID()}
+}
+
+// ThrottledEvent 在消息超过 WithRateLimit 设置的速率时发布。
+// Dropped 为 true 表示消息被丢弃，否则 Delay 是消息被推迟处理的时间。
+type ThrottledEvent struct {
+	PID     *PID
+	Message any
+	Sender  *PID
+	Dropped bool
+	Delay   time.Duration
+}
+
+func (e ThrottledEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelDebug, "消息超过速率限制", []any{"pid", e.PID.GetID(), "dropped", e.Dropped, "delay", e.Delay}
+}
+
+// ActorSlowEvent 在 actor 一次 Invoke 处理一批消息的时间超过慢 actor 阈值时发布
+// （参见 WithSlowThreshold）。MessageType 是该批中耗时最长的消息的类型，
+// 通常就是在 Receive 中执行了阻塞调用的那条消息。
+type ActorSlowEvent struct {
+	PID *PID
+	// Duration 是整批消息的处理时间，Messages 是该批的消息数量。
+	Duration time.Duration
+	Messages int
+	// MessageType 和 MessageDuration 是耗时最长的消息的类型和处理时间。
+	MessageType     string
+	MessageDuration time.Duration
+	Timestamp       time.Time
+}
+
+func (e ActorSlowEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "Actor 处理消息过慢",
+		[]any{"pid", e.PID.GetID(), "duration", e.Duration, "messages", e.Messages,
+			"msgtype", e.MessageType, "msgduration", e.MessageDuration}
+}
+
+// ActorInboxBacklogEvent 在 actor 收件箱中待处理的消息数量达到积压阈值时发布
+// （参见 WithBacklogThreshold），用于尽早发现处理速度跟不上的 actor。
+type ActorInboxBacklogEvent struct {
+	PID       *PID
+	Len       int
+	Threshold int
+	Timestamp time.Time
+}
+
+func (e ActorInboxBacklogEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "Actor 收件箱积压", []any{"pid", e.PID.GetID(), "len", e.Len, "threshold", e.Threshold}
+}
+
+// RequestTimeoutEvent 在请求超时（Request 的 timeout 到期或 RequestCtx 的 ctx 到期）时发布。
+// MessageType 是请求消息的类型，Elapsed 是从发出请求到超时经过的时间。
+type RequestTimeoutEvent struct {
+	Target      *PID
+	MessageType string
+	Elapsed     time.Duration
+	// Timeout 是 Request 设置的超时时间，RequestCtx 发起的请求为 0。
+	Timeout time.Duration
+}
+
+func (e RequestTimeoutEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "请求超时", []any{"target", e.Target.GetID(), "msgtype", e.MessageType, "elapsed", e.Elapsed}
+}
+
+// BlockingSelfRequestEvent 在 actor 阻塞等待发给自身的请求时发布。在 Receive 中这样做
+// 会使 actor 一直阻塞到请求超时，应改用 Context.RequestAsync。
+type BlockingSelfRequestEvent struct {
+	PID         *PID
+	MessageType string
+}
+
+func (e BlockingSelfRequestEvent) Log() (slog.Level, string, []any) {
+	return slog.LevelWarn, "Actor 阻塞等待发给自身的请求", []any{"pid", e.PID.GetID(), "msgtype", e.MessageType}
+}
+
 // DeadLetterEvent 在消息无法投递到其接收者时，投递到死信 actor。
 type DeadLetterEvent struct {
 	Target  *PID
@@ -0,0 +1,62 @@
+package actor
+
+import "sync"
+
+// EventBus 是只承载类型为 T 的用户事件的事件总线。它与引擎的事件流（生命周期事件、
+// 死信等）相互独立，使用户领域（例如交易事件）可以拥有自己的事件流，
+// 订阅者只会收到该总线上发布的事件。
+//
+//	trades := actor.NewEventBus[*TradeExecuted](engine, "trades")
+//	unsubscribe := trades.SubscribeFunc(func(evt *TradeExecuted) { ... })
+//	trades.Publish(&TradeExecuted{Symbol: "BTC"})
+type EventBus[T any] struct {
+	engine *Engine
+	pid    *PID
+}
+
+// NewEventBus 返回引擎中名为 name 的事件总线。总线作为系统 actor 运行，
+// 在第一次调用时创建，之后以相同名称调用将返回同一条总线。
+func NewEventBus[T any](e *Engine, name string) *EventBus[T] {
+	pid := e.GetPID("eventbus", name)
+	if pid == nil {
+		pid = e.Spawn(newEventStream(), "eventbus", WithID(name), WithSystem())
+	}
+	return &EventBus[T]{engine: e, pid: pid}
+}
+
+// PID 返回事件总线 actor 的 PID。
+func (b *EventBus[T]) PID() *PID {
+	return b.pid
+}
+
+// Publish 将事件发布到总线，通知所有订阅者。
+func (b *EventBus[T]) Publish(event T) {
+	b.engine.Send(b.pid, event)
+}
+
+// Subscribe 将给定的 PID 订阅到总线。
+func (b *EventBus[T]) Subscribe(pid *PID) {
+	b.engine.Send(b.pid, eventSub{pid: pid})
+}
+
+// Unsubscribe 将给定的 PID 从总线取消订阅。
+func (b *EventBus[T]) Unsubscribe(pid *PID) {
+	b.engine.Send(b.pid, eventUnsub{pid: pid})
+}
+
+// SubscribeFunc 以回调函数的方式订阅总线，参见 Engine.SubscribeFunc。
+func (b *EventBus[T]) SubscribeFunc(fn func(event T)) (unsubscribe func()) {
+	pid := b.engine.SpawnFunc(func(c *Context) {
+		if evt, ok := c.Message().(T); ok {
+			fn(evt)
+		}
+	}, "eventbus-sub")
+	b.Subscribe(pid)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.Unsubscribe(pid)
+			<-b.engine.Poison(pid).Done()
+		})
+	}
+}
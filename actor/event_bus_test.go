@@ -0,0 +1,57 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tradeEvent struct {
+	Symbol string
+}
+
+func TestEventBus(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	trades := NewEventBus[tradeEvent](e, "trades")
+	assert.Equal(t, trades.PID(), NewEventBus[tradeEvent](e, "trades").PID())
+
+	got := make(chan tradeEvent, 4)
+	unsubscribe := trades.SubscribeFunc(func(evt tradeEvent) {
+		got <- evt
+	})
+	engineEvents := make(chan any, 16)
+	unsubscribeEngine := e.SubscribeFunc(func(evt any) {
+		if _, ok := evt.(tradeEvent); ok {
+			engineEvents <- evt
+		}
+	})
+	defer unsubscribeEngine()
+
+	trades.Publish(tradeEvent{Symbol: "BTC"})
+	select {
+	case evt := <-got:
+		assert.Equal(t, "BTC", evt.Symbol)
+	case <-time.After(time.Second):
+		t.Fatal("expected trade event")
+	}
+
+	// 引擎的事件流与用户总线相互独立。
+	e.BroadcastEvent(tradeEvent{Symbol: "ETH"})
+	assert.Equal(t, tradeEvent{Symbol: "ETH"}, <-engineEvents)
+	select {
+	case evt := <-got:
+		t.Fatalf("unexpected event on bus: %v", evt)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unsubscribe()
+	trades.Publish(tradeEvent{Symbol: "SOL"})
+	select {
+	case evt := <-got:
+		t.Fatalf("unexpected event after unsubscribe: %v", evt)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
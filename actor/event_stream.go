@@ -2,7 +2,6 @@ package actor
 
 import (
 	"context"
-	"log/slog"
 )
 
 // eventSub 是用于订阅事件流的消息。
@@ -65,7 +64,7 @@ func (e *eventStream) Receive(c *Context) {
 		logMsg, ok := c.Message().(EventLogger)
 		if ok {
 			level, msg, attr := logMsg.Log()
-			slog.Log(context.Background(), level, msg, attr...)
+			c.engine.logger.Log(context.Background(), level, msg, attr...)
 		}
 		for sub := range e.subs {
 			c.Forward(sub)
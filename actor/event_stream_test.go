@@ -4,6 +4,7 @@ import (
 	fmt "fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,3 +80,25 @@ func TestEventStreamActorStoppedEvent(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestSubscribeFunc(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	assert.NoError(t, err)
+	events := make(chan CustomEvent, 2)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(CustomEvent); ok {
+			events <- evt
+		}
+	})
+	e.BroadcastEvent(CustomEvent{msg: "foo"})
+	assert.Equal(t, "foo", (<-events).msg)
+
+	unsubscribe()
+	unsubscribe()
+	e.BroadcastEvent(CustomEvent{msg: "bar"})
+	select {
+	case evt := <-events:
+		t.Fatalf("取消订阅后不应收到事件: %v", evt)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -2,6 +2,7 @@ package actor
 
 import (
 	"runtime"
+	"sync"
 	"sync/atomic"
 
 	"github.com/TAnNbR/Distributed-framework/ringbuffer"
@@ -19,6 +20,28 @@ const (
 	running               // 运行中
 )
 
+// batchPool 复用收件箱每次取出消息所用的批次切片。
+// 批次只在一次 Processer.Invoke 调用期间有效，Invoke 返回后会被清空并放回池中，
+// 因此 Processer 不能在 Invoke 返回后继续持有该切片（需要时应自行复制）。
+var batchPool = sync.Pool{
+	New: func() any {
+		b := make([]Envelope, 0, 64)
+		return &b
+	},
+}
+
+// getBatch 从池中取出一个空的批次切片。
+func getBatch() *[]Envelope {
+	return batchPool.Get().(*[]Envelope)
+}
+
+// putBatch 清空批次中的消息引用并将其放回池中。
+func putBatch(b *[]Envelope, msgs []Envelope) {
+	clear(msgs)
+	*b = msgs[:0]
+	batchPool.Put(b)
+}
+
 // Scheduler 是调度器接口。
 type Scheduler interface {
 	Schedule(fn func())
@@ -43,6 +66,23 @@ func NewScheduler(throughput int) Scheduler {
 	return goscheduler(throughput)
 }
 
+// schedulerSetter 由允许替换调度器的收件箱实现，必须在 Start 之前调用。
+type schedulerSetter interface {
+	setScheduler(Scheduler)
+}
+
+// scheduler 返回 actor 应使用的调度器：依次为 Opts.Scheduler、Opts.Throughput
+// 与引擎的默认调度器。返回 nil 时保留收件箱自带的调度器。
+func (opts Opts) scheduler(e *Engine) Scheduler {
+	if opts.Scheduler != nil {
+		return opts.Scheduler
+	}
+	if opts.Throughput > 0 {
+		return NewScheduler(opts.Throughput)
+	}
+	return e.scheduler
+}
+
 // Inboxer 是收件箱接口。
 type Inboxer interface {
 	Send(Envelope)
@@ -67,12 +107,28 @@ func NewInbox(size int) *Inbox {
 	}
 }
 
+// setScheduler 替换收件箱的调度器。
+func (in *Inbox) setScheduler(s Scheduler) {
+	in.scheduler = s
+}
+
 // Send 向收件箱发送消息。
 func (in *Inbox) Send(msg Envelope) {
 	in.rb.Push(msg)
 	in.schedule()
 }
 
+// SendBatch 在一次入队操作中向收件箱发送多条消息，只调度一次处理。
+func (in *Inbox) SendBatch(msgs []Envelope) {
+	in.rb.PushN(msgs...)
+	in.schedule()
+}
+
+// Len 返回收件箱中等待处理的消息数量。
+func (in *Inbox) Len() int {
+	return int(in.rb.Len())
+}
+
 // schedule 调度消息处理。
 func (in *Inbox) schedule() {
 	if atomic.CompareAndSwapInt32(&in.procStatus, idle, running) {
@@ -92,6 +148,9 @@ func (in *Inbox) process() {
 
 // run 运行消息处理循环。
 func (in *Inbox) run() {
+	batch := getBatch()
+	msgs := *batch
+	defer func() { putBatch(batch, msgs) }()
 	i, t := 0, in.scheduler.Throughput()
 	for atomic.LoadInt32(&in.procStatus) != stopped {
 		if i > t {
@@ -100,8 +159,10 @@ func (in *Inbox) run() {
 		}
 		i++
 
-		if msgs, ok := in.rb.PopN(messageBatchSize); ok && len(msgs) > 0 {
+		var ok bool
+		if msgs, ok = in.rb.PopNInto(msgs[:0], messageBatchSize); ok && len(msgs) > 0 {
 			in.proc.Invoke(msgs)
+			clear(msgs)
 		} else {
 			return
 		}
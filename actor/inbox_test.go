@@ -85,3 +85,54 @@ func TestInboxStop(t *testing.T) {
 	<-done
 	require.True(t, atomic.LoadInt32(&inbox.procStatus) == stopped)
 }
+
+// syncScheduler 在调用方的 goroutine 中同步执行调度的函数。
+type syncScheduler struct {
+	scheduled atomic.Int32
+}
+
+func (s *syncScheduler) Schedule(fn func()) {
+	s.scheduled.Add(1)
+	fn()
+}
+
+func (s *syncScheduler) Throughput() int { return defaultThroughput }
+
+func TestWithScheduler(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	sched := &syncScheduler{}
+	var received atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			received.Add(1)
+		}
+	}, "sync", WithScheduler(sched))
+	for i := 0; i < 10; i++ {
+		e.Send(pid, "hello")
+		// 同步调度器在 Send 返回之前就已经处理完消息。
+		require.Equal(t, int32(i+1), received.Load())
+	}
+	require.NotZero(t, sched.scheduled.Load())
+}
+
+func TestEngineDefaultScheduler(t *testing.T) {
+	sched := &syncScheduler{}
+	e, err := NewEngine(NewEngineConfig().WithScheduler(sched))
+	require.NoError(t, err)
+	var received atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			received.Add(1)
+		}
+	}, "sync")
+	e.Send(pid, "hello")
+	require.Equal(t, int32(1), received.Load())
+
+	// actor 自身的选项优先于引擎的默认调度器。
+	pid = e.SpawnFunc(func(c *Context) {}, "tuned", WithThroughput(7))
+	proc := e.Registry.get(pid).(*process)
+	require.Equal(t, 7, proc.inbox.(*Inbox).scheduler.Throughput())
+	proc = e.Registry.get(e.SpawnFunc(func(c *Context) {}, "bounded", WithBoundedInbox(4, OverflowDropNewest), WithThroughput(3))).(*process)
+	require.Equal(t, 3, proc.inbox.(*BoundedInbox).scheduler.Throughput())
+}
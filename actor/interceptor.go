@@ -0,0 +1,52 @@
+package actor
+
+import "slices"
+
+// SpawnInterceptor 在每次 Spawn 和 SpawnChild 创建进程之前调用，可以修改 actor 的配置
+// （例如添加中间件、注入指标或设置默认的监督策略）。返回错误将拒绝创建该 actor：
+// 返回的 PID 不会被注册，发送给它的消息将成为死信。系统 actor 不会经过拦截器。
+type SpawnInterceptor func(opts *Opts) error
+
+// WithGlobalMiddleware 添加作用于引擎中每个 actor 的中间件。全局中间件位于 actor
+// 自身通过 WithMiddleware 添加的中间件之外，按添加顺序执行。系统 actor 不受影响。
+func (config EngineConfig) WithGlobalMiddleware(mw ...MiddlewareFunc) EngineConfig {
+	config.globalMiddleware = append(slices.Clip(config.globalMiddleware), mw...)
+	return config
+}
+
+// WithSpawnInterceptor 添加在每次创建 actor 时调用的拦截器，拦截器按添加顺序调用，
+// 并且在全局中间件应用之后调用。用于统一添加追踪、检查命名约定等，而不必修改每一个调用点。
+//
+//	config := actor.NewEngineConfig().WithSpawnInterceptor(func(opts *actor.Opts) error {
+//		if !strings.HasPrefix(opts.Kind, "svc.") {
+//			return fmt.Errorf("kind %q 不符合命名约定", opts.Kind)
+//		}
+//		return nil
+//	})
+func (config EngineConfig) WithSpawnInterceptor(i ...SpawnInterceptor) EngineConfig {
+	config.spawnInterceptors = append(slices.Clip(config.spawnInterceptors), i...)
+	return config
+}
+
+// interceptSpawn 对即将创建的 actor 应用全局中间件和拦截器。
+func (e *Engine) interceptSpawn(opts *Opts) error {
+	if opts.System {
+		return nil
+	}
+	if len(e.globalMiddleware) > 0 {
+		opts.Middleware = slices.Concat(e.globalMiddleware, opts.Middleware)
+	}
+	for _, intercept := range e.spawnInterceptors {
+		if err := intercept(opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectSpawn 记录被拦截器拒绝的 actor，并返回它未注册的 PID。
+func (e *Engine) rejectSpawn(opts Opts, err error) *PID {
+	pid := NewPID(e.address, opts.Kind+pidSeparator+opts.ID)
+	e.logger.Warn("拦截器拒绝创建 actor", "pid", pid, "err", err)
+	return pid
+}
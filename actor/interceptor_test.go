@@ -0,0 +1,103 @@
+package actor
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobalMiddleware(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(name string) MiddlewareFunc {
+		return func(next ReceiveFunc) ReceiveFunc {
+			return func(c *Context) {
+				if _, ok := c.Message().(string); ok {
+					mu.Lock()
+					calls = append(calls, name)
+					mu.Unlock()
+				}
+				next(c)
+			}
+		}
+	}
+	e, err := NewEngine(NewEngineConfig().WithGlobalMiddleware(record("global")))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			close(done)
+		}
+	}, "foo", WithMiddleware(record("local")))
+	e.Send(pid, "hello")
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"global", "local"}, calls)
+}
+
+func TestSpawnInterceptor(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		kinds []string
+	)
+	e, err := NewEngine(NewEngineConfig().WithSpawnInterceptor(
+		func(opts *Opts) error {
+			mu.Lock()
+			kinds = append(kinds, opts.Kind)
+			mu.Unlock()
+			return nil
+		},
+		func(opts *Opts) error {
+			if strings.HasPrefix(opts.Kind, "bad") {
+				return errors.New("invalid kind")
+			}
+			return nil
+		},
+	))
+	require.NoError(t, err)
+
+	childDone := make(chan *PID, 1)
+	parent := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			childDone <- c.SpawnChildFunc(func(*Context) {}, "child")
+		}
+	}, "parent")
+	child := <-childDone
+	assert.NotNil(t, e.Registry.get(parent))
+	assert.NotNil(t, e.Registry.get(child))
+
+	rejected := e.SpawnFunc(func(*Context) {}, "bad")
+	assert.Nil(t, e.Registry.get(rejected))
+	assert.Equal(t, "bad", strings.Split(rejected.ID, pidSeparator)[0])
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 系统 actor（事件流）不经过拦截器。
+	require.Len(t, kinds, 3)
+	assert.Equal(t, "parent", kinds[0])
+	assert.Equal(t, parent.ID+pidSeparator+"child", kinds[1])
+	assert.Equal(t, "bad", kinds[2])
+}
+
+func TestSpawnInterceptorModifiesOpts(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithSpawnInterceptor(func(opts *Opts) error {
+		opts.ID = "fixed"
+		return nil
+	}))
+	require.NoError(t, err)
+	pid := e.SpawnFunc(func(*Context) {}, "foo")
+	assert.Equal(t, "foo/fixed", pid.ID)
+	assert.Eventually(t, func() bool {
+		return e.Registry.getByID("foo/fixed") != nil
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,81 @@
+package actor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMPSCQueue(t *testing.T) {
+	q := newMPSCQueue()
+	require.Empty(t, q.popN(nil, 10))
+	for i := 0; i < 5; i++ {
+		q.push(Envelope{Msg: i})
+	}
+	msgs := q.popN(nil, 3)
+	require.Len(t, msgs, 3)
+	msgs = q.popN(msgs, 10)
+	for i, env := range msgs {
+		require.Equal(t, i, env.Msg)
+	}
+	require.Equal(t, int64(0), q.len.Load())
+}
+
+func TestMPSCInboxFanIn(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	const (
+		senders = 16
+		perSend = 1000
+	)
+	var wg sync.WaitGroup
+	wg.Add(senders * perSend)
+	last := make(map[int]int)
+	pid := e.SpawnFunc(func(c *Context) {
+		if m, ok := c.Message().([2]int); ok {
+			// 同一个发送者的消息保持顺序。
+			if prev, ok := last[m[0]]; ok && prev+1 != m[1] {
+				panic(fmt.Sprintf("发送者 %d 的消息乱序: %d 之后是 %d", m[0], prev, m[1]))
+			}
+			last[m[0]] = m[1]
+			wg.Done()
+		}
+	}, "fanin", WithMPSCInbox(), WithMaxRestarts(0))
+	for s := 0; s < senders; s++ {
+		go func(s int) {
+			for i := 0; i < perSend; i++ {
+				e.Send(pid, [2]int{s, i})
+			}
+		}(s)
+	}
+	wg.Wait()
+}
+
+func benchmarkFanIn(b *testing.B, opts ...OptFunc) {
+	e, _ := NewEngine(NewEngineConfig())
+	var wg sync.WaitGroup
+	wg.Add(b.N)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			wg.Done()
+		}
+	}, "bench", opts...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e.Send(pid, 1)
+		}
+	})
+	wg.Wait()
+}
+
+func BenchmarkFanInRingBufferInbox(b *testing.B) {
+	benchmarkFanIn(b)
+}
+
+func BenchmarkFanInMPSCInbox(b *testing.B) {
+	benchmarkFanIn(b, WithMPSCInbox())
+}
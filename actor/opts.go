@@ -20,14 +20,49 @@ type MiddlewareFunc = func(ReceiveFunc) ReceiveFunc
 
 // Opts 包含 Actor 的配置选项。
 type Opts struct {
-	Producer     Producer          // Actor 生产者
-	Kind         string            // Actor 类型
-	ID           string            // Actor ID
-	MaxRestarts  int32             // 最大重启次数
-	RestartDelay time.Duration     // 重启延迟
-	InboxSize    int               // 收件箱大小
-	Middleware   []MiddlewareFunc  // 中间件列表
-	Context      context.Context   // Go 上下文
+	Producer     Producer           // Actor 生产者
+	Kind         string             // Actor 类型
+	ID           string             // Actor ID
+	MaxRestarts  int32              // 最大重启次数
+	RestartDelay time.Duration      // 重启延迟，设置了 RestartBackoff 时不使用
+	InboxSize    int                // 收件箱大小
+	Middleware   []MiddlewareFunc   // 中间件列表
+	Context      context.Context    // Go 上下文
+	Supervisor   SupervisorStrategy // 子 actor 的监督策略
+	// RestartPolicy 是该 actor 自身崩溃时使用的监督策略，为 nil 时使用父 actor 的 Supervisor。
+	RestartPolicy SupervisorStrategy
+	// ReceiveTimeout 是接收超时时间，actor 空闲超过该时间将收到 ReceiveTimeout 消息。
+	ReceiveTimeout time.Duration
+	// Inbox 根据收件箱大小创建收件箱，为 nil 时使用默认的 Inbox。
+	Inbox func(size int) Inboxer
+	// Journal 是持久化 actor 的事件存储，为 nil 时使用引擎的 Journal。
+	Journal Journal
+	// SnapshotStore 是持久化 actor 的快照存储，为 nil 时使用引擎的 SnapshotStore。
+	SnapshotStore SnapshotStore
+	// SnapshotEvery 大于 0 时，每持久化 N 条事件自动保存一次快照。
+	SnapshotEvery int
+	// CrashBuffer 决定崩溃重启时如何处理尚未处理的消息。
+	CrashBuffer CrashBufferPolicy
+	// RestartBackoff 不为 nil 时，重启使用指数退避代替固定的 RestartDelay。
+	RestartBackoff *RestartBackoff
+	// PanicHandler 在 actor 崩溃时调用，为 nil 时使用引擎的 PanicHandler。
+	PanicHandler PanicHandler
+	// Scheduler 是收件箱使用的调度器，为 nil 时使用引擎的默认调度器。
+	Scheduler Scheduler
+	// DrainTo 不为 nil 时，actor 停止时尚未处理的消息会转发给它。
+	DrainTo *PID
+	// DedicatedGoroutine 为 true 时，actor 使用专用的常驻 goroutine 处理消息。
+	DedicatedGoroutine bool
+	// Throughput 大于 0 且未设置 Scheduler 时，使用该批处理大小的 goroutine 调度器。
+	Throughput int
+	// System 标记系统 actor，Engine.Shutdown 会在所有普通 actor 停止之后才停止它们。
+	System bool
+	// Tags 是附加在 actor 上的键值标签，可以通过 Registry.FindByTag 查询。
+	Tags map[string]string
+	// SlowThreshold 大于 0 时，一批消息的处理时间超过该值将广播 ActorSlowEvent。
+	SlowThreshold time.Duration
+	// BacklogThreshold 大于 0 时，收件箱积压达到该值将广播 ActorInboxBacklogEvent。
+	BacklogThreshold int
 }
 
 // OptFunc 是配置选项函数的类型。
@@ -86,3 +121,93 @@ func WithID(id string) OptFunc {
 		opts.ID = id
 	}
 }
+
+// WithReceiveTimeout 设置接收超时：actor 在 d 时间内没有收到任何消息时，
+// 将收到一条 ReceiveTimeout 消息。常用于钝化空闲的会话 actor。
+func WithReceiveTimeout(d time.Duration) OptFunc {
+	return func(opts *Opts) {
+		opts.ReceiveTimeout = d
+	}
+}
+
+// WithPriorityInbox 使 actor 使用 PriorityInbox：系统消息和实现了 Prioritized
+// 的高优先级消息将先于普通消息被处理。
+func WithPriorityInbox() OptFunc {
+	return func(opts *Opts) {
+		opts.Inbox = func(size int) Inboxer {
+			return NewPriorityInbox(size)
+		}
+	}
+}
+
+// WithMPSCInbox 使 actor 使用基于无锁队列的 MPSCInbox，适用于大量发送者
+// 同时向同一个 actor 发送消息的场景。
+func WithMPSCInbox() OptFunc {
+	return func(opts *Opts) {
+		opts.Inbox = func(size int) Inboxer {
+			return NewMPSCInbox(size)
+		}
+	}
+}
+
+// WithBoundedInbox 使 actor 使用容量为 size 的 BoundedInbox，
+// 收件箱已满时按照给定的 OverflowPolicy 处理新消息。
+func WithBoundedInbox(size int, policy OverflowPolicy) OptFunc {
+	return func(opts *Opts) {
+		opts.InboxSize = size
+		opts.Inbox = func(size int) Inboxer {
+			return NewBoundedInbox(size, policy)
+		}
+	}
+}
+
+// WithJournal 设置持久化 actor 使用的事件存储，覆盖引擎级别的 Journal。
+func WithJournal(j Journal) OptFunc {
+	return func(opts *Opts) {
+		opts.Journal = j
+	}
+}
+
+// WithSnapshotStore 设置持久化 actor 使用的快照存储，覆盖引擎级别的 SnapshotStore。
+func WithSnapshotStore(s SnapshotStore) OptFunc {
+	return func(opts *Opts) {
+		opts.SnapshotStore = s
+	}
+}
+
+// WithSnapshotEvery 使实现了 Snapshotter 的持久化 actor 每持久化 n 条事件自动保存一次快照。
+func WithSnapshotEvery(n int) OptFunc {
+	return func(opts *Opts) {
+		opts.SnapshotEvery = n
+	}
+}
+
+// WithScheduler 设置 actor 收件箱使用的调度器，测试中可以注入同步调度器。
+func WithScheduler(s Scheduler) OptFunc {
+	return func(opts *Opts) {
+		opts.Scheduler = s
+	}
+}
+
+// WithThroughput 设置收件箱每轮调度处理的最大消息数。
+// 对延迟敏感的 actor 可以调小该值，让出调度机会。
+func WithThroughput(n int) OptFunc {
+	return func(opts *Opts) {
+		opts.Throughput = n
+	}
+}
+
+// WithSystem 将 actor 标记为系统 actor（例如远程模块的流路由器）。
+// Engine.Shutdown 会在所有普通 actor 停止、远程消息刷新之后才停止系统 actor。
+func WithSystem() OptFunc {
+	return func(opts *Opts) {
+		opts.System = true
+	}
+}
+
+// WithSupervisor 设置该 actor 用于监督其子 actor 的策略。
+func WithSupervisor(strategy SupervisorStrategy) OptFunc {
+	return func(opts *Opts) {
+		opts.Supervisor = strategy
+	}
+}
@@ -0,0 +1,36 @@
+package actor
+
+// PanicHandler 在 actor 崩溃时、进入监督/重启流程之前被调用，
+// 参数为崩溃的 actor、panic 的值和清理后的调用栈。
+// 它在崩溃 actor 的 goroutine 中同步执行，应尽快返回（例如异步上报到 Sentry）。
+type PanicHandler func(pid *PID, reason any, stack []byte)
+
+// WithPanicHandler 设置 actor 崩溃时调用的钩子，覆盖引擎级别的 PanicHandler。
+func WithPanicHandler(h PanicHandler) OptFunc {
+	return func(opts *Opts) {
+		opts.PanicHandler = h
+	}
+}
+
+// WithPanicHandler 设置引擎中所有 actor 默认使用的崩溃钩子。
+func (config EngineConfig) WithPanicHandler(h PanicHandler) EngineConfig {
+	config.panicHandler = h
+	return config
+}
+
+// reportPanic 调用崩溃钩子。钩子自身的 panic 会被记录并忽略，不影响重启流程。
+func (p *process) reportPanic(reason any, stack []byte) {
+	h := p.Opts.PanicHandler
+	if h == nil {
+		h = p.context.engine.panicHandler
+	}
+	if h == nil {
+		return
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			p.context.engine.logger.Error("PanicHandler panic", "pid", p.pid, "err", v)
+		}
+	}()
+	h(p.pid, reason, stack)
+}
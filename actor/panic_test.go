@@ -0,0 +1,51 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportedPanic struct {
+	pid    *PID
+	reason any
+	stack  []byte
+}
+
+func TestPanicHandler(t *testing.T) {
+	engineReports := make(chan reportedPanic, 1)
+	e, err := NewEngine(NewEngineConfig().WithPanicHandler(func(pid *PID, reason any, stack []byte) {
+		engineReports <- reportedPanic{pid, reason, stack}
+	}))
+	require.NoError(t, err)
+
+	crash := func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			panic(msg)
+		}
+	}
+	pid := e.SpawnFunc(crash, "default", WithRestartDelay(time.Millisecond))
+	e.Send(pid, "boom")
+	report := <-engineReports
+	assert.Equal(t, pid, report.pid)
+	assert.Equal(t, "boom", report.reason)
+	assert.Contains(t, string(report.stack), "panic_test.go")
+
+	// actor 级别的钩子覆盖引擎级别的钩子；钩子自身的 panic 不影响重启。
+	actorReports := make(chan any, 1)
+	pid = e.SpawnFunc(crash, "custom", WithRestartDelay(time.Millisecond), WithPanicHandler(func(_ *PID, reason any, _ []byte) {
+		actorReports <- reason
+		panic("hook failed")
+	}))
+	e.Send(pid, "bang")
+	assert.Equal(t, "bang", <-actorReports)
+	select {
+	case r := <-engineReports:
+		t.Fatalf("引擎级别的钩子不应被调用: %v", r)
+	default:
+	}
+	e.Send(pid, "again")
+	assert.Equal(t, "again", <-actorReports)
+}
@@ -0,0 +1,111 @@
+package actor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PersistentReceiver 是支持事件溯源的接收器。actor 通过 Context.Persist 持久化事件，
+// 事件写入 Journal 后再通过 ApplyEvent 应用到 actor 的状态上。
+// actor 每次启动（包括崩溃后的重启）时，都会按顺序将 Journal 中的事件回放给 ApplyEvent，
+// 因此在收到 Initialized 和 Started 消息时，actor 的状态已经恢复。
+type PersistentReceiver interface {
+	Receiver
+	// PersistenceID 返回用于在 Journal 中存储事件的唯一 ID。
+	PersistenceID() string
+	// ApplyEvent 将事件应用到 actor 的状态上。它应当只修改状态，不产生副作用。
+	ApplyEvent(event any)
+}
+
+// Journal 是事件日志存储接口。
+type Journal interface {
+	// WriteEvent 为给定的持久化 ID 追加一条序号为 seq 的事件。
+	// seq 从 1 开始连续递增；如果 seq 不是下一个序号，应返回错误。
+	WriteEvent(persistenceID string, seq uint64, event any) error
+	// ReplayEvents 按顺序对所有序号大于等于 fromSeq 的事件调用 fn。
+	ReplayEvents(persistenceID string, fromSeq uint64, fn func(seq uint64, event any)) error
+}
+
+// MemoryJournal 是基于内存的 Journal 实现，事件只在进程生命周期内保留。
+// 未配置 Journal 时引擎默认使用它。
+type MemoryJournal struct {
+	mu     sync.RWMutex
+	events map[string][]any
+}
+
+// NewMemoryJournal 创建一个新的内存 Journal。
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{
+		events: make(map[string][]any),
+	}
+}
+
+// WriteEvent 实现 Journal 接口。
+func (j *MemoryJournal) WriteEvent(persistenceID string, seq uint64, event any) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	events := j.events[persistenceID]
+	if want := uint64(len(events)) + 1; seq != want {
+		return fmt.Errorf("事件序号冲突 (%s): 期望 %d，得到 %d", persistenceID, want, seq)
+	}
+	j.events[persistenceID] = append(events, event)
+	return nil
+}
+
+// ReplayEvents 实现 Journal 接口。
+func (j *MemoryJournal) ReplayEvents(persistenceID string, fromSeq uint64, fn func(seq uint64, event any)) error {
+	j.mu.RLock()
+	events := j.events[persistenceID]
+	j.mu.RUnlock()
+	if fromSeq == 0 {
+		fromSeq = 1
+	}
+	for seq := fromSeq; seq <= uint64(len(events)); seq++ {
+		fn(seq, events[seq-1])
+	}
+	return nil
+}
+
+// Persist 将事件写入 Journal，并在写入成功后调用接收器的 ApplyEvent。
+// 只能在实现了 PersistentReceiver 的 actor 中调用。
+func (c *Context) Persist(event any) error {
+	recv, ok := c.receiver.(PersistentReceiver)
+	if !ok {
+		return fmt.Errorf("接收器 %T 没有实现 PersistentReceiver", c.receiver)
+	}
+	seq := c.persistSeq + 1
+	if err := c.journal.WriteEvent(recv.PersistenceID(), seq, event); err != nil {
+		return err
+	}
+	c.persistSeq = seq
+	recv.ApplyEvent(event)
+	return c.maybeSnapshot()
+}
+
+// SequenceNr 返回最后一条已持久化（或已回放）事件的序号。
+func (c *Context) SequenceNr() uint64 {
+	return c.persistSeq
+}
+
+// recover 恢复持久化 actor 的状态：如果存在快照，先通过 receive 投递
+// RecoverFromSnapshot，然后从 Journal 中回放快照之后的事件。
+func (c *Context) recover(receive ReceiveFunc) error {
+	c.persistSeq = 0
+	recv, ok := c.receiver.(PersistentReceiver)
+	if !ok {
+		return nil
+	}
+	snapshot, err := c.snapshotStore.LoadSnapshot(recv.PersistenceID())
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		c.message = RecoverFromSnapshot{Seq: snapshot.Seq, State: snapshot.State}
+		receive(c)
+		c.persistSeq = snapshot.Seq
+	}
+	return c.journal.ReplayEvents(recv.PersistenceID(), c.persistSeq+1, func(seq uint64, event any) {
+		recv.ApplyEvent(event)
+		c.persistSeq = seq
+	})
+}
@@ -0,0 +1,122 @@
+package actor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type incremented struct{ by int }
+
+type persistentCounter struct {
+	id    string
+	count int
+}
+
+func (c *persistentCounter) PersistenceID() string { return c.id }
+
+func (c *persistentCounter) ApplyEvent(event any) {
+	if e, ok := event.(incremented); ok {
+		c.count += e.by
+	}
+}
+
+func (c *persistentCounter) Receive(ctx *Context) {
+	switch msg := ctx.Message().(type) {
+	case int:
+		if err := ctx.Persist(incremented{by: msg}); err != nil {
+			panic(err)
+		}
+	case string:
+		switch msg {
+		case "count":
+			ctx.Respond(c.count)
+		case "crash":
+			panic("crash")
+		}
+	}
+}
+
+func newPersistentCounter(id string) Producer {
+	return func() Receiver {
+		return &persistentCounter{id: id}
+	}
+}
+
+func requestCount(t *testing.T, e *Engine, pid *PID) int {
+	resp, err := e.Request(pid, "count", time.Second).Result()
+	require.NoError(t, err)
+	return resp.(int)
+}
+
+func TestPersistRecoverAfterRestart(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.Spawn(newPersistentCounter("counter-1"), "counter", WithRestartDelay(time.Millisecond))
+	e.Send(pid, 1)
+	e.Send(pid, 2)
+	assert.Equal(t, 3, requestCount(t, e, pid))
+
+	e.Send(pid, "crash")
+	assert.Equal(t, 3, requestCount(t, e, pid))
+}
+
+func TestPersistRecoverOnRespawn(t *testing.T) {
+	journal := NewMemoryJournal()
+	e, err := NewEngine(NewEngineConfig().WithJournal(journal))
+	require.NoError(t, err)
+	pid := e.Spawn(newPersistentCounter("counter-2"), "counter", WithID("a"))
+	e.Send(pid, 5)
+	assert.Equal(t, 5, requestCount(t, e, pid))
+	<-e.Poison(pid).Done()
+
+	pid = e.Spawn(newPersistentCounter("counter-2"), "counter", WithID("a"))
+	e.Send(pid, 1)
+	assert.Equal(t, 6, requestCount(t, e, pid))
+
+	var events []any
+	require.NoError(t, journal.ReplayEvents("counter-2", 1, func(_ uint64, event any) {
+		events = append(events, event)
+	}))
+	assert.Equal(t, []any{incremented{by: 5}, incremented{by: 1}}, events)
+}
+
+type failingJournal struct{ *MemoryJournal }
+
+func (failingJournal) WriteEvent(string, uint64, any) error {
+	return errors.New("disk full")
+}
+
+func TestPersistJournalError(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	e.Spawn(func() Receiver {
+		return &persistentCounter{id: "counter-3"}
+	}, "counter", WithJournal(failingJournal{NewMemoryJournal()}), WithMiddleware(func(next ReceiveFunc) ReceiveFunc {
+		return func(c *Context) {
+			if _, ok := c.Message().(Started); ok {
+				errCh <- c.Persist(incremented{by: 1})
+				assert.Equal(t, uint64(0), c.SequenceNr())
+				assert.Equal(t, 0, c.Receiver().(*persistentCounter).count)
+			}
+			next(c)
+		}
+	}))
+	assert.EqualError(t, <-errCh, "disk full")
+}
+
+func TestPersistNonPersistentReceiver(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			errCh <- c.Persist("event")
+		}
+	}, "plain")
+	assert.Error(t, <-errCh)
+}
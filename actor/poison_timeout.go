@@ -0,0 +1,83 @@
+package actor
+
+import (
+	"context"
+	"time"
+)
+
+// PoisonOutcome 表示 PoisonWithTimeout 停止 actor 的方式。
+type PoisonOutcome int
+
+const (
+	// PoisonGraceful 表示 actor 在期限内处理完收件箱并优雅停止。
+	PoisonGraceful PoisonOutcome = iota
+	// PoisonForced 表示 actor 未能在期限内停止，已被强制停止。
+	PoisonForced
+	// PoisonNotFound 表示给定的 PID 没有对应的本地 actor。
+	PoisonNotFound
+)
+
+func (o PoisonOutcome) String() string {
+	switch o {
+	case PoisonGraceful:
+		return "graceful"
+	case PoisonForced:
+		return "forced"
+	case PoisonNotFound:
+		return "not found"
+	}
+	return "unknown"
+}
+
+// PoisonWithTimeout 尝试优雅地停止给定的 actor，如果 actor 在 d 时间内没有处理完收件箱
+// （例如在 Receive 中永久阻塞），则强制停止它，并返回实际采用的方式。
+// 它会阻塞直到 actor 停止。
+//
+// 强制停止会立即将 actor 从注册表中移除、停止其收件箱、通知监视者并广播
+// ActorStoppedEvent，剩余的消息作为死信投递，子 actor 被 Stop。由于无法中断正在执行的
+// Receive，阻塞的 goroutine 会在 Receive 返回后退出，接收者不会再收到 Stopped 消息。
+func (e *Engine) PoisonWithTimeout(pid *PID, d time.Duration) PoisonOutcome {
+	proc, ok := e.Registry.get(pid).(*process)
+	if !ok {
+		return PoisonNotFound
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	done := e.Poison(pid)
+	select {
+	case <-done.Done():
+		return PoisonGraceful
+	case <-ctx.Done():
+	}
+	if !proc.kill() {
+		// actor 恰好在期限到达时完成了优雅停止。
+		<-done.Done()
+		return PoisonGraceful
+	}
+	return PoisonForced
+}
+
+// kill 强制停止进程，不等待其 goroutine 返回。进程已经终止时返回 false。
+func (p *process) kill() bool {
+	if !p.terminated.CompareAndSwap(false, true) {
+		return false
+	}
+	e := p.context.engine
+	p.context.stopTimers()
+	p.inbox.Stop()
+	if p.dedicated != nil {
+		p.dedicated.stop()
+	}
+	// 取消收件箱中的 poisonPill（包括 PoisonWithTimeout 自己发送的），其他等待者因此返回。
+	defer p.discard(p.drainInbox(nil))
+	e.Registry.Remove(p.pid)
+	if p.context.parentCtx != nil {
+		p.context.parentCtx.children.Delete(p.pid.ID)
+	}
+	for _, child := range p.context.Children() {
+		e.Stop(child)
+	}
+	p.notifyWatchers()
+	e.BroadcastEvent(ActorStoppedEvent{PID: p.pid, Timestamp: time.Now()})
+	return true
+}
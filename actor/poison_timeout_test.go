@@ -0,0 +1,98 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoisonWithTimeoutGraceful(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	stopped := make(chan struct{})
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Stopped); ok {
+			close(stopped)
+		}
+	}, "foo")
+	assert.Equal(t, PoisonGraceful, e.PoisonWithTimeout(pid, time.Second))
+	<-stopped
+	assert.Nil(t, e.Registry.get(pid))
+	assert.Equal(t, PoisonNotFound, e.PoisonWithTimeout(pid, time.Second))
+}
+
+func TestPoisonWithTimeoutForced(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		release   = make(chan struct{})
+		blocked   = make(chan struct{})
+		childDone = make(chan *PID, 1)
+	)
+	defer close(release)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			childDone <- c.SpawnChildFunc(func(*Context) {}, "child")
+		case string:
+			close(blocked)
+			<-release
+		}
+	}, "hung")
+	child := <-childDone
+	terminated := make(chan *Terminated, 1)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.Watch(pid)
+		case *Terminated:
+			terminated <- msg
+		}
+	}, "watcher")
+	e.Send(pid, "block")
+	<-blocked
+
+	assert.Equal(t, PoisonForced, e.PoisonWithTimeout(pid, 20*time.Millisecond))
+	assert.Nil(t, e.Registry.get(pid))
+	select {
+	case msg := <-terminated:
+		assert.Equal(t, pid, msg.PID)
+	case <-time.After(time.Second):
+		t.Fatal("expected Terminated")
+	}
+	assert.Eventually(t, func() bool {
+		return e.Registry.get(child) == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestPoisonWithTimeoutReleasesOtherWaiters(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		release = make(chan struct{})
+		blocked = make(chan struct{})
+	)
+	defer close(release)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			close(blocked)
+			<-release
+		}
+	}, "hung")
+	e.Send(pid, "block")
+	<-blocked
+
+	// 两个等待者的 poisonPill 排在阻塞的消息之后，强制停止时必须一起完成。
+	first := e.Poison(pid)
+	second := e.Poison(pid)
+	assert.Equal(t, PoisonForced, e.PoisonWithTimeout(pid, 20*time.Millisecond))
+	for _, done := range []interface{ Done() <-chan struct{} }{first, second} {
+		select {
+		case <-done.Done():
+		case <-time.After(time.Second):
+			t.Fatal("poison waiter should be released by the forced stop")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package actor
+
+import "slices"
+
+// Grow 发送给 SpawnPool 返回的 PID，使池增加 N 个工作者。
+type Grow struct {
+	N int
+}
+
+// Shrink 发送给 SpawnPool 返回的 PID，使池优雅地停止 N 个工作者。
+// 被移除的工作者会先处理完收件箱中已有的消息。
+type Shrink struct {
+	N int
+}
+
+// PoolWorkers 是发送给池的请求，池以 []*PID 响应当前的工作者列表。
+type PoolWorkers struct{}
+
+// pool 是 SpawnPool 创建的路由 actor，它把收到的消息轮询转发给工作者。
+type pool struct {
+	producer   Producer
+	size       int
+	workerOpts []OptFunc
+	workers    []*PID
+	next       int
+}
+
+// SpawnPool 创建一个包含 size 个工作者的 actor 池，并返回池的 PID。
+// 发送到该 PID 的消息以轮询方式转发给工作者，并保留原始发送者，
+// 因此工作者可以直接 Respond 请求。池的大小可以通过 Grow 和 Shrink 消息动态调整。
+//
+// 工作者是池的子 actor：WithID 和 WithSupervisor 作用于池本身（监督策略决定崩溃的
+// 工作者是重启还是停止），其余选项作用于每个工作者。工作者永久停止（例如超过
+// 最大重启次数）时，池会创建一个新的工作者代替它，使池保持原有大小。
+func (e *Engine) SpawnPool(p Producer, kind string, size int, opts ...OptFunc) *PID {
+	options := DefaultOpts(p)
+	for _, opt := range opts {
+		opt(&options)
+	}
+	poolOpts := []OptFunc{WithID(options.ID)}
+	if options.Supervisor != nil {
+		poolOpts = append(poolOpts, WithSupervisor(options.Supervisor))
+	}
+	// 工作者使用自动生成的 ID。
+	workerOpts := append(slices.Clone(opts), WithID(""))
+	return e.Spawn(func() Receiver {
+		return &pool{
+			producer:   p,
+			size:       size,
+			workerOpts: workerOpts,
+		}
+	}, kind, poolOpts...)
+}
+
+// Receive 处理池的消息。
+func (p *pool) Receive(c *Context) {
+	switch msg := c.Message().(type) {
+	case Started:
+		p.spawn(c, p.size)
+	case Stopped, Initialized:
+	case Grow:
+		p.size += msg.N
+		p.spawn(c, msg.N)
+	case Shrink:
+		n := min(msg.N, len(p.workers))
+		p.size -= n
+		for _, pid := range p.workers[len(p.workers)-n:] {
+			c.Unwatch(pid)
+			c.engine.Poison(pid)
+		}
+		p.workers = p.workers[:len(p.workers)-n]
+	case PoolWorkers:
+		c.Respond(slices.Clone(p.workers))
+	case *Terminated:
+		i := slices.IndexFunc(p.workers, msg.PID.Equals)
+		if i < 0 {
+			return
+		}
+		p.workers = slices.Delete(p.workers, i, i+1)
+		p.spawn(c, 1)
+	default:
+		p.route(c, msg)
+	}
+}
+
+// spawn 创建 n 个新的工作者并监视它们。
+func (p *pool) spawn(c *Context, n int) {
+	for i := 0; i < n; i++ {
+		pid := c.SpawnChild(p.producer, "worker", p.workerOpts...)
+		c.Watch(pid)
+		p.workers = append(p.workers, pid)
+	}
+}
+
+// route 把消息转发给下一个工作者。池中没有工作者时消息成为死信。
+func (p *pool) route(c *Context, msg any) {
+	if len(p.workers) == 0 {
+		c.engine.deadLetter(c.PID(), msg, c.Sender())
+		return
+	}
+	p.next %= len(p.workers)
+	c.engine.SendWithSender(p.workers[p.next], msg, c.Sender())
+	p.next++
+}
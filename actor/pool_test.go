@@ -0,0 +1,79 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func poolWorkers(t *testing.T, e *Engine, pid *PID) []*PID {
+	resp, err := e.Request(pid, PoolWorkers{}, time.Second).Result()
+	require.NoError(t, err)
+	return resp.([]*PID)
+}
+
+func TestSpawnPoolRouting(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		mu   sync.Mutex
+		seen = map[string]int{}
+	)
+	pid := e.SpawnPool(newFuncReceiver(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			mu.Lock()
+			seen[c.PID().ID]++
+			mu.Unlock()
+			c.Respond(c.PID())
+		}
+	}), "pool", 3, WithID("p"))
+	require.Equal(t, "pool/p", pid.ID)
+
+	for i := 0; i < 6; i++ {
+		_, err := e.Request(pid, "work", time.Second).Result()
+		require.NoError(t, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, seen, 3)
+	for _, n := range seen {
+		require.Equal(t, 2, n)
+	}
+}
+
+func TestSpawnPoolResize(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.SpawnPool(newFuncReceiver(func(c *Context) {}), "pool", 2)
+	require.Len(t, poolWorkers(t, e, pid), 2)
+
+	e.Send(pid, Grow{N: 3})
+	require.Len(t, poolWorkers(t, e, pid), 5)
+
+	workers := poolWorkers(t, e, pid)
+	e.Send(pid, Shrink{N: 4})
+	require.Len(t, poolWorkers(t, e, pid), 1)
+	require.Eventually(t, func() bool {
+		return e.Registry.get(workers[4]) == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSpawnPoolReplacesStoppedWorker(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.SpawnPool(newFuncReceiver(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			panic("boom")
+		}
+	}), "pool", 2, WithSupervisor(NewOneForOneStrategy(func(*PID, any) Directive {
+		return DirectiveStop
+	})))
+	before := poolWorkers(t, e, pid)
+	e.Send(pid, "crash")
+	require.Eventually(t, func() bool {
+		after := poolWorkers(t, e, pid)
+		return len(after) == 2 && after[0].Equals(before[1]) && !after[1].Equals(before[0])
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,152 @@
+package actor
+
+import (
+	"runtime"
+	"sync/atomic"
+
+	"github.com/TAnNbR/Distributed-framework/ringbuffer"
+)
+
+// Priority 是消息的优先级。
+type Priority int8
+
+const (
+	// PriorityNormal 是普通消息的优先级。
+	PriorityNormal Priority = iota
+	// PriorityHigh 是高优先级，高优先级消息总是在普通消息之前被处理。
+	PriorityHigh
+)
+
+// Prioritized 可以由用户消息实现，用于在 PriorityInbox 中声明消息的优先级。
+type Prioritized interface {
+	Priority() Priority
+}
+
+// isSystemMessage 判断给定的消息是否为引擎内部的系统/控制消息。
+// 优雅的 poisonPill 需要在之前的消息处理完成后才生效，因此不算作系统消息。
+func isSystemMessage(msg any) bool {
+	switch m := msg.(type) {
+	case poisonPill:
+		return !m.graceful
+	case supervisorRestart, escalatedFailure, *Watch, *Unwatch:
+		return true
+	}
+	return false
+}
+
+// PriorityInbox 是支持消息优先级的收件箱。系统消息（如 Stop 发送的 poisonPill）
+// 总是最先被处理，其次是实现了 Prioritized 并返回 PriorityHigh 的消息，最后是普通消息。
+// 同一优先级内的消息保持先进先出的顺序。
+type PriorityInbox struct {
+	system     *ringbuffer.RingBuffer[Envelope]
+	high       *ringbuffer.RingBuffer[Envelope]
+	normal     *ringbuffer.RingBuffer[Envelope]
+	proc       Processer
+	scheduler  Scheduler
+	procStatus int32
+}
+
+// NewPriorityInbox 创建一个新的优先级收件箱。
+func NewPriorityInbox(size int) *PriorityInbox {
+	return &PriorityInbox{
+		system:     ringbuffer.New[Envelope](int64(size)),
+		high:       ringbuffer.New[Envelope](int64(size)),
+		normal:     ringbuffer.New[Envelope](int64(size)),
+		scheduler:  NewScheduler(defaultThroughput),
+		procStatus: stopped,
+	}
+}
+
+// setScheduler 替换收件箱的调度器。
+func (in *PriorityInbox) setScheduler(s Scheduler) {
+	in.scheduler = s
+}
+
+// Send 根据消息的优先级将其放入对应的队列。
+func (in *PriorityInbox) Send(msg Envelope) {
+	switch {
+	case isSystemMessage(msg.Msg):
+		in.system.Push(msg)
+	case isHighPriority(msg.Msg):
+		in.high.Push(msg)
+	default:
+		in.normal.Push(msg)
+	}
+	in.schedule()
+}
+
+func isHighPriority(msg any) bool {
+	p, ok := msg.(Prioritized)
+	return ok && p.Priority() >= PriorityHigh
+}
+
+// len 返回所有队列中的消息总数。
+func (in *PriorityInbox) len() int64 {
+	return in.system.Len() + in.high.Len() + in.normal.Len()
+}
+
+// Len 返回所有队列中等待处理的消息数量。
+func (in *PriorityInbox) Len() int {
+	return int(in.len())
+}
+
+func (in *PriorityInbox) schedule() {
+	if atomic.CompareAndSwapInt32(&in.procStatus, idle, running) {
+		in.scheduler.Schedule(in.process)
+	}
+}
+
+func (in *PriorityInbox) process() {
+	in.run()
+	if atomic.CompareAndSwapInt32(&in.procStatus, running, idle) && in.len() > 0 {
+		in.schedule()
+	}
+}
+
+// pop 按优先级从高到低取出一批消息，写入 dst 的底层数组。
+func (in *PriorityInbox) pop(dst []Envelope) ([]Envelope, bool) {
+	if msgs, ok := in.system.PopNInto(dst, messageBatchSize); ok {
+		return msgs, true
+	}
+	if msgs, ok := in.high.PopNInto(dst, messageBatchSize); ok {
+		return msgs, true
+	}
+	return in.normal.PopNInto(dst, messageBatchSize)
+}
+
+func (in *PriorityInbox) run() {
+	batch := getBatch()
+	msgs := *batch
+	defer func() { putBatch(batch, msgs) }()
+	i, t := 0, in.scheduler.Throughput()
+	for atomic.LoadInt32(&in.procStatus) != stopped {
+		if i > t {
+			i = 0
+			runtime.Gosched()
+		}
+		i++
+
+		var ok bool
+		if msgs, ok = in.pop(msgs[:0]); ok && len(msgs) > 0 {
+			in.proc.Invoke(msgs)
+			clear(msgs)
+		} else {
+			return
+		}
+	}
+}
+
+// Start 启动收件箱。
+func (in *PriorityInbox) Start(proc Processer) {
+	if atomic.CompareAndSwapInt32(&in.procStatus, stopped, starting) {
+		in.proc = proc
+		atomic.SwapInt32(&in.procStatus, idle)
+		in.schedule()
+	}
+}
+
+// Stop 停止收件箱。
+func (in *PriorityInbox) Stop() error {
+	atomic.StoreInt32(&in.procStatus, stopped)
+	return nil
+}
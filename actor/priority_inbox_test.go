@@ -0,0 +1,71 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type urgent struct{ n int }
+
+func (urgent) Priority() Priority { return PriorityHigh }
+
+func TestPriorityInboxOrder(t *testing.T) {
+	inbox := NewPriorityInbox(4)
+	var (
+		mu        sync.Mutex
+		processed []any
+		done      = make(chan struct{})
+	)
+	mockProc := MockProcesser{
+		processFunc: func(envelopes []Envelope) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, e := range envelopes {
+				processed = append(processed, e.Msg)
+			}
+			if len(processed) == 5 {
+				close(done)
+			}
+		},
+	}
+	// 收件箱启动前发送的消息会被缓冲，启动后按优先级处理。
+	inbox.Send(Envelope{Msg: 1})
+	inbox.Send(Envelope{Msg: urgent{n: 1}})
+	inbox.Send(Envelope{Msg: 2})
+	inbox.Send(Envelope{Msg: poisonPill{}})
+	inbox.Send(Envelope{Msg: urgent{n: 2}})
+	inbox.Start(mockProc)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, processed, 5)
+	assert.IsType(t, poisonPill{}, processed[0])
+	assert.Equal(t, []any{urgent{n: 1}, urgent{n: 2}, 1, 2}, processed[1:])
+}
+
+func TestPriorityInboxGracefulPoisonNotPrioritized(t *testing.T) {
+	assert.True(t, isSystemMessage(poisonPill{graceful: false}))
+	assert.False(t, isSystemMessage(poisonPill{graceful: true}))
+	assert.False(t, isSystemMessage(1))
+}
+
+func TestSpawnWithPriorityInbox(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case int, urgent:
+			wg.Done()
+		}
+	}, "prio", WithPriorityInbox())
+	e.Send(pid, 1)
+	e.Send(pid, urgent{})
+	wg.Wait()
+	<-e.Stop(pid).Done()
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"runtime/debug"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/gostackparse"
@@ -15,9 +17,15 @@ import (
 type Envelope struct {
 	Msg    any
 	Sender *PID
+	// CorrelationID 是消息所属的逻辑请求的关联 ID，参见 Context.CorrelationID。
+	CorrelationID string
+	// RequestID 是远程请求的请求 ID，响应时原样带回，参见 Engine.SendLocalRequest。
+	RequestID uint64
 }
 
 // Processer 是一个接口，抽象了进程的行为方式。
+// 收件箱传给 Invoke 的切片来自共享的批次池，Invoke 返回后即被复用，
+// 实现不能在返回后继续持有它。
 type Processer interface {
 	Start()
 	PID() *PID
@@ -34,19 +42,75 @@ type process struct {
 	context  *Context
 	pid      *PID
 	restarts int32
-	mbuffer  []Envelope
+	// restartDelay 和 lastRestart 用于计算重启退避。
+	restartDelay time.Duration
+	lastRestart  time.Time
+	mbuffer      []Envelope
+	// watchers 是监视该进程的 PID 集合，进程终止时它们将收到 *Terminated。
+	// 强制停止可能在其他 goroutine 中通知监视者，因此由 watchMu 保护。
+	watchMu  sync.Mutex
+	watchers *PIDSet
+	// terminated 在进程清理或被强制停止后为 true，保证终止流程只执行一次。
+	terminated atomic.Bool
+	// backlogged 在收件箱积压达到阈值后为 true，回落到阈值以下时重置。
+	backlogged bool
+	// dedicated 是 WithDedicatedGoroutine 创建的专用调度器，进程终止时停止。
+	dedicated *dedicatedScheduler
 }
 
 // newProcess 创建一个新的进程。
 func newProcess(e *Engine, opts Opts) *process {
 	pid := NewPID(e.address, opts.Kind+pidSeparator+opts.ID)
+	var inbox Inboxer
+	if opts.Inbox != nil {
+		inbox = opts.Inbox(opts.InboxSize)
+	} else {
+		inbox = NewInbox(opts.InboxSize)
+	}
+	var dedicated *dedicatedScheduler
+	if s, ok := inbox.(schedulerSetter); ok {
+		if opts.DedicatedGoroutine {
+			dedicated = newDedicatedScheduler(opts.Throughput)
+			s.setScheduler(dedicated)
+		} else if sched := opts.scheduler(e); sched != nil {
+			s.setScheduler(sched)
+		}
+	}
+	if n, ok := inbox.(overflowNotifier); ok {
+		n.setOverflowHandler(func(env Envelope) {
+			e.BroadcastEvent(MailboxFullEvent{Target: pid, Message: env.Msg, Sender: env.Sender})
+		}, func(env Envelope) {
+			e.deadLetter(pid, env.Msg, env.Sender)
+		})
+	}
+	if opts.SlowThreshold == 0 && !opts.System {
+		opts.SlowThreshold = e.slowThreshold
+	}
+	if opts.BacklogThreshold == 0 && !opts.System {
+		opts.BacklogThreshold = e.backlogThreshold
+	}
 	ctx := newContext(opts.Context, e, pid)
+	ctx.supervisor = opts.Supervisor
+	ctx.receiveTimeout = opts.ReceiveTimeout
+	ctx.journal = opts.Journal
+	if ctx.journal == nil {
+		ctx.journal = e.journal
+	}
+	ctx.snapshotStore = opts.SnapshotStore
+	if ctx.snapshotStore == nil {
+		ctx.snapshotStore = e.snapshotStore
+	}
+	if opts.SnapshotEvery > 0 {
+		ctx.snapshotEvery = uint64(opts.SnapshotEvery)
+	}
 	p := &process{
-		pid:     pid,
-		inbox:   NewInbox(opts.InboxSize),
-		Opts:    opts,
-		context: ctx,
-		mbuffer: nil,
+		pid:       pid,
+		inbox:     inbox,
+		Opts:      opts,
+		context:   ctx,
+		mbuffer:   nil,
+		watchers:  NewPIDSet(),
+		dedicated: dedicated,
 	}
 	return p
 }
@@ -62,69 +126,147 @@ func applyMiddleware(rcv ReceiveFunc, middleware ...MiddlewareFunc) ReceiveFunc
 // Invoke 处理消息列表。
 func (p *process) Invoke(msgs []Envelope) {
 	var (
-		// 需要处理的消息数量
-		nmsg = len(msgs)
 		// 已处理的消息数量
 		nproc = 0
 		// 修复: 我们可以在这里使用 nproc，但由于某种原因，将 nproc++ 放在
 		// 函数底部会冻结某些测试。因此，我创建了一个新的计数器用于记录。
 		processed = 0
+		slow      = p.newSlowBatch()
 	)
+	if p.terminated.Load() {
+		p.discard(msgs)
+		return
+	}
+	p.checkBacklog(len(msgs))
 	defer func() {
 		// 如果我们恢复了，我们将缓冲所有无法处理的消息，
 		// 以便在下次重启时重试。
 		if v := recover(); v != nil {
-			p.context.message = Stopped{}
-			p.context.receiver.Receive(p.context)
-
-			p.mbuffer = make([]Envelope, nmsg-nproc)
-			for i := 0; i < nmsg-nproc; i++ {
-				p.mbuffer[i] = msgs[i+nproc]
+			var offending *Envelope
+			if nproc > 0 {
+				offending = &msgs[nproc-1]
 			}
+			p.bufferAndStop(msgs[nproc:], offending)
 			p.tryRestart(v)
 		}
 	}()
 
 	for i := 0; i < len(msgs); i++ {
+		// 进程在处理上一条消息时被强制停止，丢弃剩余的消息。
+		if p.terminated.Load() {
+			p.discard(msgs[i:])
+			return
+		}
 		nproc++
 		msg := msgs[i]
 		if pill, ok := msg.Msg.(poisonPill); ok {
 			// 如果需要优雅停止，我们处理收件箱中的所有消息，
 			// 否则我们忽略并清理。
+			var pending []Envelope
 			if pill.graceful {
 				msgsToProcess := msgs[processed:]
 				for _, m := range msgsToProcess {
 					p.invokeMsg(m)
 				}
+				// 批次中其他的 poisonPill 也在停止后完成。
+				defer func() {
+					for _, m := range msgsToProcess {
+						if other, ok := m.Msg.(poisonPill); ok && other.cancel != nil {
+							other.cancel()
+						}
+					}
+				}()
+			} else {
+				pending = msgs[nproc:]
 			}
-			p.cleanup(pill.cancel)
+			p.cleanup(pill.cancel, pending)
+			return
+		}
+		switch m := msg.Msg.(type) {
+		case supervisorRestart:
+			p.bufferAndStop(msgs[nproc:], nil)
+			p.restart(m.reason, nil)
+			return
+		case escalatedFailure:
+			// 子 actor 上报的失败，视同自身崩溃，交由我们的监督者处理。
+			p.bufferAndStop(msgs[nproc:], nil)
+			p.tryRestart(m.reason)
 			return
 		}
+		slow.begin()
 		p.invokeMsg(msg)
+		slow.end(msg.Msg)
 		processed++
 	}
+	p.reportSlow(&slow)
+}
+
+// bufferAndStop 按照 CrashBufferPolicy 缓冲尚未处理的消息（包括暂存的消息）
+// 以便重启后重试，并向接收者投递 Stopped 消息。offending 是导致崩溃的消息。
+func (p *process) bufferAndStop(msgs []Envelope, offending *Envelope) {
+	p.context.message = Stopped{}
+	p.context.receiver.Receive(p.context)
+	p.mbuffer = p.crashBuffer(p.context.takeStash(), msgs, offending)
 }
 
 // invokeMsg 处理单条消息。
 func (p *process) invokeMsg(msg Envelope) {
-	// 在这里过滤 poisonPill 消息。它们是 actor 引擎私有的。
-	if _, ok := msg.Msg.(poisonPill); ok {
+	var trace *TraceContext
+	if t, ok := msg.Msg.(*Traced); ok {
+		msg.Msg, trace = t.Message, &t.Trace
+	}
+	// 在这里过滤 poisonPill 和监督消息。它们是 actor 引擎私有的。
+	switch m := msg.Msg.(type) {
+	case poisonPill, supervisorRestart, escalatedFailure:
 		return
+	case *Watch:
+		p.watchMu.Lock()
+		p.watchers.Add(m.Watcher)
+		p.watchMu.Unlock()
+		return
+	case *Unwatch:
+		p.watchMu.Lock()
+		p.watchers.Remove(m.Watcher)
+		p.watchMu.Unlock()
+		return
+	case *Terminated:
+		// 远程 actor 已通知我们它终止了，不再需要在其节点不可达时通知。
+		if !p.context.engine.isLocalMessage(m.PID) {
+			p.context.engine.remoteWatches.remove(m.PID, p.pid)
+		}
 	}
 	p.context.message = msg.Msg
 	p.context.sender = msg.Sender
-	recv := p.context.receiver
+	p.context.correlationID = msg.CorrelationID
+	p.context.requestID = msg.RequestID
+	p.context.trace = trace
+	receive := p.context.behavior()
 	if len(p.Opts.Middleware) > 0 {
-		applyMiddleware(recv.Receive, p.Opts.Middleware...)(p.context)
+		applyMiddleware(receive, p.Opts.Middleware...)(p.context)
 	} else {
-		recv.Receive(p.context)
+		receive(p.context)
+	}
+	p.context.resetReceiveTimeout()
+	// 重新投递通过 UnstashAll 释放的消息。
+	for len(p.context.unstashed) > 0 {
+		next := p.context.unstashed[0]
+		p.context.unstashed = p.context.unstashed[1:]
+		p.invokeMsg(next)
 	}
 }
 
 // Start 启动进程。
 func (p *process) Start() {
+	// 被强制停止的进程不会再被重启。
+	if p.terminated.Load() {
+		return
+	}
 	recv := p.Producer()
 	p.context.receiver = recv
+	// 新的接收器实例从其默认行为开始。
+	p.context.behaviors = nil
+	p.context.correlationID = ""
+	p.context.requestID = 0
 	defer func() {
 		if v := recover(); v != nil {
 			p.context.message = Stopped{}
@@ -132,6 +274,10 @@ func (p *process) Start() {
 			p.tryRestart(v)
 		}
 	}()
+	// 持久化 actor 在处理任何消息之前先恢复状态。
+	if err := p.context.recover(applyMiddleware(recv.Receive, p.Opts.Middleware...)); err != nil {
+		panic(fmt.Errorf("恢复持久化状态失败: %w", err))
+	}
 	p.context.message = Initialized{}
 	applyMiddleware(recv.Receive, p.Opts.Middleware...)(p.context)
 	p.context.engine.BroadcastEvent(ActorInitializedEvent{PID: p.pid, Timestamp: time.Now()})
@@ -145,6 +291,8 @@ func (p *process) Start() {
 		p.mbuffer = nil
 	}
 
+	p.context.resetReceiveTimeout()
+	p.context.resumeTimers()
 	p.inbox.Start(p)
 }
 
@@ -155,23 +303,64 @@ func (p *process) tryRestart(v any) {
 	// 我们可以持续拨号直到它恢复上线。
 	// 注意：不确定这是否是最佳选择。如果该节点永远不再上线怎么办？
 	if msg, ok := v.(*InternalError); ok {
-		slog.Error(msg.From, "err", msg.Err)
-		time.Sleep(p.Opts.RestartDelay)
+		p.context.engine.logger.Error(msg.From, "err", msg.Err)
+		p.context.engine.clock.Sleep(p.nextRestartDelay())
 		p.Start()
 		return
 	}
 	stackTrace := cleanTrace(debug.Stack())
-	// 如果达到最大重启次数，我们关闭收件箱并清理一切。
+	p.reportPanic(v, stackTrace)
+	strategy := p.supervisorStrategy()
+	switch strategy.Decide(p.pid, v) {
+	case DirectiveStop:
+		p.cleanup(nil, p.mbuffer)
+	case DirectiveEscalate:
+		// 没有父 actor 时无处上报，直接停止。
+		if parent := p.context.Parent(); parent != nil {
+			p.context.engine.SendLocal(parent, escalatedFailure{child: p.pid, reason: v}, p.pid)
+		}
+		p.cleanup(nil, p.mbuffer)
+	case DirectiveNotifyParent:
+		p.cleanup(nil, p.mbuffer)
+		if parent := p.context.Parent(); parent != nil {
+			p.context.engine.SendLocal(parent, ChildFailed{PID: p.pid, Reason: v}, p.pid)
+		}
+	default:
+		if strategy.AllForOne() && p.context.parentCtx != nil {
+			for _, sibling := range p.context.parentCtx.Children() {
+				if !sibling.Equals(p.pid) {
+					p.context.engine.SendLocal(sibling, supervisorRestart{reason: v}, p.pid)
+				}
+			}
+		}
+		p.restart(v, stackTrace)
+	}
+}
+
+// supervisorStrategy 返回监督该进程的策略：优先使用自身的 RestartPolicy，
+// 其次是父 actor 配置的策略。
+func (p *process) supervisorStrategy() SupervisorStrategy {
+	if p.Opts.RestartPolicy != nil {
+		return p.Opts.RestartPolicy
+	}
+	if p.context.parentCtx != nil && p.context.parentCtx.supervisor != nil {
+		return p.context.parentCtx.supervisor
+	}
+	return defaultSupervisorStrategy
+}
+
+// restart 在重启延迟后重启进程。如果达到最大重启次数，则关闭收件箱并清理一切。
+func (p *process) restart(v any, stackTrace []byte) {
 	if p.restarts == p.MaxRestarts {
 		p.context.engine.BroadcastEvent(ActorMaxRestartsExceededEvent{
 			PID:       p.pid,
 			Timestamp: time.Now(),
 		})
-		p.cleanup(nil)
+		p.cleanup(nil, p.mbuffer)
 		return
 	}
 
-	p.restarts++
+	atomic.AddInt32(&p.restarts, 1)
 	// 在重启延迟后重启进程
 	p.context.engine.BroadcastEvent(ActorRestartedEvent{
 		PID:        p.pid,
@@ -180,13 +369,19 @@ func (p *process) tryRestart(v any) {
 		Reason:     v,
 		Restarts:   p.restarts,
 	})
-	time.Sleep(p.Opts.RestartDelay)
+	p.context.pauseTimers()
+	p.context.engine.clock.Sleep(p.nextRestartDelay())
 	p.Start()
 }
 
-// cleanup 清理进程资源。
-func (p *process) cleanup(cancel context.CancelFunc) {
-	defer cancel()
+// cleanup 清理进程资源。pending 是尚未处理的消息，配置了 DrainTo 时转发给它。
+func (p *process) cleanup(cancel context.CancelFunc, pending []Envelope) {
+	if cancel != nil {
+		defer cancel()
+	}
+	if !p.terminated.CompareAndSwap(false, true) {
+		return
+	}
 
 	if p.context.parentCtx != nil {
 		p.context.parentCtx.children.Delete(p.pid.ID)
@@ -199,14 +394,40 @@ func (p *process) cleanup(cancel context.CancelFunc) {
 		}
 	}
 
+	p.context.stopReceiveTimeout()
+	p.context.stopTimers()
 	p.inbox.Stop()
+	if p.dedicated != nil {
+		p.dedicated.stop()
+	}
 	p.context.engine.Registry.Remove(p.pid)
 	p.context.message = Stopped{}
 	applyMiddleware(p.context.receiver.Receive, p.Opts.Middleware...)(p.context)
 
+	if p.DrainTo != nil {
+		p.handOff(p.context.takeStash(), pending)
+	} else {
+		for _, env := range p.context.takeStash() {
+			p.context.engine.deadLetter(p.pid, env.Msg, env.Sender)
+		}
+		// 剩余的 poisonPill 在停止完成后取消，其余消息作为死信投递。
+		defer p.discard(p.drainInbox(pending[:len(pending):len(pending)]))
+	}
+
+	p.notifyWatchers()
 	p.context.engine.BroadcastEvent(ActorStoppedEvent{PID: p.pid, Timestamp: time.Now()})
 }
 
+// notifyWatchers 通知所有监视者该进程已终止。
+func (p *process) notifyWatchers() {
+	p.watchMu.Lock()
+	defer p.watchMu.Unlock()
+	p.watchers.ForEach(func(_ int, watcher *PID) {
+		p.context.engine.Send(watcher, &Terminated{PID: p.pid})
+	})
+	p.watchers.Clear()
+}
+
 // PID 返回进程的 PID。
 func (p *process) PID() *PID { return p.pid }
 
@@ -217,7 +438,7 @@ func (p *process) Send(_ *PID, msg any, sender *PID) {
 
 // Shutdown 关闭进程。
 func (p *process) Shutdown() {
-	p.cleanup(nil)
+	p.cleanup(nil, nil)
 }
 
 // cleanTrace 清理堆栈跟踪信息。
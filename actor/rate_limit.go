@@ -0,0 +1,64 @@
+package actor
+
+import (
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/internal/ratelimit"
+)
+
+// RateLimitPolicy 决定消息超过速率限制时如何处理。
+type RateLimitPolicy int
+
+const (
+	// RateLimitDelay 推迟处理，直到速率允许。actor 会放慢消费收件箱的速度，
+	// 消息在收件箱中排队（配合 WithBoundedInbox 可以限制排队的数量）。
+	RateLimitDelay RateLimitPolicy = iota
+	// RateLimitDrop 丢弃超过速率的消息。
+	RateLimitDrop
+)
+
+// WithRateLimit 限制 actor 每 per 时间内最多处理 n 条用户消息（令牌桶，允许 n 条的突发）。
+// 超过限制时按照 policy 推迟或丢弃消息，并在事件流上广播 ThrottledEvent。
+// 生命周期消息不受限制。适用于封装有速率限制的外部 API 的 actor。
+// 令牌按引擎的 Clock 补充。n 或 per 不是正数时不限制速率。
+func WithRateLimit(n int, per time.Duration, policy RateLimitPolicy) OptFunc {
+	return func(opts *Opts) {
+		if n <= 0 || per <= 0 {
+			return
+		}
+		// 令牌桶在处理第一条消息时按引擎的时钟开始计时。
+		limiter := ratelimit.New(n, per, time.Time{})
+		opts.Middleware = append(opts.Middleware, rateLimitMiddleware(limiter, policy))
+	}
+}
+
+func rateLimitMiddleware(limiter *ratelimit.TokenBucket, policy RateLimitPolicy) MiddlewareFunc {
+	return func(next ReceiveFunc) ReceiveFunc {
+		return func(c *Context) {
+			switch c.Message().(type) {
+			case Initialized, Started, Stopped:
+				next(c)
+				return
+			}
+			wait := limiter.Reserve(c.engine.clock.Now(), policy == RateLimitDelay)
+			if wait == 0 {
+				next(c)
+				return
+			}
+			evt := ThrottledEvent{
+				PID:     c.PID(),
+				Message: c.Message(),
+				Sender:  c.Sender(),
+			}
+			if policy == RateLimitDrop {
+				evt.Dropped = true
+				c.engine.BroadcastEvent(evt)
+				return
+			}
+			evt.Delay = wait
+			c.engine.BroadcastEvent(evt)
+			c.engine.clock.Sleep(wait)
+			next(c)
+		}
+	}
+}
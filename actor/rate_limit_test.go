@@ -0,0 +1,104 @@
+package actor
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitDrop(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var dropped atomic.Int32
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(ThrottledEvent); ok && evt.Dropped {
+			dropped.Add(1)
+		}
+	})
+	defer unsubscribe()
+
+	var processed atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			processed.Add(1)
+		}
+	}, "limited", WithRateLimit(5, time.Hour, RateLimitDrop))
+	for i := 0; i < 20; i++ {
+		e.Send(pid, i)
+	}
+	<-e.Poison(pid).Done()
+	assert.Equal(t, int32(5), processed.Load())
+	assert.Eventually(t, func() bool { return dropped.Load() == 15 }, time.Second, time.Millisecond)
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg    sync.WaitGroup
+		start = time.Now()
+	)
+	wg.Add(6)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			wg.Done()
+		}
+	}, "limited", WithRateLimit(3, 60*time.Millisecond, RateLimitDelay))
+	for i := 0; i < 6; i++ {
+		e.Send(pid, i)
+	}
+	wg.Wait()
+	// 前 3 条立即处理，后 3 条每条间隔约 20ms。
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestRateLimitManualClock(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	e, err := NewEngine(NewEngineConfig().WithClock(clock))
+	require.NoError(t, err)
+	var dropped atomic.Int32
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(ThrottledEvent); ok && evt.Dropped {
+			dropped.Add(1)
+		}
+	})
+	defer unsubscribe()
+	var processed atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			processed.Add(1)
+		}
+	}, "limited", WithRateLimit(2, time.Minute, RateLimitDrop))
+	for i := 0; i < 3; i++ {
+		e.Send(pid, i)
+	}
+	require.Eventually(t, func() bool { return dropped.Load() == 1 }, time.Second, time.Millisecond)
+
+	// 令牌只在引擎的时钟前进后补充。
+	clock.Advance(time.Minute)
+	for i := 0; i < 3; i++ {
+		e.Send(pid, i)
+	}
+	<-e.Poison(pid).Done()
+	assert.Equal(t, int32(4), processed.Load())
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var processed atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			processed.Add(1)
+		}
+	}, "unlimited", WithRateLimit(10, 0, RateLimitDrop), WithRateLimit(0, time.Hour, RateLimitDrop))
+	for i := 0; i < 20; i++ {
+		e.Send(pid, i)
+	}
+	<-e.Poison(pid).Done()
+	assert.Equal(t, int32(20), processed.Load())
+}
@@ -0,0 +1,36 @@
+package actor
+
+import "time"
+
+// SetReceiveTimeout 设置接收超时：如果在 d 时间内没有收到任何消息，
+// actor 将收到一条 ReceiveTimeout 消息。只要 actor 保持空闲，
+// ReceiveTimeout 就会每隔 d 投递一次。d <= 0 表示关闭接收超时。
+func (c *Context) SetReceiveTimeout(d time.Duration) {
+	c.receiveTimeout = d
+	if d <= 0 {
+		c.stopReceiveTimeout()
+		return
+	}
+	c.resetReceiveTimeout()
+}
+
+// resetReceiveTimeout 重新开始接收超时的计时。
+func (c *Context) resetReceiveTimeout() {
+	if c.receiveTimeout <= 0 {
+		return
+	}
+	if c.receiveTimer == nil {
+		c.receiveTimer = c.engine.clock.AfterFunc(c.receiveTimeout, func() {
+			c.engine.SendLocal(c.pid, ReceiveTimeout{}, nil)
+		})
+		return
+	}
+	c.receiveTimer.Reset(c.receiveTimeout)
+}
+
+// stopReceiveTimeout 停止接收超时的计时。
+func (c *Context) stopReceiveTimeout() {
+	if c.receiveTimer != nil {
+		c.receiveTimer.Stop()
+	}
+}
@@ -0,0 +1,52 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiveTimeout(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	start := time.Now()
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case ReceiveTimeout:
+			assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+			c.Engine().Poison(c.PID())
+			wg.Done()
+		}
+	}, "idle", WithReceiveTimeout(20*time.Millisecond))
+	// 活跃期间不应收到 ReceiveTimeout。
+	for i := 0; i < 5; i++ {
+		time.Sleep(5 * time.Millisecond)
+		e.Send(pid, i)
+	}
+	wg.Wait()
+}
+
+func TestSetReceiveTimeoutDisable(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	timeouts := make(chan struct{}, 10)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case ReceiveTimeout:
+			timeouts <- struct{}{}
+			c.SetReceiveTimeout(0)
+		}
+	}, "idle", WithReceiveTimeout(5*time.Millisecond))
+	<-timeouts
+	select {
+	case <-timeouts:
+		t.Fatal("ReceiveTimeout delivered after being disabled")
+	case <-time.After(30 * time.Millisecond):
+	}
+	<-e.Poison(pid).Done()
+}
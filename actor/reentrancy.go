@@ -0,0 +1,41 @@
+package actor
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestAsync 向 pid 发送请求，并保证不会阻塞当前 actor：响应到达后作为消息投递给
+// 当前 actor 自身，发送者为 pid；请求失败（超时或对方调用了 RespondErr）时投递 RequestFailure。
+// 关联 ID 会随响应一起投递。即使 pid 是当前 actor 自身也不会死锁。
+func (c *Context) RequestAsync(pid *PID, msg any, timeout time.Duration) {
+	var (
+		e    = c.engine
+		self = c.pid
+		id   = c.correlationID
+	)
+	e.request(pid, msg, timeout, id).Then(func(resp any, err error) {
+		if err != nil {
+			e.sendCorrelated(self, RequestFailure{Target: pid, Err: err}, nil, id)
+			return
+		}
+		e.sendCorrelated(self, resp, pid, id)
+	})
+}
+
+// warnSelfRequest 在阻塞等待 actor 发给自己的请求时广播 BlockingSelfRequestEvent。
+// 如果在 Receive 中等待，actor 无法处理这个请求，只能等到超时。
+func (r *Response) warnSelfRequest() {
+	if r.requester == nil {
+		return
+	}
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+	r.engine.BroadcastEvent(BlockingSelfRequestEvent{
+		PID:         r.requester,
+		MessageType: fmt.Sprintf("%T", r.msg),
+	})
+}
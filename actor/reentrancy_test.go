@@ -0,0 +1,91 @@
+package actor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type selfPing struct{}
+
+func TestRequestAsyncSelf(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	done := make(chan *PID, 1)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			if c.Sender() == nil {
+				c.RequestAsync(c.PID(), selfPing{}, time.Second)
+				return
+			}
+			done <- c.Sender()
+		case selfPing:
+			c.Respond("pong")
+		}
+	}, "self")
+	e.Send(pid, "start")
+
+	select {
+	case sender := <-done:
+		assert.True(t, sender.Equals(pid))
+	case <-time.After(time.Second):
+		t.Fatal("自请求没有完成")
+	}
+}
+
+func TestRequestAsyncFailure(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	target := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(selfPing); ok {
+			c.RespondErr(errors.New("rejected"))
+		}
+	}, "target")
+	failed := make(chan RequestFailure, 1)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch m := c.Message().(type) {
+		case string:
+			c.RequestAsync(target, selfPing{}, time.Second)
+		case RequestFailure:
+			failed <- m
+		}
+	}, "requester")
+	e.Send(pid, "start")
+
+	f := <-failed
+	assert.True(t, f.Target.Equals(target))
+	assert.EqualError(t, f.Err, "rejected")
+}
+
+func TestBlockingSelfRequestEvent(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	events := make(chan BlockingSelfRequestEvent, 1)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(BlockingSelfRequestEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+
+	result := make(chan error, 1)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			_, err := c.Request(c.PID(), selfPing{}, 10*time.Millisecond).Result()
+			result <- err
+		}
+	}, "blocking")
+	e.Send(pid, "start")
+
+	evt := <-events
+	assert.True(t, evt.PID.Equals(pid))
+	assert.Equal(t, "actor.selfPing", evt.MessageType)
+	assert.ErrorIs(t, <-result, ErrRequestTimeout)
+}
@@ -1,6 +1,8 @@
 package actor
 
 import (
+	"path"
+	"sort"
 	"sync"
 )
 
@@ -32,6 +34,24 @@ func (r *Registry) GetPID(kind, id string) *PID {
 	return nil
 }
 
+// Lookup 返回 ID 与给定模式匹配的所有进程的 PID，按 ID 排序。
+// 模式语法与 path.Match 相同，"*" 不会跨越 "/"，例如 "player/*" 匹配 "player/1"，
+// 但不匹配子 actor "player/1/weapon/1"。无效的模式不匹配任何进程。
+func (r *Registry) Lookup(pattern string) []*PID {
+	r.mu.RLock()
+	pids := make([]*PID, 0)
+	for id, proc := range r.lookup {
+		if ok, _ := path.Match(pattern, id); ok {
+			pids = append(pids, proc.PID())
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i].ID < pids[j].ID
+	})
+	return pids
+}
+
 // Remove 从注册表中移除给定的 PID。
 func (r *Registry) Remove(pid *PID) {
 	r.mu.Lock()
@@ -61,16 +81,28 @@ func (r *Registry) getByID(id string) Processer {
 	return r.lookup[id]
 }
 
-// add 向注册表添加一个进程并启动它。
-func (r *Registry) add(proc Processer) {
+// processes 返回当前注册的所有进程的快照。
+func (r *Registry) processes() []Processer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	procs := make([]Processer, 0, len(r.lookup))
+	for _, proc := range r.lookup {
+		procs = append(procs, proc)
+	}
+	return procs
+}
+
+// add 向注册表添加一个进程并启动它。ID 已被占用时广播 ActorDuplicateIdEvent 并返回 false。
+func (r *Registry) add(proc Processer) bool {
 	r.mu.Lock()
 	id := proc.PID().ID
 	if _, ok := r.lookup[id]; ok {
 		r.mu.Unlock()
 		r.engine.BroadcastEvent(ActorDuplicateIdEvent{PID: proc.PID()})
-		return
+		return false
 	}
 	r.lookup[id] = proc
 	r.mu.Unlock()
 	proc.Start()
+	return true
 }
@@ -2,6 +2,7 @@ package actor
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +29,25 @@ func TestGetRemoveAdd(t *testing.T) {
 	proc = reg.get(eproc.PID())
 	assert.Nil(t, proc)
 }
+
+func TestLookup(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	assert.NoError(t, err)
+	noop := func(*Context) {}
+	p2 := e.SpawnFunc(noop, "player", WithID("2"))
+	p1 := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			c.SpawnChildFunc(noop, "weapon", WithID("1"))
+		}
+	}, "player", WithID("1"))
+	e.SpawnFunc(noop, "monster", WithID("1"))
+
+	assert.Equal(t, []*PID{p1, p2}, e.Lookup("player/*"))
+	assert.Equal(t, p1, e.GetPID("player", "1"))
+	assert.Nil(t, e.GetPID("player", "3"))
+	assert.Eventually(t, func() bool {
+		return len(e.Lookup("player/*/weapon/*")) == 1
+	}, time.Second, time.Millisecond)
+	assert.Len(t, e.Lookup("*/1"), 2)
+	assert.Empty(t, e.Lookup("["))
+}
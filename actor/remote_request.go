@@ -0,0 +1,156 @@
+package actor
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// remoteRequestShards 是 remoteRequests 的分片数，分片减少高频请求之间的锁争用。
+const remoteRequestShards = 32
+
+// remoteResponsePrefix 是远程请求的 Response 的 PID 前缀，后面是请求 ID。
+const remoteResponsePrefix = "response" + pidSeparator
+
+// requestRemoter 由能够在消息中携带请求 ID 的 Remoter 实现。远程请求携带请求 ID，
+// 接收者的响应带回同一个 ID，请求方按 ID 直接把响应交给等待的 Response，参见 Engine.Request。
+type requestRemoter interface {
+	SendRequest(pid *PID, msg any, sender *PID, requestID uint64)
+	SendResponse(pid *PID, msg any, requestID uint64)
+}
+
+// remoteRequests 记录向远程 PID 发起的、等待响应的请求，按请求 ID 索引。这些 Response
+// 不注册到 Registry，高频的远程请求因此不会争用注册表的写锁。
+type remoteRequests struct {
+	// next 是上一个分配的请求 ID，从随机值开始，避免引擎重启后把迟到的响应交给新的请求。
+	next   atomic.Uint64
+	shards [remoteRequestShards]remoteRequestShard
+}
+
+type remoteRequestShard struct {
+	mu      sync.Mutex
+	pending map[uint64]*Response
+}
+
+func newRemoteRequests() *remoteRequests {
+	rr := &remoteRequests{}
+	rr.next.Store(uint64(rand.Int63()))
+	return rr
+}
+
+func (rr *remoteRequests) shard(id uint64) *remoteRequestShard {
+	return &rr.shards[id%remoteRequestShards]
+}
+
+// add 为 resp 分配请求 ID 和对应的 PID 并记录它。
+func (rr *remoteRequests) add(e *Engine, resp *Response) {
+	id := rr.next.Add(1)
+	resp.requestID = id
+	resp.pid = NewPID(e.address, remoteResponsePrefix+strconv.FormatUint(id, 10))
+	s := rr.shard(id)
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[uint64]*Response)
+	}
+	s.pending[id] = resp
+	s.mu.Unlock()
+}
+
+func (rr *remoteRequests) remove(id uint64) {
+	s := rr.shard(id)
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+// take 移除并返回等待请求 id 的 Response，没有这样的请求或者它的 PID 不是 pid 时返回 nil。
+// 每个请求只接受第一个响应。
+func (rr *remoteRequests) take(id uint64, pid *PID) *Response {
+	s := rr.shard(id)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := s.pending[id]
+	if resp == nil || !resp.pid.Equals(pid) {
+		return nil
+	}
+	delete(s.pending, id)
+	return resp
+}
+
+// len 返回等待响应的远程请求数。
+func (rr *remoteRequests) len() int {
+	n := 0
+	for i := range rr.shards {
+		s := &rr.shards[i]
+		s.mu.Lock()
+		n += len(s.pending)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// takePID 移除并返回 PID 为 pid 的远程请求的 Response。远程节点不支持请求 ID 时，
+// 响应按 Response 的 PID 投递到这里。
+func (rr *remoteRequests) takePID(pid *PID) *Response {
+	s, ok := strings.CutPrefix(pid.ID, remoteResponsePrefix)
+	if !ok {
+		return nil
+	}
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return rr.take(id, pid)
+}
+
+// addResponse 让 resp 可以接收发往它的 PID 的响应，并设置请求结束时的注销函数。
+// 目标是远程 PID 时使用 remoteRequests，否则注册到 Registry。
+func (e *Engine) addResponse(target *PID, resp *Response) {
+	if e.remote != nil && !e.isLocalMessage(target) {
+		e.remoteRequests.add(e, resp)
+		resp.unregister = func() { e.remoteRequests.remove(resp.requestID) }
+		return
+	}
+	e.Registry.add(resp)
+	resp.unregister = func() { e.Registry.Remove(resp.pid) }
+}
+
+// sendRequest 发送请求消息，响应的接收者是 resp。Remoter 支持请求 ID 时，
+// 远程请求携带 resp 的请求 ID。
+func (e *Engine) sendRequest(pid *PID, msg any, resp *Response, correlationID string) {
+	if rr, ok := e.remote.(requestRemoter); ok && resp.requestID != 0 {
+		rr.SendRequest(pid, msg, resp.pid, resp.requestID)
+		return
+	}
+	e.sendCorrelated(pid, msg, resp.pid, correlationID)
+}
+
+// respondRemote 以请求 ID 把响应直接发回远程的请求方。pid 不是远程 PID
+// 或者 Remoter 不支持请求 ID 时返回 false，调用者按 PID 发送响应。
+func (e *Engine) respondRemote(pid *PID, msg any, requestID uint64) bool {
+	rr, ok := e.remote.(requestRemoter)
+	if !ok || requestID == 0 || e.isLocalMessage(pid) {
+		return false
+	}
+	rr.SendResponse(pid, msg, requestID)
+	return true
+}
+
+// SendLocalRequest 把远程节点发来的请求投递给本地的 pid。requestID 是请求方分配的请求 ID，
+// 接收者通过 Context.Respond 或 Context.RespondErr 响应时，响应携带这个 ID 直接返回请求方。
+// 它由远程模块调用。
+func (e *Engine) SendLocalRequest(pid *PID, msg any, sender *PID, requestID uint64) {
+	e.deliverLocal(pid, Envelope{Msg: msg, Sender: sender, RequestID: requestID})
+}
+
+// SendLocalResponse 把远程节点以请求 ID 返回的响应交给等待的 Response，pid 是 Response 的 PID。
+// 请求已经结束（例如超时）时，响应被发送到死信。它由远程模块调用。
+func (e *Engine) SendLocalResponse(pid *PID, msg any, requestID uint64) {
+	if resp := e.remoteRequests.take(requestID, pid); resp != nil {
+		resp.Send(pid, msg, nil)
+		return
+	}
+	e.deadLetter(pid, msg, nil)
+}
@@ -0,0 +1,139 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoRemote 模拟远程节点：收到请求后以 "pong" 响应发送者，silent 为 true 时不响应。
+type echoRemote struct {
+	engine *Engine
+	silent bool
+}
+
+func (r *echoRemote) Address() string { return "local" }
+
+func (r *echoRemote) Start(e *Engine) error {
+	r.engine = e
+	return nil
+}
+
+func (r *echoRemote) Stop() *sync.WaitGroup { return &sync.WaitGroup{} }
+
+func (r *echoRemote) Send(_ *PID, _ any, sender *PID) {
+	if !r.silent && sender != nil {
+		go r.engine.SendLocal(sender, "pong", nil)
+	}
+}
+
+func TestRemoteRequestSkipsRegistry(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithRemote(&echoRemote{}))
+	require.NoError(t, err)
+	target := NewPID("remote:4000", "foo")
+
+	for i := 0; i < 10; i++ {
+		resp := e.Request(target, "ping", time.Second)
+		assert.Empty(t, e.Registry.Lookup("response/*"))
+		v, err := resp.Result()
+		require.NoError(t, err)
+		assert.Equal(t, "pong", v)
+	}
+	assert.Equal(t, 0, e.remoteRequests.len())
+}
+
+func TestRemoteRequestTimeoutUnregisters(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithRemote(&echoRemote{silent: true}))
+	require.NoError(t, err)
+	target := NewPID("remote:4000", "foo")
+
+	_, err = e.Request(target, "ping", 10*time.Millisecond).Result()
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.Equal(t, 0, e.remoteRequests.len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := e.RequestCtx(ctx, target, "ping")
+	assert.Equal(t, 1, e.remoteRequests.len())
+	cancel()
+	assert.Eventually(t, func() bool { return e.remoteRequests.len() == 0 }, time.Second, time.Millisecond)
+	_, err = resp.Result()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// loopRemote 模拟支持请求 ID 的远程节点：请求被投递给本引擎上 ID 相同的 actor，
+// 发送者被改写为远程地址，响应携带的请求 ID 记录在 responses 中。
+type loopRemote struct {
+	engine    *Engine
+	responses chan uint64
+}
+
+func (r *loopRemote) Address() string { return "local" }
+
+func (r *loopRemote) Start(e *Engine) error {
+	r.engine = e
+	return nil
+}
+
+func (r *loopRemote) Stop() *sync.WaitGroup { return &sync.WaitGroup{} }
+
+func (r *loopRemote) Send(*PID, any, *PID) {}
+
+func (r *loopRemote) SendRequest(pid *PID, msg any, sender *PID, requestID uint64) {
+	go r.engine.SendLocalRequest(NewPID("local", pid.ID), msg, NewPID("remote:5000", sender.ID), requestID)
+}
+
+func (r *loopRemote) SendResponse(pid *PID, msg any, requestID uint64) {
+	r.responses <- requestID
+	go r.engine.SendLocalResponse(NewPID("local", pid.ID), msg, requestID)
+}
+
+func TestRemoteRequestCarriesRequestID(t *testing.T) {
+	remote := &loopRemote{responses: make(chan uint64, 2)}
+	e, err := NewEngine(NewEngineConfig().WithRemote(remote))
+	require.NoError(t, err)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case string:
+			if msg == "fail" {
+				c.RespondErr(errors.New("boom"))
+				return
+			}
+			c.Respond("pong")
+		}
+	}, "foo")
+	target := NewPID("remote:4000", pid.ID)
+
+	resp := e.Request(target, "ping", time.Second)
+	require.NotZero(t, resp.requestID)
+	v, err := resp.Result()
+	require.NoError(t, err)
+	assert.Equal(t, "pong", v)
+	assert.Equal(t, resp.requestID, <-remote.responses)
+
+	resp = e.Request(target, "fail", time.Second)
+	_, err = resp.Result()
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, "boom", respErr.Message)
+	assert.Equal(t, resp.requestID, <-remote.responses)
+	assert.Equal(t, 0, e.remoteRequests.len())
+}
+
+func TestRemoteResponseWrongPID(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithRemote(&echoRemote{silent: true}))
+	require.NoError(t, err)
+
+	resp := e.Request(NewPID("remote:4000", "foo"), "ping", time.Second)
+	// 请求 ID 与 PID 不符的响应不会交给 Response。
+	e.SendLocalResponse(NewPID(e.Address(), "response/1"), "pong", resp.requestID)
+	assert.Equal(t, 1, e.remoteRequests.len())
+	e.SendLocalResponse(resp.PID(), "pong", resp.requestID)
+	v, err := resp.Result()
+	require.NoError(t, err)
+	assert.Equal(t, "pong", v)
+}
@@ -0,0 +1,57 @@
+package actor
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RequestStats 是引擎中请求的统计信息。
+type RequestStats struct {
+	// Open 是已经发出、尚未收到响应也尚未超时或取消的请求数量。
+	Open int64
+	// Expired 是自引擎创建以来超时的请求总数。
+	Expired int64
+}
+
+// requestStats 记录引擎中请求的统计信息。
+type requestStats struct {
+	open    atomic.Int64
+	expired atomic.Int64
+}
+
+// RequestStats 返回引擎中请求的统计信息。
+func (e *Engine) RequestStats() RequestStats {
+	return RequestStats{
+		Open:    e.requests.open.Load(),
+		Expired: e.requests.expired.Load(),
+	}
+}
+
+// track 将响应记为一个进行中的请求。
+func (r *Response) track(target *PID, msg any) {
+	r.target = target
+	r.msg = msg
+	r.start = r.engine.clock.Now()
+	r.engine.requests.open.Add(1)
+}
+
+// finish 将请求记为已结束，只有第一次调用生效。
+func (r *Response) finish() {
+	if r.finished.CompareAndSwap(false, true) {
+		r.engine.requests.open.Add(-1)
+	}
+}
+
+// expire 记录一次超时并广播 RequestTimeoutEvent，只有第一次调用生效。
+func (r *Response) expire() {
+	if !r.expired.CompareAndSwap(false, true) {
+		return
+	}
+	r.engine.requests.expired.Add(1)
+	r.engine.BroadcastEvent(RequestTimeoutEvent{
+		Target:      r.target,
+		MessageType: fmt.Sprintf("%T", r.msg),
+		Elapsed:     r.engine.clock.Now().Sub(r.start),
+		Timeout:     r.timeout,
+	})
+}
@@ -0,0 +1,99 @@
+package actor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type slowQuery struct{}
+
+func TestRequestTimeoutEvent(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	events := make(chan RequestTimeoutEvent, 4)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(RequestTimeoutEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			c.Respond("pong")
+		}
+	}, "silent")
+
+	resp := e.Request(pid, slowQuery{}, 10*time.Millisecond)
+	assert.Equal(t, RequestStats{Open: 1}, e.RequestStats())
+	_, err = resp.Result()
+	require.ErrorIs(t, err, ErrRequestTimeout)
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, pid, evt.Target)
+		assert.Equal(t, "actor.slowQuery", evt.MessageType)
+		assert.Equal(t, 10*time.Millisecond, evt.Timeout)
+		assert.GreaterOrEqual(t, evt.Elapsed, 10*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("expected RequestTimeoutEvent")
+	}
+	assert.Equal(t, RequestStats{Open: 0, Expired: 1}, e.RequestStats())
+
+	v, err := e.Request(pid, "ping", time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, "pong", v)
+	assert.Equal(t, RequestStats{Open: 0, Expired: 1}, e.RequestStats())
+}
+
+func TestRequestCtxExpiredWithoutResult(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.SpawnFunc(func(*Context) {}, "silent")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	e.RequestCtx(ctx, pid, slowQuery{})
+	canceled, cancelNow := context.WithCancel(context.Background())
+	e.RequestCtx(canceled, pid, slowQuery{})
+	assert.Equal(t, int64(2), e.RequestStats().Open)
+	cancelNow()
+
+	assert.Eventually(t, func() bool {
+		return e.RequestStats() == RequestStats{Open: 0, Expired: 1}
+	}, time.Second, time.Millisecond)
+}
+
+func TestRequestExpiredWithoutResult(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	e, err := NewEngine(NewEngineConfig().WithClock(clock))
+	require.NoError(t, err)
+	events := make(chan RequestTimeoutEvent, 1)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(RequestTimeoutEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+	pid := e.SpawnFunc(func(*Context) {}, "silent")
+
+	// 没有人等待的请求在时钟到期后同样计为超时并注销响应进程。
+	resp := e.Request(pid, slowQuery{}, time.Second)
+	assert.Equal(t, RequestStats{Open: 1}, e.RequestStats())
+	clock.Advance(time.Second)
+	assert.Equal(t, RequestStats{Open: 0, Expired: 1}, e.RequestStats())
+	assert.Nil(t, e.Registry.get(resp.PID()))
+	select {
+	case evt := <-events:
+		assert.Equal(t, time.Second, evt.Elapsed)
+	case <-time.After(time.Second):
+		t.Fatal("expected RequestTimeoutEvent")
+	}
+	_, err = resp.Result()
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.Equal(t, RequestStats{Open: 0, Expired: 1}, e.RequestStats())
+}
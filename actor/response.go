@@ -2,49 +2,277 @@ package actor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrRequestTimeout 在请求超时时由 Response.Result 返回（同时包装了 context 的错误）。
+// 应用层通过 Context.RespondErr 返回的错误不会包装它，以便调用者区分两者。
+var ErrRequestTimeout = errors.New("请求超时")
+
+// responseError 是本地请求中由 Context.RespondErr 返回的错误，原样交给调用者。
+type responseError struct {
+	err error
+}
+
+// Error 实现 error 接口。远程请求中由 Context.RespondErr 返回的错误以
+// *ResponseError 的形式传输，调用者只能得到错误信息。
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+// RespondErr 以错误响应当前请求。对于本地请求，调用者的 Response.Result 将原样返回 err；
+// 对于远程请求，返回包含错误信息的 *ResponseError。
+func (c *Context) RespondErr(err error) {
+	if c.sender == nil {
+		c.engine.logger.Warn("context got no sender", "func", "RespondErr", "pid", c.PID())
+		return
+	}
+	if c.engine.isLocalMessage(c.sender) {
+		c.engine.sendCorrelated(c.sender, responseError{err: err}, nil, c.correlationID)
+		return
+	}
+	msg := &ResponseError{Message: err.Error()}
+	if c.engine.respondRemote(c.sender, msg, c.requestID) {
+		return
+	}
+	c.engine.Send(c.sender, msg)
+}
+
 // Response 表示请求-响应模式中的响应对象。
 type Response struct {
 	engine  *Engine
 	pid     *PID
+	target  *PID // 请求的目标，由 Engine.Request 设置
+	msg     any  // 请求的消息
+	start   time.Time
 	result  chan any
 	timeout time.Duration
+	// ctx 不为 nil 时（RequestCtx），由它而不是 timeout 决定请求何时放弃。
+	ctx context.Context
+	// timedOut 在 armTimeout 的计时器触发时关闭。
+	timedOut chan struct{}
+	// stopCleanup 取消超时或 ctx 结束时的注销回调。
+	stopCleanup func() bool
+	// unregister 在请求结束时注销 Response，由 Engine 发起请求时设置。
+	unregister func()
+	// requestID 是远程请求的请求 ID，本地请求为 0，参见 remoteRequests。
+	requestID uint64
+
+	// await 等待结果，只会被调用一次，结果保存在 value 和 err 中，随后关闭 done。
+	await     func() (any, error)
+	once      sync.Once
+	watchOnce sync.Once
+	done      chan struct{}
+	value     any
+	err       error
+	// requester 是向自身发起请求的 actor，用于检测阻塞的自请求。
+	requester *PID
+	// finished 和 expired 保证请求统计只更新一次。
+	finished atomic.Bool
+	expired  atomic.Bool
 }
 
 // NewResponse 创建一个新的 Response 对象。
 func NewResponse(e *Engine, timeout time.Duration) *Response {
-	return &Response{
+	r := &Response{
 		engine:  e,
 		result:  make(chan any, 1),
 		timeout: timeout,
 		pid:     NewPID(e.address, "response"+pidSeparator+strconv.Itoa(rand.Intn(math.MaxInt32))),
+		done:    make(chan struct{}),
 	}
+	r.await = r.receive
+	return r
 }
 
-// Result 等待并返回响应结果。如果超时，返回错误。
+// Result 等待并返回响应结果。如果超时，返回包装了 ErrRequestTimeout 的错误；
+// 如果接收者通过 Context.RespondErr 返回了错误，则返回该错误。
+// 对于 RequestCtx 发起的请求，ctx 被取消时返回 ctx.Err()。
+//
+// Result 可以被多次调用，也可以在多个 goroutine 中并发调用，它们得到相同的结果。
 func (r *Response) Result() (any, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	r.warnSelfRequest()
+	return r.wait()
+}
+
+// wait 等待并返回响应结果，不检测阻塞的自请求。
+func (r *Response) wait() (any, error) {
+	r.once.Do(func() {
+		r.value, r.err = r.await()
+		close(r.done)
+	})
+	return r.value, r.err
+}
+
+// Done 返回一个在响应完成（收到结果、失败或超时）时关闭的 channel，
+// 之后调用 Result 不会阻塞。可以用它在 select 中同时等待多个请求或其他事件。
+func (r *Response) Done() <-chan struct{} {
+	r.watchOnce.Do(func() {
+		go r.wait()
+	})
+	return r.done
+}
+
+// Then 在后台等待响应，并在完成时以结果调用 f。f 在独立的 goroutine 中执行，
+// 如果需要修改 actor 的状态，请使用 PipeTo 把结果投递回 actor。
+func (r *Response) Then(f func(any, error)) {
+	go func() {
+		f(r.wait())
+	}()
+}
+
+// Map 返回一个新的 Response，它的结果是把 f 应用于 r 的结果。
+// r 失败时 f 不会被调用，新的 Response 返回相同的错误。
+//
+//	name := engine.Request(pid, &GetUser{}, time.Second).Map(func(v any) (any, error) {
+//		return v.(*User).Name, nil
+//	})
+func (r *Response) Map(f func(any) (any, error)) *Response {
+	return &Response{
+		engine:    r.engine,
+		pid:       r.pid,
+		target:    r.target,
+		msg:       r.msg,
+		requester: r.requester,
+		done:      make(chan struct{}),
+		await: func() (any, error) {
+			v, err := r.wait()
+			if err != nil {
+				return nil, err
+			}
+			return f(v)
+		},
+	}
+}
+
+// WaitAll 等待所有响应完成，按顺序返回它们的结果。失败的响应对应的结果为 nil，
+// 返回的错误通过 errors.Join 聚合了所有失败的响应的错误。
+//
+//	results, err := actor.WaitAll(
+//		engine.Request(a, &Query{}, time.Second),
+//		engine.Request(b, &Query{}, time.Second),
+//	)
+func WaitAll(responses ...*Response) ([]any, error) {
+	for _, r := range responses {
+		r.Done()
+	}
+	var (
+		results = make([]any, len(responses))
+		errs    []error
+	)
+	for i, r := range responses {
+		v, err := r.Result()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results[i] = v
+	}
+	return results, errors.Join(errs...)
+}
+
+// armTimeout 在引擎的时钟上为请求计时。即使没有人等待结果，请求超时时也会注销响应进程、
+// 计入 RequestStats 并广播 RequestTimeoutEvent。
+func (r *Response) armTimeout() {
+	r.timedOut = make(chan struct{})
+	timer := r.engine.clock.AfterFunc(r.timeout, func() {
+		r.unregister()
+		// 注销之前已经收到的响应不算超时。
+		if len(r.result) == 0 {
+			r.expire()
+		}
+		r.finish()
+		close(r.timedOut)
+	})
+	r.stopCleanup = timer.Stop
+}
+
+// receive 等待响应进程收到结果，然后注销响应进程。
+func (r *Response) receive() (any, error) {
+	var (
+		ctx      context.Context
+		cancel   context.CancelFunc
+		timedOut <-chan struct{}
+	)
+	switch {
+	case r.ctx != nil:
+		ctx, cancel = context.WithCancel(r.ctx)
+	case r.timedOut != nil:
+		ctx, cancel = context.WithCancel(context.Background())
+		timedOut = r.timedOut
+	default:
+		ctx, cancel = context.WithTimeout(context.Background(), r.timeout)
+	}
 	defer func() {
 		cancel()
-		r.engine.Registry.Remove(r.pid)
+		if r.stopCleanup != nil {
+			r.stopCleanup()
+		}
+		if r.unregister != nil {
+			r.unregister()
+		} else {
+			r.engine.Registry.Remove(r.pid)
+		}
+		r.finish()
 	}()
 
 	select {
 	case resp := <-r.result:
-		return resp, nil
+		return responseResult(resp)
+	case <-timedOut:
+		select {
+		case resp := <-r.result:
+			return responseResult(resp)
+		default:
+		}
+		return nil, fmt.Errorf("%w: %w", ErrRequestTimeout, context.DeadlineExceeded)
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, ctx.Err()
+		}
+		r.expire()
+		return nil, fmt.Errorf("%w: %w", ErrRequestTimeout, ctx.Err())
 	}
 }
 
+// responseResult 把响应进程收到的消息转换为 Result 的返回值。
+func responseResult(resp any) (any, error) {
+	switch resp := resp.(type) {
+	case responseError:
+		return nil, resp.err
+	case *ResponseError:
+		return nil, resp
+	}
+	return resp, nil
+}
+
+// PipeTo 在后台等待响应，并将结果作为消息异步投递给给定的 PID（通常是当前 actor 自身），
+// 消息的发送者为请求的目标。如果请求失败（超时或对方调用了 RespondErr），投递的是 RequestFailure。
+// 在 Receive 中应使用 PipeTo 而不是阻塞的 Result。
+func (r *Response) PipeTo(pid *PID) {
+	r.Then(func(resp any, err error) {
+		if err != nil {
+			r.engine.Send(pid, RequestFailure{Target: r.target, Err: err})
+			return
+		}
+		r.engine.SendWithSender(pid, resp, r.target)
+	})
+}
+
 // Send 实现 Processer 接口，用于接收响应消息。
 func (r *Response) Send(_ *PID, msg any, _ *PID) {
+	if t, ok := msg.(*Traced); ok {
+		msg = t.Message
+	}
 	r.result <- msg
+	r.finish()
 }
 
 // PID 返回 Response 的 PID。
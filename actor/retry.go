@@ -0,0 +1,45 @@
+package actor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RequestWithRetry 与 Request 相同，但在请求超时时自动重试，最多重试 retries 次，
+// 两次尝试之间按照 backoff 等待（等待使用引擎的 Clock）。backoff.ResetAfter 在这里不起作用。
+//
+// 只有超时（ErrRequestTimeout）会触发重试；接收者通过 Context.RespondErr 返回的错误
+// 会立即返回。所有尝试都超时后，返回的错误通过 errors.Join 聚合了每一次尝试的错误，
+// 因此 errors.Is(err, ErrRequestTimeout) 仍然成立。
+//
+// 重试意味着同一条消息可能被处理多次，接收者应当能够幂等地处理它。
+//
+//	resp, err := engine.RequestWithRetry(pid, &GetState{}, time.Second, 3, actor.RestartBackoff{
+//		Initial:    100 * time.Millisecond,
+//		Multiplier: 2,
+//		Max:        time.Second,
+//	})
+func (e *Engine) RequestWithRetry(pid *PID, msg any, timeout time.Duration, retries int, backoff RestartBackoff) (any, error) {
+	var (
+		errs  []error
+		delay time.Duration
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err := e.Request(pid, msg, timeout).Result()
+		if err == nil {
+			return resp, nil
+		}
+		if !errors.Is(err, ErrRequestTimeout) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("第 %d 次请求: %w", attempt+1, err))
+		if attempt >= retries {
+			return nil, errors.Join(errs...)
+		}
+		delay = backoff.next(delay, 0)
+		if delay > 0 {
+			e.clock.Sleep(delay)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package actor
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestWithRetry(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var attempts atomic.Int32
+	// 前两次请求不响应，第三次才响应。
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); !ok {
+			return
+		}
+		if attempts.Add(1) < 3 {
+			return
+		}
+		c.Respond("pong")
+	}, "flaky")
+
+	resp, err := e.RequestWithRetry(pid, "ping", 20*time.Millisecond, 3, RestartBackoff{Initial: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, "pong", resp)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRequestWithRetryExhausted(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var attempts atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			attempts.Add(1)
+		}
+	}, "silent")
+
+	_, err = e.RequestWithRetry(pid, "ping", 10*time.Millisecond, 2, RestartBackoff{})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRequestWithRetryRespondErr(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	errBoom := errors.New("boom")
+	var attempts atomic.Int32
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			attempts.Add(1)
+			c.RespondErr(errBoom)
+		}
+	}, "failing")
+
+	_, err = e.RequestWithRetry(pid, "ping", time.Second, 3, RestartBackoff{})
+	assert.ErrorIs(t, err, errBoom)
+	assert.NotErrorIs(t, err, ErrRequestTimeout)
+	assert.Equal(t, int32(1), attempts.Load())
+}
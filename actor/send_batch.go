@@ -0,0 +1,56 @@
+package actor
+
+// batchInbox 由支持一次放入多条消息的收件箱实现：所有消息在一次入队操作中放入，
+// 并且只调度一次处理。
+type batchInbox interface {
+	SendBatch([]Envelope)
+}
+
+// batchSender 由支持批量投递的进程实现。
+type batchSender interface {
+	SendBatch(pid *PID, msgs []any, sender *PID)
+}
+
+// SendBatch 将一组消息按顺序发送给给定的 PID。本地 actor 的收件箱支持批量入队时，
+// 所有消息只需一次加锁和一次调度，适合向同一个 actor 推送大量小消息的生产者。
+// 否则（包括远程 PID）等同于依次调用 Send。
+func (e *Engine) SendBatch(pid *PID, msgs []any) {
+	e.sendBatch(pid, msgs, nil)
+}
+
+func (e *Engine) sendBatch(pid *PID, msgs []any, sender *PID) {
+	if pid == nil || len(msgs) == 0 {
+		return
+	}
+	if e.isLocalMessage(pid) {
+		if proc, ok := e.Registry.get(pid).(batchSender); ok {
+			proc.SendBatch(pid, msgs, sender)
+			return
+		}
+	}
+	for _, msg := range msgs {
+		e.send(pid, msg, sender)
+	}
+}
+
+// SendBatch 以当前 actor 作为发送者，将一组消息按顺序发送给给定的 PID。
+// 参见 Engine.SendBatch。
+func (c *Context) SendBatch(pid *PID, msgs []any) {
+	c.engine.sendBatch(pid, msgs, c.pid)
+}
+
+// SendBatch 向进程批量发送消息。收件箱不支持批量入队时逐条发送。
+func (p *process) SendBatch(_ *PID, msgs []any, sender *PID) {
+	in, ok := p.inbox.(batchInbox)
+	if !ok {
+		for _, msg := range msgs {
+			p.inbox.Send(Envelope{Msg: msg, Sender: sender})
+		}
+		return
+	}
+	envs := make([]Envelope, len(msgs))
+	for i, msg := range msgs {
+		envs[i] = Envelope{Msg: msg, Sender: sender}
+	}
+	in.SendBatch(envs)
+}
@@ -0,0 +1,87 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendBatch(t *testing.T) {
+	for name, opt := range map[string]OptFunc{
+		"inbox":    WithInboxSize(4),
+		"mpsc":     WithMPSCInbox(),
+		"priority": WithPriorityInbox(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			e, err := NewEngine(NewEngineConfig())
+			require.NoError(t, err)
+			var (
+				wg      sync.WaitGroup
+				got     []int
+				senders []*PID
+			)
+			wg.Add(20)
+			pid := e.SpawnFunc(func(c *Context) {
+				if n, ok := c.Message().(int); ok {
+					got = append(got, n)
+					senders = append(senders, c.Sender())
+					wg.Done()
+				}
+			}, "batch", opt)
+			msgs := make([]any, 10)
+			for i := range msgs {
+				msgs[i] = i
+			}
+			e.SendBatch(pid, msgs)
+
+			producer := e.SpawnFunc(func(c *Context) {
+				if _, ok := c.Message().(Started); ok {
+					batch := make([]any, 10)
+					for i := range batch {
+						batch[i] = 10 + i
+					}
+					c.SendBatch(pid, batch)
+				}
+			}, "producer")
+			wg.Wait()
+
+			for i, n := range got {
+				require.Equal(t, i, n)
+			}
+			require.Nil(t, senders[0])
+			require.Equal(t, producer, senders[19])
+		})
+	}
+}
+
+func TestSendBatchDeadLetter(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithDeadLetterRetention(10))
+	require.NoError(t, err)
+	e.SendBatch(NewPID(LocalLookupAddr, "missing/1"), []any{"a", "b", "c"})
+	require.Equal(t, 3, e.Deadletters().Len())
+}
+
+func BenchmarkSendBatch(b *testing.B) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(b, err)
+	pid := e.SpawnFunc(func(_ *Context) {}, "bench")
+	msgs := make([]any, 100)
+	for i := range msgs {
+		msgs[i] = i
+	}
+	b.Run("send", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, msg := range msgs {
+				e.Send(pid, msg)
+			}
+		}
+	})
+	b.Run("send_batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			e.SendBatch(pid, msgs)
+		}
+	})
+}
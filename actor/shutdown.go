@@ -0,0 +1,117 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrEngineShuttingDown 在对已经开始关闭的引擎再次调用 Shutdown 或 SpawnChecked 时返回。
+var ErrEngineShuttingDown = errors.New("引擎已经在关闭")
+
+// RemoteDrainer 可以由 Remoter 实现。Engine.Shutdown 在停止远程模块之前调用 Drain，
+// 将所有尚未写入网络的消息刷新出去。
+type RemoteDrainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Shutdown 优雅地关闭整个引擎，在所有工作完成后返回：
+//
+//  1. 不再接受新的 actor；
+//  2. 按依赖顺序（子 actor 先于父 actor）毒杀所有普通 actor，并等待它们处理完收件箱；
+//  3. 刷新远程模块中尚未发送的消息（如果 Remoter 实现了 RemoteDrainer）并停止远程模块；
+//  4. 停止系统 actor，事件流最后停止。
+//
+// 如果 ctx 在完成之前被取消，返回 ctx.Err()，此时引擎可能只关闭了一部分。
+func (e *Engine) Shutdown(ctx context.Context) error {
+	if !e.shuttingDown.CompareAndSwap(false, true) {
+		return ErrEngineShuttingDown
+	}
+	var system []*PID
+	for _, level := range e.shutdownLevels() {
+		var pids []*PID
+		for _, proc := range level {
+			if proc.Opts.System {
+				system = append(system, proc.pid)
+				continue
+			}
+			pids = append(pids, proc.pid)
+		}
+		if err := e.poisonAll(ctx, pids); err != nil {
+			return err
+		}
+	}
+
+	if e.remote != nil {
+		if d, ok := e.remote.(RemoteDrainer); ok {
+			if err := d.Drain(ctx); err != nil {
+				return err
+			}
+		}
+		done := make(chan struct{})
+		go func() {
+			e.remote.Stop().Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// 事件流最后停止，以便前面的步骤仍然可以广播事件。
+	var eventStream *PID
+	pids := system[:0]
+	for _, pid := range system {
+		if pid.Equals(e.eventStream) {
+			eventStream = pid
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	if err := e.poisonAll(ctx, pids); err != nil {
+		return err
+	}
+	if eventStream != nil {
+		return e.poisonAll(ctx, []*PID{eventStream})
+	}
+	return nil
+}
+
+// shutdownLevels 将所有已注册的 actor 进程按层级深度分组，最深的一组在前。
+// 子 actor 的 ID 以父 actor 的 ID 为前缀，因此 ID 中分隔符越多层级越深。
+func (e *Engine) shutdownLevels() [][]*process {
+	byDepth := make(map[int][]*process)
+	for _, proc := range e.Registry.processes() {
+		p, ok := proc.(*process)
+		if !ok {
+			continue
+		}
+		depth := strings.Count(p.pid.ID, pidSeparator)
+		byDepth[depth] = append(byDepth[depth], p)
+	}
+	depths := make([]int, 0, len(byDepth))
+	for depth := range byDepth {
+		depths = append(depths, depth)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(depths)))
+	levels := make([][]*process, len(depths))
+	for i, depth := range depths {
+		levels[i] = byDepth[depth]
+	}
+	return levels
+}
+
+// poisonAll 并发地毒杀给定的 actor，并等待它们全部停止或 ctx 被取消。
+func (e *Engine) poisonAll(ctx context.Context, pids []*PID) error {
+	dones := make([]context.Context, len(pids))
+	for i, pid := range pids {
+		dones[i] = e.PoisonCtx(ctx, pid)
+	}
+	for _, done := range dones {
+		<-done.Done()
+	}
+	return ctx.Err()
+}
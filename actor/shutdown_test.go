@@ -0,0 +1,108 @@
+package actor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownStopsChildrenBeforeParents(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		mu      sync.Mutex
+		stopped []string
+		started sync.WaitGroup
+	)
+	record := func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			started.Done()
+		case Stopped:
+			mu.Lock()
+			stopped = append(stopped, c.PID().ID)
+			mu.Unlock()
+		}
+	}
+	started.Add(3)
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			c.SpawnChildFunc(func(c *Context) {
+				if _, ok := c.Message().(Started); ok {
+					c.SpawnChildFunc(record, "grandchild", WithID("1"))
+				}
+				record(c)
+			}, "child", WithID("1"))
+		}
+		record(c)
+	}, "parent", WithID("1"))
+	started.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, e.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"parent/1/child/1/grandchild/1",
+		"parent/1/child/1",
+		"parent/1",
+	}, stopped)
+	assert.Empty(t, e.Registry.processes())
+}
+
+func TestShutdownDrainsInbox(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		mu        sync.Mutex
+		processed int
+	)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			processed++
+			mu.Unlock()
+		}
+	}, "worker")
+	for i := 0; i < 50; i++ {
+		e.Send(pid, i)
+	}
+	require.NoError(t, e.Shutdown(context.Background()))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 50, processed)
+}
+
+func TestShutdownRejectsSpawnAndSecondCall(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	pid := e.SpawnFunc(func(*Context) {}, "late")
+	assert.Nil(t, e.Registry.get(pid))
+	assert.ErrorIs(t, e.Shutdown(context.Background()), ErrEngineShuttingDown)
+}
+
+func TestShutdownContextCanceled(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	block := make(chan struct{})
+	defer close(block)
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(string); ok {
+			<-block
+		}
+	}, "blocked")
+	e.Send(pid, "block")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Shutdown(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,83 @@
+package actor
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithSlowThreshold 设置慢 actor 检测的阈值：一次 Invoke 处理一批消息的时间超过 d 时，
+// 在事件流上广播 ActorSlowEvent，覆盖引擎级别的阈值。d 为 0 时使用引擎的阈值。
+func WithSlowThreshold(d time.Duration) OptFunc {
+	return func(opts *Opts) {
+		opts.SlowThreshold = d
+	}
+}
+
+// WithSlowActorThreshold 为引擎中所有非系统 actor 启用慢 actor 检测，
+// 用于在生产环境中找出在 Receive 中执行阻塞调用的 actor。默认不检测。
+func (config EngineConfig) WithSlowActorThreshold(d time.Duration) EngineConfig {
+	config.slowThreshold = d
+	return config
+}
+
+// slowBatch 记录一批消息的处理时间以及其中耗时最长的消息。
+// 阈值为 0 时所有方法都不做任何事情，不会读取时钟。
+type slowBatch struct {
+	threshold time.Duration
+	clock     Clock
+	start     time.Time
+	msgStart  time.Time
+	slowest   any
+	longest   time.Duration
+	count     int
+}
+
+// newSlowBatch 开始记录一批消息的处理时间。
+func (p *process) newSlowBatch() slowBatch {
+	b := slowBatch{
+		threshold: p.Opts.SlowThreshold,
+		clock:     p.context.engine.clock,
+	}
+	if b.threshold > 0 {
+		b.start = b.clock.Now()
+	}
+	return b
+}
+
+// begin 在处理单条消息之前调用。
+func (b *slowBatch) begin() {
+	if b.threshold > 0 {
+		b.msgStart = b.clock.Now()
+	}
+}
+
+// end 在处理完单条消息之后调用。
+func (b *slowBatch) end(msg any) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.count++
+	if d := b.clock.Now().Sub(b.msgStart); d >= b.longest {
+		b.longest = d
+		b.slowest = msg
+	}
+}
+
+// reportSlow 在整批消息的处理时间超过阈值时广播 ActorSlowEvent。
+func (p *process) reportSlow(b *slowBatch) {
+	if b.threshold <= 0 || b.count == 0 {
+		return
+	}
+	elapsed := b.clock.Now().Sub(b.start)
+	if elapsed <= b.threshold {
+		return
+	}
+	p.context.engine.BroadcastEvent(ActorSlowEvent{
+		PID:             p.pid,
+		Duration:        elapsed,
+		Messages:        b.count,
+		MessageType:     fmt.Sprintf("%T", b.slowest),
+		MessageDuration: b.longest,
+		Timestamp:       b.clock.Now(),
+	})
+}
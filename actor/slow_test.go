@@ -0,0 +1,69 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blockingCall struct{}
+
+func TestSlowActorEvent(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock).WithSlowActorThreshold(100 * time.Millisecond))
+	require.NoError(t, err)
+	events := make(chan ActorSlowEvent, 8)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(ActorSlowEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(blockingCall); ok {
+			clock.Advance(time.Second)
+		}
+	}, "slow")
+	e.Send(pid, 1)
+	e.Send(pid, blockingCall{})
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, pid, evt.PID)
+		assert.Equal(t, "actor.blockingCall", evt.MessageType)
+		assert.Equal(t, time.Second, evt.MessageDuration)
+		assert.GreaterOrEqual(t, evt.Duration, time.Second)
+	case <-time.After(time.Second):
+		t.Fatal("expected ActorSlowEvent")
+	}
+}
+
+func TestSlowThresholdOverride(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock).WithSlowActorThreshold(100 * time.Millisecond))
+	require.NoError(t, err)
+	events := make(chan ActorSlowEvent, 8)
+	unsubscribe := e.SubscribeFunc(func(event any) {
+		if evt, ok := event.(ActorSlowEvent); ok {
+			events <- evt
+		}
+	})
+	defer unsubscribe()
+
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(blockingCall); ok {
+			clock.Advance(time.Second)
+		}
+	}, "tolerant", WithSlowThreshold(time.Minute))
+	e.Send(pid, blockingCall{})
+	<-e.Poison(pid).Done()
+
+	select {
+	case evt := <-events:
+		t.Fatalf("unexpected ActorSlowEvent: %+v", evt)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
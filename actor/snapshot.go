@@ -0,0 +1,92 @@
+package actor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RecoverFromSnapshot 在持久化 actor 启动时、回放事件之前投递给 actor，
+// 其中包含最近一次保存的快照。之后只会回放序号大于 Seq 的事件。
+type RecoverFromSnapshot struct {
+	Seq   uint64
+	State any
+}
+
+// Snapshot 是保存在 SnapshotStore 中的快照。
+type Snapshot struct {
+	// Seq 是快照所包含的最后一条事件的序号。
+	Seq   uint64
+	State any
+}
+
+// Snapshotter 可以由 PersistentReceiver 实现。配合 WithSnapshotEvery 使用时，
+// 每持久化 N 条事件就会自动调用 Snapshot 保存一次快照。
+type Snapshotter interface {
+	Snapshot() any
+}
+
+// SnapshotStore 是快照存储接口。
+type SnapshotStore interface {
+	// SaveSnapshot 保存给定持久化 ID 的快照，覆盖之前的快照。
+	SaveSnapshot(persistenceID string, snapshot Snapshot) error
+	// LoadSnapshot 返回给定持久化 ID 最近的快照，不存在时返回 nil。
+	LoadSnapshot(persistenceID string) (*Snapshot, error)
+}
+
+// MemorySnapshotStore 是基于内存的 SnapshotStore 实现。
+// 未配置 SnapshotStore 时引擎默认使用它。
+type MemorySnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]Snapshot
+}
+
+// NewMemorySnapshotStore 创建一个新的内存快照存储。
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{
+		snapshots: make(map[string]Snapshot),
+	}
+}
+
+// SaveSnapshot 实现 SnapshotStore 接口。
+func (s *MemorySnapshotStore) SaveSnapshot(persistenceID string, snapshot Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[persistenceID] = snapshot
+	return nil
+}
+
+// LoadSnapshot 实现 SnapshotStore 接口。
+func (s *MemorySnapshotStore) LoadSnapshot(persistenceID string) (*Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snapshot, ok := s.snapshots[persistenceID]
+	if !ok {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
+
+// SaveSnapshot 保存持久化 actor 当前状态的快照，快照的序号为当前的 SequenceNr。
+// 下次启动时，actor 将先收到 RecoverFromSnapshot，然后只回放快照之后的事件。
+func (c *Context) SaveSnapshot(state any) error {
+	recv, ok := c.receiver.(PersistentReceiver)
+	if !ok {
+		return fmt.Errorf("接收器 %T 没有实现 PersistentReceiver", c.receiver)
+	}
+	return c.snapshotStore.SaveSnapshot(recv.PersistenceID(), Snapshot{
+		Seq:   c.persistSeq,
+		State: state,
+	})
+}
+
+// maybeSnapshot 在配置了 WithSnapshotEvery 时，每隔 N 条事件自动保存一次快照。
+func (c *Context) maybeSnapshot() error {
+	if c.snapshotEvery == 0 || c.persistSeq%c.snapshotEvery != 0 {
+		return nil
+	}
+	s, ok := c.receiver.(Snapshotter)
+	if !ok {
+		return nil
+	}
+	return c.SaveSnapshot(s.Snapshot())
+}
@@ -0,0 +1,94 @@
+package actor
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshottingCounter 每持久化 N 条事件由引擎自动保存快照。
+type snapshottingCounter struct {
+	persistentCounter
+	recovered *atomic.Int32
+}
+
+func (c *snapshottingCounter) Snapshot() any { return c.count }
+
+func (c *snapshottingCounter) Receive(ctx *Context) {
+	if msg, ok := ctx.Message().(RecoverFromSnapshot); ok {
+		c.count = msg.State.(int)
+		c.recovered.Add(1)
+		return
+	}
+	c.persistentCounter.Receive(ctx)
+}
+
+func TestSnapshotEvery(t *testing.T) {
+	journal := NewMemoryJournal()
+	store := NewMemorySnapshotStore()
+	e, err := NewEngine(NewEngineConfig().WithJournal(journal).WithSnapshotStore(store))
+	require.NoError(t, err)
+	var recovered atomic.Int32
+	producer := func() Receiver {
+		return &snapshottingCounter{persistentCounter: persistentCounter{id: "snap-1"}, recovered: &recovered}
+	}
+	pid := e.Spawn(producer, "counter", WithID("a"), WithSnapshotEvery(2))
+	for i := 1; i <= 5; i++ {
+		e.Send(pid, i)
+	}
+	assert.Equal(t, 15, requestCount(t, e, pid))
+
+	snapshot, err := store.LoadSnapshot("snap-1")
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, Snapshot{Seq: 4, State: 10}, *snapshot)
+	<-e.Poison(pid).Done()
+
+	pid = e.Spawn(producer, "counter", WithID("a"), WithSnapshotEvery(2))
+	assert.Equal(t, 15, requestCount(t, e, pid))
+	assert.Equal(t, int32(1), recovered.Load())
+
+	e.Send(pid, 1)
+	assert.Equal(t, 16, requestCount(t, e, pid))
+}
+
+func TestSaveSnapshotManually(t *testing.T) {
+	store := NewMemorySnapshotStore()
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var recovered atomic.Int32
+	pid := e.Spawn(func() Receiver {
+		return &snapshottingCounter{persistentCounter: persistentCounter{id: "snap-2"}, recovered: &recovered}
+	}, "counter", WithSnapshotStore(store), WithRestartDelay(time.Millisecond), WithMiddleware(func(next ReceiveFunc) ReceiveFunc {
+		return func(c *Context) {
+			if msg, ok := c.Message().(string); ok && msg == "save" {
+				require.NoError(t, c.SaveSnapshot(c.Receiver().(*snapshottingCounter).count))
+				return
+			}
+			next(c)
+		}
+	}))
+	e.Send(pid, 3)
+	e.Send(pid, "save")
+	e.Send(pid, 4)
+	assert.Equal(t, 7, requestCount(t, e, pid))
+
+	e.Send(pid, "crash")
+	assert.Equal(t, 7, requestCount(t, e, pid))
+	assert.Equal(t, int32(1), recovered.Load())
+}
+
+func TestSaveSnapshotNonPersistentReceiver(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			errCh <- c.SaveSnapshot(1)
+		}
+	}, "plain")
+	assert.Error(t, <-errCh)
+}
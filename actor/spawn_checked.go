@@ -0,0 +1,57 @@
+package actor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNilProducer 在 SpawnChecked 的 Producer 为 nil 时返回。
+	ErrNilProducer = errors.New("producer 不能为 nil")
+	// ErrInvalidKind 在 SpawnChecked 的 kind 为空或包含 PID 分隔符时返回。
+	ErrInvalidKind = errors.New("无效的 actor kind")
+	// ErrDuplicateID 在 SpawnChecked 的 actor ID 已被占用时返回。
+	ErrDuplicateID = errors.New("actor ID 已被占用")
+)
+
+// newOpts 生成 actor 的配置：先基于 Producer 生成默认配置，再应用选项，
+// 没有设置 ID 时生成一个随机 ID。
+func newOpts(p Producer, kind string, opts []OptFunc) Opts {
+	options := DefaultOpts(p)
+	options.Kind = kind
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(options.ID) == 0 {
+		options.ID = strconv.Itoa(rand.Intn(math.MaxInt))
+	}
+	return options
+}
+
+// SpawnChecked 与 Spawn 相同，但在 actor 没有被注册时返回错误而不是一个无效的 PID：
+// Producer 为 nil、kind 为空或包含分隔符、SpawnInterceptor 拒绝创建、
+// 引擎正在关闭（ErrEngineShuttingDown）或者 ID 已被占用（ErrDuplicateID）。
+func (e *Engine) SpawnChecked(p Producer, kind string, opts ...OptFunc) (*PID, error) {
+	if p == nil {
+		return nil, ErrNilProducer
+	}
+	if kind == "" || strings.Contains(kind, pidSeparator) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidKind, kind)
+	}
+	options := newOpts(p, kind, opts)
+	if err := e.interceptSpawn(&options); err != nil {
+		return nil, err
+	}
+	if e.shuttingDown.Load() {
+		return nil, ErrEngineShuttingDown
+	}
+	proc := newProcess(e, options)
+	if !e.Registry.add(proc) {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateID, proc.PID())
+	}
+	return proc.PID(), nil
+}
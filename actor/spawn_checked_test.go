@@ -0,0 +1,50 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpawnChecked(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	noop := newFuncReceiver(func(*Context) {})
+	pid, err := e.SpawnChecked(noop, "foo", WithID("1"))
+	require.NoError(t, err)
+	assert.NotNil(t, e.Registry.get(pid))
+
+	_, err = e.SpawnChecked(noop, "foo", WithID("1"))
+	assert.ErrorIs(t, err, ErrDuplicateID)
+
+	_, err = e.SpawnChecked(nil, "foo")
+	assert.ErrorIs(t, err, ErrNilProducer)
+
+	_, err = e.SpawnChecked(noop, "foo/bar")
+	assert.ErrorIs(t, err, ErrInvalidKind)
+	_, err = e.SpawnChecked(noop, "")
+	assert.ErrorIs(t, err, ErrInvalidKind)
+}
+
+func TestSpawnCheckedRejected(t *testing.T) {
+	errDenied := errors.New("denied")
+	e, err := NewEngine(NewEngineConfig().WithSpawnInterceptor(func(opts *Opts) error {
+		if opts.Kind == "denied" {
+			return errDenied
+		}
+		return nil
+	}))
+	require.NoError(t, err)
+
+	noop := newFuncReceiver(func(*Context) {})
+	_, err = e.SpawnChecked(noop, "denied")
+	assert.ErrorIs(t, err, errDenied)
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	_, err = e.SpawnChecked(noop, "foo")
+	assert.ErrorIs(t, err, ErrEngineShuttingDown)
+}
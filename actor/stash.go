@@ -0,0 +1,36 @@
+package actor
+
+// Stash 将当前消息（及其发送者）暂存起来，稍后可以通过 UnstashAll 按原顺序重新投递。
+// 适用于 actor 尚未就绪（例如正在等待异步初始化的响应）时推迟处理消息。
+// actor 崩溃重启时，暂存的消息会在重启后重新投递；actor 停止时，
+// 仍在暂存中的消息将作为 DeadLetterEvent 广播。
+func (c *Context) Stash() {
+	msg := c.message
+	if c.trace != nil {
+		msg = &Traced{Message: msg, Trace: *c.trace}
+	}
+	c.stash = append(c.stash, Envelope{Msg: msg, Sender: c.sender, CorrelationID: c.correlationID, RequestID: c.requestID})
+}
+
+// UnstashAll 按暂存的顺序重新投递所有暂存的消息。
+// 这些消息将在当前消息处理完成后、收件箱中的其他消息之前被处理。
+func (c *Context) UnstashAll() {
+	if len(c.stash) == 0 {
+		return
+	}
+	c.unstashed = append(c.unstashed, c.stash...)
+	c.stash = nil
+}
+
+// StashSize 返回当前暂存的消息数量。
+func (c *Context) StashSize() int {
+	return len(c.stash)
+}
+
+// takeStash 移除并返回所有待重新投递以及仍在暂存中的消息。
+func (c *Context) takeStash() []Envelope {
+	pending := append(c.unstashed, c.stash...)
+	c.unstashed = nil
+	c.stash = nil
+	return pending
+}
@@ -0,0 +1,75 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ready struct{}
+
+func TestStashUnstashAll(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg       sync.WaitGroup
+		isReady  bool
+		received []int
+	)
+	wg.Add(1)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case ready:
+			isReady = true
+			assert.Equal(t, 3, c.StashSize())
+			c.UnstashAll()
+			assert.Equal(t, 0, c.StashSize())
+		case int:
+			if !isReady {
+				c.Stash()
+				return
+			}
+			received = append(received, msg)
+			if len(received) == 5 {
+				wg.Done()
+			}
+		}
+	}, "stash")
+
+	for i := 0; i < 3; i++ {
+		e.Send(pid, i)
+	}
+	e.Send(pid, ready{})
+	e.Send(pid, 3)
+	e.Send(pid, 4)
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, received)
+}
+
+func TestStashedMessagesDeadLetterOnStop(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			c.Engine().Subscribe(c.PID())
+		case DeadLetterEvent:
+			if _, ok := msg.Message.(int); ok {
+				wg.Done()
+			}
+		}
+	}, "deadletter")
+	pid := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(int); ok {
+			c.Stash()
+		}
+	}, "stash")
+	e.Send(pid, 1)
+	e.Send(pid, 2)
+	<-e.Poison(pid).Done()
+	wg.Wait()
+}
@@ -0,0 +1,144 @@
+package actor
+
+// Directive 表示监督者对失败的子 actor 做出的处理指令。
+type Directive int
+
+const (
+	// DirectiveRestart 重启失败的 actor（受 MaxRestarts 限制）。
+	DirectiveRestart Directive = iota
+	// DirectiveStop 停止失败的 actor。
+	DirectiveStop
+	// DirectiveEscalate 停止失败的 actor，并将失败上报给监督者自身的父 actor。
+	DirectiveEscalate
+	// DirectiveNotifyParent 停止失败的 actor，并向父 actor 投递一条 ChildFailed 消息，
+	// 由父 actor 在 Receive 中自行决定如何处理（例如重新创建子 actor）。
+	DirectiveNotifyParent
+)
+
+// String 返回指令的字符串表示形式。
+func (d Directive) String() string {
+	switch d {
+	case DirectiveRestart:
+		return "restart"
+	case DirectiveStop:
+		return "stop"
+	case DirectiveEscalate:
+		return "escalate"
+	case DirectiveNotifyParent:
+		return "notify-parent"
+	default:
+		return "unknown"
+	}
+}
+
+// Decider 根据失败的 actor 和失败原因（panic 的值）返回处理指令。
+type Decider func(child *PID, reason any) Directive
+
+// DefaultDecider 总是返回 DirectiveRestart。
+func DefaultDecider(_ *PID, _ any) Directive {
+	return DirectiveRestart
+}
+
+// SupervisorStrategy 是监督策略接口。父 actor 通过 WithSupervisor 配置策略，
+// 当其子 actor 崩溃时，由该策略决定子 actor 的命运。
+// 没有父 actor 的 actor 使用默认策略（OneForOne + 重启）。
+type SupervisorStrategy interface {
+	// Decide 返回对失败 actor 的处理指令。
+	Decide(child *PID, reason any) Directive
+	// AllForOne 返回 true 时，重启指令将同时作用于失败 actor 的所有兄弟 actor。
+	AllForOne() bool
+}
+
+// oneForOneStrategy 只处理失败的那个子 actor。
+type oneForOneStrategy struct {
+	decider Decider
+}
+
+// NewOneForOneStrategy 创建一个只作用于失败子 actor 的监督策略。
+// 如果 decider 为 nil，则使用 DefaultDecider。
+func NewOneForOneStrategy(decider Decider) SupervisorStrategy {
+	if decider == nil {
+		decider = DefaultDecider
+	}
+	return oneForOneStrategy{decider: decider}
+}
+
+func (s oneForOneStrategy) Decide(child *PID, reason any) Directive {
+	return s.decider(child, reason)
+}
+
+func (s oneForOneStrategy) AllForOne() bool { return false }
+
+// allForOneStrategy 在一个子 actor 需要重启时重启所有子 actor。
+type allForOneStrategy struct {
+	decider Decider
+}
+
+// NewAllForOneStrategy 创建一个监督策略：当某个子 actor 被重启时，
+// 它的所有兄弟 actor 也会一起重启。停止和上报指令只作用于失败的子 actor。
+// 如果 decider 为 nil，则使用 DefaultDecider。
+func NewAllForOneStrategy(decider Decider) SupervisorStrategy {
+	if decider == nil {
+		decider = DefaultDecider
+	}
+	return allForOneStrategy{decider: decider}
+}
+
+func (s allForOneStrategy) Decide(child *PID, reason any) Directive {
+	return s.decider(child, reason)
+}
+
+func (s allForOneStrategy) AllForOne() bool { return true }
+
+// NewEscalatingStrategy 创建一个总是将子 actor 的失败上报给父 actor 的监督策略。
+func NewEscalatingStrategy() SupervisorStrategy {
+	return NewOneForOneStrategy(func(*PID, any) Directive {
+		return DirectiveEscalate
+	})
+}
+
+// NewNotifyParentStrategy 创建一个监督策略：子 actor 崩溃时不会被独立重启，
+// 而是停止并以 ChildFailed 消息通知父 actor。
+func NewNotifyParentStrategy() SupervisorStrategy {
+	return NewOneForOneStrategy(func(*PID, any) Directive {
+		return DirectiveNotifyParent
+	})
+}
+
+// WithRestartPolicy 设置该 actor 崩溃时使用的监督策略，覆盖父 actor 通过 WithSupervisor
+// 配置的策略。父 actor 可以在 SpawnChild 时为每个子 actor 指定不同的重启策略：
+//
+//	c.SpawnChild(newWorker, "worker", actor.WithRestartPolicy(actor.NewOneForOneStrategy(decider)))
+func WithRestartPolicy(strategy SupervisorStrategy) OptFunc {
+	return func(opts *Opts) {
+		opts.RestartPolicy = strategy
+	}
+}
+
+// WithNotifyParent 使该 actor 崩溃时不再独立重启，而是停止并向父 actor 投递
+// ChildFailed 消息。等同于 WithRestartPolicy(NewNotifyParentStrategy())。
+func WithNotifyParent() OptFunc {
+	return WithRestartPolicy(NewNotifyParentStrategy())
+}
+
+// ChildFailed 在子 actor 崩溃且监督指令为 DirectiveNotifyParent 时投递给父 actor。
+// 投递时子 actor 已经停止并从注册表中移除，父 actor 可以用相同的 ID 重新创建它。
+type ChildFailed struct {
+	PID    *PID
+	Reason any
+}
+
+// defaultSupervisorStrategy 是未配置监督策略时使用的默认策略。
+var defaultSupervisorStrategy = NewOneForOneStrategy(DefaultDecider)
+
+// supervisorRestart 是监督者要求 actor 重启的私有消息（AllForOne 策略）。
+type supervisorRestart struct {
+	reason any
+}
+
+// escalatedFailure 是子 actor 上报给父 actor 的失败。父 actor 收到后，
+// 将像自身崩溃一样交由它的监督者处理。
+type escalatedFailure struct {
+	child  *PID
+	reason any
+}
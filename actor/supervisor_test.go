@@ -0,0 +1,228 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisorOneForOneStop(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		starts int
+	)
+	wg.Add(1)
+	strategy := NewOneForOneStrategy(func(_ *PID, reason any) Directive {
+		assert.Equal(t, "boom", reason)
+		return DirectiveStop
+	})
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			c.Engine().Subscribe(c.PID())
+		case ActorStoppedEvent:
+			if msg.PID.ID == "parent/1/child/1" {
+				wg.Done()
+			}
+		}
+	}, "watcher")
+
+	e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			childPID := c.SpawnChildFunc(func(c *Context) {
+				switch c.Message().(type) {
+				case Started:
+					mu.Lock()
+					starts++
+					mu.Unlock()
+				case string:
+					panic("boom")
+				}
+			}, "child", WithID("1"))
+			c.Send(childPID, "crash")
+		}
+	}, "parent", WithID("1"), WithSupervisor(strategy))
+
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, starts)
+	assert.Nil(t, e.Registry.getByID("parent/1/child/1"))
+}
+
+func TestSupervisorAllForOneRestartsSiblings(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+	wg.Add(2)
+	childFunc := func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			panic("boom")
+		}
+	}
+	restarted := make(chan *PID, 2)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			c.Engine().Subscribe(c.PID())
+		case ActorRestartedEvent:
+			restarted <- msg.PID
+			wg.Done()
+		case Started:
+			once.Do(func() {
+				a := c.SpawnChildFunc(childFunc, "a", WithRestartDelay(time.Millisecond))
+				c.SpawnChildFunc(childFunc, "b", WithRestartDelay(time.Millisecond))
+				c.Send(a, "crash")
+			})
+		}
+	}, "parent", WithSupervisor(NewAllForOneStrategy(nil)))
+
+	wg.Wait()
+	close(restarted)
+	var ids []string
+	for pid := range restarted {
+		ids = append(ids, pid.ID)
+	}
+	require.Len(t, ids, 2)
+	assert.NotEqual(t, ids[0], ids[1])
+}
+
+func TestSupervisorEscalate(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		// 父 actor 启动的次数，上报后父 actor 会被重启。
+		parentStarts int
+	)
+	wg.Add(1)
+	e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			mu.Lock()
+			parentStarts++
+			n := parentStarts
+			mu.Unlock()
+			if n == 2 {
+				wg.Done()
+				return
+			}
+			child := c.SpawnChildFunc(func(c *Context) {
+				if _, ok := c.Message().(string); ok {
+					panic("boom")
+				}
+			}, "child")
+			c.Send(child, "crash")
+		}
+	}, "parent", WithSupervisor(NewEscalatingStrategy()), WithRestartDelay(time.Millisecond))
+
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, parentStarts)
+}
+
+func TestDirectiveString(t *testing.T) {
+	assert.Equal(t, "restart", DirectiveRestart.String())
+	assert.Equal(t, "stop", DirectiveStop.String())
+	assert.Equal(t, "escalate", DirectiveEscalate.String())
+}
+
+func TestSupervisorNotifyParent(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		failed  = make(chan ChildFailed, 1)
+		starts  = make(chan struct{}, 4)
+		respawn = make(chan *PID, 1)
+	)
+	child := func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			starts <- struct{}{}
+		case string:
+			panic("boom")
+		}
+	}
+	parent := e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Started:
+			c.SpawnChildFunc(child, "child", WithID("1"), WithNotifyParent())
+		case ChildFailed:
+			assert.Empty(t, c.Children())
+			failed <- msg
+			respawn <- c.SpawnChildFunc(child, "child", WithID("1"), WithNotifyParent())
+		}
+	}, "parent", WithID("1"))
+	<-starts
+	childPID := e.GetPID("parent/1/child", "1")
+	require.NotNil(t, childPID)
+
+	e.Send(childPID, "crash")
+	msg := <-failed
+	assert.Equal(t, childPID, msg.PID)
+	assert.Equal(t, "boom", msg.Reason)
+	assert.Equal(t, childPID, <-respawn)
+	<-starts
+	assert.NotNil(t, e.Registry.get(childPID))
+	assert.NotNil(t, e.Registry.get(parent))
+}
+
+func TestSupervisorPerChildRestartPolicy(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg      sync.WaitGroup
+		stopped = make(chan *PID, 2)
+	)
+	wg.Add(2)
+	e.SpawnFunc(func(c *Context) {
+		switch msg := c.Message().(type) {
+		case Initialized:
+			c.Engine().Subscribe(c.PID())
+		case ActorStoppedEvent:
+			stopped <- msg.PID
+		}
+	}, "watcher")
+	stopOnCrash := NewOneForOneStrategy(func(*PID, any) Directive { return DirectiveStop })
+	crash := func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			wg.Done()
+		case string:
+			panic("boom")
+		}
+	}
+	var fragile, sturdy *PID
+	e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			fragile = c.SpawnChildFunc(crash, "fragile", WithRestartPolicy(stopOnCrash))
+			sturdy = c.SpawnChildFunc(crash, "sturdy", WithRestartDelay(0))
+		}
+	}, "parent")
+	wg.Wait()
+
+	wg.Add(1)
+	e.Send(fragile, "crash")
+	e.Send(sturdy, "crash")
+	wg.Wait() // sturdy 被重启
+	select {
+	case pid := <-stopped:
+		assert.Equal(t, fragile, pid)
+	case <-time.After(time.Second):
+		t.Fatal("expected fragile child to stop")
+	}
+	assert.NotNil(t, e.Registry.get(sturdy))
+}
@@ -0,0 +1,71 @@
+package actor
+
+import "sync"
+
+// SyncScheduler 是同步、单线程的调度器，用于编写确定性的测试。
+//
+// 调用 Schedule 的 goroutine 如果当前没有其他 goroutine 正在执行调度任务，
+// 就会在返回前依次执行队列中的所有任务，包括执行过程中新调度的任务。
+// 因此在测试 goroutine 中调用 Send 返回时，该消息以及由它引发的所有消息都已经
+// 按确定的顺序（广度优先）处理完毕，不需要任何等待或超时。
+//
+// 由于所有 actor 在同一个 goroutine 中执行，actor 不能在 Receive 中同步等待
+// 其他 actor（例如调用 Request(...).Result()），否则会一直等到超时。
+// 此时应使用 Response.PipeTo。
+type SyncScheduler struct {
+	mu       sync.Mutex
+	queue    []func()
+	draining bool
+}
+
+// NewSyncScheduler 创建一个新的同步调度器。同一个 SyncScheduler 应当被一个引擎
+// 中的所有 actor 共享，参见 EngineConfig.WithSynchronousDispatch。
+func NewSyncScheduler() *SyncScheduler {
+	return &SyncScheduler{}
+}
+
+// Schedule 将函数放入队列，如果当前没有正在执行的任务，则在调用者的 goroutine
+// 中执行队列直到为空。
+func (s *SyncScheduler) Schedule(fn func()) {
+	s.mu.Lock()
+	s.queue = append(s.queue, fn)
+	if s.draining {
+		s.mu.Unlock()
+		return
+	}
+	s.draining = true
+	s.mu.Unlock()
+	s.drain()
+}
+
+// drain 执行队列中的任务直到队列为空。任务 panic 时释放执行权，
+// 剩余的任务将由下一次 Schedule 执行。
+func (s *SyncScheduler) drain() {
+	done := false
+	defer func() {
+		if !done {
+			s.mu.Lock()
+			s.draining = false
+			s.mu.Unlock()
+		}
+	}()
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.draining = false
+			s.mu.Unlock()
+			done = true
+			return
+		}
+		next := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+		next()
+	}
+}
+
+// Throughput 返回调度器的吞吐量。
+func (s *SyncScheduler) Throughput() int {
+	return defaultThroughput
+}
@@ -0,0 +1,56 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type hop struct{ n int }
+
+func TestSynchronousDispatch(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig().WithSynchronousDispatch())
+	require.NoError(t, err)
+
+	var log []string
+	var b *PID
+	a := e.SpawnFunc(func(c *Context) {
+		if m, ok := c.Message().(hop); ok {
+			log = append(log, "a", string(rune('0'+m.n)))
+			if m.n < 3 {
+				c.Send(b, hop{n: m.n + 1})
+			}
+		}
+	}, "a")
+	b = e.SpawnFunc(func(c *Context) {
+		if m, ok := c.Message().(hop); ok {
+			log = append(log, "b", string(rune('0'+m.n)))
+			c.Send(c.Sender(), hop{n: m.n + 1})
+		}
+	}, "b")
+
+	// Send 返回时整条消息链已经处理完毕，无需等待。
+	e.Send(a, hop{n: 0})
+	require.Equal(t, []string{"a", "0", "b", "1", "a", "2", "b", "3", "a", "4"}, log)
+
+	echo := e.SpawnFunc(func(c *Context) {
+		if msg, ok := c.Message().(string); ok {
+			c.Respond(msg)
+		}
+	}, "echo")
+	resp := e.Request(echo, "hello", time.Second)
+	result, err := resp.Result()
+	require.NoError(t, err)
+	require.Equal(t, "hello", result)
+}
+
+func TestSyncSchedulerRecoversFromPanic(t *testing.T) {
+	s := NewSyncScheduler()
+	require.Panics(t, func() {
+		s.Schedule(func() { panic("boom") })
+	})
+	ran := false
+	s.Schedule(func() { ran = true })
+	require.True(t, ran)
+}
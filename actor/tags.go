@@ -0,0 +1,61 @@
+package actor
+
+import (
+	"maps"
+	"sort"
+)
+
+// WithTags 为 actor 附加键值标签（例如租户、交易品种、分片），标签保存在进程中，
+// 可以通过 Registry.FindByTag 查询，便于运维工具按标签对 actor 分组。
+// 多次调用会合并标签，相同的键以后设置的为准。
+func WithTags(tags map[string]string) OptFunc {
+	return func(opts *Opts) {
+		if opts.Tags == nil {
+			opts.Tags = make(map[string]string, len(tags))
+		}
+		maps.Copy(opts.Tags, tags)
+	}
+}
+
+// tagged 由带有标签的 Processer 实现。
+type tagged interface {
+	tags() map[string]string
+}
+
+func (p *process) tags() map[string]string {
+	return p.Opts.Tags
+}
+
+// Tags 返回给定 PID 对应进程的标签副本。进程不存在或没有标签时返回 nil。
+func (r *Registry) Tags(pid *PID) map[string]string {
+	proc := r.get(pid)
+	if t, ok := proc.(tagged); ok && len(t.tags()) > 0 {
+		return maps.Clone(t.tags())
+	}
+	return nil
+}
+
+// FindByTag 返回带有标签 key=value 的所有进程的 PID，按 ID 排序。
+func (r *Registry) FindByTag(key, value string) []*PID {
+	r.mu.RLock()
+	pids := make([]*PID, 0)
+	for _, proc := range r.lookup {
+		t, ok := proc.(tagged)
+		if !ok {
+			continue
+		}
+		if v, ok := t.tags()[key]; ok && v == value {
+			pids = append(pids, proc.PID())
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(pids, func(i, j int) bool {
+		return pids[i].ID < pids[j].ID
+	})
+	return pids
+}
+
+// FindByTag 返回带有标签 key=value 的所有本地 actor 的 PID，参见 Registry.FindByTag。
+func (e *Engine) FindByTag(key, value string) []*PID {
+	return e.Registry.FindByTag(key, value)
+}
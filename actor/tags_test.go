@@ -0,0 +1,30 @@
+package actor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindByTag(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	noop := func(*Context) {}
+	a := e.SpawnFunc(noop, "trader", WithID("a"), WithTags(map[string]string{"tenant": "acme", "symbol": "BTC"}))
+	b := e.SpawnFunc(noop, "trader", WithID("b"),
+		WithTags(map[string]string{"tenant": "acme"}),
+		WithTags(map[string]string{"symbol": "ETH"}))
+	e.SpawnFunc(noop, "trader", WithID("c"), WithTags(map[string]string{"tenant": "other"}))
+	e.SpawnFunc(noop, "trader", WithID("d"))
+
+	assert.Equal(t, []*PID{a, b}, e.FindByTag("tenant", "acme"))
+	assert.Equal(t, []*PID{b}, e.FindByTag("symbol", "ETH"))
+	assert.Empty(t, e.FindByTag("tenant", "missing"))
+	assert.Equal(t, map[string]string{"tenant": "acme", "symbol": "ETH"}, e.Registry.Tags(b))
+	assert.Nil(t, e.Registry.Tags(e.GetPID("trader", "d")))
+
+	<-e.Poison(a).Done()
+	assert.Equal(t, []*PID{b}, e.FindByTag("tenant", "acme"))
+	assert.Nil(t, e.Registry.Tags(a))
+}
@@ -0,0 +1,149 @@
+// Package testkit 提供编写 actor 测试的辅助工具。
+//
+// Probe 是一个记录收到的消息的测试 actor：把它的 PID 交给被测 actor 作为
+// 消息的目标或发送者，然后用 ExpectMessage 断言收到了什么，
+// 而不必在测试中自行同步 goroutine 或睡眠等待。
+//
+//	probe := testkit.NewProbe(t, engine)
+//	probe.Send(pid, Ping{})
+//	pong := testkit.ExpectMessage[Pong](probe, time.Second)
+package testkit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// DefaultTimeout 是 Expect 系列方法在 timeout 小于等于 0 时使用的超时时间。
+const DefaultTimeout = 3 * time.Second
+
+// Message 是 Probe 收到的一条消息及其发送者。
+type Message struct {
+	Msg    any
+	Sender *actor.PID
+}
+
+// Probe 是一个记录所有收到的消息的 actor。生命周期消息（actor.Initialized、
+// actor.Started、actor.Stopped）不会被记录。
+type Probe struct {
+	t      testing.TB
+	engine *actor.Engine
+	pid    *actor.PID
+	msgs   chan Message
+	last   *actor.PID
+}
+
+// NewProbe 在给定引擎上创建一个 Probe，测试结束时自动停止。
+func NewProbe(t testing.TB, e *actor.Engine) *Probe {
+	t.Helper()
+	p := &Probe{
+		t:      t,
+		engine: e,
+		msgs:   make(chan Message, 1024),
+	}
+	p.pid = e.SpawnFunc(p.receive, "probe")
+	t.Cleanup(func() {
+		<-e.Stop(p.pid).Done()
+	})
+	return p
+}
+
+func (p *Probe) receive(c *actor.Context) {
+	switch c.Message().(type) {
+	case actor.Initialized, actor.Started, actor.Stopped:
+		return
+	}
+	p.msgs <- Message{Msg: c.Message(), Sender: c.Sender()}
+}
+
+// PID 返回 Probe 的 PID。
+func (p *Probe) PID() *actor.PID {
+	return p.pid
+}
+
+// Engine 返回 Probe 所在的引擎。
+func (p *Probe) Engine() *actor.Engine {
+	return p.engine
+}
+
+// Send 以 Probe 作为发送者把消息发送给 pid，被测 actor 的 Respond 将回到 Probe。
+func (p *Probe) Send(pid *actor.PID, msg any) {
+	p.engine.SendWithSender(pid, msg, p.pid)
+}
+
+// Reply 以 Probe 作为发送者回复最近一条被 Expect 的消息的发送者。
+func (p *Probe) Reply(msg any) {
+	p.t.Helper()
+	if p.last == nil {
+		p.t.Fatal("testkit: 没有可以回复的发送者")
+	}
+	p.Send(p.last, msg)
+}
+
+// LastSender 返回最近一条被 Expect 的消息的发送者。
+func (p *Probe) LastSender() *actor.PID {
+	return p.last
+}
+
+// Watch 使 Probe 监视 pid，pid 停止时 Probe 将收到 *actor.Terminated。
+func (p *Probe) Watch(pid *actor.PID) {
+	p.Send(pid, &actor.Watch{Watcher: p.pid})
+}
+
+// ExpectAny 等待下一条消息并返回它。超时后测试失败。
+func (p *Probe) ExpectAny(timeout time.Duration) Message {
+	p.t.Helper()
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	select {
+	case m := <-p.msgs:
+		p.last = m.Sender
+		return m
+	case <-time.After(timeout):
+		p.t.Fatalf("testkit: %v 内没有收到消息", timeout)
+		return Message{}
+	}
+}
+
+// ExpectNoMessage 断言在 d 时间内没有收到任何消息。
+func (p *Probe) ExpectNoMessage(d time.Duration) {
+	p.t.Helper()
+	select {
+	case m := <-p.msgs:
+		p.t.Fatalf("testkit: 意外收到消息 %T: %+v", m.Msg, m.Msg)
+	case <-time.After(d):
+	}
+}
+
+// ExpectMessage 等待下一条消息并断言它的类型为 T，返回该消息。
+// 超时或消息类型不符时测试失败。
+func ExpectMessage[T any](p *Probe, timeout time.Duration) T {
+	p.t.Helper()
+	m := p.ExpectAny(timeout)
+	msg, ok := m.Msg.(T)
+	if !ok {
+		p.t.Fatalf("testkit: 期望收到 %v，实际收到 %T: %+v", reflect.TypeFor[T](), m.Msg, m.Msg)
+	}
+	return msg
+}
+
+// ExpectMessageFrom 与 ExpectMessage 相同，但同时断言消息的发送者为 sender。
+func ExpectMessageFrom[T any](p *Probe, sender *actor.PID, timeout time.Duration) T {
+	p.t.Helper()
+	msg := ExpectMessage[T](p, timeout)
+	if !samePID(p.last, sender) {
+		p.t.Fatalf("testkit: 期望发送者为 %v，实际为 %v", sender, p.last)
+	}
+	return msg
+}
+
+func samePID(a, b *actor.PID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(b)
+}
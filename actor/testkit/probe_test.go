@@ -0,0 +1,56 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/require"
+)
+
+type ping struct{ n int }
+type pong struct{ n int }
+
+func newPonger() actor.Receiver {
+	return ponger{}
+}
+
+type ponger struct{}
+
+func (ponger) Receive(c *actor.Context) {
+	if msg, ok := c.Message().(ping); ok {
+		c.Send(c.Sender(), pong{n: msg.n})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	pid := e.Spawn(newPonger, "ponger")
+	probe := NewProbe(t, e)
+
+	probe.Send(pid, ping{n: 1})
+	require.Equal(t, pong{n: 1}, ExpectMessage[pong](probe, time.Second))
+	require.True(t, pid.Equals(probe.LastSender()))
+
+	probe.Send(pid, ping{n: 2})
+	require.Equal(t, 2, ExpectMessageFrom[pong](probe, pid, time.Second).n)
+	probe.ExpectNoMessage(20 * time.Millisecond)
+
+	probe.Watch(pid)
+	e.Stop(pid)
+	terminated := ExpectMessage[*actor.Terminated](probe, time.Second)
+	require.True(t, pid.Equals(terminated.PID))
+}
+
+func TestProbeReply(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	probe := NewProbe(t, e)
+	resp := e.Request(probe.PID(), ping{n: 3}, time.Second)
+	require.Equal(t, ping{n: 3}, ExpectMessage[ping](probe, time.Second))
+	probe.Reply(pong{n: 3})
+	result, err := resp.Result()
+	require.NoError(t, err)
+	require.Equal(t, pong{n: 3}, result)
+}
@@ -0,0 +1,116 @@
+package actor
+
+import "time"
+
+// throttleTick 通知节流器一个间隔已经结束。
+type throttleTick struct{}
+
+// debounceTick 通知防抖器一个窗口已经结束，gen 用于忽略已被后续消息替换的窗口。
+type debounceTick struct {
+	gen uint64
+}
+
+// throttler 每个间隔最多向目标转发一条消息。
+type throttler struct {
+	target   *PID
+	interval time.Duration
+	pending  *Envelope
+	timer    *SendTimer
+}
+
+// NewThrottler 返回一个节流 actor 的 Producer：它收到的消息以每 interval 最多一条的速率
+// 转发给 target。间隔内的第一条消息立即转发，之后到达的消息只保留最新的一条，
+// 在间隔结束时转发（行情数据合并）。转发保留原始的发送者，actor 停止时转发尚未发出的消息。
+//
+//	pid := engine.Spawn(actor.NewThrottler(uiPID, 100*time.Millisecond), "ui-throttle")
+func NewThrottler(target *PID, interval time.Duration) Producer {
+	return func() Receiver {
+		return &throttler{target: target, interval: interval}
+	}
+}
+
+func (t *throttler) Receive(c *Context) {
+	switch c.Message().(type) {
+	case Initialized, Started:
+	case Stopped:
+		if t.timer != nil {
+			t.timer.Stop()
+		}
+		t.flush(c)
+	case throttleTick:
+		t.timer = nil
+		if t.pending != nil {
+			t.flush(c)
+			t.arm(c)
+		}
+	default:
+		t.pending = &Envelope{Msg: c.Message(), Sender: c.Sender()}
+		if t.timer == nil {
+			t.flush(c)
+			t.arm(c)
+		}
+	}
+}
+
+func (t *throttler) arm(c *Context) {
+	timer := c.SendAfter(c.PID(), throttleTick{}, t.interval)
+	t.timer = &timer
+}
+
+func (t *throttler) flush(c *Context) {
+	if t.pending == nil {
+		return
+	}
+	c.engine.SendWithSender(t.target, t.pending.Msg, t.pending.Sender)
+	t.pending = nil
+}
+
+// debouncer 在消息停止到达一段时间后转发最后一条消息。
+type debouncer struct {
+	target  *PID
+	window  time.Duration
+	pending *Envelope
+	gen     uint64
+	timer   *SendTimer
+}
+
+// NewDebouncer 返回一个防抖 actor 的 Producer：只有在 window 时间内没有新的消息到达时，
+// 才将最后一条消息转发给 target，突发的消息被合并为一条（例如 UI 刷新）。
+// 转发保留原始的发送者，actor 停止时转发尚未发出的消息。
+func NewDebouncer(target *PID, window time.Duration) Producer {
+	return func() Receiver {
+		return &debouncer{target: target, window: window}
+	}
+}
+
+func (d *debouncer) Receive(c *Context) {
+	switch msg := c.Message().(type) {
+	case Initialized, Started:
+	case Stopped:
+		if d.timer != nil {
+			d.timer.Stop()
+		}
+		d.flush(c)
+	case debounceTick:
+		if msg.gen == d.gen {
+			d.timer = nil
+			d.flush(c)
+		}
+	default:
+		d.pending = &Envelope{Msg: msg, Sender: c.Sender()}
+		if d.timer != nil {
+			d.timer.Stop()
+		}
+		d.gen++
+		timer := c.SendAfter(c.PID(), debounceTick{gen: d.gen}, d.window)
+		d.timer = &timer
+	}
+}
+
+func (d *debouncer) flush(c *Context) {
+	if d.pending == nil {
+		return
+	}
+	c.engine.SendWithSender(d.target, d.pending.Msg, d.pending.Sender)
+	d.pending = nil
+}
@@ -0,0 +1,63 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRecorder 创建一个记录收到的 int 消息的 actor。引擎使用同步调度，无需加锁。
+func newRecorder(e *Engine, got *[]int) *PID {
+	return e.SpawnFunc(func(c *Context) {
+		if n, ok := c.Message().(int); ok {
+			*got = append(*got, n)
+		}
+	}, "recorder")
+}
+
+func TestThrottler(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock).WithSynchronousDispatch())
+	require.NoError(t, err)
+	var got []int
+	pid := e.Spawn(NewThrottler(newRecorder(e, &got), 100*time.Millisecond), "throttle")
+
+	e.Send(pid, 1)
+	e.Send(pid, 2)
+	e.Send(pid, 3)
+	assert.Equal(t, []int{1}, got)
+
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, []int{1, 3}, got)
+
+	clock.Advance(100 * time.Millisecond)
+	e.Send(pid, 4)
+	assert.Equal(t, []int{1, 3, 4}, got)
+
+	e.Send(pid, 5)
+	<-e.Poison(pid).Done()
+	assert.Equal(t, []int{1, 3, 4, 5}, got)
+}
+
+func TestDebouncer(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	e, err := NewEngine(NewEngineConfig().WithClock(clock).WithSynchronousDispatch())
+	require.NoError(t, err)
+	var got []int
+	pid := e.Spawn(NewDebouncer(newRecorder(e, &got), 100*time.Millisecond), "debounce")
+
+	e.Send(pid, 1)
+	clock.Advance(60 * time.Millisecond)
+	e.Send(pid, 2)
+	clock.Advance(60 * time.Millisecond)
+	assert.Empty(t, got)
+
+	clock.Advance(40 * time.Millisecond)
+	assert.Equal(t, []int{2}, got)
+
+	e.Send(pid, 3)
+	<-e.Poison(pid).Done()
+	assert.Equal(t, []int{2, 3}, got)
+}
@@ -0,0 +1,45 @@
+package actor
+
+import "time"
+
+// SendTimer 表示一次延迟发送，可以在消息发出之前通过 Stop 取消。
+type SendTimer struct {
+	timer Timer
+}
+
+// Stop 取消尚未发出的消息。如果成功取消返回 true，
+// 如果消息已经发出或已被取消返回 false。
+func (st SendTimer) Stop() bool {
+	return st.timer.Stop()
+}
+
+func (e *Engine) sendAfter(pid *PID, msg any, sender *PID, delay time.Duration) SendTimer {
+	target := pid.CloneVT()
+	return SendTimer{
+		timer: e.clock.AfterFunc(delay, func() {
+			e.SendWithSender(target, msg, sender)
+		}),
+	}
+}
+
+// SendAfter 在给定的延迟之后将消息发送给给定的 PID。
+// 返回一个 SendTimer，可以在消息发出之前调用 Stop() 取消发送。
+func (e *Engine) SendAfter(pid *PID, msg any, delay time.Duration) SendTimer {
+	return e.sendAfter(pid, msg, nil, delay)
+}
+
+// SendAt 在给定的时间点将消息发送给给定的 PID。如果该时间点已经过去，消息将立即发送。
+// 返回一个 SendTimer，可以在消息发出之前调用 Stop() 取消发送。
+func (e *Engine) SendAt(pid *PID, msg any, at time.Time) SendTimer {
+	return e.sendAfter(pid, msg, nil, at.Sub(e.clock.Now()))
+}
+
+// SendAfter 在给定的延迟之后将消息发送给给定的 PID，发送者为当前 actor。
+func (c *Context) SendAfter(pid *PID, msg any, delay time.Duration) SendTimer {
+	return c.engine.sendAfter(pid, msg, c.pid, delay)
+}
+
+// SendAt 在给定的时间点将消息发送给给定的 PID，发送者为当前 actor。
+func (c *Context) SendAt(pid *PID, msg any, at time.Time) SendTimer {
+	return c.engine.sendAfter(pid, msg, c.pid, at.Sub(c.engine.clock.Now()))
+}
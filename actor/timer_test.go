@@ -0,0 +1,62 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendAfter(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	start := time.Now()
+	pid := e.SpawnFunc(func(c *Context) {
+		if c.Message() == "later" {
+			assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+			wg.Done()
+		}
+	}, "timer")
+	e.SendAfter(pid, "later", 10*time.Millisecond)
+	wg.Wait()
+}
+
+func TestSendAtWithSender(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			c.SendAt(c.PID(), "at", time.Now().Add(5*time.Millisecond))
+		case string:
+			assert.True(t, c.PID().Equals(c.Sender()))
+			wg.Done()
+		}
+	}, "timer")
+	wg.Wait()
+}
+
+func TestSendAfterStop(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	received := make(chan struct{}, 1)
+	pid := e.SpawnFunc(func(c *Context) {
+		if c.Message() == "never" {
+			received <- struct{}{}
+		}
+	}, "timer")
+	st := e.SendAfter(pid, "never", 10*time.Millisecond)
+	assert.True(t, st.Stop())
+	assert.False(t, st.Stop())
+	select {
+	case <-received:
+		t.Fatal("message delivered after Stop")
+	case <-time.After(30 * time.Millisecond):
+	}
+}
@@ -0,0 +1,27 @@
+package actor
+
+// TraceContext 是分布式追踪的上下文，字段与 W3C Trace Context 对应（十六进制的 trace ID 和 span ID）。
+// 它随 Traced 消息在 actor 之间以及节点之间传递，追踪系统（例如 OpenTelemetry）借此跟随消息跨越节点边界。
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	// Baggage 是随追踪传递的键值对。
+	Baggage map[string]string
+}
+
+// Traced 是携带追踪上下文的消息：
+//
+//	engine.Send(pid, &actor.Traced{Message: msg, Trace: tc})
+//
+// 接收者的 Receive 收到的是 Message 本身，追踪上下文通过 Context.TraceContext 获取。
+// 发往远程节点时，追踪上下文写入网络 Envelope，并在接收端还原。
+type Traced struct {
+	Message any
+	Trace   TraceContext
+}
+
+// TraceContext 返回当前消息携带的追踪上下文，消息不是以 Traced 发送时返回 nil。
+// 追踪上下文不会自动传递给 actor 发出的消息，需要时以新的 span 构造 Traced。
+func (c *Context) TraceContext() *TraceContext {
+	return c.trace
+}
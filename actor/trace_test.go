@@ -0,0 +1,50 @@
+package actor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracedMessage(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	traces := make(chan *TraceContext, 3)
+	stashed := false
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case string:
+			// 暂存的消息重新投递时保留追踪上下文。
+			if !stashed {
+				stashed = true
+				c.Stash()
+				c.UnstashAll()
+				return
+			}
+			traces <- c.TraceContext()
+		case int:
+			traces <- c.TraceContext()
+		}
+	}, "traced")
+
+	tc := TraceContext{TraceID: "trace", SpanID: "span"}
+	e.Send(pid, &Traced{Message: "hello", Trace: tc})
+	e.Send(pid, 1)
+
+	select {
+	case got := <-traces:
+		require.NotNil(t, got)
+		assert.Equal(t, tc, *got)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到消息")
+	}
+	select {
+	case got := <-traces:
+		assert.Nil(t, got)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到消息")
+	}
+}
@@ -0,0 +1,96 @@
+package actor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ActorState 描述 actor 在某一时刻的调度状态。
+type ActorState string
+
+const (
+	ActorStarting ActorState = "starting" // 启动中
+	ActorIdle     ActorState = "idle"     // 空闲，收件箱中没有正在处理的消息
+	ActorRunning  ActorState = "running"  // 正在处理消息
+	ActorStopped  ActorState = "stopped"  // 已停止
+)
+
+// ActorNode 是监督树中的一个节点。
+type ActorNode struct {
+	PID      *PID
+	State    ActorState
+	Restarts int32
+	Children []*ActorNode
+}
+
+// String 以缩进文本的形式输出以该节点为根的监督树，便于调试。
+func (n *ActorNode) String() string {
+	var sb strings.Builder
+	n.write(&sb, 0)
+	return sb.String()
+}
+
+func (n *ActorNode) write(sb *strings.Builder, depth int) {
+	fmt.Fprintf(sb, "%s%s [%s restarts=%d]\n", strings.Repeat("  ", depth), n.PID, n.State, n.Restarts)
+	for _, child := range n.Children {
+		child.write(sb, depth+1)
+	}
+}
+
+// inboxStatuser 由能够报告调度状态的收件箱实现。
+type inboxStatuser interface {
+	status() int32
+}
+
+func (in *Inbox) status() int32         { return atomic.LoadInt32(&in.procStatus) }
+func (in *MPSCInbox) status() int32     { return atomic.LoadInt32(&in.procStatus) }
+func (in *PriorityInbox) status() int32 { return atomic.LoadInt32(&in.procStatus) }
+func (in *BoundedInbox) status() int32  { return atomic.LoadInt32(&in.procStatus) }
+
+// state 返回进程当前的调度状态。
+func (p *process) state() ActorState {
+	if p.terminated.Load() {
+		return ActorStopped
+	}
+	in, ok := p.inbox.(inboxStatuser)
+	if !ok {
+		return ActorIdle
+	}
+	switch in.status() {
+	case starting:
+		return ActorStarting
+	case running:
+		return ActorRunning
+	case stopped:
+		return ActorStopped
+	}
+	return ActorIdle
+}
+
+// Tree 返回以 root 为根的完整监督树，包括每个 actor 的状态和重启次数，
+// 子节点按 ID 排序。root 不是本地 actor 时返回 nil。
+// 返回的是调用时刻的快照，树中的 actor 可能随时停止或重启。
+func (e *Engine) Tree(root *PID) *ActorNode {
+	proc, ok := e.Registry.get(root).(*process)
+	if !ok {
+		return nil
+	}
+	node := &ActorNode{
+		PID:      proc.pid,
+		State:    proc.state(),
+		Restarts: atomic.LoadInt32(&proc.restarts),
+	}
+	children := proc.context.Children()
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].ID < children[j].ID
+	})
+	for _, child := range children {
+		// 子 actor 可能已经停止但尚未从父 actor 中移除。
+		if n := e.Tree(child); n != nil {
+			node.Children = append(node.Children, n)
+		}
+	}
+	return node
+}
@@ -0,0 +1,77 @@
+package actor
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTree(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	leaf := func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			wg.Done()
+		}
+	}
+	root := e.SpawnFunc(func(c *Context) {
+		if _, ok := c.Message().(Started); ok {
+			c.SpawnChildFunc(func(c *Context) {
+				if _, ok := c.Message().(Started); ok {
+					c.SpawnChildFunc(leaf, "leaf", WithID("c"))
+				}
+			}, "mid", WithID("b"))
+			c.SpawnChildFunc(leaf, "leaf", WithID("a"))
+		}
+	}, "root")
+	wg.Wait()
+
+	tree := e.Tree(root)
+	require.NotNil(t, tree)
+	assert.True(t, tree.PID.Equals(root))
+	assert.Equal(t, int32(0), tree.Restarts)
+	require.Len(t, tree.Children, 2)
+	// 子节点按 ID 排序。
+	assert.True(t, strings.HasSuffix(tree.Children[0].PID.ID, "/a"))
+	assert.True(t, strings.HasSuffix(tree.Children[1].PID.ID, "/b"))
+	require.Len(t, tree.Children[1].Children, 1)
+	assert.Empty(t, tree.Children[0].Children)
+	assert.Equal(t, 4, strings.Count(tree.String(), "\n"))
+
+	assert.Nil(t, e.Tree(NewPID(e.Address(), "missing")))
+}
+
+func TestTreeRestarts(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+
+	restarted := make(chan struct{}, 1)
+	pid := e.SpawnFunc(func(c *Context) {
+		switch c.Message().(type) {
+		case Started:
+			select {
+			case restarted <- struct{}{}:
+			default:
+			}
+		case string:
+			panic("boom")
+		}
+	}, "crash", WithRestartDelay(0))
+	<-restarted
+	e.Send(pid, "crash")
+	<-restarted
+
+	tree := e.Tree(pid)
+	require.NotNil(t, tree)
+	assert.Equal(t, int32(1), tree.Restarts)
+	assert.NotEqual(t, ActorStopped, tree.State)
+
+	<-e.Poison(pid).Done()
+	assert.Nil(t, e.Tree(pid))
+}
@@ -0,0 +1,89 @@
+package actor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnexpectedResponse 在响应的类型与期望的类型不一致时返回。
+var ErrUnexpectedResponse = errors.New("意外的响应类型")
+
+// RequestTyped 将类型为 Req 的消息作为请求发送给给定的 PID，
+// 阻塞直到收到响应或超时，并将响应断言为 Resp 类型。
+// 如果响应不是 Resp 类型，返回包装了 ErrUnexpectedResponse 的错误。
+//
+//	pid, err := actor.RequestTyped[*GetPlayer, *actor.PID](engine, registry, &GetPlayer{ID: "1"}, time.Second)
+func RequestTyped[Req, Resp any](e *Engine, pid *PID, msg Req, timeout time.Duration) (Resp, error) {
+	var zero Resp
+	resp, err := e.Request(pid, msg, timeout).Result()
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := resp.(Resp)
+	if !ok {
+		return zero, fmt.Errorf("%w: 期望 %T，得到 %T", ErrUnexpectedResponse, zero, resp)
+	}
+	return typed, nil
+}
+
+// TypedReceiver 是只处理类型为 M 的用户消息的接收器。
+// 如果实现者同时实现了 Receiver 接口，其余消息（如 Started、Stopped 等生命周期消息）
+// 将交给 Receive 处理，否则这些消息会被忽略。
+type TypedReceiver[M any] interface {
+	ReceiveTyped(*Context, M)
+}
+
+// NewTypedProducer 将生产 TypedReceiver 的函数包装为普通的 Producer。
+func NewTypedProducer[M any](p func() TypedReceiver[M]) Producer {
+	return func() Receiver {
+		return &typedReceiver[M]{recv: p()}
+	}
+}
+
+// typedReceiver 将 TypedReceiver 适配为 Receiver。
+type typedReceiver[M any] struct {
+	recv TypedReceiver[M]
+}
+
+func (r *typedReceiver[M]) Receive(c *Context) {
+	if msg, ok := c.Message().(M); ok {
+		r.recv.ReceiveTyped(c, msg)
+		return
+	}
+	if recv, ok := r.recv.(Receiver); ok {
+		recv.Receive(c)
+	}
+}
+
+// typedFuncReceiver 将处理类型为 M 的消息的函数适配为 TypedReceiver。
+type typedFuncReceiver[M any] struct {
+	f func(*Context, M)
+}
+
+func (r typedFuncReceiver[M]) ReceiveTyped(c *Context, msg M) {
+	r.f(c, msg)
+}
+
+// SpawnTypedFunc 将给定的函数作为只处理类型为 M 的消息的无状态 actor 来创建进程。
+// 其他类型的消息（包括生命周期消息）都会被忽略。
+func SpawnTypedFunc[M any](e *Engine, f func(*Context, M), kind string, opts ...OptFunc) *PID {
+	return e.Spawn(NewTypedProducer(func() TypedReceiver[M] {
+		return typedFuncReceiver[M]{f: f}
+	}), kind, opts...)
+}
+
+// TypedPID 是只接受类型为 M 的消息的 PID 包装，用于在编译期约束发送的消息类型。
+type TypedPID[M any] struct {
+	*PID
+}
+
+// NewTypedPID 将给定的 PID 包装为 TypedPID。
+func NewTypedPID[M any](pid *PID) TypedPID[M] {
+	return TypedPID[M]{PID: pid}
+}
+
+// Send 通过给定的引擎将消息发送给该 PID。
+func (p TypedPID[M]) Send(e *Engine, msg M) {
+	e.Send(p.PID, msg)
+}
@@ -0,0 +1,61 @@
+package actor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedPing struct{ n int }
+
+type typedPong struct{ n int }
+
+func TestRequestTyped(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	pid := SpawnTypedFunc(e, func(c *Context, msg typedPing) {
+		c.Respond(typedPong{n: msg.n + 1})
+	}, "typed")
+
+	pong, err := RequestTyped[typedPing, typedPong](e, pid, typedPing{n: 1}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pong.n)
+
+	_, err = RequestTyped[typedPing, string](e, pid, typedPing{n: 1}, time.Second)
+	assert.ErrorIs(t, err, ErrUnexpectedResponse)
+}
+
+type typedCounter struct {
+	wg    *sync.WaitGroup
+	count int
+}
+
+func (r *typedCounter) ReceiveTyped(c *Context, msg typedPing) {
+	r.count += msg.n
+	if r.count == 3 {
+		r.wg.Done()
+	}
+}
+
+func (r *typedCounter) Receive(c *Context) {
+	if _, ok := c.Message().(Started); ok {
+		r.wg.Done()
+	}
+}
+
+func TestTypedProducer(t *testing.T) {
+	e, err := NewEngine(NewEngineConfig())
+	require.NoError(t, err)
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	pid := NewTypedPID[typedPing](e.Spawn(NewTypedProducer(func() TypedReceiver[typedPing] {
+		return &typedCounter{wg: wg}
+	}), "counter"))
+	pid.Send(e, typedPing{n: 1})
+	e.Send(pid.PID, "ignored")
+	pid.Send(e, typedPing{n: 2})
+	wg.Wait()
+}
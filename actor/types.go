@@ -22,3 +22,14 @@ type Started struct{}
 
 // Stopped 是停止完成消息。
 type Stopped struct{}
+
+// RequestFailure 在通过 Response.PipeTo 投递的请求失败（超时或对方调用了 RespondErr）时投递给接收者。
+type RequestFailure struct {
+	// Target 是请求的目标。
+	Target *PID
+	Err    error
+}
+
+// ReceiveTimeout 在 actor 空闲超过接收超时时间时投递给 actor。
+// 参见 WithReceiveTimeout 和 Context.SetReceiveTimeout。
+type ReceiveTimeout struct{}
@@ -0,0 +1,92 @@
+package actor
+
+import (
+	"runtime"
+	"sync"
+)
+
+// WorkerPool 是基于固定数量工作 goroutine 的调度器。与默认的 goroutine 调度器
+// 每次调度都创建一个新 goroutine 不同，它把所有收件箱的处理函数放入共享队列，
+// 由固定的工作者依次执行，适合存在大量空闲 actor 的系统。
+//
+// 注意：actor 在 Receive 中阻塞（例如同步等待 Request 的结果）会占用一个工作者，
+// 所有工作者都被阻塞时其他 actor 无法得到调度。
+//
+// 可以通过 EngineConfig.WithScheduler 作为引擎的默认调度器，也可以通过
+// WithScheduler 只用于部分 actor。
+type WorkerPool struct {
+	throughput int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func()
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewWorkerPool 创建一个拥有 workers 个工作者的调度器，throughput 是每轮调度
+// 处理的最大消息数。workers 小于等于 0 时使用 runtime.GOMAXPROCS(0)，
+// throughput 小于等于 0 时使用默认吞吐量。
+func NewWorkerPool(workers, throughput int) *WorkerPool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if throughput <= 0 {
+		throughput = defaultThroughput
+	}
+	p := &WorkerPool{throughput: throughput}
+	p.cond = sync.NewCond(&p.mu)
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Schedule 将函数放入共享队列，由空闲的工作者执行。队列没有上限，
+// 因此在工作者内部调用 Schedule 不会阻塞。工作池停止后函数将在新的 goroutine 中执行。
+func (p *WorkerPool) Schedule(fn func()) {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		go fn()
+		return
+	}
+	p.queue = append(p.queue, fn)
+	p.mu.Unlock()
+	p.cond.Signal()
+}
+
+// Throughput 返回调度器的吞吐量。
+func (p *WorkerPool) Throughput() int {
+	return p.throughput
+}
+
+// Stop 在队列中剩余的函数执行完毕后停止所有工作者。
+func (p *WorkerPool) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+	p.wg.Wait()
+}
+
+// work 是工作者的主循环。
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.stopped {
+			p.cond.Wait()
+		}
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		fn := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		fn()
+	}
+}
@@ -0,0 +1,48 @@
+package actor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(2, 10)
+	defer pool.Stop()
+	require.Equal(t, 10, pool.Throughput())
+
+	e, err := NewEngine(NewEngineConfig().WithScheduler(pool))
+	require.NoError(t, err)
+
+	const n = 1000
+	var wg sync.WaitGroup
+	wg.Add(n)
+	// 每个 actor 把消息转发给下一个，工作者内部的 Schedule 不能阻塞。
+	var next *PID
+	for i := 0; i < n; i++ {
+		to := next
+		next = e.SpawnFunc(func(c *Context) {
+			if _, ok := c.Message().(string); ok {
+				if to != nil {
+					c.Forward(to)
+				}
+				wg.Done()
+			}
+		}, "worker", WithID(fmt.Sprint(i)))
+	}
+	e.Send(next, "hop")
+	wg.Wait()
+}
+
+func TestWorkerPoolStop(t *testing.T) {
+	pool := NewWorkerPool(1, 0)
+	require.Equal(t, defaultThroughput, pool.Throughput())
+	done := make(chan struct{})
+	pool.Schedule(func() {})
+	pool.Stop()
+	// 停止后调度的函数仍然会被执行。
+	pool.Schedule(func() { close(done) })
+	<-done
+}
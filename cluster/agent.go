@@ -1,8 +1,7 @@
 package cluster
 
 import (
-	"log/slog"
-	"reflect"
+	"fmt"
 	"strings"
 
 	"github.com/TAnNbR/Distributed-framework/actor"
@@ -36,6 +35,8 @@ type Agent struct {
 	localKinds map[string]kind
 	// 集群范围内可用的所有 actor。
 	activated map[string]*actor.PID
+	// 分片 kind 的分配。
+	shards map[string]*shardTable
 }
 
 // NewAgent 创建一个新的 Agent Producer。
@@ -47,12 +48,17 @@ func NewAgent(c *Cluster) actor.Producer {
 		localKinds[kind.name] = kind
 	}
 	return func() actor.Receiver {
+		shards := make(map[string]*shardTable)
+		for kind, n := range c.config.shards {
+			shards[kind] = newShardTable(kind, n)
+		}
 		return &Agent{
 			members:    NewMemberSet(),
 			cluster:    c,
 			kinds:      kinds,
 			localKinds: localKinds,
 			activated:  make(map[string]*actor.PID),
+			shards:     shards,
 		}
 	}
 }
@@ -62,6 +68,7 @@ func (a *Agent) Receive(c *actor.Context) {
 	switch msg := c.Message().(type) {
 	case actor.Started:
 	case actor.Stopped:
+		a.stopShards()
 	case *ActorTopology:
 		a.handleActorTopology(msg)
 	case *Members:
@@ -69,14 +76,22 @@ func (a *Agent) Receive(c *actor.Context) {
 	case *Activation:
 		a.handleActivation(msg)
 	case activate:
-		pid := a.activate(msg.kind, msg.config)
+		pid, err := a.activate(msg.kind, msg.config)
+		if err != nil {
+			c.RespondErr(err)
+			return
+		}
 		c.Respond(pid)
 	case deactivate:
 		a.bcast(&Deactivation{PID: msg.pid})
 	case *Deactivation:
 		a.handleDeactivation(msg)
 	case *ActivationRequest:
-		resp := a.handleActivationRequest(msg)
+		resp, err := a.handleActivationRequest(msg)
+		if err != nil {
+			c.RespondErr(err)
+			return
+		}
 		c.Respond(resp)
 	case getMembers:
 		c.Respond(a.members.Slice())
@@ -90,6 +105,8 @@ func (a *Agent) Receive(c *actor.Context) {
 		c.Respond(kinds)
 	case getActive:
 		a.handleGetActive(c, msg)
+	case getShard:
+		a.handleGetShard(c, msg)
 	}
 }
 
@@ -136,10 +153,10 @@ func (a *Agent) handleActivation(msg *Activation) {
 }
 
 // handleActivationRequest 处理激活请求。
-func (a *Agent) handleActivationRequest(msg *ActivationRequest) *ActivationResponse {
+func (a *Agent) handleActivationRequest(msg *ActivationRequest) (*ActivationResponse, error) {
 	if !a.hasKindLocal(msg.Kind) {
-		slog.Error("收到激活请求但 kind 未在本地节点注册", "kind", msg.Kind)
-		return &ActivationResponse{Success: false}
+		a.cluster.engine.Logger().Error("收到激活请求但 kind 未在本地节点注册", "kind", msg.Kind)
+		return nil, fmt.Errorf("%w: %s", ErrKindNotRegistered, msg.Kind)
 	}
 
 	kind := a.localKinds[msg.Kind]
@@ -148,21 +165,19 @@ func (a *Agent) handleActivationRequest(msg *ActivationRequest) *ActivationRespo
 		PID:     pid,
 		Success: true,
 	}
-	return resp
+	return resp, nil
 }
 
 // activate 激活指定 kind 的 actor。
-func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
+func (a *Agent) activate(kind string, config ActivationConfig) (*actor.PID, error) {
 	// 确保 actor 在整个集群中是唯一的。
 	id := kind + "/" + config.id // PID 的 id 部分
 	if _, ok := a.activated[id]; ok {
-		slog.Warn("激活失败", "err", "集群中存在重复的 actor id", "id", id)
-		return nil
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateActorID, id)
 	}
 	members := a.members.FilterByKind(kind)
 	if len(members) == 0 {
-		slog.Warn("找不到具有该 kind 的成员", "kind", kind)
-		return nil
+		return nil, fmt.Errorf("%w: %s", ErrNoMemberForKind, kind)
 	}
 	if config.selectMember == nil {
 		config.selectMember = SelectRandomMember
@@ -173,8 +188,7 @@ func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
 		Kind:    kind,
 	})
 	if memberPID == nil {
-		slog.Warn("激活器未找到可激活的成员")
-		return nil
+		return nil, ErrNoActivatorMember
 	}
 	req := &ActivationRequest{Kind: kind, ID: config.id}
 	activatorPID := actor.NewPID(memberPID.Host, "cluster/"+memberPID.ID)
@@ -182,24 +196,21 @@ func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
 	var activationResp *ActivationResponse
 	// 本地激活
 	if memberPID.Host == a.cluster.engine.Address() {
-		activationResp = a.handleActivationRequest(req)
+		resp, err := a.handleActivationRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		activationResp = resp
 	} else {
 		// 远程激活
 		//
 		// TODO: 拓扑哈希
-		resp, err := a.cluster.engine.Request(activatorPID, req, a.cluster.config.requestTimeout).Result()
+		r, err := actor.RequestTyped[*ActivationRequest, *ActivationResponse](a.cluster.engine, activatorPID, req, a.cluster.config.requestTimeout)
 		if err != nil {
-			slog.Error("激活请求失败", "err", err)
-			return nil
-		}
-		r, ok := resp.(*ActivationResponse)
-		if !ok {
-			slog.Error("期望 *ActivationResponse", "msg", reflect.TypeOf(resp))
-			return nil
+			return nil, fmt.Errorf("远程激活失败 (%s): %w", memberPID.Host, err)
 		}
 		if !r.Success {
-			slog.Error("激活不成功", "msg", r)
-			return nil
+			return nil, fmt.Errorf("远程激活不成功 (%s)", memberPID.Host)
 		}
 		activationResp = r
 	}
@@ -208,7 +219,7 @@ func (a *Agent) activate(kind string, config ActivationConfig) *actor.PID {
 		PID: activationResp.PID,
 	})
 
-	return activationResp.PID
+	return activationResp.PID, nil
 }
 
 // handleMembers 处理成员列表消息。
@@ -222,6 +233,9 @@ func (a *Agent) handleMembers(members []*Member) {
 	for _, member := range left {
 		a.memberLeave(member)
 	}
+	if len(joined) > 0 || len(left) > 0 {
+		a.rebalanceShards()
+	}
 }
 
 // memberJoin 处理成员加入。
@@ -253,7 +267,7 @@ func (a *Agent) memberJoin(member *Member) {
 		Member: member,
 	})
 
-	slog.Debug("[CLUSTER] 成员加入",
+	a.cluster.engine.Logger().Debug("[CLUSTER] 成员加入",
 		"id", member.ID,
 		"host", member.Host,
 		"kinds", member.Kinds,
@@ -275,7 +289,7 @@ func (a *Agent) memberLeave(member *Member) {
 
 	a.cluster.engine.BroadcastEvent(MemberLeaveEvent{Member: member})
 
-	slog.Debug("[CLUSTER] 成员离开", "id", member.ID, "host", member.Host, "kinds", member.Kinds)
+	a.cluster.engine.Logger().Debug("[CLUSTER] 成员离开", "id", member.ID, "host", member.Host, "kinds", member.Kinds)
 }
 
 // bcast 向所有成员广播消息。
@@ -290,14 +304,14 @@ func (a *Agent) bcast(msg any) {
 func (a *Agent) addActivated(pid *actor.PID) {
 	if _, ok := a.activated[pid.ID]; !ok {
 		a.activated[pid.ID] = pid
-		slog.Debug("集群上新 actor 可用", "pid", pid)
+		a.cluster.engine.Logger().Debug("集群上新 actor 可用", "pid", pid)
 	}
 }
 
 // removeActivated 移除激活的 actor。
 func (a *Agent) removeActivated(pid *actor.PID) {
 	delete(a.activated, pid.ID)
-	slog.Debug("actor 从集群移除", "pid", pid)
+	a.cluster.engine.Logger().Debug("actor 从集群移除", "pid", pid)
 }
 
 // hasKindLocal 检查 kind 是否在本地注册。
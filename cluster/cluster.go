@@ -1,11 +1,11 @@
 package cluster
 
 import (
+	"errors"
 	fmt "fmt"
 	"log/slog"
 	"math"
 	"math/rand"
-	"reflect"
 	"slices"
 	"time"
 
@@ -16,28 +16,43 @@ import (
 // 选择一个合理的超时时间，以便长距离网络的节点也能正常工作。
 var defaultRequestTimeout = time.Second
 
+var (
+	// ErrDuplicateActorID 在集群中已存在相同 ID 的 actor 时由激活返回。
+	ErrDuplicateActorID = errors.New("集群中存在重复的 actor id")
+	// ErrNoMemberForKind 在没有任何成员注册了该 kind 时由激活返回。
+	ErrNoMemberForKind = errors.New("找不到具有该 kind 的成员")
+	// ErrNoActivatorMember 在成员选择函数没有选出成员时由激活返回。
+	ErrNoActivatorMember = errors.New("激活器未找到可激活的成员")
+	// ErrKindNotRegistered 在被选中的成员没有在本地注册该 kind 时由激活返回。
+	ErrKindNotRegistered = errors.New("kind 未在本地节点注册")
+)
+
 // Producer 是一个函数，给定 *cluster.Cluster 返回一个 actor.Producer。
 // 简单但强大的工具，用于构建依赖于 Cluster 的接收器。
 type Producer func(c *Cluster) actor.Producer
 
 // Config 保存集群配置。
 type Config struct {
-	listenAddr     string
-	id             string
-	region         string
-	engine         *actor.Engine
-	provider       Producer
-	requestTimeout time.Duration
+	listenAddr      string
+	id              string
+	region          string
+	engine          *actor.Engine
+	provider        Producer
+	requestTimeout  time.Duration
+	logger          *slog.Logger
+	leaderHeartbeat time.Duration
+	shards          map[string]int
 }
 
 // NewConfig 返回一个用默认值初始化的 Config。
 func NewConfig() Config {
 	return Config{
-		listenAddr:     getRandomListenAddr(),
-		id:             fmt.Sprintf("%d", rand.Intn(math.MaxInt)),
-		region:         "default",
-		provider:       NewSelfManagedProvider(NewSelfManagedConfig()),
-		requestTimeout: defaultRequestTimeout,
+		listenAddr:      getRandomListenAddr(),
+		id:              fmt.Sprintf("%d", rand.Intn(math.MaxInt)),
+		region:          "default",
+		provider:        NewSelfManagedProvider(NewSelfManagedConfig()),
+		requestTimeout:  defaultRequestTimeout,
+		leaderHeartbeat: defaultLeaderHeartbeat,
 	}
 }
 
@@ -48,6 +63,30 @@ func (config Config) WithRequestTimeout(d time.Duration) Config {
 	return config
 }
 
+// WithLeaderHeartbeat 设置领导者选举中领导者发送心跳的间隔，
+// 跟随者在 3 个间隔内没有收到心跳时重新选举。默认为 1 秒。
+func (config Config) WithLeaderHeartbeat(d time.Duration) Config {
+	config.leaderHeartbeat = d
+	return config
+}
+
+// WithShards 把 kind 分成 n 个分片，分片通过一致性哈希分配到注册了该 kind 的成员上，
+// 成员加入或离开时重新分配。每个分片是该 kind 的一个 actor，ID 为 "shard-<i>"，
+// 消息通过 Cluster.ShardPID 按 key 路由到分片。集群中所有成员必须使用相同的分片配置，
+// 包括没有注册该 kind、只发送消息的成员。n 小于 1 时按 1 处理。
+func (config Config) WithShards(kind string, n int) Config {
+	if n < 1 {
+		n = 1
+	}
+	shards := make(map[string]int, len(config.shards)+1)
+	for k, v := range config.shards {
+		shards[k] = v
+	}
+	shards[kind] = n
+	config.shards = shards
+	return config
+}
+
 // WithProvider 设置集群提供者。
 // 默认为 SelfManagedProvider。
 func (config Config) WithProvider(p Producer) Config {
@@ -62,6 +101,13 @@ func (config Config) WithEngine(e *actor.Engine) Config {
 	return config
 }
 
+// WithLogger 设置集群内部创建的引擎所使用的日志记录器。
+// 通过 WithEngine 提供引擎时，集群使用该引擎的日志记录器，此选项不生效。
+func (config Config) WithLogger(l *slog.Logger) Config {
+	config.logger = l
+	return config
+}
+
 // WithListenAddr 设置底层远程模块的监听地址。
 // 默认为随机端口号。
 func (config Config) WithListenAddr(addr string) Config {
@@ -92,13 +138,14 @@ type Cluster struct {
 	providerPID *actor.PID
 	isStarted   bool
 	kinds       []kind
+	elections   []*Election
 }
 
 // New 根据给定的 Config 返回一个新的集群。
 func New(config Config) (*Cluster, error) {
 	if config.engine == nil {
 		remote := remote.New(config.listenAddr, remote.NewConfig())
-		e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(remote))
+		e, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(remote).WithLogger(config.logger))
 		if err != nil {
 			return nil, err
 		}
@@ -117,10 +164,17 @@ func (c *Cluster) Start() {
 	c.agentPID = c.engine.Spawn(NewAgent(c), "cluster", actor.WithID(c.config.id))
 	c.providerPID = c.engine.Spawn(c.config.provider(c), "provider", actor.WithID(c.config.id))
 	c.isStarted = true
+	for _, e := range c.elections {
+		e.start()
+	}
 }
 
 // Stop 将关闭集群，毒化其所有 actor。
 func (c *Cluster) Stop() {
+	// 先退出选举，其他成员在本节点离开之前就开始重新选举。
+	for _, e := range c.elections {
+		<-c.engine.Poison(e.pid).Done()
+	}
 	<-c.engine.Poison(c.agentPID).Done()
 	<-c.engine.Poison(c.providerPID).Done()
 }
@@ -142,23 +196,25 @@ func (c *Cluster) Spawn(p actor.Producer, id string, opts ...actor.OptFunc) *act
 //
 //	playerPID := cluster.Activate("player", cluster.NewActivationConfig())
 func (c *Cluster) Activate(kind string, config ActivationConfig) *actor.PID {
-	msg := activate{
-		kind:   kind,
-		config: config,
-	}
-	resp, err := c.engine.Request(c.agentPID, msg, c.config.requestTimeout).Result()
+	pid, err := c.TryActivate(kind, config)
 	if err != nil {
-		slog.Error("激活失败", "err", err)
-		return nil
-	}
-	pid, ok := resp.(*actor.PID)
-	if !ok {
-		slog.Warn("激活期望响应类型为 *actor.PID", "got", reflect.TypeOf(resp))
+		c.engine.Logger().Error("激活失败", "err", err)
 		return nil
 	}
 	return pid
 }
 
+// TryActivate 与 Activate 相同，但在激活失败时返回错误，而不是只记录日志并返回 nil。
+// 错误可能是 ErrDuplicateActorID、ErrNoMemberForKind 等，也可能是请求超时
+// （actor.ErrRequestTimeout）或远程成员返回的 *actor.ResponseError。
+func (c *Cluster) TryActivate(kind string, config ActivationConfig) (*actor.PID, error) {
+	msg := activate{
+		kind:   kind,
+		config: config,
+	}
+	return actor.RequestTyped[activate, *actor.PID](c.engine, c.agentPID, msg, c.config.requestTimeout)
+}
+
 // Deactivate 停用给定的 PID。
 func (c *Cluster) Deactivate(pid *actor.PID) {
 	c.engine.Send(c.agentPID, deactivate{pid: pid})
@@ -171,7 +227,7 @@ func (c *Cluster) Deactivate(pid *actor.PID) {
 // 注意：kind 只能在集群启动之前注册。
 func (c *Cluster) RegisterKind(kind string, producer actor.Producer, config KindConfig) {
 	if c.isStarted {
-		slog.Warn("注册 kind 失败", "reason", "集群已启动", "kind", kind)
+		c.engine.Logger().Warn("注册 kind 失败", "reason", "集群已启动", "kind", kind)
 		return
 	}
 	c.kinds = append(c.kinds, newKind(kind, producer, config))
@@ -248,14 +304,11 @@ func (c *Cluster) GetActiveByKind(kind string) []*actor.PID {
 //	playerPid := c.GetActiveByID("player/1")
 //	// 127.0.0.1:34364/player/1
 func (c *Cluster) GetActiveByID(id string) *actor.PID {
-	resp, err := c.engine.Request(c.agentPID, getActive{id: id}, c.config.requestTimeout).Result()
+	pid, err := actor.RequestTyped[getActive, *actor.PID](c.engine, c.agentPID, getActive{id: id}, c.config.requestTimeout)
 	if err != nil {
 		return nil
 	}
-	if res, ok := resp.(*actor.PID); ok {
-		return res
-	}
-	return nil
+	return pid
 }
 
 // Member 返回此节点的成员信息。
@@ -410,6 +410,29 @@ func TestCannotDuplicateActor(t *testing.T) {
 	c2.Stop()
 }
 
+func TestTryActivateErrors(t *testing.T) {
+	c := makeCluster(t, getRandomLocalhostAddr(), "A", "eu")
+	c.RegisterKind("player", NewPlayer, NewKindConfig())
+	c.Start()
+	defer c.Stop()
+
+	// 等待本节点出现在成员列表中。
+	var pid *actor.PID
+	require.Eventually(t, func() bool {
+		var err error
+		pid, err = c.TryActivate("player", NewActivationConfig().WithID("1"))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "player/1", pid.ID)
+
+	_, err := c.TryActivate("player", NewActivationConfig().WithID("1"))
+	assert.ErrorIs(t, err, ErrDuplicateActorID)
+
+	_, err = c.TryActivate("unknown", NewActivationConfig())
+	assert.ErrorIs(t, err, ErrNoMemberForKind)
+	assert.NotErrorIs(t, err, actor.ErrRequestTimeout)
+}
+
 func makeCluster(t *testing.T, addr, id, region string) *Cluster {
 	config := NewConfig().
 		WithID(id).
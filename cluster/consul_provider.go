@@ -3,7 +3,6 @@ package cluster
 import (
 	fmt "fmt"
 	"log"
-	"log/slog"
 	"net"
 	"strconv"
 	"time"
@@ -14,35 +13,87 @@ import (
 )
 
 const (
-	registerTTL = 4 * time.Second
-	refreshTTL  = 2 * time.Second
-	removeTTL   = 8 * time.Second
+	// defaultConsulServiceName 是成员注册使用的默认 Consul 服务名。
+	defaultConsulServiceName = "Actors_actor"
+	registerTTL              = 4 * time.Second
+	removeTTL                = 8 * time.Second
 )
 
+// ConsulProviderConfig 是 Consul 提供者的配置。
 type ConsulProviderConfig struct {
-	address string
+	address     string
+	serviceName string
+	ttl         time.Duration
+	deregister  time.Duration
+	token       string
+	datacenter  string
 }
 
+// NewConsulProviderConfig 返回一个新的 Consul 提供者配置，默认连接本机的 Consul agent。
 func NewConsulProviderConfig() ConsulProviderConfig {
 	return ConsulProviderConfig{
-		address: "127.0.0.1:8500",
+		address:     "127.0.0.1:8500",
+		serviceName: defaultConsulServiceName,
+		ttl:         registerTTL,
+		deregister:  removeTTL,
 	}
 }
 
+// WithAddress 设置 Consul agent 的地址。
 func (c ConsulProviderConfig) WithAddress(address string) ConsulProviderConfig {
 	c.address = address
 	return c
 }
 
+// WithServiceName 设置成员注册的服务名，同一个集群的所有成员必须使用相同的服务名。
+// 默认为 "Actors_actor"。
+func (c ConsulProviderConfig) WithServiceName(name string) ConsulProviderConfig {
+	c.serviceName = name
+	return c
+}
+
+// WithTTL 设置健康检查的 TTL 和检查失败后注销服务的时间。成员每 ttl/2 刷新一次检查，
+// 超过 ttl 没有刷新的成员从其他成员的视图中移除。默认为 4 秒和 8 秒。
+func (c ConsulProviderConfig) WithTTL(ttl, deregisterAfter time.Duration) ConsulProviderConfig {
+	c.ttl = ttl
+	c.deregister = deregisterAfter
+	return c
+}
+
+// WithToken 设置访问 Consul 使用的 ACL 令牌。
+func (c ConsulProviderConfig) WithToken(token string) ConsulProviderConfig {
+	c.token = token
+	return c
+}
+
+// WithDatacenter 设置查询的数据中心，默认为 agent 所在的数据中心。
+func (c ConsulProviderConfig) WithDatacenter(dc string) ConsulProviderConfig {
+	c.datacenter = dc
+	return c
+}
+
+// apiConfig 返回 Consul 客户端的配置。
+func (c ConsulProviderConfig) apiConfig() *api.Config {
+	return &api.Config{
+		Address:    c.address,
+		Token:      c.token,
+		Datacenter: c.datacenter,
+	}
+}
+
+// ConsulProvider 把每个成员注册为带 TTL 健康检查的 Consul 服务，并根据目录中健康检查通过的
+// 服务实例构建成员集合。成员的 kind 保存在服务的标签中，ID 和区域保存在服务的元数据中。
 type ConsulProvider struct {
 	config    ConsulProviderConfig
 	cluster   *Cluster
 	client    *api.Client
 	id        string
+	plan      *watch.Plan
 	prevIndex watch.BlockingParamVal
 	quitch    chan struct{}
 }
 
+// Receive 处理接收到的消息。
 func (p *ConsulProvider) Receive(c *actor.Context) {
 	switch msg := c.Message().(type) {
 	case actor.Started:
@@ -50,17 +101,30 @@ func (p *ConsulProvider) Receive(c *actor.Context) {
 		if err := p.registerService(); err != nil {
 			panic(err)
 		}
+		plan, err := watch.Parse(map[string]any{
+			"type":        "service",
+			"service":     p.config.serviceName,
+			"passingonly": true,
+		})
+		if err != nil {
+			panic(err)
+		}
+		plan.HybridHandler = p.onUpdate
+		p.plan = plan
 		go p.watch()
 		go p.updateTTL()
 	case actor.Stopped:
 		close(p.quitch)
+		p.plan.Stop()
+		if err := p.client.Agent().ServiceDeregister(p.id); err != nil {
+			p.cluster.engine.Logger().Warn("[CLUSTER] 注销 Consul 服务失败", "err", err)
+		}
 	}
 }
 
+// NewConsulProvider 创建一个新的 Consul 提供者。
 func NewConsulProvider(config ConsulProviderConfig) Producer {
-	client, err := api.NewClient(&api.Config{
-		Address: config.address,
-	})
+	client, err := api.NewClient(config.apiConfig())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -77,100 +141,86 @@ func NewConsulProvider(config ConsulProviderConfig) Producer {
 	}
 }
 
+// registerService 在 Consul agent 上注册本成员的服务和 TTL 健康检查。
 func (p *ConsulProvider) registerService() error {
-	var (
-		config      = p.cluster.config
-		meta        = map[string]string{}
-		taggedAddrs = map[string]api.ServiceAddress{}
-	)
-
-	meta["name"] = config.id
+	config := p.cluster.config
+	p.id = p.memberID()
 	check := &api.AgentServiceCheck{
-		DeregisterCriticalServiceAfter: removeTTL.String(),
-		TLSSkipVerify:                  true,
-		TTL:                            registerTTL.String(),
-		CheckID:                        p.memberID(),
+		DeregisterCriticalServiceAfter: p.config.deregister.String(),
+		TTL:                            p.config.ttl.String(),
+		CheckID:                        p.id,
+		// 注册时检查即通过，新成员不需要等待第一次刷新就能被发现。
+		Status: api.HealthPassing,
 	}
-	p.id = check.CheckID
 
 	host, portStr, _ := net.SplitHostPort(p.cluster.Address())
 	port, _ := strconv.Atoi(portStr)
 
 	reg := &api.AgentServiceRegistration{
-		ID:                p.memberID(),
-		Name:              "Actors_actor",
-		Tags:              p.cluster.kindsToString(),
-		Address:           host,
-		Port:              port,
-		Meta:              meta,
-		TaggedAddresses:   taggedAddrs,
+		ID:      p.id,
+		Name:    p.config.serviceName,
+		Tags:    p.cluster.kindsToString(),
+		Address: host,
+		Port:    port,
+		Meta: map[string]string{
+			"name":   config.id,
+			"region": config.region,
+		},
 		EnableTagOverride: true,
 		Check:             check,
 	}
 	regopts := api.ServiceRegisterOpts{
 		ReplaceExistingChecks: true,
 	}
-
 	return p.client.Agent().ServiceRegisterOpts(reg, regopts)
 }
 
+// watch 通过阻塞查询监视服务目录，直到提供者停止。
 func (p *ConsulProvider) watch() {
-	query := map[string]any{
-		"type":        "service",
-		"service":     "Actors_actor",
-		"passingonly": true,
-	}
-
-	plan, err := watch.Parse(query)
-	if err != nil {
-		slog.Warn("consul provider", "err", err.Error())
-		return
-	}
-	plan.HybridHandler = p.onUpdate
-
-	config := &api.Config{
-		Address: p.config.address,
-	}
-	if err := plan.RunWithConfig(config.Address, config); err != nil {
-		panic(err)
+	p.plan.Token = p.config.token
+	p.plan.Datacenter = p.config.datacenter
+	if err := p.plan.RunWithConfig(p.config.address, p.config.apiConfig()); err != nil {
+		p.cluster.engine.Logger().Error("[CLUSTER] Consul watch 失败", "err", err)
 	}
 }
 
+// onUpdate 根据健康检查通过的服务实例构建成员集合并发送给集群代理。
 func (p *ConsulProvider) onUpdate(index watch.BlockingParamVal, msg any) {
 	entries, ok := msg.([]*api.ServiceEntry)
 	if !ok {
 		return
 	}
+	if p.prevIndex.Equal(index) {
+		return
+	}
+	p.prevIndex = index
 
-	var members []*Member
+	// 本成员总是在集合中，即使目录还没有反映它的注册。
+	members := NewMemberSet(p.cluster.Member())
 	for _, entry := range entries {
-		if len(entry.Checks) > 0 && entry.Checks.AggregatedStatus() == api.HealthPassing {
-			port := strconv.Itoa(entry.Service.Port)
-			member := &Member{
-				ID:    entry.Service.Meta["name"],
-				Host:  entry.Service.Address + ":" + port,
-				Kinds: entry.Service.Tags,
-			}
-			members = append(members, member)
+		if len(entry.Checks) == 0 || entry.Checks.AggregatedStatus() != api.HealthPassing {
+			continue
 		}
+		members.Add(&Member{
+			ID:     entry.Service.Meta["name"],
+			Host:   net.JoinHostPort(entry.Service.Address, strconv.Itoa(entry.Service.Port)),
+			Kinds:  entry.Service.Tags,
+			Region: entry.Service.Meta["region"],
+		})
 	}
-
-	if !p.prevIndex.Equal(index) && len(members) > 0 {
-		msg := Members{Members: members}
-		p.cluster.engine.Send(p.cluster.PID(), &msg)
-	}
-
-	p.prevIndex = index
+	p.cluster.engine.Send(p.cluster.PID(), &Members{Members: members.Slice()})
 }
 
+// updateTTL 每 ttl/2 刷新一次健康检查，直到提供者停止。
 func (p *ConsulProvider) updateTTL() {
-	ticker := time.NewTicker(time.Second)
+	ticker := time.NewTicker(p.config.ttl / 2)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
 			err := p.client.Agent().UpdateTTL(p.id, "", api.HealthPassing)
 			if err != nil {
-				slog.Warn("failed to update TTL", "err", err.Error())
+				p.cluster.engine.Logger().Warn("failed to update TTL", "err", err.Error())
 			}
 		case <-p.quitch:
 			return
@@ -178,6 +228,7 @@ func (p *ConsulProvider) updateTTL() {
 	}
 }
 
+// memberID 返回本成员的服务 ID。
 func (p *ConsulProvider) memberID() string {
 	config := p.cluster.config
 	host, port, _ := net.SplitHostPort(config.listenAddr)
@@ -0,0 +1,133 @@
+package cluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsul 模拟 Consul agent 的服务注册、TTL 检查和健康服务的阻塞查询。
+type fakeConsul struct {
+	mu       sync.Mutex
+	index    uint64
+	changed  chan struct{}
+	services map[string]*api.AgentServiceRegistration
+}
+
+func newFakeConsul() *fakeConsul {
+	return &fakeConsul{
+		index:    1,
+		changed:  make(chan struct{}),
+		services: make(map[string]*api.AgentServiceRegistration),
+	}
+}
+
+// bump 在持有锁时调用，唤醒等待中的阻塞查询。
+func (f *fakeConsul) bump() {
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/v1/agent/service/register":
+		reg := &api.AgentServiceRegistration{}
+		if err := json.NewDecoder(r.Body).Decode(reg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.services[reg.ID] = reg
+		f.bump()
+		f.mu.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		f.mu.Lock()
+		delete(f.services, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+		f.bump()
+		f.mu.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/"):
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		f.health(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) health(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	if r.URL.Query().Get("index") == strconv.FormatUint(f.index, 10) {
+		changed := f.changed
+		f.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(100 * time.Millisecond):
+		}
+		f.mu.Lock()
+	}
+	entries := []*api.ServiceEntry{}
+	for _, reg := range f.services {
+		entries = append(entries, &api.ServiceEntry{
+			Service: &api.AgentService{
+				ID:      reg.ID,
+				Service: reg.Name,
+				Tags:    reg.Tags,
+				Meta:    reg.Meta,
+				Address: reg.Address,
+				Port:    reg.Port,
+			},
+			Checks: api.HealthChecks{{CheckID: reg.ID, Status: api.HealthPassing}},
+		})
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	f.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func makeConsulCluster(t *testing.T, id string, consul *httptest.Server) *Cluster {
+	config := NewConsulProviderConfig().
+		WithAddress(strings.TrimPrefix(consul.URL, "http://")).
+		WithServiceName("test_actors")
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithRegion("eu-west").
+		WithProvider(NewConsulProvider(config)))
+	require.NoError(t, err)
+	c.RegisterKind("player", NewPlayer, NewKindConfig())
+	return c
+}
+
+func TestConsulProvider(t *testing.T) {
+	consul := newFakeConsul()
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	a := makeConsulCluster(t, "A", server)
+	b := makeConsulCluster(t, "B", server)
+	a.Start()
+	b.Start()
+	defer a.Stop()
+
+	assert.Eventually(t, func() bool { return len(a.Members()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	for _, member := range a.Members() {
+		assert.Equal(t, "eu-west", member.Region)
+		assert.Equal(t, []string{"player"}, member.Kinds)
+	}
+
+	// 停止的成员注销服务，从其他成员的视图中移除。
+	b.Stop()
+	assert.Eventually(t, func() bool { return len(a.Members()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	consul.mu.Lock()
+	assert.Len(t, consul.services, 1)
+	consul.mu.Unlock()
+}
@@ -0,0 +1,299 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// defaultSeedRefreshInterval 是默认的种子解析间隔。
+	defaultSeedRefreshInterval = 10 * time.Second
+	// seedLookupTimeout 是一次 DNS 解析的最长时间。
+	seedLookupTimeout = 5 * time.Second
+	// seedID 是每个节点上接收种子握手的 actor 的 ID，种子只知道地址，不知道成员 ID。
+	seedID = "seed"
+)
+
+// DNSConfig 是 DNS/静态种子提供者的配置。
+type DNSConfig struct {
+	name     string
+	seeds    []string
+	seedsEnv string
+	file     string
+	port     int
+	refresh  time.Duration
+	lookup   func(ctx context.Context, host string) ([]string, error)
+}
+
+// NewDNSConfig 返回一个新的 DNS/静态种子提供者配置。
+func NewDNSConfig() DNSConfig {
+	return DNSConfig{
+		refresh: defaultSeedRefreshInterval,
+		lookup:  net.DefaultResolver.LookupHost,
+	}
+}
+
+// WithName 设置解析为种子节点的 DNS 名称（例如 Kubernetes 的 headless Service 或云上的内网域名），
+// 它的每个 A/AAAA 记录是一个种子，端口由 WithPort 设置。
+func (c DNSConfig) WithName(name string) DNSConfig {
+	c.name = name
+	return c
+}
+
+// WithSeeds 添加静态的种子地址（"host:port"，省略端口时使用 WithPort 的端口）。
+func (c DNSConfig) WithSeeds(addrs ...string) DNSConfig {
+	c.seeds = append(append([]string(nil), c.seeds...), addrs...)
+	return c
+}
+
+// WithSeedsFromEnv 从环境变量 key 读取逗号分隔的种子地址，每次刷新时重新读取。
+func (c DNSConfig) WithSeedsFromEnv(key string) DNSConfig {
+	c.seedsEnv = key
+	return c
+}
+
+// WithSeedsFile 从文件读取种子地址，每行一个，忽略空行和 "#" 开头的注释，每次刷新时重新读取。
+func (c DNSConfig) WithSeedsFile(path string) DNSConfig {
+	c.file = path
+	return c
+}
+
+// WithPort 设置 DNS 解析结果和没有端口的种子使用的端口，默认与本节点的监听端口相同。
+func (c DNSConfig) WithPort(port int) DNSConfig {
+	c.port = port
+	return c
+}
+
+// WithRefreshInterval 设置重新解析种子的间隔，默认为 10 秒。
+func (c DNSConfig) WithRefreshInterval(d time.Duration) DNSConfig {
+	c.refresh = d
+	return c
+}
+
+type (
+	// seedRefresh 是重新解析种子的消息。
+	seedRefresh struct{}
+	// seedAddrs 是解析得到的种子地址。
+	seedAddrs struct {
+		addrs []string
+	}
+)
+
+// DNSProvider 是适用于任何环境的最小提供者：它定期解析 DNS 名称（或读取静态列表），
+// 向还不是成员的种子地址发送 Handshake，成员关系随后像 SelfManaged 一样通过握手传播，
+// 并在远程节点不可达时移除。种子由每个节点上 ID 为 "provider/seed" 的 actor 接收，
+// 因此集群 ID 不能是 "seed"。
+type DNSProvider struct {
+	config       DNSConfig
+	cluster      *Cluster
+	members      *MemberSet
+	pid          *actor.PID
+	seedPID      *actor.PID
+	eventSubPID  *actor.PID
+	refresher    actor.SendRepeater
+	memberPinger actor.SendRepeater
+}
+
+// NewDNSProvider 创建一个新的 DNS/静态种子提供者。
+func NewDNSProvider(config DNSConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &DNSProvider{
+				config:  config,
+				cluster: c,
+				members: NewMemberSet(),
+			}
+		}
+	}
+}
+
+// Receive 处理接收到的消息。
+func (d *DNSProvider) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		d.pid = c.PID()
+		d.members.Add(d.cluster.Member())
+		d.sendMembersToAgent()
+
+		pid := d.pid
+		d.seedPID = d.cluster.engine.SpawnFunc(func(c *actor.Context) {
+			if hs, ok := c.Message().(*Handshake); ok {
+				// 保留发送者，回复直接发给握手的发起者。
+				c.Engine().SendWithSender(pid, hs, c.Sender())
+			}
+		}, "provider", actor.WithID(seedID))
+		d.eventSubPID = c.SpawnChildFunc(d.handleEventStream, "event")
+		d.cluster.engine.Subscribe(d.eventSubPID)
+
+		d.memberPinger = c.SendRepeat(d.pid, memberPing{}, memberPingInterval)
+		d.refresher = c.SendRepeat(d.pid, seedRefresh{}, d.config.refresh)
+		d.resolve()
+	case actor.Stopped:
+		d.refresher.Stop()
+		d.memberPinger.Stop()
+		d.cluster.engine.Unsubscribe(d.eventSubPID)
+		d.cluster.engine.Poison(d.seedPID)
+	case seedRefresh:
+		d.resolve()
+	case seedAddrs:
+		d.handshake(msg.addrs)
+	case *Handshake:
+		joined := !d.members.Contains(msg.Member)
+		d.addMembers(msg.Member)
+		members := &Members{Members: d.members.Slice()}
+		d.cluster.engine.Send(c.Sender(), members)
+		if joined {
+			// 种子节点把新成员告诉其他成员，只认识种子的节点因此也能相互发现。
+			d.members.ForEach(func(member *Member) bool {
+				if member.ID != msg.Member.ID && member.Host != d.cluster.Address() {
+					c.Send(memberToProviderPID(member), members)
+				}
+				return true
+			})
+		}
+	case *Members:
+		d.addMembers(msg.Members...)
+	case memberPing:
+		d.members.ForEach(func(member *Member) bool {
+			if member.Host != d.cluster.Address() {
+				c.Send(memberToProviderPID(member), &actor.Ping{From: c.PID()})
+			}
+			return true
+		})
+	case memberLeave:
+		if member := d.members.GetByHost(msg.ListenAddr); member != nil {
+			d.members.Remove(member)
+			d.sendMembersToAgent()
+		}
+	case *actor.Ping:
+	case actor.Initialized:
+	default:
+		d.cluster.engine.Logger().Warn("收到未处理的消息", "msg", msg, "t", reflect.TypeOf(msg))
+	}
+}
+
+// resolve 在后台解析种子，结果以 seedAddrs 发送给提供者。
+func (d *DNSProvider) resolve() {
+	go func() {
+		addrs := d.config.seedAddrs(d.defaultPort(), d.cluster.engine.Logger().Warn)
+		d.cluster.engine.Send(d.pid, seedAddrs{addrs: addrs})
+	}()
+}
+
+// handshake 向还不是成员的种子地址发送 Handshake。
+func (d *DNSProvider) handshake(addrs []string) {
+	for _, addr := range addrs {
+		if addr == d.cluster.Address() || d.members.GetByHost(addr) != nil {
+			continue
+		}
+		d.cluster.engine.SendWithSender(actor.NewPID(addr, "provider/"+seedID), &Handshake{
+			Member: d.cluster.Member(),
+		}, d.pid)
+	}
+}
+
+// addMembers 添加成员。
+func (d *DNSProvider) addMembers(members ...*Member) {
+	for _, member := range members {
+		if !d.members.Contains(member) {
+			d.members.Add(member)
+		}
+	}
+	d.sendMembersToAgent()
+}
+
+// sendMembersToAgent 向本地集群代理发送所有当前成员。
+func (d *DNSProvider) sendMembersToAgent() {
+	d.cluster.engine.Send(d.cluster.PID(), &Members{
+		Members: d.members.Slice(),
+	})
+}
+
+// handleEventStream 把远程节点不可达转换为成员离开。
+func (d *DNSProvider) handleEventStream(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.RemoteUnreachableEvent:
+		c.Send(d.pid, memberLeave{ListenAddr: msg.ListenAddr})
+	}
+}
+
+// defaultPort 返回本节点的监听端口。
+func (d *DNSProvider) defaultPort() int {
+	_, p, _ := net.SplitHostPort(d.cluster.Address())
+	port, _ := strconv.Atoi(p)
+	return port
+}
+
+// seedAddrs 返回所有来源的种子地址，单个来源失败时通过 warn 记录并跳过它。
+func (c DNSConfig) seedAddrs(defaultPort int, warn func(msg string, args ...any)) []string {
+	port := c.port
+	if port == 0 {
+		port = defaultPort
+	}
+	withPort := func(addr string) string {
+		if _, _, err := net.SplitHostPort(addr); err == nil {
+			return addr
+		}
+		return net.JoinHostPort(addr, strconv.Itoa(port))
+	}
+
+	var addrs []string
+	for _, seed := range c.seeds {
+		addrs = append(addrs, withPort(seed))
+	}
+	if c.seedsEnv != "" {
+		for _, seed := range strings.Split(os.Getenv(c.seedsEnv), ",") {
+			if seed = strings.TrimSpace(seed); seed != "" {
+				addrs = append(addrs, withPort(seed))
+			}
+		}
+	}
+	if c.file != "" {
+		seeds, err := readSeedsFile(c.file)
+		if err != nil {
+			warn("[CLUSTER] 读取种子文件失败", "file", c.file, "err", err)
+		}
+		for _, seed := range seeds {
+			addrs = append(addrs, withPort(seed))
+		}
+	}
+	if c.name != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), seedLookupTimeout)
+		hosts, err := c.lookup(ctx, c.name)
+		cancel()
+		if err != nil {
+			warn("[CLUSTER] 解析种子失败", "name", c.name, "err", err)
+		}
+		for _, host := range hosts {
+			addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(port)))
+		}
+	}
+	return addrs
+}
+
+// readSeedsFile 读取种子文件中的地址。
+func readSeedsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var seeds []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		seeds = append(seeds, line)
+	}
+	return seeds, scanner.Err()
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSConfigSeedAddrs(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "seeds")
+	require.NoError(t, os.WriteFile(file, []byte("# 种子\n10.0.0.3\n\n10.0.0.4:5000\n"), 0o644))
+	t.Setenv("TEST_CLUSTER_SEEDS", "10.0.0.2, 10.0.0.5:6000")
+
+	config := NewDNSConfig().
+		WithSeeds("10.0.0.1:4000").
+		WithSeedsFromEnv("TEST_CLUSTER_SEEDS").
+		WithSeedsFile(file).
+		WithName("actors.internal")
+	config.lookup = func(_ context.Context, host string) ([]string, error) {
+		assert.Equal(t, "actors.internal", host)
+		return []string{"10.0.1.1", "fd00::1"}, nil
+	}
+	warn := func(msg string, args ...any) { t.Error(msg, args) }
+	assert.Equal(t, []string{
+		"10.0.0.1:4000",
+		"10.0.0.2:3000", "10.0.0.5:6000",
+		"10.0.0.3:3000", "10.0.0.4:5000",
+		"10.0.1.1:3000", "[fd00::1]:3000",
+	}, config.seedAddrs(3000, warn))
+
+	config = config.WithPort(7000)
+	assert.Contains(t, config.seedAddrs(3000, warn), "10.0.1.1:7000")
+}
+
+func TestDNSProvider(t *testing.T) {
+	seed := getRandomLocalhostAddr()
+	makeDNSCluster := func(id, addr string) *Cluster {
+		config := NewDNSConfig().
+			WithSeeds(seed).
+			WithRefreshInterval(50 * time.Millisecond)
+		c, err := New(NewConfig().
+			WithID(id).
+			WithListenAddr(addr).
+			WithProvider(NewDNSProvider(config)))
+		require.NoError(t, err)
+		return c
+	}
+	a := makeDNSCluster("A", seed)
+	b := makeDNSCluster("B", getRandomLocalhostAddr())
+	c := makeDNSCluster("C", getRandomLocalhostAddr())
+	// 种子节点可以晚于其他节点启动。
+	b.Start()
+	c.Start()
+	a.Start()
+	defer a.Stop()
+	defer b.Stop()
+	defer c.Stop()
+
+	for _, node := range []*Cluster{a, b, c} {
+		assert.Eventually(t, func() bool { return len(node.Members()) == 3 }, 3*time.Second, 10*time.Millisecond,
+			"%s 没有发现所有成员", node.ID())
+	}
+}
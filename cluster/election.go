@@ -0,0 +1,257 @@
+package cluster
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// defaultLeaderHeartbeat 是领导者发送心跳的默认间隔。
+const defaultLeaderHeartbeat = time.Second
+
+// electionState 是参与者在选举中的角色。
+type electionState int
+
+const (
+	electionFollower electionState = iota
+	electionCandidate
+	electionLeader
+)
+
+// electionTick 驱动领导者的心跳和超时检查。
+type electionTick struct{}
+
+// Election 是 Cluster.LeaderElection 返回的领导者选举句柄。
+type Election struct {
+	name    string
+	cluster *Cluster
+	pid     *actor.PID
+	leader  atomic.Pointer[Member]
+}
+
+// LeaderElection 使本节点参与名为 name 的领导者选举。同名选举的参与者中 ID 最大的成员当选
+// （bully 算法）：领导者定期向所有成员发送心跳，跟随者在 3 个心跳间隔内没有收到心跳、
+// 或领导者离开集群时发起选举；ID 更大的参与者加入后会接管领导权。
+// 本节点看到的领导者变化以 LeaderChangedEvent 发布到事件流。
+//
+//	election := c.LeaderElection("scheduler")
+//	if election.IsLeader() { ... }
+//
+// 可以在集群启动之前调用，选举在集群启动后开始。
+func (c *Cluster) LeaderElection(name string) *Election {
+	e := &Election{
+		name:    name,
+		cluster: c,
+	}
+	c.elections = append(c.elections, e)
+	if c.isStarted {
+		e.start()
+	}
+	return e
+}
+
+func (e *Election) start() {
+	e.pid = e.cluster.engine.Spawn(newElectionActor(e), "election", actor.WithID(e.name))
+}
+
+// Name 返回选举的名称。
+func (e *Election) Name() string {
+	return e.name
+}
+
+// Leader 返回本节点看到的当前领导者，选举尚未产生结果时返回 nil。
+func (e *Election) Leader() *Member {
+	return e.leader.Load()
+}
+
+// IsLeader 返回本节点是否是当前的领导者。
+func (e *Election) IsLeader() bool {
+	leader := e.leader.Load()
+	return leader != nil && leader.ID == e.cluster.ID()
+}
+
+// Stop 使本节点退出选举。本节点是领导者时，其他参与者立即重新选举。
+func (e *Election) Stop() {
+	e.cluster.elections = slices.DeleteFunc(e.cluster.elections, func(other *Election) bool {
+		return other == e
+	})
+	if e.pid != nil {
+		<-e.cluster.engine.Poison(e.pid).Done()
+	}
+}
+
+// electionActor 在每个参与者上运行选举，ID 为 "election/<name>"。
+type electionActor struct {
+	election *Election
+	cluster  *Cluster
+	self     *Member
+	members  *MemberSet
+	interval time.Duration
+	state    electionState
+	leader   *Member
+	// deadline 是跟随者等待心跳、候选者等待回复的截止时间。
+	deadline time.Time
+	ticker   actor.SendRepeater
+}
+
+func newElectionActor(e *Election) actor.Producer {
+	return func() actor.Receiver {
+		return &electionActor{
+			election: e,
+			cluster:  e.cluster,
+			interval: e.cluster.config.leaderHeartbeat,
+		}
+	}
+}
+
+// Receive 处理接收到的消息。
+func (e *electionActor) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		e.self = e.cluster.Member()
+		// 先订阅再读取成员，读取之后的成员变化不会丢失。
+		c.Engine().Subscribe(c.PID())
+		e.members = NewMemberSet(e.cluster.Members()...)
+		e.members.Add(e.self)
+		// 先等待现有领导者的心跳，而不是立即宣布当选。
+		e.deadline = time.Now().Add(3 * e.interval)
+		e.ticker = c.SendRepeat(c.PID(), electionTick{}, e.interval)
+	case actor.Stopped:
+		e.ticker.Stop()
+		c.Engine().Unsubscribe(c.PID())
+		if e.state == electionLeader {
+			e.bcast(c, &LeaderResign{Leader: e.self})
+		}
+		e.setLeader(nil)
+	case electionTick:
+		e.tick(c)
+	case *ElectionRequest:
+		c.Send(e.electionPID(msg.Candidate), &ElectionAlive{Member: e.self})
+		switch e.state {
+		case electionLeader:
+			c.Send(e.electionPID(msg.Candidate), &LeaderHeartbeat{Leader: e.self})
+		case electionFollower:
+			e.startElection(c)
+		}
+	case *ElectionAlive:
+		if e.state == electionCandidate {
+			// ID 更大的参与者接管了选举，等待它宣布结果。
+			e.state = electionFollower
+			e.deadline = time.Now().Add(3 * e.interval)
+		}
+	case *LeaderHeartbeat:
+		e.handleHeartbeat(c, msg.Leader)
+	case *LeaderResign:
+		if e.leader != nil && e.leader.ID == msg.Leader.ID {
+			e.setLeader(nil)
+			e.startElection(c)
+		}
+	case MemberJoinEvent:
+		e.members.Add(msg.Member)
+		if e.state == electionLeader {
+			c.Send(e.electionPID(msg.Member), &LeaderHeartbeat{Leader: e.self})
+		}
+	case MemberLeaveEvent:
+		e.members.Remove(msg.Member)
+		if e.leader != nil && e.leader.ID == msg.Member.ID {
+			e.setLeader(nil)
+			e.startElection(c)
+		}
+	}
+}
+
+// tick 由领导者发送心跳，由跟随者和候选者检查超时。
+func (e *electionActor) tick(c *actor.Context) {
+	switch e.state {
+	case electionLeader:
+		e.bcast(c, &LeaderHeartbeat{Leader: e.self})
+	case electionCandidate:
+		// 没有 ID 更大的参与者回复。
+		if time.Now().After(e.deadline) {
+			e.becomeLeader(c)
+		}
+	case electionFollower:
+		if time.Now().After(e.deadline) {
+			e.setLeader(nil)
+			e.startElection(c)
+		}
+	}
+}
+
+// startElection 向 ID 更大的成员发送 ElectionRequest，没有这样的成员时直接当选。
+// 没有参与该选举的成员不会回复，等同于不可达。
+func (e *electionActor) startElection(c *actor.Context) {
+	var higher []*Member
+	e.members.ForEach(func(member *Member) bool {
+		if member.ID > e.self.ID {
+			higher = append(higher, member)
+		}
+		return true
+	})
+	if len(higher) == 0 {
+		e.becomeLeader(c)
+		return
+	}
+	e.state = electionCandidate
+	e.deadline = time.Now().Add(2 * e.interval)
+	for _, member := range higher {
+		c.Send(e.electionPID(member), &ElectionRequest{Candidate: e.self})
+	}
+}
+
+// becomeLeader 宣布本节点当选。
+func (e *electionActor) becomeLeader(c *actor.Context) {
+	e.state = electionLeader
+	e.setLeader(e.self)
+	e.bcast(c, &LeaderHeartbeat{Leader: e.self})
+}
+
+// handleHeartbeat 接受 ID 不小于本节点的领导者，否则由本节点接管领导权。
+func (e *electionActor) handleHeartbeat(c *actor.Context, leader *Member) {
+	if leader.ID < e.self.ID {
+		switch e.state {
+		case electionLeader:
+			c.Send(e.electionPID(leader), &LeaderHeartbeat{Leader: e.self})
+		case electionFollower:
+			e.startElection(c)
+		}
+		return
+	}
+	e.state = electionFollower
+	e.deadline = time.Now().Add(3 * e.interval)
+	e.setLeader(leader)
+}
+
+// setLeader 记录领导者，领导者变化时发布 LeaderChangedEvent。
+func (e *electionActor) setLeader(leader *Member) {
+	if e.leader == nil && leader == nil {
+		return
+	}
+	if e.leader != nil && leader != nil && e.leader.Equals(leader) {
+		return
+	}
+	e.leader = leader
+	e.election.leader.Store(leader)
+	e.cluster.engine.BroadcastEvent(LeaderChangedEvent{
+		Name:   e.election.name,
+		Leader: leader,
+	})
+	e.cluster.engine.Logger().Debug("[CLUSTER] 领导者变化", "election", e.election.name, "leader", leader)
+}
+
+// bcast 向除本节点以外的所有成员发送消息。
+func (e *electionActor) bcast(c *actor.Context, msg any) {
+	e.members.ForEach(func(member *Member) bool {
+		if member.ID != e.self.ID {
+			c.Send(e.electionPID(member), msg)
+		}
+		return true
+	})
+}
+
+// electionPID 返回成员上同名选举的 PID。
+func (e *electionActor) electionPID(m *Member) *actor.PID {
+	return actor.NewPID(m.Host, "election/"+e.election.name)
+}
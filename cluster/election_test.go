@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeElectionCluster(t *testing.T, id string) *Cluster {
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithLeaderHeartbeat(50 * time.Millisecond))
+	require.NoError(t, err)
+	return c
+}
+
+// leaderID 返回选举看到的领导者的 ID，没有领导者时返回空字符串。
+func leaderID(e *Election) string {
+	if leader := e.Leader(); leader != nil {
+		return leader.ID
+	}
+	return ""
+}
+
+func TestLeaderElection(t *testing.T) {
+	var (
+		c1 = makeElectionCluster(t, "E1")
+		c2 = makeElectionCluster(t, "E2")
+		c3 = makeElectionCluster(t, "E3")
+		// E4 的 ID 最大，但没有参与选举。
+		c4 = makeElectionCluster(t, "E4")
+	)
+	e1 := c1.LeaderElection("coordinator")
+	e2 := c2.LeaderElection("coordinator")
+	e3 := c3.LeaderElection("coordinator")
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	unsubscribe := c1.Engine().SubscribeFunc(func(event any) {
+		if msg, ok := event.(LeaderChangedEvent); ok && msg.Name == "coordinator" && msg.Leader != nil {
+			mu.Lock()
+			changes = append(changes, msg.Leader.ID)
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	for _, c := range []*Cluster{c1, c2, c3, c4} {
+		c.Start()
+	}
+	defer c1.Stop()
+	defer c2.Stop()
+	defer c4.Stop()
+
+	for _, e := range []*Election{e1, e2, e3} {
+		assert.Eventually(t, func() bool { return leaderID(e) == "E3" }, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.True(t, e3.IsLeader())
+	assert.False(t, e1.IsLeader())
+	mu.Lock()
+	assert.Equal(t, "E3", changes[len(changes)-1])
+	mu.Unlock()
+
+	// 领导者停止后剩余参与者中 ID 最大的当选。
+	c3.Stop()
+	for _, e := range []*Election{e1, e2} {
+		assert.Eventually(t, func() bool { return leaderID(e) == "E2" }, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.True(t, e2.IsLeader())
+}
+
+func TestLeaderElectionTakeoverAndResign(t *testing.T) {
+	c1 := makeElectionCluster(t, "F1")
+	c2 := makeElectionCluster(t, "F2")
+	e1 := c1.LeaderElection("scheduler")
+	c1.Start()
+	defer c1.Stop()
+	assert.Eventually(t, e1.IsLeader, 5*time.Second, 10*time.Millisecond)
+
+	// ID 更大的参与者加入后接管领导权。
+	c2.Start()
+	defer c2.Stop()
+	e2 := c2.LeaderElection("scheduler")
+	assert.Eventually(t, e2.IsLeader, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return leaderID(e1) == "F2" }, 5*time.Second, 10*time.Millisecond)
+
+	// 领导者退出选举，但仍然是集群成员。
+	e2.Stop()
+	assert.Nil(t, e2.Leader())
+	assert.Eventually(t, e1.IsLeader, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, c1.Members(), 2)
+}
@@ -21,3 +21,25 @@ type ActivationEvent struct {
 type DeactivationEvent struct {
 	PID *actor.PID
 }
+
+// LeaderChangedEvent 在本节点看到的选举 Name 的领导者变化时触发。
+// Leader 为 nil 表示领导者已经离开，新的选举还没有结果。
+type LeaderChangedEvent struct {
+	Name   string
+	Leader *Member
+}
+
+// ShardRebalancedEvent 在成员变化使分片 kind 的分片换了成员时触发，每次重新分配触发一次。
+// 触发时本节点上移走的分片 actor 已经停止。
+type ShardRebalancedEvent struct {
+	Kind  string
+	Moves []ShardMove
+}
+
+// ShardMove 是一个分片的重新分配。From 为 nil 表示分片之前没有成员，
+// To 为 nil 表示没有注册该 kind 的成员可以托管它。
+type ShardMove struct {
+	Shard int
+	From  *Member
+	To    *Member
+}
@@ -0,0 +1,470 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// kubernetesServiceAccountDir 是 Pod 中服务账号的令牌、CA 证书和命名空间所在的目录。
+	kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	// kubernetesRetryInterval 是 watch 失败后重新列出资源之前等待的时间。
+	kubernetesRetryInterval = 2 * time.Second
+)
+
+// KubernetesConfig 是 Kubernetes 提供者的配置。
+type KubernetesConfig struct {
+	apiServer     string
+	namespace     string
+	service       string
+	labelSelector string
+	portName      string
+	port          int
+	tokenFile     string
+	client        *http.Client
+}
+
+// NewKubernetesConfig 返回一个新的 Kubernetes 提供者配置，默认使用 Pod 的服务账号访问集群内的 API。
+func NewKubernetesConfig() KubernetesConfig {
+	return KubernetesConfig{
+		tokenFile: filepath.Join(kubernetesServiceAccountDir, "token"),
+	}
+}
+
+// WithService 通过 headless Service 的 Endpoints 发现成员，只有就绪的地址是成员。
+func (c KubernetesConfig) WithService(name string) KubernetesConfig {
+	c.service = name
+	return c
+}
+
+// WithLabelSelector 通过匹配标签选择器的 Pod 发现成员，只有就绪的 Pod 是成员。
+func (c KubernetesConfig) WithLabelSelector(selector string) KubernetesConfig {
+	c.labelSelector = selector
+	return c
+}
+
+// WithNamespace 设置命名空间，默认为 Pod 所在的命名空间。
+func (c KubernetesConfig) WithNamespace(namespace string) KubernetesConfig {
+	c.namespace = namespace
+	return c
+}
+
+// WithPortName 选择 Endpoints 中名为 name 的端口，默认使用第一个端口。
+func (c KubernetesConfig) WithPortName(name string) KubernetesConfig {
+	c.portName = name
+	return c
+}
+
+// WithPort 设置按标签发现的 Pod 的远程端口，默认与本节点的监听端口相同。
+func (c KubernetesConfig) WithPort(port int) KubernetesConfig {
+	c.port = port
+	return c
+}
+
+// WithAPIServer 设置 API 服务器的地址（例如 "https://10.0.0.1:443"），默认从
+// KUBERNETES_SERVICE_HOST 和 KUBERNETES_SERVICE_PORT 环境变量获得。
+func (c KubernetesConfig) WithAPIServer(addr string) KubernetesConfig {
+	c.apiServer = strings.TrimSuffix(addr, "/")
+	return c
+}
+
+// WithTokenFile 设置 API 请求使用的 Bearer 令牌文件，每次请求时重新读取，空字符串表示不认证。
+func (c KubernetesConfig) WithTokenFile(path string) KubernetesConfig {
+	c.tokenFile = path
+	return c
+}
+
+// WithHTTPClient 设置访问 API 服务器的 HTTP 客户端，默认信任服务账号的 CA 证书。
+func (c KubernetesConfig) WithHTTPClient(client *http.Client) KubernetesConfig {
+	c.client = client
+	return c
+}
+
+// resolve 用集群内的默认值补全配置。
+func (c KubernetesConfig) resolve() (KubernetesConfig, error) {
+	if c.service == "" && c.labelSelector == "" {
+		return c, errors.New("需要设置 WithService 或 WithLabelSelector")
+	}
+	if c.apiServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return c, errors.New("未设置 KUBERNETES_SERVICE_HOST，不在 Kubernetes 集群中运行")
+		}
+		c.apiServer = "https://" + net.JoinHostPort(host, port)
+	}
+	if c.namespace == "" {
+		c.namespace = "default"
+		if b, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "namespace")); err == nil {
+			c.namespace = strings.TrimSpace(string(b))
+		}
+	}
+	if c.client == nil {
+		c.client = http.DefaultClient
+		if pem, err := os.ReadFile(filepath.Join(kubernetesServiceAccountDir, "ca.crt")); err == nil {
+			roots := x509.NewCertPool()
+			roots.AppendCertsFromPEM(pem)
+			c.client = &http.Client{Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+			}}
+		}
+	}
+	return c, nil
+}
+
+// path 返回列出（watch 为 false）或监视被发现资源的 API 路径。
+func (c KubernetesConfig) path(watch bool, resourceVersion string) string {
+	query := url.Values{}
+	resource := "endpoints"
+	if c.service != "" {
+		query.Set("fieldSelector", "metadata.name="+c.service)
+	} else {
+		resource = "pods"
+		query.Set("labelSelector", c.labelSelector)
+	}
+	if watch {
+		query.Set("watch", "true")
+		query.Set("resourceVersion", resourceVersion)
+	}
+	return fmt.Sprintf("/api/v1/namespaces/%s/%s?%s", url.PathEscape(c.namespace), resource, query.Encode())
+}
+
+// kubernetesPeers 是 watch 得到的就绪成员，从成员 ID（Pod 名称）映射到远程地址。
+type kubernetesPeers struct {
+	peers map[string]string
+}
+
+// KubernetesProvider 通过 Kubernetes API 发现集群成员，适用于 mDNS 无法跨越 Pod 的网络。
+// 每个节点的集群 ID 必须是它的 Pod 名称（例如通过 Downward API 把 metadata.name 传给 WithID），
+// 提供者向新出现的 Pod 发送 Handshake 获取它们的 kind，并在 Pod 不再就绪时移除对应的成员。
+// 服务账号需要对 endpoints（WithService）或 pods（WithLabelSelector）的 list 和 watch 权限。
+type KubernetesProvider struct {
+	config  KubernetesConfig
+	cluster *Cluster
+	members *MemberSet
+	peers   map[string]string
+	pid     *actor.PID
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewKubernetesProvider 创建一个新的 Kubernetes 提供者。
+func NewKubernetesProvider(config KubernetesConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &KubernetesProvider{
+				config:  config,
+				cluster: c,
+				members: NewMemberSet(),
+				peers:   make(map[string]string),
+			}
+		}
+	}
+}
+
+// Receive 处理接收到的消息。
+func (k *KubernetesProvider) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case actor.Started:
+		k.ctx, k.cancel = context.WithCancel(context.Background())
+		k.pid = c.PID()
+		k.members.Add(k.cluster.Member())
+		k.sendMembersToAgent()
+
+		config, err := k.config.resolve()
+		if err != nil {
+			k.cluster.engine.Logger().Error("[CLUSTER] Kubernetes 提供者配置无效", "err", err)
+			return
+		}
+		k.config = config
+		go k.watch()
+	case actor.Stopped:
+		k.cancel()
+	case kubernetesPeers:
+		k.handlePeers(msg.peers)
+	case *Handshake:
+		// 未就绪或不在选择器中的节点只收到当前的成员列表，不会加入成员。
+		if k.isPeer(msg.Member) {
+			k.addMembers(msg.Member)
+		}
+		k.cluster.engine.Send(c.Sender(), &Members{
+			Members: k.members.Slice(),
+		})
+	case *Members:
+		for _, member := range msg.Members {
+			if k.isPeer(member) {
+				k.addMembers(member)
+			}
+		}
+	case actor.Initialized:
+	default:
+		k.cluster.engine.Logger().Warn("收到未处理的消息", "msg", msg, "t", reflect.TypeOf(msg))
+	}
+}
+
+// handlePeers 向新出现的 Pod 握手并移除不再就绪的成员。
+func (k *KubernetesProvider) handlePeers(peers map[string]string) {
+	k.peers = peers
+	self := k.cluster.ID()
+	for _, member := range k.members.Slice() {
+		if member.ID == self {
+			continue
+		}
+		// Pod 重建后名称不变但地址改变，旧的成员同样需要移除。
+		if addr, ok := peers[member.ID]; !ok || addr != member.Host {
+			k.members.Remove(member)
+		}
+	}
+	for id, addr := range peers {
+		if id == self || k.members.members[id] != nil {
+			continue
+		}
+		k.cluster.engine.SendWithSender(actor.NewPID(addr, "provider/"+id), &Handshake{
+			Member: k.cluster.Member(),
+		}, k.pid)
+	}
+	k.sendMembersToAgent()
+}
+
+// isPeer 返回 member 是否是已知就绪的 Pod。Kubernetes 是成员关系的依据，
+// 握手和成员列表只能添加这些 Pod。
+func (k *KubernetesProvider) isPeer(member *Member) bool {
+	if member == nil {
+		return false
+	}
+	_, ok := k.peers[member.ID]
+	return ok
+}
+
+// addMembers 添加成员。
+func (k *KubernetesProvider) addMembers(members ...*Member) {
+	for _, member := range members {
+		if !k.members.Contains(member) {
+			k.members.Add(member)
+		}
+	}
+	k.sendMembersToAgent()
+}
+
+// sendMembersToAgent 向本地集群代理发送所有当前成员。
+func (k *KubernetesProvider) sendMembersToAgent() {
+	k.cluster.engine.Send(k.cluster.PID(), &Members{
+		Members: k.members.Slice(),
+	})
+}
+
+// watch 列出并监视被发现的资源，失败时等待一段时间后重新开始，直到提供者停止。
+func (k *KubernetesProvider) watch() {
+	for {
+		err := k.watchOnce()
+		if k.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			continue
+		}
+		k.cluster.engine.Logger().Warn("[CLUSTER] Kubernetes watch 失败", "err", err)
+		select {
+		case <-k.ctx.Done():
+			return
+		case <-time.After(kubernetesRetryInterval):
+		}
+	}
+}
+
+// watchOnce 列出资源，然后处理 watch 事件直到连接结束（服务器超时时返回 nil）。
+func (k *KubernetesProvider) watchOnce() error {
+	body, err := k.get(k.config.path(false, ""))
+	if err != nil {
+		return err
+	}
+	var list struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}
+	err = json.NewDecoder(body).Decode(&list)
+	body.Close()
+	if err != nil {
+		return err
+	}
+	objects := make(map[string]map[string]string)
+	for _, item := range list.Items {
+		name, peers, err := k.parse(item)
+		if err != nil {
+			return err
+		}
+		objects[name] = peers
+	}
+	k.publish(objects)
+
+	body, err = k.get(k.config.path(true, list.Metadata.ResourceVersion))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	dec := json.NewDecoder(body)
+	for {
+		var event struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := dec.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			name, peers, err := k.parse(event.Object)
+			if err != nil {
+				return err
+			}
+			if event.Type == "DELETED" {
+				delete(objects, name)
+			} else {
+				objects[name] = peers
+			}
+			k.publish(objects)
+		case "ERROR":
+			// 通常是 resourceVersion 过期（410 Gone），重新列出资源。
+			return fmt.Errorf("watch 错误: %s", event.Object)
+		}
+	}
+}
+
+// get 向 API 服务器发送 GET 请求，返回响应体。
+func (k *KubernetesProvider) get(path string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(k.ctx, http.MethodGet, k.config.apiServer+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if k.config.tokenFile != "" {
+		token, err := os.ReadFile(k.config.tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := k.config.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Kubernetes API 返回 %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// publish 把所有资源中的就绪成员发送给提供者。
+func (k *KubernetesProvider) publish(objects map[string]map[string]string) {
+	peers := make(map[string]string)
+	for _, object := range objects {
+		for id, addr := range object {
+			peers[id] = addr
+		}
+	}
+	k.cluster.engine.Send(k.pid, kubernetesPeers{peers: peers})
+}
+
+// parse 解析一个 Endpoints 或 Pod 对象，返回对象名称和其中的就绪成员。
+func (k *KubernetesProvider) parse(data []byte) (string, map[string]string, error) {
+	if k.config.service != "" {
+		return parseEndpoints(data, k.config.portName)
+	}
+	port := k.config.port
+	if port == 0 {
+		_, p, _ := net.SplitHostPort(k.cluster.Address())
+		port, _ = strconv.Atoi(p)
+	}
+	return parsePod(data, port)
+}
+
+// parseEndpoints 返回 Endpoints 的名称和就绪地址，成员 ID 是地址引用的 Pod 名称。
+func parseEndpoints(data []byte, portName string) (string, map[string]string, error) {
+	var endpoints struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Subsets []struct {
+			Addresses []struct {
+				IP        string `json:"ip"`
+				TargetRef *struct {
+					Name string `json:"name"`
+				} `json:"targetRef"`
+			} `json:"addresses"`
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"subsets"`
+	}
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return "", nil, err
+	}
+	peers := make(map[string]string)
+	for _, subset := range endpoints.Subsets {
+		port := 0
+		for _, p := range subset.Ports {
+			if portName == "" || p.Name == portName {
+				port = p.Port
+				break
+			}
+		}
+		if port == 0 {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil {
+				continue
+			}
+			peers[addr.TargetRef.Name] = net.JoinHostPort(addr.IP, strconv.Itoa(port))
+		}
+	}
+	return endpoints.Metadata.Name, peers, nil
+}
+
+// parsePod 返回 Pod 的名称，Pod 就绪时还返回它在 port 上的地址。
+func parsePod(data []byte, port int) (string, map[string]string, error) {
+	var pod struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			PodIP      string `json:"podIP"`
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &pod); err != nil {
+		return "", nil, err
+	}
+	peers := make(map[string]string)
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "Ready" && cond.Status == "True" && pod.Status.PodIP != "" {
+			peers[pod.Metadata.Name] = net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port))
+		}
+	}
+	return pod.Metadata.Name, peers, nil
+}
@@ -0,0 +1,152 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKubernetes 模拟 API 服务器上名为 "actors" 的 Endpoints，每个 watch 请求收到之后的所有变更。
+type fakeKubernetes struct {
+	mu       sync.Mutex
+	object   map[string]any
+	watchers []chan map[string]any
+}
+
+func (f *fakeKubernetes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	if r.URL.Query().Get("watch") != "true" {
+		list := map[string]any{
+			"metadata": map[string]any{"resourceVersion": "1"},
+			"items":    []any{f.object},
+		}
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(list)
+		return
+	}
+	ch := make(chan map[string]any, 8)
+	if f.object != nil {
+		// 列出之后、watch 之前的变更。
+		ch <- f.object
+	}
+	f.watchers = append(f.watchers, ch)
+	f.mu.Unlock()
+	w.(http.Flusher).Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case object := <-ch:
+			_ = json.NewEncoder(w).Encode(map[string]any{"type": "MODIFIED", "object": object})
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// set 把 Endpoints 的地址设置为 members 中的节点并通知所有 watch。
+func (f *fakeKubernetes) set(members ...*Cluster) {
+	subsets := []any{}
+	for _, c := range members {
+		host, port, _ := net.SplitHostPort(c.Address())
+		p, _ := strconv.Atoi(port)
+		subsets = append(subsets, map[string]any{
+			"addresses": []any{map[string]any{"ip": host, "targetRef": map[string]any{"name": c.ID()}}},
+			"ports":     []any{map[string]any{"name": "remote", "port": p}},
+		})
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.object = map[string]any{"metadata": map[string]any{"name": "actors"}, "subsets": subsets}
+	for _, ch := range f.watchers {
+		ch <- f.object
+	}
+}
+
+func makeKubernetesCluster(t *testing.T, id string, api *httptest.Server) *Cluster {
+	config := NewKubernetesConfig().
+		WithAPIServer(api.URL).
+		WithNamespace("default").
+		WithService("actors").
+		WithPortName("remote").
+		WithTokenFile("")
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithProvider(NewKubernetesProvider(config)))
+	require.NoError(t, err)
+	return c
+}
+
+func TestKubernetesProvider(t *testing.T) {
+	api := &fakeKubernetes{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	a := makeKubernetesCluster(t, "A", server)
+	b := makeKubernetesCluster(t, "B", server)
+	a.Start()
+	b.Start()
+	api.set(a, b)
+	defer a.Stop()
+	defer b.Stop()
+
+	for _, c := range []*Cluster{a, b} {
+		assert.Eventually(t, func() bool { return len(c.Members()) == 2 }, 2*time.Second, 10*time.Millisecond,
+			fmt.Sprintf("%s 没有发现另一个成员", c.ID()))
+	}
+
+	// B 不再就绪。
+	api.set(a)
+	assert.Eventually(t, func() bool { return len(a.Members()) == 1 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestKubernetesParsePod(t *testing.T) {
+	pod := `{"metadata":{"name":"actors-0"},"status":{"podIP":"10.1.2.3",
+		"conditions":[{"type":"Ready","status":"True"}]}}`
+	name, peers, err := parsePod([]byte(pod), 4000)
+	require.NoError(t, err)
+	assert.Equal(t, "actors-0", name)
+	assert.Equal(t, map[string]string{"actors-0": "10.1.2.3:4000"}, peers)
+
+	pending := `{"metadata":{"name":"actors-1"},"status":{"podIP":"10.1.2.4",
+		"conditions":[{"type":"Ready","status":"False"}]}}`
+	name, peers, err = parsePod([]byte(pending), 4000)
+	require.NoError(t, err)
+	assert.Equal(t, "actors-1", name)
+	assert.Empty(t, peers)
+}
+
+func TestKubernetesConfigPath(t *testing.T) {
+	config := NewKubernetesConfig().WithNamespace("prod").WithLabelSelector("app=actors")
+	assert.Equal(t, "/api/v1/namespaces/prod/pods?labelSelector=app%3Dactors", config.path(false, ""))
+	config = config.WithService("actors")
+	assert.Equal(t, "/api/v1/namespaces/prod/endpoints?fieldSelector=metadata.name%3Dactors&resourceVersion=7&watch=true",
+		config.path(true, "7"))
+}
+
+func TestKubernetesProviderIgnoresUnknownHandshake(t *testing.T) {
+	api := &fakeKubernetes{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	a := makeKubernetesCluster(t, "A", server)
+	c := makeKubernetesCluster(t, "C", server)
+	a.Start()
+	c.Start()
+	defer a.Stop()
+	defer c.Stop()
+	// C 不在 Endpoints 中，它向 A 握手只会收到 A 的成员列表。
+	api.set(a)
+
+	assert.Eventually(t, func() bool { return len(c.Members()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.Never(t, func() bool { return len(a.Members()) != 1 }, 200*time.Millisecond, 10*time.Millisecond)
+}
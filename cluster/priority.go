@@ -0,0 +1,43 @@
+package cluster
+
+import "github.com/TAnNbR/Distributed-framework/remote"
+
+// 集群的成员协议和激活消息作为远程优先消息发送，大量的应用消息因此不会推迟成员变更，
+// 避免被误判为成员离开。
+var (
+	_ remote.Prioritized = (*Handshake)(nil)
+	_ remote.Prioritized = (*Members)(nil)
+	_ remote.Prioritized = (*MembersJoin)(nil)
+	_ remote.Prioritized = (*MembersLeave)(nil)
+	_ remote.Prioritized = (*Topology)(nil)
+	_ remote.Prioritized = (*ActorTopology)(nil)
+	_ remote.Prioritized = (*Activation)(nil)
+	_ remote.Prioritized = (*Deactivation)(nil)
+	_ remote.Prioritized = (*ActivationRequest)(nil)
+	_ remote.Prioritized = (*ActivationResponse)(nil)
+	_ remote.Prioritized = (*SwimPing)(nil)
+	_ remote.Prioritized = (*SwimAck)(nil)
+	_ remote.Prioritized = (*SwimPingReq)(nil)
+	_ remote.Prioritized = (*ElectionRequest)(nil)
+	_ remote.Prioritized = (*ElectionAlive)(nil)
+	_ remote.Prioritized = (*LeaderHeartbeat)(nil)
+	_ remote.Prioritized = (*LeaderResign)(nil)
+)
+
+func (*Handshake) RemotePriority() bool          { return true }
+func (*Members) RemotePriority() bool            { return true }
+func (*MembersJoin) RemotePriority() bool        { return true }
+func (*MembersLeave) RemotePriority() bool       { return true }
+func (*Topology) RemotePriority() bool           { return true }
+func (*ActorTopology) RemotePriority() bool      { return true }
+func (*Activation) RemotePriority() bool         { return true }
+func (*Deactivation) RemotePriority() bool       { return true }
+func (*ActivationRequest) RemotePriority() bool  { return true }
+func (*ActivationResponse) RemotePriority() bool { return true }
+func (*SwimPing) RemotePriority() bool           { return true }
+func (*SwimAck) RemotePriority() bool            { return true }
+func (*SwimPingReq) RemotePriority() bool        { return true }
+func (*ElectionRequest) RemotePriority() bool    { return true }
+func (*ElectionAlive) RemotePriority() bool      { return true }
+func (*LeaderHeartbeat) RemotePriority() bool    { return true }
+func (*LeaderResign) RemotePriority() bool       { return true }
@@ -0,0 +1,410 @@
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// defaultRedisMemberTTL 是成员键的默认有效期。
+	defaultRedisMemberTTL = 4 * time.Second
+	// minRedisMemberTTL 是成员键的最短有效期，每 ttl/2 一次的刷新需要留出往返 Redis 的时间。
+	minRedisMemberTTL = 100 * time.Millisecond
+	// redisDialTimeout 是连接 Redis 的超时时间。
+	redisDialTimeout = 5 * time.Second
+)
+
+// RedisConfig 是 Redis 提供者的配置。
+type RedisConfig struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	ttl      time.Duration
+}
+
+// NewRedisConfig 返回一个新的 Redis 提供者配置，默认连接本机的 Redis。
+func NewRedisConfig() RedisConfig {
+	return RedisConfig{
+		addr:   "127.0.0.1:6379",
+		prefix: "actors:",
+		ttl:    defaultRedisMemberTTL,
+	}
+}
+
+// WithAddress 设置 Redis 的地址。
+func (c RedisConfig) WithAddress(addr string) RedisConfig {
+	c.addr = addr
+	return c
+}
+
+// WithPassword 设置 AUTH 使用的密码。
+func (c RedisConfig) WithPassword(password string) RedisConfig {
+	c.password = password
+	return c
+}
+
+// WithDB 设置使用的数据库编号，默认为 0。
+func (c RedisConfig) WithDB(db int) RedisConfig {
+	c.db = db
+	return c
+}
+
+// WithPrefix 设置成员键和通知频道的前缀，同一个集群的所有成员必须使用相同的前缀。
+// 默认为 "actors:"。
+func (c RedisConfig) WithPrefix(prefix string) RedisConfig {
+	c.prefix = prefix
+	return c
+}
+
+// WithTTL 设置成员键的有效期。成员每 ttl/2 刷新一次键并重新读取成员列表，
+// 没有正常停止的成员在 ttl 之后从其他成员的视图中移除。默认为 4 秒，小于 100ms 时使用默认值。
+func (c RedisConfig) WithTTL(ttl time.Duration) RedisConfig {
+	if ttl < minRedisMemberTTL {
+		ttl = defaultRedisMemberTTL
+	}
+	c.ttl = ttl
+	return c
+}
+
+func (c RedisConfig) memberKey(id string) string { return c.prefix + "member:" + id }
+func (c RedisConfig) channel() string            { return c.prefix + "events" }
+
+// redisMember 是保存在成员键中的成员信息。
+type redisMember struct {
+	ID     string   `json:"id"`
+	Host   string   `json:"host"`
+	Kinds  []string `json:"kinds"`
+	Region string   `json:"region"`
+}
+
+// RedisProvider 使用带有效期的 Redis 键注册成员，并通过发布/订阅通知成员的加入和离开，
+// 适用于已经运行 Redis、不想引入 etcd 或 Consul 的场景。成员列表由所有未过期的成员键构成，
+// 收到通知时立即重新读取，否则每 ttl/2 读取一次。
+type RedisProvider struct {
+	config  RedisConfig
+	cluster *Cluster
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRedisProvider 创建一个新的 Redis 提供者。
+func NewRedisProvider(config RedisConfig) Producer {
+	return func(c *Cluster) actor.Producer {
+		return func() actor.Receiver {
+			return &RedisProvider{
+				config:  config,
+				cluster: c,
+				done:    make(chan struct{}),
+			}
+		}
+	}
+}
+
+// Receive 处理接收到的消息。
+func (p *RedisProvider) Receive(c *actor.Context) {
+	switch c.Message().(type) {
+	case actor.Started:
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+		p.sendMembersToAgent(NewMemberSet(p.cluster.Member()))
+		notify := make(chan struct{}, 1)
+		go p.subscribe(notify)
+		go p.run(notify)
+	case actor.Stopped:
+		p.cancel()
+		<-p.done
+	}
+}
+
+// run 注册本成员并维护成员列表，直到提供者停止，停止时删除成员键并通知其他成员。
+func (p *RedisProvider) run(notify <-chan struct{}) {
+	defer close(p.done)
+	var conn *redisConn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	ticker := time.NewTicker(p.config.ttl / 2)
+	defer ticker.Stop()
+	joined := false
+	for {
+		if conn == nil {
+			var err error
+			if conn, err = dialRedis(p.ctx, p.config); err != nil {
+				p.cluster.engine.Logger().Warn("[CLUSTER] 连接 Redis 失败", "err", err)
+				conn = nil
+			}
+		}
+		if conn != nil {
+			if err := p.register(conn, !joined); err != nil {
+				p.cluster.engine.Logger().Warn("[CLUSTER] Redis 注册失败", "err", err)
+				conn.Close()
+				conn = nil
+			} else {
+				joined = true
+			}
+		}
+		select {
+		case <-p.ctx.Done():
+			if conn != nil {
+				p.leave(conn)
+			}
+			return
+		case <-ticker.C:
+		case <-notify:
+		}
+	}
+}
+
+// register 刷新本成员的键并把读取的成员列表发送给集群代理，announce 时通知其他成员。
+func (p *RedisProvider) register(conn *redisConn, announce bool) error {
+	member := p.cluster.Member()
+	b, err := json.Marshal(redisMember{
+		ID:     member.ID,
+		Host:   member.Host,
+		Kinds:  member.Kinds,
+		Region: member.Region,
+	})
+	if err != nil {
+		return err
+	}
+	ttl := strconv.FormatInt(p.config.ttl.Milliseconds(), 10)
+	if _, err := conn.do("SET", p.config.memberKey(member.ID), string(b), "PX", ttl); err != nil {
+		return err
+	}
+	if announce {
+		if _, err := conn.do("PUBLISH", p.config.channel(), "join:"+member.ID); err != nil {
+			return err
+		}
+	}
+	members, err := p.members(conn)
+	if err != nil {
+		return err
+	}
+	p.sendMembersToAgent(members)
+	return nil
+}
+
+// leave 删除本成员的键并通知其他成员。
+func (p *RedisProvider) leave(conn *redisConn) {
+	id := p.cluster.ID()
+	if _, err := conn.do("DEL", p.config.memberKey(id)); err != nil {
+		p.cluster.engine.Logger().Warn("[CLUSTER] 删除 Redis 成员键失败", "err", err)
+		return
+	}
+	_, _ = conn.do("PUBLISH", p.config.channel(), "leave:"+id)
+}
+
+// members 读取所有未过期的成员键，本成员总是在结果中。
+func (p *RedisProvider) members(conn *redisConn) (*MemberSet, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := conn.do("SCAN", cursor, "MATCH", p.config.memberKey("*"), "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		parts, ok := reply.([]any)
+		if !ok || len(parts) != 2 {
+			return nil, fmt.Errorf("SCAN 返回意外的回复 %v", reply)
+		}
+		cursor, _ = parts[0].(string)
+		batch, _ := parts[1].([]any)
+		for _, key := range batch {
+			if s, ok := key.(string); ok {
+				keys = append(keys, s)
+			}
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+
+	members := NewMemberSet(p.cluster.Member())
+	if len(keys) == 0 {
+		return members, nil
+	}
+	reply, err := conn.do(append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	values, _ := reply.([]any)
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			// 读取之间过期的键。
+			continue
+		}
+		var m redisMember
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			p.cluster.engine.Logger().Warn("[CLUSTER] 无效的 Redis 成员键", "err", err)
+			continue
+		}
+		members.Add(&Member{ID: m.ID, Host: m.Host, Kinds: m.Kinds, Region: m.Region})
+	}
+	return members, nil
+}
+
+// subscribe 订阅成员通知，收到通知时唤醒 run，连接断开后重新订阅。
+func (p *RedisProvider) subscribe(notify chan<- struct{}) {
+	for p.ctx.Err() == nil {
+		err := p.subscribeOnce(notify)
+		if p.ctx.Err() != nil {
+			return
+		}
+		p.cluster.engine.Logger().Warn("[CLUSTER] Redis 订阅失败", "err", err)
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(p.config.ttl / 2):
+		}
+	}
+}
+
+func (p *RedisProvider) subscribeOnce(notify chan<- struct{}) error {
+	conn, err := dialRedis(p.ctx, p.config)
+	if err != nil {
+		return err
+	}
+	// 停止时关闭连接，结束阻塞的读取。
+	stop := context.AfterFunc(p.ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+	if _, err := conn.do("SUBSCRIBE", p.config.channel()); err != nil {
+		return err
+	}
+	// 订阅之后的读取没有超时。
+	if err := conn.conn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	for {
+		reply, err := conn.read()
+		if err != nil {
+			return err
+		}
+		if msg, ok := reply.([]any); ok && len(msg) == 3 && msg[0] == "message" {
+			select {
+			case notify <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// sendMembersToAgent 向本地集群代理发送成员列表。
+func (p *RedisProvider) sendMembersToAgent(members *MemberSet) {
+	p.cluster.engine.Send(p.cluster.PID(), &Members{
+		Members: members.Slice(),
+	})
+}
+
+// redisError 是 Redis 返回的错误回复。
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisConn 是一个最小的 RESP 客户端，只支持提供者需要的命令。
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialRedis 连接到 Redis，按配置认证并选择数据库。
+func dialRedis(ctx context.Context, config RedisConfig) (*redisConn, error) {
+	d := net.Dialer{Timeout: redisDialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", config.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if config.password != "" {
+		if _, err := c.do("AUTH", config.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if config.db != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(config.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) Close() error {
+	return c.conn.Close()
+}
+
+// do 发送命令并读取回复，Redis 没有在 redisDialTimeout 内回复时返回错误。
+func (c *redisConn) do(args ...string) (any, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(redisDialTimeout)); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.conn.Write([]byte(b.String())); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read 读取一个回复：简单字符串和批量字符串为 string，空批量字符串为 nil，
+// 整数为 int64，数组为 []any，错误回复作为 redisError 返回。
+func (c *redisConn) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: 空的回复")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: 无法识别的回复 %q", line)
+}
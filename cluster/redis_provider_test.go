@@ -0,0 +1,193 @@
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis 实现提供者使用的 Redis 命令：SET（PX）、DEL、SCAN、MGET、PUBLISH 和 SUBSCRIBE。
+type fakeRedis struct {
+	ln          net.Listener
+	mu          sync.Mutex
+	values      map[string]string
+	expires     map[string]time.Time
+	subscribers map[string][]*fakeRedisClient
+}
+
+type fakeRedisClient struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (c *fakeRedisClient) write(s string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = c.conn.Write([]byte(s))
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{
+		ln:          ln,
+		values:      make(map[string]string),
+		expires:     make(map[string]time.Time),
+		subscribers: make(map[string][]*fakeRedisClient),
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) addr() string { return f.ln.Addr().String() }
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	client := &fakeRedisClient{conn: conn}
+	r := bufio.NewReader(conn)
+	for {
+		c := &redisConn{conn: conn, r: r}
+		reply, err := c.read()
+		if err != nil {
+			return
+		}
+		items := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = item.(string)
+		}
+		client.write(f.exec(client, args))
+	}
+}
+
+func bulk(s string) string { return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s) }
+
+// exec 执行命令并返回 RESP 编码的回复。
+func (f *fakeRedis) exec(client *fakeRedisClient, args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, exp := range f.expires {
+		if time.Now().After(exp) {
+			delete(f.values, key)
+			delete(f.expires, key)
+		}
+	}
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		f.values[args[1]] = args[2]
+		delete(f.expires, args[1])
+		if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+			ms, _ := strconv.Atoi(args[4])
+			f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := f.values[args[1]]
+		delete(f.values, args[1])
+		delete(f.expires, args[1])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SCAN":
+		var keys []string
+		for key := range f.values {
+			if ok, _ := path.Match(args[3], key); ok {
+				keys = append(keys, bulk(key))
+			}
+		}
+		return fmt.Sprintf("*2\r\n%s*%d\r\n%s", bulk("0"), len(keys), strings.Join(keys, ""))
+	case "MGET":
+		out := fmt.Sprintf("*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			if v, ok := f.values[key]; ok {
+				out += bulk(v)
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return out
+	case "PUBLISH":
+		subs := f.subscribers[args[1]]
+		msg := "*3\r\n" + bulk("message") + bulk(args[1]) + bulk(args[2])
+		for _, sub := range subs {
+			go sub.write(msg)
+		}
+		return fmt.Sprintf(":%d\r\n", len(subs))
+	case "SUBSCRIBE":
+		f.subscribers[args[1]] = append(f.subscribers[args[1]], client)
+		return "*3\r\n" + bulk("subscribe") + bulk(args[1]) + ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func makeRedisCluster(t *testing.T, id string, redis *fakeRedis, ttl time.Duration) *Cluster {
+	config := NewRedisConfig().
+		WithAddress(redis.addr()).
+		WithPrefix("test:").
+		WithTTL(ttl)
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithProvider(NewRedisProvider(config)))
+	require.NoError(t, err)
+	return c
+}
+
+func TestRedisProvider(t *testing.T) {
+	redis := newFakeRedis(t)
+	// 较长的 TTL 确保成员变化来自通知而不是定期刷新。
+	a := makeRedisCluster(t, "A", redis, time.Minute)
+	b := makeRedisCluster(t, "B", redis, time.Minute)
+	a.Start()
+	defer a.Stop()
+	b.Start()
+
+	for _, c := range []*Cluster{a, b} {
+		assert.Eventually(t, func() bool { return len(c.Members()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	}
+
+	b.Stop()
+	assert.Eventually(t, func() bool { return len(a.Members()) == 1 }, 2*time.Second, 10*time.Millisecond)
+	redis.mu.Lock()
+	assert.NotContains(t, redis.values, "test:member:B")
+	redis.mu.Unlock()
+}
+
+func TestRedisProviderMemberExpires(t *testing.T) {
+	redis := newFakeRedis(t)
+	a := makeRedisCluster(t, "A", redis, 200*time.Millisecond)
+	a.Start()
+	defer a.Stop()
+
+	// 没有刷新的键（崩溃的成员）过期后被移除。
+	redis.exec(nil, []string{"SET", "test:member:X", `{"id":"X","host":"127.0.0.1:1"}`, "PX", "300"})
+	assert.Eventually(t, func() bool { return len(a.Members()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return len(a.Members()) == 1 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRedisConfigTTL(t *testing.T) {
+	assert.Equal(t, 200*time.Millisecond, NewRedisConfig().WithTTL(200*time.Millisecond).ttl)
+	// 过短的有效期会使刷新的 ticker panic，使用默认值。
+	for _, ttl := range []time.Duration{-time.Second, 0, time.Nanosecond, time.Millisecond} {
+		assert.Equal(t, defaultRedisMemberTTL, NewRedisConfig().WithTTL(ttl).ttl)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"log/slog"
 	"net"
 	"reflect"
 	"strconv"
@@ -38,26 +37,45 @@ type (
 // SelfManagedConfig 是自管理提供者的配置。
 type SelfManagedConfig struct {
 	bootstrapMembers []MemberAddr
+	probeInterval    time.Duration
+	suspicionTimeout time.Duration
 }
 
 // NewSelfManagedConfig 返回一个新的自管理配置。
 func NewSelfManagedConfig() SelfManagedConfig {
 	return SelfManagedConfig{
 		bootstrapMembers: make([]MemberAddr, 0),
+		probeInterval:    defaultProbeInterval,
+		suspicionTimeout: defaultSuspicionTimeout,
 	}
 }
 
+// WithProbeInterval 设置 SWIM 探测周期，每个周期探测一个成员。默认为 1 秒。
+func (c SelfManagedConfig) WithProbeInterval(d time.Duration) SelfManagedConfig {
+	c.probeInterval = d
+	return c
+}
+
+// WithSuspicionTimeout 设置被怀疑的成员在没有反驳时被宣布死亡并离开集群之前的时间。
+// 默认为 4 秒。
+func (c SelfManagedConfig) WithSuspicionTimeout(d time.Duration) SelfManagedConfig {
+	c.suspicionTimeout = d
+	return c
+}
+
 // WithBootstrapMember 添加一个引导成员。
 func (c SelfManagedConfig) WithBootstrapMember(member MemberAddr) SelfManagedConfig {
 	c.bootstrapMembers = append(c.bootstrapMembers, member)
 	return c
 }
 
-// SelfManaged 是自管理的集群提供者。
+// SelfManaged 是自管理的集群提供者。成员通过引导成员和 mDNS 发现，
+// 成员的存活由 SWIM 协议检测，参见 swim。
 type SelfManaged struct {
 	config       SelfManagedConfig
 	cluster      *Cluster
 	members      *MemberSet
+	swim         *swim
 	memberPinger actor.SendRepeater
 	eventSubPID  *actor.PID
 
@@ -96,63 +114,51 @@ func (s *SelfManaged) Receive(c *actor.Context) {
 		s.members.Add(s.cluster.Member())
 		s.sendMembersToAgent()
 
-		s.memberPinger = c.SendRepeat(c.PID(), memberPing{}, memberPingInterval)
+		s.swim = newSwim(s.cluster.Member(), s.members, s.config)
+		s.swim.send = func(pid *actor.PID, msg any) {
+			s.cluster.engine.SendWithSender(pid, msg, s.pid)
+		}
+		s.swim.schedule = func(d time.Duration, msg any) {
+			time.AfterFunc(d, func() { s.cluster.engine.Send(s.pid, msg) })
+		}
+		s.swim.changed = s.sendMembersToAgent
+		s.memberPinger = c.SendRepeat(c.PID(), swimTick{}, s.config.probeInterval)
 		s.start(c)
 	case actor.Stopped:
 		s.memberPinger.Stop()
+		s.swim.leave()
 		s.cluster.engine.Unsubscribe(s.eventSubPID)
 		s.announcer.Shutdown()
 		s.cancel()
 	case *Handshake:
-		s.addMembers(msg.Member)
+		s.swim.join(msg.Member, true)
 		members := s.members.Slice()
 		s.cluster.engine.Send(c.Sender(), &Members{
 			Members: members,
 		})
 	case *Members:
-		s.addMembers(msg.Members...)
-	case memberPing:
-		s.handleMemberPing(c)
+		for _, member := range msg.Members {
+			s.swim.join(member, false)
+		}
+	case swimTick:
+		s.swim.tick()
+	case swimProbeTimeout:
+		s.swim.probeTimeout(msg.seq)
+	case *SwimPing:
+		s.swim.handlePing(c.Sender(), msg)
+	case *SwimAck:
+		s.swim.handleAck(msg)
+	case *SwimPingReq:
+		s.swim.handlePingReq(c.Sender(), msg)
 	case memberLeave:
-		member := s.members.GetByHost(msg.ListenAddr)
-		s.removeMember(member)
+		// 不可达只是怀疑的依据，成员在怀疑超时之前仍可以反驳。
+		s.swim.suspect(msg.ListenAddr)
 	case *actor.Ping:
 	case actor.Initialized:
 		_ = msg
 	default:
-		slog.Warn("收到未处理的消息", "msg", msg, "t", reflect.TypeOf(msg))
-	}
-}
-
-// handleMemberPing 处理成员心跳。
-func (s *SelfManaged) handleMemberPing(c *actor.Context) {
-	s.members.ForEach(func(member *Member) bool {
-		if member.Host != s.cluster.agentPID.Address {
-			ping := &actor.Ping{
-				From: c.PID(),
-			}
-			c.Send(memberToProviderPID(member), ping)
-		}
-		return true
-	})
-}
-
-// addMembers 添加成员。
-func (s *SelfManaged) addMembers(members ...*Member) {
-	for _, member := range members {
-		if !s.members.Contains(member) {
-			s.members.Add(member)
-		}
-	}
-	s.sendMembersToAgent()
-}
-
-// removeMember 移除成员。
-func (s *SelfManaged) removeMember(member *Member) {
-	if s.members.Contains(member) {
-		s.members.Remove(member)
+		s.cluster.engine.Logger().Warn("收到未处理的消息", "msg", msg, "t", reflect.TypeOf(msg))
 	}
-	s.sendMembersToAgent()
 }
 
 // sendMembersToAgent 向本地集群代理发送所有当前成员。
@@ -227,12 +233,12 @@ func (s *SelfManaged) startAutoDiscovery() {
 				s.cluster.engine.SendWithSender(memberPID, hs, self)
 			}
 		}
-		slog.Debug("[CLUSTER] 停止发现", "id", s.cluster.ID())
+		s.cluster.engine.Logger().Debug("[CLUSTER] 停止发现", "id", s.cluster.ID())
 	}(entries)
 
 	err := s.resolver.Browse(s.ctx, serviceName, domain, entries)
 	if err != nil {
-		slog.Error("[CLUSTER] 发现失败", "err", err)
+		s.cluster.engine.Logger().Error("[CLUSTER] 发现失败", "err", err)
 		panic(err)
 	}
 }
@@ -0,0 +1,204 @@
+package cluster
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// shardRingReplicas 是每个成员在哈希环上的虚拟节点数。
+const shardRingReplicas = 100
+
+// getShard 是获取 key 所在分片 actor 的请求消息。
+type getShard struct {
+	kind string
+	key  string
+}
+
+// ShardPID 返回 key 所在分片的 actor PID。kind 必须通过 Config.WithShards 配置为分片的 kind，
+// 没有成员可以托管该分片时返回 nil。
+//
+//	pid := c.ShardPID("player", "player-42")
+//	c.Engine().Send(pid, &Move{...})
+//
+// 成员变化后分片可能被重新分配到其他成员，不要长期保存返回的 PID。
+func (c *Cluster) ShardPID(kind, key string) *actor.PID {
+	pid, err := actor.RequestTyped[getShard, *actor.PID](c.engine, c.agentPID, getShard{kind: kind, key: key}, c.config.requestTimeout)
+	if err != nil {
+		return nil
+	}
+	return pid
+}
+
+// shardID 返回第 shard 个分片 actor 的 ID。
+func shardID(shard int) string {
+	return "shard-" + strconv.Itoa(shard)
+}
+
+// shardOf 返回 key 所在的分片。
+func shardOf(key string, shards int) int {
+	return int(ringHash(key) % uint64(shards))
+}
+
+// ringHash 是 FNV-1a 加上 splitmix64 的混合，使相似的字符串在环上均匀分布。
+func ringHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+type ringPoint struct {
+	hash   uint64
+	member *Member
+}
+
+// hashRing 是一致性哈希环：成员加入或离开时只有它前后的分片换了成员。
+type hashRing struct {
+	points []ringPoint
+}
+
+// newHashRing 用给定的成员创建哈希环，结果与成员的顺序无关。
+func newHashRing(members []*Member) *hashRing {
+	r := &hashRing{points: make([]ringPoint, 0, len(members)*shardRingReplicas)}
+	for _, member := range members {
+		for i := 0; i < shardRingReplicas; i++ {
+			r.points = append(r.points, ringPoint{
+				hash:   ringHash(member.ID + "#" + strconv.Itoa(i)),
+				member: member,
+			})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash == r.points[j].hash {
+			return r.points[i].member.ID < r.points[j].member.ID
+		}
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// get 返回托管分片的成员，环为空时返回 nil。
+func (r *hashRing) get(shard int) *Member {
+	if len(r.points) == 0 {
+		return nil
+	}
+	h := ringHash("shard#" + strconv.Itoa(shard))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].member
+}
+
+// shardTable 记录一个分片 kind 的分配和本节点托管的分片 actor。
+type shardTable struct {
+	kind   string
+	owners []*Member
+	local  map[int]*actor.PID
+}
+
+func newShardTable(kind string, shards int) *shardTable {
+	return &shardTable{
+		kind:   kind,
+		owners: make([]*Member, shards),
+		local:  make(map[int]*actor.PID),
+	}
+}
+
+// pid 返回 key 所在分片的 actor PID，分片没有成员时返回 nil。
+func (t *shardTable) pid(key string) *actor.PID {
+	shard := shardOf(key, len(t.owners))
+	owner := t.owners[shard]
+	if owner == nil {
+		return nil
+	}
+	return actor.NewPID(owner.Host, t.kind+"/"+shardID(shard))
+}
+
+// rebalance 按成员重新分配分片并返回换了成员的分片。
+func (t *shardTable) rebalance(members []*Member) []ShardMove {
+	ring := newHashRing(members)
+	var moves []ShardMove
+	for shard, from := range t.owners {
+		to := ring.get(shard)
+		if sameMember(from, to) {
+			continue
+		}
+		t.owners[shard] = to
+		moves = append(moves, ShardMove{Shard: shard, From: from, To: to})
+	}
+	return moves
+}
+
+func sameMember(a, b *Member) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equals(b)
+}
+
+// handleGetShard 处理获取分片 actor 的请求。
+func (a *Agent) handleGetShard(c *actor.Context, msg getShard) {
+	var pid *actor.PID
+	if table, ok := a.shards[msg.kind]; ok {
+		pid = table.pid(msg.key)
+	}
+	c.Respond(pid)
+}
+
+// rebalanceShards 在成员变化后重新分配所有分片 kind：停止移到其他成员的本地分片，
+// 激活分配给本节点的分片，并为每个有变化的 kind 发布 ShardRebalancedEvent。
+// 本节点发布事件时，移走的分片 actor 已经停止。但每个成员独立地看到成员变化，
+// 新成员可能在旧成员上的分片停止之前激活分片，分片 actor 需要容忍短暂的重叠，
+// 例如通过持久化的状态恢复，而不是假设同一时间只有一个实例。
+func (a *Agent) rebalanceShards() {
+	for kindName, table := range a.shards {
+		moves := table.rebalance(a.members.FilterByKind(kindName))
+		if len(moves) == 0 {
+			continue
+		}
+		kind, hosted := a.localKinds[kindName]
+		var stopping []context.Context
+		for _, move := range moves {
+			if pid, ok := table.local[move.Shard]; ok && !a.isLocal(move.To) {
+				stopping = append(stopping, a.cluster.engine.Poison(pid))
+				delete(table.local, move.Shard)
+			}
+			if _, ok := table.local[move.Shard]; !ok && hosted && a.isLocal(move.To) {
+				table.local[move.Shard] = a.cluster.engine.Spawn(kind.producer, kindName, actor.WithID(shardID(move.Shard)))
+			}
+		}
+		for _, ctx := range stopping {
+			<-ctx.Done()
+		}
+		a.cluster.engine.BroadcastEvent(ShardRebalancedEvent{
+			Kind:  kindName,
+			Moves: moves,
+		})
+		a.cluster.engine.Logger().Debug("[CLUSTER] 分片重新分配", "kind", kindName, "moved", len(moves), "local", len(table.local))
+	}
+}
+
+// stopShards 停止本节点托管的所有分片 actor。
+func (a *Agent) stopShards() {
+	for _, table := range a.shards {
+		for shard, pid := range table.local {
+			<-a.cluster.engine.Poison(pid).Done()
+			delete(table.local, shard)
+		}
+	}
+}
+
+// isLocal 返回成员是否是本节点。
+func (a *Agent) isLocal(member *Member) bool {
+	return member != nil && member.ID == a.cluster.ID()
+}
@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ringMembers(ids ...string) []*Member {
+	members := make([]*Member, len(ids))
+	for i, id := range ids {
+		members[i] = &Member{ID: id, Host: "host-" + id}
+	}
+	return members
+}
+
+func TestShardTableRebalance(t *testing.T) {
+	table := newShardTable("player", 64)
+	moves := table.rebalance(ringMembers("A", "B", "C"))
+	require.Len(t, moves, 64)
+	counts := make(map[string]int)
+	for _, owner := range table.owners {
+		counts[owner.ID]++
+	}
+	for _, id := range []string{"A", "B", "C"} {
+		assert.Greater(t, counts[id], 5, id)
+	}
+
+	// 新成员只从其他成员那里接管分片。
+	before := append([]*Member(nil), table.owners...)
+	moves = table.rebalance(ringMembers("A", "B", "C", "D"))
+	require.NotEmpty(t, moves)
+	for _, move := range moves {
+		assert.Equal(t, "D", move.To.ID)
+		assert.Equal(t, before[move.Shard], move.From)
+	}
+
+	// 成员离开时只有它的分片换了成员，结果与成员的顺序无关。
+	moves = table.rebalance(ringMembers("D", "C", "A"))
+	for _, move := range moves {
+		assert.Equal(t, "B", move.From.ID)
+	}
+	assert.Empty(t, table.rebalance(ringMembers("A", "C", "D")))
+
+	moves = table.rebalance(nil)
+	assert.Len(t, moves, 64)
+	assert.Nil(t, table.pid("key"))
+}
+
+func TestShardOf(t *testing.T) {
+	assert.Equal(t, shardOf("player-42", 16), shardOf("player-42", 16))
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		shard := shardOf(fmt.Sprintf("player-%d", i), 8)
+		assert.True(t, shard >= 0 && shard < 8)
+		seen[shard] = true
+	}
+	assert.Len(t, seen, 8)
+}
+
+func TestWithShardsAtLeastOne(t *testing.T) {
+	for _, n := range []int{0, -3} {
+		config := NewConfig().WithShards("player", n)
+		assert.Equal(t, 1, config.shards["player"])
+		table := newShardTable("player", config.shards["player"])
+		table.rebalance([]*Member{{ID: "A", Host: "127.0.0.1:1", Kinds: []string{"player"}}})
+		assert.Equal(t, "player/shard-0", table.pid("key").ID)
+	}
+}
+
+func makeShardCluster(t *testing.T, id string) *Cluster {
+	c, err := New(NewConfig().
+		WithID(id).
+		WithListenAddr(getRandomLocalhostAddr()).
+		WithShards("player", 8))
+	require.NoError(t, err)
+	c.RegisterKind("player", NewPlayer, NewKindConfig())
+	return c
+}
+
+func TestShardRebalance(t *testing.T) {
+	c1 := makeShardCluster(t, "S1")
+	c2 := makeShardCluster(t, "S2")
+
+	var (
+		mu    sync.Mutex
+		moved = make(map[int]string)
+		// running 记录事件发布时仍在 c1 上运行的、已经移到其他成员的分片。
+		running []int
+	)
+	unsubscribe := c1.Engine().SubscribeFunc(func(event any) {
+		if msg, ok := event.(ShardRebalancedEvent); ok && msg.Kind == "player" {
+			mu.Lock()
+			for _, move := range msg.Moves {
+				if move.To != nil {
+					moved[move.Shard] = move.To.ID
+				}
+				if move.From != nil && move.From.ID == "S1" && len(c1.Engine().Lookup("player/"+shardID(move.Shard))) > 0 {
+					running = append(running, move.Shard)
+				}
+			}
+			mu.Unlock()
+		}
+	})
+	defer unsubscribe()
+
+	c1.Start()
+	defer c1.Stop()
+	c2.Start()
+
+	// 两个成员托管所有分片，每个分片只在一个成员上。
+	assert.Eventually(t, func() bool {
+		n1, n2 := len(c1.Engine().Lookup("player/shard-*")), len(c2.Engine().Lookup("player/shard-*"))
+		return n1 > 0 && n2 > 0 && n1+n2 == 8
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pid := c1.ShardPID("player", "player-42")
+	require.NotNil(t, pid)
+	assert.Equal(t, pid, c2.ShardPID("player", "player-42"))
+	owner := c1
+	if pid.Address == c2.Address() {
+		owner = c2
+	}
+	assert.Len(t, owner.Engine().Lookup(pid.ID), 1)
+	assert.Nil(t, c1.ShardPID("inventory", "player-42"))
+
+	// 成员离开后它的分片在剩余成员上重新激活。
+	c2.Stop()
+	assert.Eventually(t, func() bool {
+		return len(c1.Engine().Lookup("player/shard-*")) == 8
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, c1.Address(), c1.ShardPID("player", "player-42").Address)
+	mu.Lock()
+	for shard := 0; shard < 8; shard++ {
+		assert.Equal(t, "S1", moved[shard])
+	}
+	assert.Empty(t, running)
+	mu.Unlock()
+}
@@ -0,0 +1,375 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	defaultProbeInterval    = time.Second
+	defaultSuspicionTimeout = 4 * time.Second
+	// swimIndirectProbes 是直接探测超时后请求代为探测的成员数。
+	swimIndirectProbes = 3
+	// swimMaxPiggyback 是每条消息最多附带的成员状态变化数。
+	swimMaxPiggyback = 8
+	// swimRetransmitMult 决定每个状态变化被传播的次数：swimRetransmitMult * ceil(log10(n+1))。
+	swimRetransmitMult = 3
+	// swimTombstoneTTL 是死亡成员的记录保留的时间，期间过时的成员列表不会使它复活。
+	swimTombstoneTTL = 30 * time.Second
+)
+
+type (
+	// swimTick 开始一个新的探测周期。
+	swimTick struct{}
+	// swimProbeTimeout 在直接探测没有及时确认时触发间接探测。
+	swimProbeTimeout struct {
+		seq uint64
+	}
+)
+
+// swimMember 是一个成员的 SWIM 状态。
+type swimMember struct {
+	member      *Member
+	incarnation uint64
+	status      MemberStatus
+	// since 是进入当前状态的时间。
+	since time.Time
+}
+
+// swimRelay 是代替 origin 发出的间接探测。
+type swimRelay struct {
+	origin *actor.PID
+	seq    uint64
+	at     time.Time
+}
+
+// swimGossip 是等待传播的成员状态变化。
+type swimGossip struct {
+	update    *MemberUpdate
+	transmits int
+}
+
+// swim 实现 SWIM 成员协议：每个周期直接探测一个成员，超时后请其他成员间接探测，
+// 仍然没有确认的成员进入怀疑状态，怀疑超时后才被宣布死亡；成员状态的变化附带在探测消息上传播，
+// 被怀疑的成员收到关于自己的怀疑时增加 incarnation 进行反驳。
+// swim 不是 actor，它的方法由提供者在自己的 Receive 中调用。
+type swim struct {
+	self        *Member
+	incarnation uint64
+	// members 是存活和被怀疑的成员，与提供者共享。
+	members *MemberSet
+	states  map[string]*swimMember
+	gossip  map[string]*swimGossip
+
+	probeInterval    time.Duration
+	suspicionTimeout time.Duration
+
+	order    []string
+	next     int
+	seq      uint64
+	probeSeq uint64
+	probeID  string
+	acked    bool
+	relays   map[uint64]swimRelay
+
+	send     func(pid *actor.PID, msg any)
+	schedule func(d time.Duration, msg any)
+	changed  func()
+	now      func() time.Time
+}
+
+func newSwim(self *Member, members *MemberSet, config SelfManagedConfig) *swim {
+	return &swim{
+		self:             self,
+		members:          members,
+		states:           make(map[string]*swimMember),
+		gossip:           make(map[string]*swimGossip),
+		relays:           make(map[uint64]swimRelay),
+		probeInterval:    config.probeInterval,
+		suspicionTimeout: config.suspicionTimeout,
+		send:             func(*actor.PID, any) {},
+		schedule:         func(time.Duration, any) {},
+		changed:          func() {},
+		now:              time.Now,
+	}
+}
+
+// join 添加通过握手或成员列表得知的成员。direct 表示消息来自该成员自己，这时死亡的成员
+// （例如重新启动的节点）以更高的 incarnation 重新加入。
+func (s *swim) join(member *Member, direct bool) {
+	if member.ID == s.self.ID {
+		return
+	}
+	m := s.states[member.ID]
+	switch {
+	case m == nil:
+		m = &swimMember{member: member, status: MemberStatus_Alive, since: s.now()}
+		s.states[member.ID] = m
+	case m.status == MemberStatus_Dead && direct:
+		m.member = member
+		m.incarnation++
+		m.status = MemberStatus_Alive
+		m.since = s.now()
+	default:
+		return
+	}
+	s.members.Add(member)
+	s.queue(m)
+	s.changed()
+}
+
+// suspect 怀疑 host 上的成员，例如远程节点不可达时。
+func (s *swim) suspect(host string) {
+	for _, m := range s.states {
+		if m.member.Host == host {
+			s.markSuspect(m)
+		}
+	}
+}
+
+// tick 结束上一个探测周期并开始新的周期。
+func (s *swim) tick() {
+	now := s.now()
+	if s.probeID != "" && !s.acked {
+		if m, ok := s.states[s.probeID]; ok {
+			s.markSuspect(m)
+		}
+	}
+	s.probeID = ""
+	for id, m := range s.states {
+		switch {
+		case m.status == MemberStatus_Suspect && now.Sub(m.since) >= s.suspicionTimeout:
+			s.markDead(m)
+		case m.status == MemberStatus_Dead && now.Sub(m.since) >= swimTombstoneTTL:
+			delete(s.states, id)
+		}
+	}
+	for seq, relay := range s.relays {
+		if now.Sub(relay.at) >= s.probeInterval {
+			delete(s.relays, seq)
+		}
+	}
+
+	target := s.nextTarget()
+	if target == nil {
+		return
+	}
+	s.seq++
+	s.probeSeq, s.probeID, s.acked = s.seq, target.member.ID, false
+	s.send(memberToProviderPID(target.member), &SwimPing{Seq: s.seq, Updates: s.piggyback()})
+	s.schedule(s.probeInterval*2/5, swimProbeTimeout{seq: s.seq})
+}
+
+// probeTimeout 在直接探测没有确认时请其他成员代为探测。
+func (s *swim) probeTimeout(seq uint64) {
+	if seq != s.probeSeq || s.acked {
+		return
+	}
+	target, ok := s.states[s.probeID]
+	if !ok {
+		return
+	}
+	var helpers []*swimMember
+	for _, m := range s.states {
+		if m.status == MemberStatus_Alive && m.member.ID != s.probeID {
+			helpers = append(helpers, m)
+		}
+	}
+	rand.Shuffle(len(helpers), func(i, j int) { helpers[i], helpers[j] = helpers[j], helpers[i] })
+	if len(helpers) > swimIndirectProbes {
+		helpers = helpers[:swimIndirectProbes]
+	}
+	for _, m := range helpers {
+		s.send(memberToProviderPID(m.member), &SwimPingReq{Seq: seq, Target: target.member, Updates: s.piggyback()})
+	}
+}
+
+// handlePing 确认 from 的探测。
+func (s *swim) handlePing(from *actor.PID, msg *SwimPing) {
+	s.apply(msg.Updates)
+	if from != nil && msg.Seq != 0 {
+		s.send(from, &SwimAck{Seq: msg.Seq, Updates: s.piggyback()})
+	}
+}
+
+// handleAck 处理探测的确认，间接探测的确认转发给请求者。
+func (s *swim) handleAck(msg *SwimAck) {
+	s.apply(msg.Updates)
+	if msg.Seq == s.probeSeq {
+		s.acked = true
+		return
+	}
+	if relay, ok := s.relays[msg.Seq]; ok {
+		delete(s.relays, msg.Seq)
+		s.send(relay.origin, &SwimAck{Seq: relay.seq, Updates: s.piggyback()})
+	}
+}
+
+// handlePingReq 代替 from 探测目标成员。
+func (s *swim) handlePingReq(from *actor.PID, msg *SwimPingReq) {
+	s.apply(msg.Updates)
+	if from == nil || msg.Target == nil {
+		return
+	}
+	s.seq++
+	s.relays[s.seq] = swimRelay{origin: from, seq: msg.Seq, at: s.now()}
+	s.send(memberToProviderPID(msg.Target), &SwimPing{Seq: s.seq, Updates: s.piggyback()})
+}
+
+// leave 通知所有成员本节点离开集群。
+func (s *swim) leave() {
+	update := &MemberUpdate{Member: s.self, Incarnation: s.incarnation, Status: MemberStatus_Dead}
+	for _, m := range s.states {
+		if m.status != MemberStatus_Dead {
+			s.send(memberToProviderPID(m.member), &SwimPing{Updates: []*MemberUpdate{update}})
+		}
+	}
+}
+
+// apply 应用收到的成员状态变化。
+func (s *swim) apply(updates []*MemberUpdate) {
+	changed := false
+	for _, u := range updates {
+		if u.Member != nil && s.applyOne(u) {
+			changed = true
+		}
+	}
+	if changed {
+		s.changed()
+	}
+}
+
+// applyOne 应用一个成员状态变化，返回成员集合是否改变。
+func (s *swim) applyOne(u *MemberUpdate) bool {
+	if u.Member.ID == s.self.ID {
+		switch {
+		case u.Status != MemberStatus_Alive && u.Incarnation >= s.incarnation:
+			// 反驳关于本节点的怀疑或死亡。
+			s.incarnation = u.Incarnation + 1
+			s.gossip[s.self.ID] = &swimGossip{update: &MemberUpdate{
+				Member: s.self, Incarnation: s.incarnation, Status: MemberStatus_Alive,
+			}}
+		case u.Status == MemberStatus_Alive && u.Incarnation > s.incarnation:
+			s.incarnation = u.Incarnation
+		}
+		return false
+	}
+	m := s.states[u.Member.ID]
+	switch u.Status {
+	case MemberStatus_Alive:
+		if m != nil && u.Incarnation <= m.incarnation {
+			return false
+		}
+		joined := m == nil || m.status == MemberStatus_Dead
+		m = &swimMember{member: u.Member, incarnation: u.Incarnation, status: MemberStatus_Alive, since: s.now()}
+		s.states[u.Member.ID] = m
+		s.members.Add(u.Member)
+		s.queue(m)
+		return joined
+	case MemberStatus_Suspect:
+		if m == nil || m.status == MemberStatus_Dead {
+			return false
+		}
+		if (m.status == MemberStatus_Alive && u.Incarnation >= m.incarnation) || u.Incarnation > m.incarnation {
+			m.incarnation = u.Incarnation
+			m.status = MemberStatus_Suspect
+			m.since = s.now()
+			s.queue(m)
+		}
+		return false
+	case MemberStatus_Dead:
+		if m != nil && (m.status == MemberStatus_Dead || u.Incarnation < m.incarnation) {
+			return false
+		}
+		if m == nil {
+			// 记录死亡的成员，过时的成员列表不会使它复活。
+			s.states[u.Member.ID] = &swimMember{member: u.Member, incarnation: u.Incarnation, status: MemberStatus_Dead, since: s.now()}
+			return false
+		}
+		m.incarnation = u.Incarnation
+		s.markDead(m)
+		return false
+	}
+	return false
+}
+
+func (s *swim) markSuspect(m *swimMember) {
+	if m.status != MemberStatus_Alive {
+		return
+	}
+	m.status = MemberStatus_Suspect
+	m.since = s.now()
+	s.queue(m)
+}
+
+func (s *swim) markDead(m *swimMember) {
+	m.status = MemberStatus_Dead
+	m.since = s.now()
+	s.members.Remove(m.member)
+	s.queue(m)
+	s.changed()
+}
+
+// queue 把成员的当前状态加入传播队列，替换该成员之前的状态变化。
+func (s *swim) queue(m *swimMember) {
+	s.gossip[m.member.ID] = &swimGossip{update: &MemberUpdate{
+		Member:      m.member,
+		Incarnation: m.incarnation,
+		Status:      m.status,
+	}}
+}
+
+// piggyback 返回附带在下一条消息上的状态变化，优先选择传播次数最少的。
+func (s *swim) piggyback() []*MemberUpdate {
+	if len(s.gossip) == 0 {
+		return nil
+	}
+	pending := make([]*swimGossip, 0, len(s.gossip))
+	for _, g := range s.gossip {
+		pending = append(pending, g)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].transmits != pending[j].transmits {
+			return pending[i].transmits < pending[j].transmits
+		}
+		return pending[i].update.Member.ID < pending[j].update.Member.ID
+	})
+	if len(pending) > swimMaxPiggyback {
+		pending = pending[:swimMaxPiggyback]
+	}
+	limit := swimRetransmitMult * int(math.Ceil(math.Log10(float64(s.members.Len()+1))))
+	updates := make([]*MemberUpdate, len(pending))
+	for i, g := range pending {
+		updates[i] = g.update
+		g.transmits++
+		if g.transmits >= limit {
+			delete(s.gossip, g.update.Member.ID)
+		}
+	}
+	return updates
+}
+
+// nextTarget 按随机的轮转顺序返回下一个要探测的成员，没有其他成员时返回 nil。
+func (s *swim) nextTarget() *swimMember {
+	for attempt := 0; attempt < 2; attempt++ {
+		for ; s.next < len(s.order); s.next++ {
+			if m, ok := s.states[s.order[s.next]]; ok && m.status != MemberStatus_Dead {
+				s.next++
+				return m
+			}
+		}
+		s.order = s.order[:0]
+		for id, m := range s.states {
+			if m.status != MemberStatus_Dead {
+				s.order = append(s.order, id)
+			}
+		}
+		rand.Shuffle(len(s.order), func(i, j int) { s.order[i], s.order[j] = s.order[j], s.order[i] })
+		s.next = 0
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentMsg 是 swim 发出的一条消息。
+type sentMsg struct {
+	to  *actor.PID
+	msg any
+}
+
+type swimHarness struct {
+	*swim
+	clock   time.Time
+	sent    []sentMsg
+	changes int
+}
+
+func newSwimHarness(peers ...string) *swimHarness {
+	self := &Member{ID: "A", Host: "127.0.0.1:1000"}
+	config := NewSelfManagedConfig().
+		WithProbeInterval(time.Second).
+		WithSuspicionTimeout(3 * time.Second)
+	h := &swimHarness{clock: time.Unix(0, 0)}
+	h.swim = newSwim(self, NewMemberSet(self), config)
+	h.swim.send = func(pid *actor.PID, msg any) { h.sent = append(h.sent, sentMsg{pid, msg}) }
+	h.swim.changed = func() { h.changes++ }
+	h.swim.now = func() time.Time { return h.clock }
+	for _, id := range peers {
+		h.join(&Member{ID: id, Host: "host-" + id}, true)
+	}
+	h.sent = nil
+	h.changes = 0
+	return h
+}
+
+// take 返回并清空已发送的消息。
+func (h *swimHarness) take() []sentMsg {
+	sent := h.sent
+	h.sent = nil
+	return sent
+}
+
+func (h *swimHarness) status(id string) MemberStatus {
+	return h.states[id].status
+}
+
+func TestSwimProbeAck(t *testing.T) {
+	h := newSwimHarness("B")
+	h.tick()
+	sent := h.take()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "host-B", sent[0].to.Address)
+	ping := sent[0].msg.(*SwimPing)
+
+	h.handleAck(&SwimAck{Seq: ping.Seq})
+	h.clock = h.clock.Add(time.Second)
+	h.tick()
+	assert.Equal(t, MemberStatus_Alive, h.status("B"))
+}
+
+func TestSwimIndirectProbeAndSuspicion(t *testing.T) {
+	h := newSwimHarness("B", "C")
+	h.tick()
+	ping := h.take()[0]
+	target := ping.to.Address
+	h.probeTimeout(ping.msg.(*SwimPing).Seq)
+
+	// 直接探测超时后请另一个成员间接探测。
+	sent := h.take()
+	require.Len(t, sent, 1)
+	req := sent[0].msg.(*SwimPingReq)
+	assert.Equal(t, target, req.Target.Host)
+	assert.NotEqual(t, target, sent[0].to.Address)
+
+	// 周期结束时仍然没有确认：目标被怀疑，但仍然是成员。
+	h.clock = h.clock.Add(time.Second)
+	h.tick()
+	id := req.Target.ID
+	assert.Equal(t, MemberStatus_Suspect, h.status(id))
+	assert.Equal(t, 3, h.members.Len())
+	assert.Zero(t, h.changes)
+
+	// 怀疑超时后宣布死亡并离开。
+	h.clock = h.clock.Add(3 * time.Second)
+	h.tick()
+	assert.Equal(t, MemberStatus_Dead, h.status(id))
+	assert.Equal(t, 2, h.members.Len())
+	assert.Equal(t, 1, h.changes)
+}
+
+func TestSwimRelayForwardsAck(t *testing.T) {
+	h := newSwimHarness("B", "C")
+	origin := actor.NewPID("host-B", "provider/B")
+	h.handlePingReq(origin, &SwimPingReq{Seq: 42, Target: &Member{ID: "C", Host: "host-C"}})
+	sent := h.take()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "host-C", sent[0].to.Address)
+
+	h.handleAck(&SwimAck{Seq: sent[0].msg.(*SwimPing).Seq})
+	sent = h.take()
+	require.Len(t, sent, 1)
+	assert.Equal(t, origin, sent[0].to)
+	assert.Equal(t, uint64(42), sent[0].msg.(*SwimAck).Seq)
+}
+
+func TestSwimRefutesSuspicion(t *testing.T) {
+	h := newSwimHarness("B")
+	h.handlePing(actor.NewPID("host-B", "provider/B"), &SwimPing{
+		Seq:     1,
+		Updates: []*MemberUpdate{{Member: h.self, Incarnation: 0, Status: MemberStatus_Suspect}},
+	})
+	assert.Equal(t, uint64(1), h.incarnation)
+
+	// 确认中附带了反驳。
+	ack := h.take()[0].msg.(*SwimAck)
+	require.NotEmpty(t, ack.Updates)
+	assert.Equal(t, "A", ack.Updates[0].Member.ID)
+	assert.Equal(t, MemberStatus_Alive, ack.Updates[0].Status)
+	assert.Equal(t, uint64(1), ack.Updates[0].Incarnation)
+}
+
+func TestSwimApplyUpdates(t *testing.T) {
+	h := newSwimHarness("B")
+	b := &Member{ID: "B", Host: "host-B"}
+
+	h.apply([]*MemberUpdate{{Member: b, Incarnation: 0, Status: MemberStatus_Suspect}})
+	assert.Equal(t, MemberStatus_Suspect, h.status("B"))
+	// 相同 incarnation 的存活不能反驳怀疑，更高的可以。
+	h.apply([]*MemberUpdate{{Member: b, Incarnation: 0, Status: MemberStatus_Alive}})
+	assert.Equal(t, MemberStatus_Suspect, h.status("B"))
+	h.apply([]*MemberUpdate{{Member: b, Incarnation: 1, Status: MemberStatus_Alive}})
+	assert.Equal(t, MemberStatus_Alive, h.status("B"))
+
+	h.apply([]*MemberUpdate{{Member: b, Incarnation: 1, Status: MemberStatus_Dead}})
+	assert.Equal(t, MemberStatus_Dead, h.status("B"))
+	assert.Equal(t, 1, h.members.Len())
+
+	// 过时的成员列表不会使死亡的成员复活，成员自己的握手可以。
+	h.join(b, false)
+	assert.Equal(t, MemberStatus_Dead, h.status("B"))
+	h.join(b, true)
+	assert.Equal(t, MemberStatus_Alive, h.status("B"))
+	assert.Equal(t, uint64(2), h.states["B"].incarnation)
+}
+
+func TestSwimPiggybackLimit(t *testing.T) {
+	h := newSwimHarness()
+	for i := 0; i < 20; i++ {
+		h.join(&Member{ID: string(rune('a' + i)), Host: "h"}, true)
+	}
+	assert.Len(t, h.piggyback(), swimMaxPiggyback)
+	// 每个变化最多传播 swimRetransmitMult * ceil(log10(n+1)) 次。
+	total := swimMaxPiggyback
+	for len(h.gossip) > 0 {
+		total += len(h.piggyback())
+	}
+	assert.Equal(t, 20*swimRetransmitMult*2, total)
+}
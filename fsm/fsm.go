@@ -0,0 +1,192 @@
+// Package fsm 提供有限状态机形式的 actor。状态机由状态、每个状态的处理函数、
+// 状态超时和状态转换钩子组成，最终编译为一个 actor.Receiver，
+// 使订单生命周期、连接握手之类的协议型 actor 不再需要手写基于状态的 switch。
+//
+//	m := fsm.New("idle").
+//		When("idle", func(c *actor.Context, msg any) fsm.State {
+//			if _, ok := msg.(Connect); ok {
+//				return "connecting"
+//			}
+//			return fsm.Stay
+//		}).
+//		When("connecting", onConnecting, fsm.WithTimeout(5*time.Second)).
+//		OnTransition(func(c *actor.Context, from, to fsm.State) { ... })
+//	pid := engine.Spawn(m.Producer(), "conn")
+package fsm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// State 是状态机的状态名。
+type State string
+
+// Stay 由处理函数返回，表示保持当前状态。
+const Stay State = ""
+
+// Handler 处理当前状态下收到的消息，返回下一个状态。返回 Stay 或当前状态表示不发生转换。
+// actor 的生命周期消息（actor.Started、actor.Stopped 等）同样会交给当前状态的处理函数。
+type Handler func(c *actor.Context, msg any) State
+
+// TransitionHook 在状态从 from 转换到 to 之后调用。
+type TransitionHook func(c *actor.Context, from, to State)
+
+// StateTimeout 在 actor 停留在某个设置了超时的状态超过指定时间后，
+// 作为消息交给该状态的处理函数。离开该状态后超时自动取消。
+type StateTimeout struct {
+	State State
+}
+
+// stateTimeout 是内部的超时消息，seq 用于丢弃已经离开的状态的过期超时。
+type stateTimeout struct {
+	seq uint64
+}
+
+// StateOpt 是状态的配置选项。
+type StateOpt func(*stateDef)
+
+// WithTimeout 设置状态超时：进入该状态 d 时间后仍未离开，处理函数将收到 StateTimeout。
+func WithTimeout(d time.Duration) StateOpt {
+	return func(s *stateDef) {
+		s.timeout = d
+	}
+}
+
+type stateDef struct {
+	handler Handler
+	timeout time.Duration
+}
+
+// Machine 描述一个状态机。Machine 只是定义，通过 Receiver 或 Producer
+// 为每个 actor 创建独立的运行实例。
+type Machine struct {
+	initial   State
+	states    map[State]stateDef
+	hooks     []TransitionHook
+	unhandled Handler
+}
+
+// New 创建一个以 initial 为初始状态的状态机。
+func New(initial State) *Machine {
+	return &Machine{
+		initial: initial,
+		states:  make(map[State]stateDef),
+	}
+}
+
+// When 定义状态 s 的处理函数。
+func (m *Machine) When(s State, h Handler, opts ...StateOpt) *Machine {
+	def := stateDef{handler: h}
+	for _, opt := range opts {
+		opt(&def)
+	}
+	m.states[s] = def
+	return m
+}
+
+// OnTransition 添加一个状态转换钩子，钩子按添加顺序调用。
+func (m *Machine) OnTransition(h TransitionHook) *Machine {
+	m.hooks = append(m.hooks, h)
+	return m
+}
+
+// WhenUnhandled 设置当前状态的处理函数返回 Stay 之后调用的兜底处理函数，
+// 用于处理在任何状态下都有效的消息。生命周期消息不会交给它。
+func (m *Machine) WhenUnhandled(h Handler) *Machine {
+	m.unhandled = h
+	return m
+}
+
+// Receiver 返回运行该状态机的 actor.Receiver。如果初始状态没有定义处理函数则 panic。
+func (m *Machine) Receiver() actor.Receiver {
+	if _, ok := m.states[m.initial]; !ok {
+		panic(fmt.Sprintf("fsm: 初始状态 %q 没有定义", m.initial))
+	}
+	return &runner{machine: m, state: m.initial}
+}
+
+// Producer 返回一个 actor.Producer，每次调用都创建一个从初始状态开始的新实例。
+// 多个实例共享处理函数，需要每个 actor 独立数据时，请在自定义的 Producer 中
+// 创建 Machine 并调用 Receiver。
+func (m *Machine) Producer() actor.Producer {
+	return func() actor.Receiver {
+		return m.Receiver()
+	}
+}
+
+// runner 是状态机的运行实例。
+type runner struct {
+	machine *Machine
+	state   State
+	seq     uint64
+	timer   *actor.SendTimer
+}
+
+// Receive 把消息交给当前状态的处理函数，并在需要时执行状态转换。
+func (r *runner) Receive(c *actor.Context) {
+	msg := c.Message()
+	switch m := msg.(type) {
+	case actor.Started:
+		r.armTimeout(c)
+	case actor.Stopped:
+		r.stopTimeout()
+	case stateTimeout:
+		if m.seq != r.seq {
+			return
+		}
+		r.timer = nil
+		msg = StateTimeout{State: r.state}
+	}
+	next := r.machine.states[r.state].handler(c, msg)
+	if next == Stay && r.machine.unhandled != nil && !isLifecycle(msg) {
+		next = r.machine.unhandled(c, msg)
+	}
+	r.transition(c, next)
+}
+
+// transition 转换到 next 状态，调用钩子并重新设置状态超时。
+func (r *runner) transition(c *actor.Context, next State) {
+	if next == Stay || next == r.state {
+		return
+	}
+	if _, ok := r.machine.states[next]; !ok {
+		panic(fmt.Sprintf("fsm: 状态 %q 没有定义（从 %q 转换）", next, r.state))
+	}
+	from := r.state
+	r.state = next
+	r.stopTimeout()
+	r.armTimeout(c)
+	for _, h := range r.machine.hooks {
+		h(c, from, next)
+	}
+}
+
+// armTimeout 为当前状态设置超时。
+func (r *runner) armTimeout(c *actor.Context) {
+	r.seq++
+	d := r.machine.states[r.state].timeout
+	if d <= 0 {
+		return
+	}
+	t := c.SendAfter(c.PID(), stateTimeout{seq: r.seq}, d)
+	r.timer = &t
+}
+
+// stopTimeout 取消当前状态的超时。
+func (r *runner) stopTimeout() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+func isLifecycle(msg any) bool {
+	switch msg.(type) {
+	case actor.Initialized, actor.Started, actor.Stopped:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package fsm
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	pay     struct{}
+	ship    struct{}
+	cancel  struct{}
+	current struct{}
+)
+
+func newOrder(transitions chan<- [2]State, timeout time.Duration) *Machine {
+	return New("created").
+		When("created", func(c *actor.Context, msg any) State {
+			switch msg.(type) {
+			case pay:
+				return "paid"
+			case StateTimeout:
+				return "cancelled"
+			}
+			return Stay
+		}, WithTimeout(timeout)).
+		When("paid", func(c *actor.Context, msg any) State {
+			if _, ok := msg.(ship); ok {
+				return "shipped"
+			}
+			return Stay
+		}).
+		When("shipped", func(*actor.Context, any) State { return Stay }).
+		When("cancelled", func(*actor.Context, any) State { return Stay }).
+		WhenUnhandled(func(c *actor.Context, msg any) State {
+			if _, ok := msg.(cancel); ok {
+				return "cancelled"
+			}
+			return Stay
+		}).
+		OnTransition(func(_ *actor.Context, from, to State) {
+			transitions <- [2]State{from, to}
+		})
+}
+
+func TestTransitions(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	transitions := make(chan [2]State, 10)
+	pid := e.Spawn(newOrder(transitions, time.Hour).Producer(), "order")
+
+	e.Send(pid, ship{}) // 在 created 状态下被忽略
+	e.Send(pid, pay{})
+	e.Send(pid, ship{})
+	require.Equal(t, [2]State{"created", "paid"}, <-transitions)
+	require.Equal(t, [2]State{"paid", "shipped"}, <-transitions)
+
+	e.Send(pid, cancel{})
+	require.Equal(t, [2]State{"shipped", "cancelled"}, <-transitions)
+}
+
+func TestStateTimeout(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	transitions := make(chan [2]State, 10)
+	e.Spawn(newOrder(transitions, 20*time.Millisecond).Producer(), "order")
+	select {
+	case tr := <-transitions:
+		require.Equal(t, [2]State{"created", "cancelled"}, tr)
+	case <-time.After(time.Second):
+		t.Fatal("状态超时没有触发")
+	}
+
+	// 离开状态后超时被取消。
+	pid := e.Spawn(newOrder(transitions, 20*time.Millisecond).Producer(), "order")
+	e.Send(pid, pay{})
+	require.Equal(t, [2]State{"created", "paid"}, <-transitions)
+	select {
+	case tr := <-transitions:
+		t.Fatalf("意外的状态转换 %v", tr)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestUndefinedState(t *testing.T) {
+	require.Panics(t, func() { New("missing").Receiver() })
+
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	m := New("a").When("a", func(_ *actor.Context, msg any) State {
+		if _, ok := msg.(current); ok {
+			return "b"
+		}
+		return Stay
+	})
+	pid := e.Spawn(m.Producer(), "fsm", actor.WithMaxRestarts(0))
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(actor.ActorMaxRestartsExceededEvent); ok {
+			wg.Done()
+		}
+	}, "sub")
+	e.Subscribe(sub)
+	e.Send(pid, current{})
+	wg.Wait()
+}
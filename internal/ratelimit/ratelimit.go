@@ -0,0 +1,55 @@
+// Package ratelimit 提供 actor 和 remote 共用的令牌桶限流器。
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket 是令牌桶限流器，容量为 n，每 per 时间补充 n 个令牌。
+// 时间由调用者传入，因此可以使用引擎的 Clock。
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	// rate 是每纳秒补充的令牌数。
+	rate float64
+	last time.Time
+}
+
+// New 创建一个装满令牌、从 now 开始计时的令牌桶，now 为零值时从第一次 Reserve 开始计时。
+// n 和 per 必须是正数。
+func New(n int, per time.Duration, now time.Time) *TokenBucket {
+	return &TokenBucket{
+		capacity: float64(n),
+		tokens:   float64(n),
+		rate:     float64(n) / float64(per),
+		last:     now,
+	}
+}
+
+// Reserve 尝试在 now 取得一个令牌。有令牌时返回 0；否则如果 wait 为 true，
+// 预支一个令牌并返回需要等待的时间，如果 wait 为 false，返回大于 0 的等待时间但不消耗令牌。
+func (b *TokenBucket) Reserve(now time.Time, wait bool) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.last.IsZero() {
+		b.last = now
+	}
+	if now.After(b.last) {
+		b.tokens = min(b.capacity, b.tokens+float64(now.Sub(b.last))*b.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	d := time.Duration((1 - b.tokens) / b.rate)
+	if d <= 0 {
+		d = 1
+	}
+	if wait {
+		b.tokens--
+	}
+	return d
+}
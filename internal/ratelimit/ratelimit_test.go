@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Unix(0, 0)
+	b := New(2, time.Second, start)
+	assert.Zero(t, b.Reserve(start, false))
+	assert.Zero(t, b.Reserve(start, false))
+
+	// 没有令牌时不预支只返回等待时间。
+	assert.InDelta(t, 500*time.Millisecond, b.Reserve(start, false), float64(time.Microsecond))
+	assert.InDelta(t, 500*time.Millisecond, b.Reserve(start, true), float64(time.Microsecond))
+	assert.InDelta(t, time.Second, b.Reserve(start, true), float64(time.Microsecond))
+
+	// 补充的令牌先偿还预支的令牌。
+	assert.Greater(t, b.Reserve(start.Add(time.Second), false), time.Duration(0))
+	assert.Zero(t, b.Reserve(start.Add(2*time.Second), false))
+
+	// 时间倒退时不补充令牌。
+	assert.Zero(t, b.Reserve(start, false))
+	assert.Greater(t, b.Reserve(start, false), time.Duration(0))
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	journalBucket   = []byte("journal")
+	snapshotsBucket = []byte("snapshots")
+)
+
+// BoltStore 是基于 BoltDB 的嵌入式存储，同时实现了 actor.Journal 和 actor.SnapshotStore。
+// 每个持久化 ID 的事件保存在 journal 桶下的独立子桶中，键为大端序的事件序号。
+type BoltStore struct {
+	db    *bolt.DB
+	codec Codec
+}
+
+var (
+	_ actor.Journal       = (*BoltStore)(nil)
+	_ actor.SnapshotStore = (*BoltStore)(nil)
+)
+
+// OpenBolt 打开（或创建）给定路径的 BoltDB 文件并返回 BoltStore。
+func OpenBolt(path string, opts ...OptFunc) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("打开 bolt 数据库失败: %w", err)
+	}
+	s, err := NewBoltStore(db, opts...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewBoltStore 使用已打开的 BoltDB 创建 BoltStore。
+func NewBoltStore(db *bolt.DB, opts ...OptFunc) (*BoltStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(journalBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 bolt 桶失败: %w", err)
+	}
+	return &BoltStore{
+		db:    db,
+		codec: newOpts(opts).Codec,
+	}, nil
+}
+
+// Close 关闭底层的 BoltDB。
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// WriteEvent 实现 actor.Journal 接口。
+func (s *BoltStore) WriteEvent(persistenceID string, seq uint64, event any) error {
+	data, err := s.codec.Encode(event)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(journalBucket).CreateBucketIfNotExists([]byte(persistenceID))
+		if err != nil {
+			return err
+		}
+		var last uint64
+		if k, _ := b.Cursor().Last(); k != nil {
+			last = binary.BigEndian.Uint64(k)
+		}
+		if want := last + 1; seq != want {
+			return fmt.Errorf("事件序号冲突 (%s): 期望 %d，得到 %d", persistenceID, want, seq)
+		}
+		return b.Put(seqKey(seq), data)
+	})
+}
+
+// ReplayEvents 实现 actor.Journal 接口。
+func (s *BoltStore) ReplayEvents(persistenceID string, fromSeq uint64, fn func(seq uint64, event any)) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(journalBucket).Bucket([]byte(persistenceID))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(seqKey(fromSeq)); k != nil; k, v = c.Next() {
+			event, err := s.codec.Decode(v)
+			if err != nil {
+				return fmt.Errorf("反序列化事件失败: %w", err)
+			}
+			fn(binary.BigEndian.Uint64(k), event)
+		}
+		return nil
+	})
+}
+
+// SaveSnapshot 实现 actor.SnapshotStore 接口。
+func (s *BoltStore) SaveSnapshot(persistenceID string, snapshot actor.Snapshot) error {
+	data, err := s.codec.Encode(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %w", err)
+	}
+	// 值的前 8 个字节是快照序号。
+	value := append(seqKey(snapshot.Seq), data...)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put([]byte(persistenceID), value)
+	})
+}
+
+// LoadSnapshot 实现 actor.SnapshotStore 接口。
+func (s *BoltStore) LoadSnapshot(persistenceID string) (*actor.Snapshot, error) {
+	var snapshot *actor.Snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(snapshotsBucket).Get([]byte(persistenceID))
+		if v == nil {
+			return nil
+		}
+		if len(v) < 8 {
+			return fmt.Errorf("快照已损坏 (%s): 长度 %d 小于序号的 8 个字节", persistenceID, len(v))
+		}
+		state, err := s.codec.Decode(v[8:])
+		if err != nil {
+			return fmt.Errorf("反序列化快照失败: %w", err)
+		}
+		snapshot = &actor.Snapshot{
+			Seq:   binary.BigEndian.Uint64(v[:8]),
+			State: state,
+		}
+		return nil
+	})
+	return snapshot, err
+}
+
+func seqKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
@@ -0,0 +1,65 @@
+// Package persistence 提供 actor.Journal 和 actor.SnapshotStore 的持久化实现：
+// 基于 BoltDB 的嵌入式存储和基于 database/sql 的 SQL 存储。
+// 未配置时引擎使用内存实现；生产环境可以选择这里的实现或自行实现这两个接口。
+package persistence
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Codec 负责事件和快照状态的序列化。
+type Codec interface {
+	Encode(v any) ([]byte, error)
+	Decode(data []byte) (any, error)
+}
+
+// GobCodec 使用 encoding/gob 序列化。事件和快照状态的具体类型必须先通过 gob.Register 注册。
+type GobCodec struct{}
+
+// gobValue 包装任意值，使 gob 能够编码接口类型并在解码时还原具体类型。
+type gobValue struct {
+	V any
+}
+
+// Encode 实现 Codec 接口。
+func (GobCodec) Encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobValue{V: v}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode 实现 Codec 接口。
+func (GobCodec) Decode(data []byte) (any, error) {
+	var v gobValue
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v.V, nil
+}
+
+// Opts 是存储的配置选项。
+type Opts struct {
+	// Codec 是事件和快照的序列化方式，默认为 GobCodec。
+	Codec Codec
+}
+
+// OptFunc 是配置选项函数的类型。
+type OptFunc func(*Opts)
+
+// WithCodec 设置事件和快照的序列化方式。
+func WithCodec(c Codec) OptFunc {
+	return func(opts *Opts) {
+		opts.Codec = c
+	}
+}
+
+func newOpts(opts []OptFunc) Opts {
+	o := Opts{Codec: GobCodec{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,149 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/gob"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type deposited struct {
+	Amount int
+}
+
+func init() {
+	gob.Register(deposited{})
+}
+
+type store interface {
+	actor.Journal
+	actor.SnapshotStore
+}
+
+func newBolt(t *testing.T) store {
+	s, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func newSQLite(t *testing.T) store {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	s := NewSQLStore(db, DialectSQLite)
+	require.NoError(t, s.CreateSchema(context.Background()))
+	return s
+}
+
+func TestStores(t *testing.T) {
+	for name, newStore := range map[string]func(*testing.T) store{
+		"bolt":   newBolt,
+		"sqlite": newSQLite,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("journal", func(t *testing.T) { testJournal(t, newStore(t)) })
+			t.Run("snapshot", func(t *testing.T) { testSnapshot(t, newStore(t)) })
+			t.Run("actor", func(t *testing.T) { testActorRecovery(t, newStore(t)) })
+		})
+	}
+}
+
+func testJournal(t *testing.T, s store) {
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, s.WriteEvent("acc", uint64(i), deposited{Amount: i * 10}))
+	}
+	require.NoError(t, s.WriteEvent("other", 1, deposited{Amount: 1}))
+	assert.Error(t, s.WriteEvent("acc", 3, deposited{}))
+	assert.Error(t, s.WriteEvent("acc", 5, deposited{}))
+
+	var (
+		seqs   []uint64
+		events []any
+	)
+	require.NoError(t, s.ReplayEvents("acc", 2, func(seq uint64, event any) {
+		seqs = append(seqs, seq)
+		events = append(events, event)
+	}))
+	assert.Equal(t, []uint64{2, 3}, seqs)
+	assert.Equal(t, []any{deposited{Amount: 20}, deposited{Amount: 30}}, events)
+
+	require.NoError(t, s.ReplayEvents("missing", 1, func(uint64, any) {
+		t.Fatal("不应回放任何事件")
+	}))
+}
+
+func testSnapshot(t *testing.T, s store) {
+	snapshot, err := s.LoadSnapshot("acc")
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	require.NoError(t, s.SaveSnapshot("acc", actor.Snapshot{Seq: 4, State: 100}))
+	require.NoError(t, s.SaveSnapshot("acc", actor.Snapshot{Seq: 8, State: 250}))
+	snapshot, err = s.LoadSnapshot("acc")
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+	assert.Equal(t, actor.Snapshot{Seq: 8, State: 250}, *snapshot)
+}
+
+func TestBoltCorruptSnapshot(t *testing.T) {
+	s, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put([]byte("acc"), []byte{1, 2, 3})
+	}))
+
+	snapshot, err := s.LoadSnapshot("acc")
+	assert.Error(t, err)
+	assert.Nil(t, snapshot)
+}
+
+type account struct {
+	balance int
+}
+
+func (a *account) PersistenceID() string { return "account-1" }
+
+func (a *account) ApplyEvent(event any) {
+	if e, ok := event.(deposited); ok {
+		a.balance += e.Amount
+	}
+}
+
+func (a *account) Receive(c *actor.Context) {
+	switch msg := c.Message().(type) {
+	case int:
+		if err := c.Persist(deposited{Amount: msg}); err != nil {
+			panic(err)
+		}
+	case string:
+		c.Respond(a.balance)
+	}
+}
+
+func testActorRecovery(t *testing.T, s store) {
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithJournal(s).WithSnapshotStore(s))
+	require.NoError(t, err)
+	producer := func() actor.Receiver { return &account{} }
+	pid := e.Spawn(producer, "account", actor.WithID("1"))
+	e.Send(pid, 5)
+	e.Send(pid, 7)
+	balance, err := e.Request(pid, "balance", time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, 12, balance)
+	<-e.Poison(pid).Done()
+
+	pid = e.Spawn(producer, "account", actor.WithID("1"))
+	balance, err = e.Request(pid, "balance", time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, 12, balance)
+}
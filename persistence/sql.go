@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// Dialect 描述 SQL 数据库之间的语法差异。
+type Dialect struct {
+	name string
+	// placeholder 返回第 n 个（从 1 开始）参数的占位符。
+	placeholder func(n int) string
+	blobType    string
+	idType      string
+	upsert      string
+}
+
+var (
+	// DialectPostgres 适用于 PostgreSQL。
+	DialectPostgres = Dialect{
+		name:        "postgres",
+		placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		blobType:    "BYTEA",
+		idType:      "TEXT",
+		upsert:      "ON CONFLICT (persistence_id) DO UPDATE SET seq = EXCLUDED.seq, payload = EXCLUDED.payload",
+	}
+	// DialectSQLite 适用于 SQLite 3.24 及以上版本。
+	DialectSQLite = Dialect{
+		name:        "sqlite",
+		placeholder: func(int) string { return "?" },
+		blobType:    "BLOB",
+		idType:      "TEXT",
+		upsert:      "ON CONFLICT (persistence_id) DO UPDATE SET seq = excluded.seq, payload = excluded.payload",
+	}
+	// DialectMySQL 适用于 MySQL 和 MariaDB。
+	DialectMySQL = Dialect{
+		name:        "mysql",
+		placeholder: func(int) string { return "?" },
+		blobType:    "LONGBLOB",
+		idType:      "VARCHAR(255)",
+		upsert:      "ON DUPLICATE KEY UPDATE seq = VALUES(seq), payload = VALUES(payload)",
+	}
+)
+
+// String 返回方言的名称。
+func (d Dialect) String() string {
+	return d.name
+}
+
+// SQLStore 是基于 database/sql 的存储，同时实现了 actor.Journal 和 actor.SnapshotStore。
+// 它不依赖具体的驱动，调用者负责导入驱动并打开 *sql.DB。
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+	codec   Codec
+
+	insertEvent  string
+	lastSeq      string
+	selectEvents string
+	saveSnapshot string
+	loadSnapshot string
+}
+
+var (
+	_ actor.Journal       = (*SQLStore)(nil)
+	_ actor.SnapshotStore = (*SQLStore)(nil)
+)
+
+// NewSQLStore 使用给定的数据库和方言创建 SQLStore。使用前需要调用 CreateSchema 建表，
+// 或者自行创建结构相同的 journal 和 snapshots 表。
+func NewSQLStore(db *sql.DB, dialect Dialect, opts ...OptFunc) *SQLStore {
+	p := dialect.placeholder
+	return &SQLStore{
+		db:      db,
+		dialect: dialect,
+		codec:   newOpts(opts).Codec,
+		insertEvent: fmt.Sprintf("INSERT INTO journal (persistence_id, seq, payload) VALUES (%s, %s, %s)",
+			p(1), p(2), p(3)),
+		lastSeq: fmt.Sprintf("SELECT COALESCE(MAX(seq), 0) FROM journal WHERE persistence_id = %s", p(1)),
+		selectEvents: fmt.Sprintf("SELECT seq, payload FROM journal WHERE persistence_id = %s AND seq >= %s ORDER BY seq",
+			p(1), p(2)),
+		saveSnapshot: fmt.Sprintf("INSERT INTO snapshots (persistence_id, seq, payload) VALUES (%s, %s, %s) %s",
+			p(1), p(2), p(3), dialect.upsert),
+		loadSnapshot: fmt.Sprintf("SELECT seq, payload FROM snapshots WHERE persistence_id = %s", p(1)),
+	}
+}
+
+// CreateSchema 在表不存在时创建 journal 和 snapshots 表。
+func (s *SQLStore) CreateSchema(ctx context.Context) error {
+	stmts := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS journal (
+	persistence_id %s NOT NULL,
+	seq BIGINT NOT NULL,
+	payload %s NOT NULL,
+	PRIMARY KEY (persistence_id, seq)
+)`, s.dialect.idType, s.dialect.blobType),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS snapshots (
+	persistence_id %s NOT NULL PRIMARY KEY,
+	seq BIGINT NOT NULL,
+	payload %s NOT NULL
+)`, s.dialect.idType, s.dialect.blobType),
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("创建表失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteEvent 实现 actor.Journal 接口。序号的唯一性由主键保证，
+// 并发写入同一序号时只有一个会成功。
+func (s *SQLStore) WriteEvent(persistenceID string, seq uint64, event any) error {
+	data, err := s.codec.Encode(event)
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var last uint64
+	if err := tx.QueryRow(s.lastSeq, persistenceID).Scan(&last); err != nil {
+		return err
+	}
+	if want := last + 1; seq != want {
+		return fmt.Errorf("事件序号冲突 (%s): 期望 %d，得到 %d", persistenceID, want, seq)
+	}
+	if _, err := tx.Exec(s.insertEvent, persistenceID, int64(seq), data); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplayEvents 实现 actor.Journal 接口。
+func (s *SQLStore) ReplayEvents(persistenceID string, fromSeq uint64, fn func(seq uint64, event any)) error {
+	rows, err := s.db.Query(s.selectEvents, persistenceID, int64(fromSeq))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			seq  int64
+			data []byte
+		)
+		if err := rows.Scan(&seq, &data); err != nil {
+			return err
+		}
+		event, err := s.codec.Decode(data)
+		if err != nil {
+			return fmt.Errorf("反序列化事件失败: %w", err)
+		}
+		fn(uint64(seq), event)
+	}
+	return rows.Err()
+}
+
+// SaveSnapshot 实现 actor.SnapshotStore 接口。
+func (s *SQLStore) SaveSnapshot(persistenceID string, snapshot actor.Snapshot) error {
+	data, err := s.codec.Encode(snapshot.State)
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %w", err)
+	}
+	_, err = s.db.Exec(s.saveSnapshot, persistenceID, int64(snapshot.Seq), data)
+	return err
+}
+
+// LoadSnapshot 实现 actor.SnapshotStore 接口。
+func (s *SQLStore) LoadSnapshot(persistenceID string) (*actor.Snapshot, error) {
+	var (
+		seq  int64
+		data []byte
+	)
+	err := s.db.QueryRow(s.loadSnapshot, persistenceID).Scan(&seq, &data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.codec.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("反序列化快照失败: %w", err)
+	}
+	return &actor.Snapshot{Seq: uint64(seq), State: state}, nil
+}
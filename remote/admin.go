@@ -0,0 +1,206 @@
+package remote
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// adminRequestTimeout 是 remote/admin 等待流路由器回复的最长时间。
+const adminRequestTimeout = 5 * time.Second
+
+// WithAdmin 启用时在引擎上创建 "remote/admin" actor，运维人员可以通过普通的 actor 消息
+// 查看和管理传输层：GetPeers 返回 *Peers，ForceDisconnect 返回 *ForceDisconnected。
+// 它的 PID 由 Remote.AdminPID 返回。
+func (c Config) WithAdmin(enabled bool) Config {
+	c.Admin = enabled
+	return c
+}
+
+// PeerState 是到远程节点的出站连接的状态。
+type PeerState int
+
+const (
+	// PeerConnecting 表示流写入器正在建立连接。
+	PeerConnecting PeerState = iota
+	// PeerConnected 表示连接已经建立。
+	PeerConnected
+	// PeerUnreachable 表示连接失败或断开，发件箱中的消息（如果有）等待重新连接。
+	PeerUnreachable
+	// PeerCircuitOpen 表示熔断器打开，发往该节点的消息直接进入死信。
+	PeerCircuitOpen
+)
+
+func (s PeerState) String() string {
+	switch s {
+	case PeerConnecting:
+		return "connecting"
+	case PeerConnected:
+		return "connected"
+	case PeerUnreachable:
+		return "unreachable"
+	case PeerCircuitOpen:
+		return "circuit-open"
+	}
+	return "unknown"
+}
+
+// PeerInfo 描述到一个远程节点的出站连接。
+type PeerInfo struct {
+	Addr  string
+	State PeerState
+	// Queued 是流写入器收件箱中等待写出的消息数。
+	Queued int
+	// Outboxed 是发件箱中等待连接建立的消息数。
+	Outboxed int
+}
+
+// GetPeers 要求 remote/admin 回复 *Peers。
+type GetPeers struct{}
+
+// Peers 是 GetPeers 的回复。
+type Peers struct {
+	// Outbound 是本节点发起的连接，按地址排序。
+	Outbound []PeerInfo
+	// Inbound 是对方发起的连接的对端网络地址（"ip:port"），按地址排序。
+	Inbound []string
+}
+
+// ForceDisconnect 要求 remote/admin 关闭到 Addr 的出站连接以及对端地址为 Addr 的入站连接，
+// 回复 *ForceDisconnected。出站连接在写出已经排队的消息后关闭，之后发往 Addr 的消息建立新的连接。
+type ForceDisconnect struct {
+	Addr string
+}
+
+// ForceDisconnected 是 ForceDisconnect 的回复。
+type ForceDisconnected struct {
+	Addr string
+	// Outbound 表示是否关闭了出站连接。
+	Outbound bool
+	// Inbound 是关闭的入站连接数。
+	Inbound int
+}
+
+// peerQuery 要求流路由器回复 []PeerInfo。
+type peerQuery struct{}
+
+// peerDisconnect 要求流路由器关闭 address 的流写入器，回复是否存在这样的流写入器。
+type peerDisconnect struct {
+	address string
+}
+
+// AdminPID 返回 remote/admin 的 PID，未启用 WithAdmin 时返回 nil。
+func (r *Remote) AdminPID() *actor.PID {
+	return r.adminPID
+}
+
+// admin 是 remote/admin actor。
+type admin struct {
+	remote *Remote
+}
+
+func newAdmin(r *Remote) actor.Producer {
+	return func() actor.Receiver {
+		return &admin{remote: r}
+	}
+}
+
+func (a *admin) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case GetPeers:
+		a.getPeers(ctx)
+	case ForceDisconnect:
+		a.forceDisconnect(ctx, msg.Addr)
+	}
+}
+
+func (a *admin) getPeers(ctx *actor.Context) {
+	peers := &Peers{}
+	res, err := ctx.Request(a.remote.streamRouterPID, peerQuery{}, adminRequestTimeout).Result()
+	if err != nil {
+		ctx.Engine().Logger().Error("查询远程节点失败", "err", err)
+	} else {
+		peers.Outbound = res.([]PeerInfo)
+	}
+	a.remote.inbound.Range(func(key, _ any) bool {
+		peers.Inbound = append(peers.Inbound, key.(*inboundConn).RemoteAddr().String())
+		return true
+	})
+	sort.Strings(peers.Inbound)
+	ctx.Respond(peers)
+}
+
+func (a *admin) forceDisconnect(ctx *actor.Context, addr string) {
+	reply := &ForceDisconnected{Addr: addr}
+	res, err := ctx.Request(a.remote.streamRouterPID, peerDisconnect{address: addr}, adminRequestTimeout).Result()
+	if err != nil {
+		ctx.Engine().Logger().Error("关闭出站连接失败", "err", err, "remote", addr)
+	} else {
+		reply.Outbound = res.(bool)
+	}
+	a.remote.inbound.Range(func(key, _ any) bool {
+		if conn := key.(*inboundConn); conn.RemoteAddr().String() == addr {
+			_ = conn.Close()
+			reply.Inbound++
+		}
+		return true
+	})
+	ctx.Engine().Logger().Info("强制断开远程节点", "remote", addr, "outbound", reply.Outbound, "inbound", reply.Inbound)
+	ctx.Respond(reply)
+}
+
+// peers 返回流路由器已知的每个远程节点的状态。
+func (s *streamRouter) peers() []PeerInfo {
+	addrs := make(map[string]struct{})
+	for addr := range s.streams {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range s.down {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range s.outboxes {
+		addrs[addr] = struct{}{}
+	}
+	infos := make([]PeerInfo, 0, len(addrs))
+	for addr := range addrs {
+		info := PeerInfo{Addr: addr, State: PeerUnreachable}
+		if w, ok := s.writers[addr]; ok {
+			info.State = PeerConnecting
+			if s.connected[addr] {
+				info.State = PeerConnected
+			}
+			info.Queued = w.inbox.(*actor.Inbox).Len()
+		}
+		if b, ok := s.breakers[addr]; ok && b.state == actor.RemoteCircuitOpen {
+			info.State = PeerCircuitOpen
+		}
+		if ob, ok := s.outboxes[addr]; ok {
+			info.Outboxed = len(ob.entries)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Addr < infos[j].Addr })
+	return infos
+}
+
+// disconnect 让 address 的流写入器写出已经收到的消息后关闭连接，返回是否存在这样的流写入器。
+// 流写入器关闭之前发往 address 的消息留在 closing 中，之后使用新的流写入器发送。
+func (s *streamRouter) disconnect(address string) bool {
+	pid, ok := s.streams[address]
+	if !ok {
+		return false
+	}
+	s.removeStream(address)
+	s.closing[address] = nil
+	flush := &streamFlush{wg: &sync.WaitGroup{}, close: true}
+	flush.wg.Add(1)
+	s.engine.Send(pid, flush)
+	go func() {
+		// 流写入器从注册表移除之后才调用 wg.Done。
+		flush.wg.Wait()
+		s.engine.Send(s.pid, &streamClosed{address: address})
+	}()
+	return true
+}
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	a, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithAdmin(true))
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithAdmin(true))
+	require.NoError(t, err)
+	assert.Equal(t, "remote/admin", ra.AdminPID().ID)
+
+	got := make(chan struct{}, 2)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			got <- struct{}{}
+		}
+	}, "foo")
+	a.Send(pidb, testMsg("foo"))
+	<-got
+
+	var peers *Peers
+	require.Eventually(t, func() bool {
+		res, err := a.Request(ra.AdminPID(), GetPeers{}, time.Second).Result()
+		require.NoError(t, err)
+		peers = res.(*Peers)
+		return len(peers.Outbound) == 1 && peers.Outbound[0].State == PeerConnected
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, rb.Address(), peers.Outbound[0].Addr)
+	assert.Equal(t, 0, peers.Outbound[0].Queued)
+
+	res, err := b.Request(rb.AdminPID(), GetPeers{}, time.Second).Result()
+	require.NoError(t, err)
+	require.Len(t, res.(*Peers).Inbound, 1)
+
+	res, err = a.Request(ra.AdminPID(), ForceDisconnect{Addr: rb.Address()}, time.Second).Result()
+	require.NoError(t, err)
+	assert.True(t, res.(*ForceDisconnected).Outbound)
+	res, err = a.Request(ra.AdminPID(), GetPeers{}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Empty(t, res.(*Peers).Outbound)
+
+	// 断开之后的消息建立新的连接。
+	a.Send(pidb, testMsg("bar"))
+	select {
+	case <-got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("断开后没有重新连接")
+	}
+}
+
+func TestAdminDisabled(t *testing.T) {
+	_, r, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	assert.Nil(t, r.AdminPID())
+}
@@ -0,0 +1,154 @@
+package remote
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	maxAuthTokenSize   = 16 * 1024   // 令牌的最大长度，防止对方发送超大的长度前缀
+	defaultTokenMaxAge = time.Minute // 签名令牌默认的有效期
+
+	authAccepted byte = 1
+	authRejected byte = 0
+)
+
+var (
+	// ErrAuthRejected 表示远程节点拒绝了本节点出示的令牌。
+	ErrAuthRejected = errors.New("远程节点拒绝了认证令牌")
+
+	errInvalidToken = errors.New("令牌无效")
+	errExpiredToken = errors.New("令牌已过期")
+)
+
+// Authenticator 实现远程节点之间的认证握手。连接建立后、Transport 打开流之前，
+// 拨号端发送 Token 返回的令牌，监听端用 Verify 验证，验证失败时关闭连接。
+// 集群中的所有节点必须使用兼容的 Authenticator。
+type Authenticator interface {
+	// Token 返回拨号到 addr 时出示的令牌。
+	Token(addr string) ([]byte, error)
+	// Verify 验证对方出示的令牌，返回错误时拒绝连接。
+	Verify(token []byte) error
+}
+
+// WithAuth 设置远程节点之间的认证握手。未通过认证的节点无法向本引擎发送消息，
+// 监听端会在事件流上广播 actor.RemoteAuthFailedEvent。
+func (c Config) WithAuth(auth Authenticator) Config {
+	c.Auth = auth
+	return c
+}
+
+// sharedSecretAuth 直接比较共享密钥。
+type sharedSecretAuth struct {
+	secret []byte
+}
+
+// NewSharedSecretAuth 返回使用共享密钥认证的 Authenticator。
+// 密钥以明文发送，应该与 TLS 一起使用，否则请使用 NewSignedTokenAuth。
+func NewSharedSecretAuth(secret string) Authenticator {
+	return sharedSecretAuth{secret: []byte(secret)}
+}
+
+func (a sharedSecretAuth) Token(string) ([]byte, error) { return a.secret, nil }
+
+func (a sharedSecretAuth) Verify(token []byte) error {
+	if subtle.ConstantTimeCompare(a.secret, token) != 1 {
+		return errInvalidToken
+	}
+	return nil
+}
+
+// signedTokenAuth 发送带时间戳的 HMAC-SHA256 签名，密钥本身不会出现在网络上。
+type signedTokenAuth struct {
+	key    []byte
+	maxAge time.Duration
+}
+
+// NewSignedTokenAuth 返回使用 HMAC-SHA256 签名令牌认证的 Authenticator。
+// 令牌包含签发时间，超过 maxAge 的令牌被拒绝；maxAge <= 0 时使用 1 分钟。
+// 有效期内的令牌可以被重放，需要防止重放时请配合 TLS 使用。
+func NewSignedTokenAuth(key []byte, maxAge time.Duration) Authenticator {
+	if maxAge <= 0 {
+		maxAge = defaultTokenMaxAge
+	}
+	return signedTokenAuth{key: key, maxAge: maxAge}
+}
+
+func (a signedTokenAuth) sign(ts []byte) []byte {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(ts)
+	return mac.Sum(nil)
+}
+
+func (a signedTokenAuth) Token(string) ([]byte, error) {
+	token := binary.BigEndian.AppendUint64(nil, uint64(time.Now().UnixNano()))
+	return append(token, a.sign(token)...), nil
+}
+
+func (a signedTokenAuth) Verify(token []byte) error {
+	if len(token) != 8+sha256.Size {
+		return errInvalidToken
+	}
+	ts, sig := token[:8], token[8:]
+	if !hmac.Equal(sig, a.sign(ts)) {
+		return errInvalidToken
+	}
+	age := time.Since(time.Unix(0, int64(binary.BigEndian.Uint64(ts))))
+	if age > a.maxAge || age < -a.maxAge {
+		return errExpiredToken
+	}
+	return nil
+}
+
+// authenticate 在拨号端执行认证握手：发送长度前缀的令牌并等待对方的结果。
+func authenticate(conn net.Conn, auth Authenticator, addr string) error {
+	token, err := auth.Token(addr)
+	if err != nil {
+		return fmt.Errorf("生成认证令牌失败: %w", err)
+	}
+	if len(token) > maxAuthTokenSize {
+		return fmt.Errorf("认证令牌过长: %d 字节", len(token))
+	}
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	if err := writeFrame(conn, token); err != nil {
+		return err
+	}
+	var result [1]byte
+	if _, err := io.ReadFull(conn, result[:]); err != nil {
+		return err
+	}
+	if result[0] != authAccepted {
+		return ErrAuthRejected
+	}
+	return nil
+}
+
+// verifyPeer 在监听端执行认证握手，返回的错误说明拒绝的原因。
+func verifyPeer(conn net.Conn, auth Authenticator) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	token, err := readFrame(conn, maxAuthTokenSize)
+	if err != nil {
+		return err
+	}
+	if err := auth.Verify(token); err != nil {
+		_, _ = conn.Write([]byte{authRejected})
+		return err
+	}
+	_, err = conn.Write([]byte{authAccepted})
+	return err
+}
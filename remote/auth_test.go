@@ -0,0 +1,101 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSend_Auth(t *testing.T) {
+	for name, config := range map[string]Config{
+		"drpc": NewConfig(),
+		"grpc": NewConfig().WithTransport(NewGRPCTransport()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			auth := NewSignedTokenAuth([]byte("secret"), 0)
+			a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config.WithAuth(auth))
+			require.NoError(t, err)
+			b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config.WithAuth(auth))
+			require.NoError(t, err)
+
+			wg := &sync.WaitGroup{}
+			wg.Add(2)
+			pida := a.SpawnFunc(func(c *actor.Context) {
+				if msg, ok := c.Message().(*TestMessage); ok {
+					assert.Equal(t, []byte("foo"), msg.Data)
+					wg.Done()
+				}
+			}, "actor on a")
+			b.Send(pida, &TestMessage{Data: []byte("foo")})
+			b.Send(pida, &TestMessage{Data: []byte("foo")})
+			wg.Wait()
+		})
+	}
+}
+
+func TestAuthRejectsWrongSecret(t *testing.T) {
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithAuth(NewSharedSecretAuth("secret")))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithAuth(NewSharedSecretAuth("wrong")))
+	require.NoError(t, err)
+
+	failed := make(chan actor.RemoteAuthFailedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteAuthFailedEvent); ok {
+			failed <- ev
+		}
+	}, "auth_events")
+	a.Subscribe(sub)
+
+	received := make(chan struct{}, 1)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			received <- struct{}{}
+		}
+	}, "actor on a")
+	b.Send(pida, &TestMessage{Data: []byte("foo")})
+
+	select {
+	case ev := <-failed:
+		assert.NotEmpty(t, ev.RemoteAddr)
+		assert.Equal(t, errInvalidToken.Error(), ev.Reason)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteAuthFailedEvent")
+	}
+	select {
+	case <-received:
+		t.Fatal("未通过认证的节点不应该能发送消息")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSignedTokenAuth(t *testing.T) {
+	auth := NewSignedTokenAuth([]byte("secret"), 50*time.Millisecond)
+	token, err := auth.Token("127.0.0.1:4000")
+	require.NoError(t, err)
+	assert.NoError(t, auth.Verify(token))
+
+	other := NewSignedTokenAuth([]byte("other"), time.Minute)
+	assert.ErrorIs(t, other.Verify(token), errInvalidToken)
+
+	tampered := append([]byte{}, token...)
+	tampered[len(tampered)-1] ^= 0xff
+	assert.ErrorIs(t, auth.Verify(tampered), errInvalidToken)
+	assert.ErrorIs(t, auth.Verify(token[:8]), errInvalidToken)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.ErrorIs(t, auth.Verify(token), errExpiredToken)
+}
+
+func TestSharedSecretAuth(t *testing.T) {
+	auth := NewSharedSecretAuth("secret")
+	token, err := auth.Token("127.0.0.1:4000")
+	require.NoError(t, err)
+	assert.NoError(t, auth.Verify(token))
+	assert.ErrorIs(t, auth.Verify([]byte("secre")), errInvalidToken)
+	assert.ErrorIs(t, auth.Verify(nil), errInvalidToken)
+}
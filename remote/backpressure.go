@@ -0,0 +1,109 @@
+package remote
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// BackpressurePolicy 决定流写入器的队列超过阈值时 Remote.Send 如何处理新消息。
+type BackpressurePolicy int
+
+const (
+	// BackpressureNotify 只广播 actor.RemoteBackpressureEvent，消息照常排队。
+	BackpressureNotify BackpressurePolicy = iota
+	// BackpressureBlock 阻塞发送者，直到队列低于阈值、流写入器关闭或远程停止。
+	// 注意：在 actor 中发送时会阻塞该 actor。
+	BackpressureBlock
+	// BackpressureDrop 丢弃新消息并交给死信处理。
+	BackpressureDrop
+)
+
+// backpressurePollInterval 是 BackpressureBlock 检查队列长度的间隔。
+const backpressurePollInterval = time.Millisecond
+
+// WithBackpressure 在发往某个地址的流写入器队列达到 threshold 条消息时（对方很慢或不可达），
+// 广播 actor.RemoteBackpressureEvent 并按照 policy 处理新消息；队列回落到阈值以下时再次广播。
+// threshold <= 0 时不启用，这也是默认值。
+func (c Config) WithBackpressure(threshold int, policy BackpressurePolicy) Config {
+	c.BackpressureThreshold = threshold
+	c.BackpressurePolicy = policy
+	return c
+}
+
+// writerQueue 是流写入器的收件箱，Remote.Send 用它检查队列长度。
+type writerQueue struct {
+	inbox *actor.Inbox
+	// overloaded 表示已经广播了队列超过阈值的事件。
+	overloaded atomic.Bool
+}
+
+// registerQueue 记录 address 的流写入器收件箱，未启用背压时返回 nil。
+func (r *Remote) registerQueue(address string, inbox *actor.Inbox) *writerQueue {
+	if r.config.BackpressureThreshold <= 0 {
+		return nil
+	}
+	q := &writerQueue{inbox: inbox}
+	r.queues.Store(address, q)
+	return q
+}
+
+// unregisterQueue 在流写入器关闭时删除它的收件箱。
+func (r *Remote) unregisterQueue(address string, q *writerQueue) {
+	if q != nil {
+		r.queues.CompareAndDelete(address, q)
+	}
+}
+
+// admit 在发往 pid 的流写入器队列超过阈值时按照背压策略处理消息，
+// 返回 false 表示消息已经被丢弃。
+func (r *Remote) admit(pid *actor.PID, msg any, sender *actor.PID) bool {
+	v, ok := r.queues.Load(pid.Address)
+	if !ok {
+		return true
+	}
+	q := v.(*writerQueue)
+	threshold := r.config.BackpressureThreshold
+	depth := q.inbox.Len()
+	if depth < threshold {
+		return true
+	}
+	if q.overloaded.CompareAndSwap(false, true) {
+		r.engine.BroadcastEvent(actor.RemoteBackpressureEvent{
+			ListenAddr: pid.Address,
+			QueueDepth: depth,
+			Threshold:  threshold,
+			Active:     true,
+		})
+	}
+	switch r.config.BackpressurePolicy {
+	case BackpressureDrop:
+		r.engine.DeadLetter(pid, msg, sender)
+		return false
+	case BackpressureBlock:
+		for q.inbox.Len() >= threshold && r.state.Load() == stateRunning {
+			if cur, ok := r.queues.Load(pid.Address); !ok || cur != q {
+				break
+			}
+			time.Sleep(backpressurePollInterval)
+		}
+	}
+	return true
+}
+
+// relieve 在队列回落到阈值以下时广播背压解除的事件，由流写入器在处理完一批消息后调用。
+func (s *streamWriter) relieve() {
+	q := s.queue
+	if q == nil || !q.overloaded.Load() {
+		return
+	}
+	threshold := s.remote.config.BackpressureThreshold
+	if depth := q.inbox.Len(); depth < threshold && q.overloaded.CompareAndSwap(true, false) {
+		s.engine.BroadcastEvent(actor.RemoteBackpressureEvent{
+			ListenAddr: s.writeToAddr,
+			QueueDepth: depth,
+			Threshold:  threshold,
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package remote
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackpressureSlowPeer(t *testing.T) {
+	// 接受连接但从不读取数据的对端，流写入器很快会阻塞在发送上。
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithBackpressure(8, BackpressureDrop))
+	require.NoError(t, err)
+	events := make(chan actor.RemoteBackpressureEvent, 1)
+	dropped := make(chan struct{}, 1)
+	sub := b.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.RemoteBackpressureEvent:
+			select {
+			case events <- msg:
+			default:
+			}
+		case actor.DeadLetterEvent:
+			if _, ok := msg.Message.(*TestMessage); ok {
+				select {
+				case dropped <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}, "events")
+	b.Subscribe(sub)
+
+	target := actor.NewPID(ln.Addr().String(), "foo")
+	data := bytes.Repeat([]byte("x"), 64*1024)
+	deadline := time.After(5 * time.Second)
+	for {
+		b.Send(target, &TestMessage{Data: data})
+		select {
+		case ev := <-events:
+			assert.True(t, ev.Active)
+			assert.Equal(t, ln.Addr().String(), ev.ListenAddr)
+			assert.GreaterOrEqual(t, ev.QueueDepth, 8)
+			b.Send(target, &TestMessage{Data: data})
+			select {
+			case <-dropped:
+			case <-time.After(time.Second):
+				t.Fatal("超过阈值的消息没有进入死信")
+			}
+			return
+		case <-deadline:
+			t.Fatal("没有收到 RemoteBackpressureEvent")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestBackpressurePolicies(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	events := make(chan actor.RemoteBackpressureEvent, 4)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteBackpressureEvent); ok {
+			events <- ev
+		}
+	}, "events")
+	e.Subscribe(sub)
+
+	newRemote := func(policy BackpressurePolicy) (*Remote, *writerQueue) {
+		r := New("127.0.0.1:0", NewConfig().WithBackpressure(2, policy))
+		r.engine = e
+		r.state.Store(stateRunning)
+		inbox := actor.NewInbox(8)
+		q := r.registerQueue("peer", inbox)
+		inbox.Send(actor.Envelope{})
+		inbox.Send(actor.Envelope{})
+		return r, q
+	}
+	target := actor.NewPID("peer", "foo")
+
+	r, _ := newRemote(BackpressureNotify)
+	assert.True(t, r.admit(target, testMsg("a"), nil))
+	ev := <-events
+	assert.True(t, ev.Active)
+	assert.Equal(t, 2, ev.QueueDepth)
+	// 已经广播过的背压不再重复广播。
+	assert.True(t, r.admit(target, testMsg("a"), nil))
+
+	r, _ = newRemote(BackpressureDrop)
+	assert.False(t, r.admit(target, testMsg("a"), nil))
+	<-events
+
+	r, q := newRemote(BackpressureBlock)
+	admitted := make(chan bool)
+	go func() { admitted <- r.admit(target, testMsg("a"), nil) }()
+	<-events
+	select {
+	case <-admitted:
+		t.Fatal("队列超过阈值时应该阻塞")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// 流写入器关闭后不再阻塞。
+	r.unregisterQueue("peer", q)
+	assert.True(t, <-admitted)
+
+	// 队列回落到阈值以下时广播背压解除。
+	r, _ = newRemote(BackpressureNotify)
+	q = &writerQueue{inbox: actor.NewInbox(8)}
+	q.overloaded.Store(true)
+	w := &streamWriter{remote: r, engine: e, writeToAddr: "peer", queue: q}
+	w.relieve()
+	ev = <-events
+	assert.False(t, ev.Active)
+	assert.False(t, q.overloaded.Load())
+	select {
+	case ev := <-events:
+		t.Fatalf("多余的事件: %+v", ev)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,38 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// WithBatching 设置流写入器的批处理策略：每个 Envelope 最多包含 size 条消息（0 表示不限制，
+// 由收件箱每次调度取出的消息数决定），linger 大于 0 时，不足 size 条的批次最多等待 linger
+// 再写出，以便累积更多消息。较大的 size 和 linger 减少系统调用次数，代价是更高的延迟。
+func (c Config) WithBatching(size int, linger time.Duration) Config {
+	c.BatchSize = size
+	c.BatchLinger = linger
+	return c
+}
+
+// streamLinger 是 linger 窗口结束时发给流写入器的消息，触发写出累积的批次。
+type streamLinger struct{}
+
+// full 报告当前批次是否已达到 batchSize 条消息。
+func (s *streamWriter) full() bool {
+	return s.batchSize > 0 && len(s.batch.messages) >= s.batchSize
+}
+
+// linger 推迟写出未满的批次，返回 false 时批次应当立即写出。
+func (s *streamWriter) linger() bool {
+	if s.batchLinger <= 0 {
+		return false
+	}
+	if !s.lingering {
+		s.lingering = true
+		time.AfterFunc(s.batchLinger, func() {
+			s.inbox.Send(actor.Envelope{Msg: streamLinger{}})
+		})
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package remote
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingStream 记录每个写出的 Envelope 包含的消息数。
+type recordingStream struct {
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *recordingStream) Send(env *Envelope) error {
+	s.mu.Lock()
+	s.batches = append(s.batches, len(env.Messages))
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recordingStream) Close() error            { return nil }
+func (s *recordingStream) Closed() <-chan struct{} { return nil }
+
+func (s *recordingStream) sent() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.batches...)
+}
+
+func newBatchingWriter(t *testing.T, size int, linger time.Duration) (*streamWriter, *recordingStream) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, peer := net.Pipe()
+	t.Cleanup(func() {
+		conn.Close()
+		peer.Close()
+	})
+	stream := &recordingStream{}
+	return &streamWriter{
+		engine:      e,
+		inbox:       actor.NewInbox(8),
+		codecs:      []Codec{ProtoSerializer{}},
+		rawconn:     conn,
+		stream:      stream,
+		batchSize:   size,
+		batchLinger: linger,
+	}, stream
+}
+
+func deliveries(n int) []actor.Envelope {
+	target := actor.NewPID("peer", "foo")
+	msgs := make([]actor.Envelope, n)
+	for i := range msgs {
+		msgs[i] = actor.Envelope{Msg: newStreamDeliver(target, testMsg("foo"), nil)}
+	}
+	return msgs
+}
+
+func TestBatchingSize(t *testing.T) {
+	w, stream := newBatchingWriter(t, 4, 0)
+	w.Invoke(deliveries(10))
+	assert.Equal(t, []int{4, 4, 2}, stream.sent())
+}
+
+func TestBatchingLinger(t *testing.T) {
+	w, stream := newBatchingWriter(t, 4, 20*time.Millisecond)
+	w.inbox.Start(w)
+	defer w.inbox.Stop()
+	for _, msg := range deliveries(6) {
+		w.inbox.Send(msg)
+	}
+	// 满 4 条的批次立即写出，剩余的消息等到 linger 窗口结束。
+	assert.Eventually(t, func() bool { return len(stream.sent()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{4}, stream.sent())
+	assert.Eventually(t, func() bool { return len(stream.sent()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{4, 2}, stream.sent())
+}
+
+func TestBatchingFlushEndsLinger(t *testing.T) {
+	w, stream := newBatchingWriter(t, 0, time.Hour)
+	w.Invoke(deliveries(3))
+	assert.Empty(t, stream.sent())
+
+	flush := &streamFlush{wg: &sync.WaitGroup{}}
+	flush.wg.Add(1)
+	w.Invoke([]actor.Envelope{{Msg: flush}})
+	flush.wg.Wait()
+	assert.Equal(t, []int{3}, stream.sent())
+}
+
+func TestBatchSkipsFailedMessages(t *testing.T) {
+	w, _ := newBatchingWriter(t, 10, 0)
+	w.batch.reset()
+	// 无法序列化的消息不在批次的查找表中留下条目。
+	w.add(newStreamDeliver(actor.NewPID("peer", "foo"), "not a proto message", actor.NewPID("local", "bar")))
+	assert.Empty(t, w.batch.messages)
+	assert.Empty(t, w.batch.typeNames)
+	assert.Empty(t, w.batch.senders)
+	assert.Empty(t, w.batch.targets)
+}
@@ -0,0 +1,83 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// WithCircuitBreaker 为每个远程地址启用熔断器。连续 failures 次无法建立连接后熔断器打开，
+// 在 cooldown 内发往该地址的消息直接进入死信，而不再反复拨号；冷却期过后的下一条消息
+// 触发一次半开的连接尝试，成功则关闭熔断器，失败则重新打开。
+// 状态改变时在事件流上广播 actor.RemoteCircuitEvent。failures <= 0 时不启用。
+func (c Config) WithCircuitBreaker(failures int, cooldown time.Duration) Config {
+	c.CircuitBreakerFailures = failures
+	c.CircuitBreakerCooldown = cooldown
+	return c
+}
+
+// streamConnected 由流写入器在连接建立后发送给流路由器。
+type streamConnected struct {
+	address string
+}
+
+// circuitBreaker 记录一个远程地址的熔断状态。
+type circuitBreaker struct {
+	state    actor.RemoteCircuitState
+	failures int
+	openedAt time.Time
+}
+
+// allow 报告是否可以为 address 创建流写入器。冷却期过后熔断器进入半开状态并放行一次。
+func (s *streamRouter) allow(address string) bool {
+	b, ok := s.breakers[address]
+	if !ok || b.state != actor.RemoteCircuitOpen {
+		return true
+	}
+	if time.Since(b.openedAt) < s.remote.config.CircuitBreakerCooldown {
+		return false
+	}
+	s.setCircuitState(address, b, actor.RemoteCircuitHalfOpen)
+	return true
+}
+
+// recordFailure 记录一次到 address 的连接失败。
+func (s *streamRouter) recordFailure(address string) {
+	if s.remote.config.CircuitBreakerFailures <= 0 {
+		return
+	}
+	b, ok := s.breakers[address]
+	if !ok {
+		b = &circuitBreaker{}
+		s.breakers[address] = b
+	}
+	b.failures++
+	if b.state == actor.RemoteCircuitHalfOpen || b.failures >= s.remote.config.CircuitBreakerFailures {
+		b.openedAt = time.Now()
+		s.setCircuitState(address, b, actor.RemoteCircuitOpen)
+	}
+}
+
+// recordSuccess 在到 address 的连接建立后重置熔断器。
+func (s *streamRouter) recordSuccess(address string) {
+	b, ok := s.breakers[address]
+	if !ok {
+		return
+	}
+	delete(s.breakers, address)
+	if b.state != actor.RemoteCircuitClosed {
+		s.setCircuitState(address, b, actor.RemoteCircuitClosed)
+	}
+}
+
+func (s *streamRouter) setCircuitState(address string, b *circuitBreaker, state actor.RemoteCircuitState) {
+	if b.state == state {
+		return
+	}
+	b.state = state
+	s.engine.BroadcastEvent(actor.RemoteCircuitEvent{
+		ListenAddr: address,
+		State:      state,
+		Failures:   b.failures,
+	})
+}
@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = 200 * time.Millisecond
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithCircuitBreaker(1, cooldown))
+	require.NoError(t, err)
+
+	circuit := make(chan actor.RemoteCircuitEvent, 8)
+	deadLetters := make(chan actor.DeadLetterEvent, 8)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.RemoteCircuitEvent:
+			circuit <- msg
+		case actor.DeadLetterEvent:
+			// 第一次拨号失败时发往已停止的流写入器的消息也会进入死信，这里只关心被熔断的消息。
+			if _, ok := msg.Message.(*TestMessage); ok {
+				deadLetters <- msg
+			}
+		}
+	}, "events")
+	a.Subscribe(sub)
+
+	bAddr := getRandomLocalhostAddr()
+	target := actor.NewPID(bAddr, "foo/1")
+
+	// 对方不可达，重试失败后熔断器打开。
+	a.Send(target, &TestMessage{Data: []byte("lost")})
+	select {
+	case ev := <-circuit:
+		assert.Equal(t, actor.RemoteCircuitOpen, ev.State)
+		assert.Equal(t, bAddr, ev.ListenAddr)
+		assert.Equal(t, 1, ev.Failures)
+	case <-time.After(10 * time.Second):
+		t.Fatal("熔断器没有打开")
+	}
+
+	// 冷却期内的消息直接进入死信。
+	a.Send(target, &TestMessage{Data: []byte("fast fail")})
+	select {
+	case ev := <-deadLetters:
+		assert.True(t, ev.Target.Equals(target))
+		assert.Equal(t, []byte("fast fail"), ev.Message.(*TestMessage).Data)
+	case <-time.After(time.Second):
+		t.Fatal("冷却期内的消息没有进入死信")
+	}
+
+	// 对方恢复，冷却期过后半开探测成功，熔断器关闭。
+	b, _, err := makeRemoteEngine(bAddr)
+	require.NoError(t, err)
+	received := make(chan struct{}, 1)
+	b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			received <- struct{}{}
+		}
+	}, "foo", actor.WithID("1"))
+	time.Sleep(cooldown)
+
+	a.Send(target, &TestMessage{Data: []byte("probe")})
+	for _, state := range []actor.RemoteCircuitState{actor.RemoteCircuitHalfOpen, actor.RemoteCircuitClosed} {
+		select {
+		case ev := <-circuit:
+			assert.Equal(t, state, ev.State)
+		case <-time.After(time.Second):
+			t.Fatalf("没有收到 %s 事件", state)
+		}
+	}
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("熔断器关闭后消息没有送达")
+	}
+}
@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// handshakeTimeout 是连接建立时认证和协商握手的超时时间。
+const handshakeTimeout = time.Second * 5
+
+// writeFrame 写出一个以 4 字节长度为前缀的帧。
+func writeFrame(conn net.Conn, payload []byte) error {
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	_, err := conn.Write(append(frame, payload...))
+	return err
+}
+
+// readFrame 读取一个以 4 字节长度为前缀的帧，长度超过 max 时返回错误，
+// 防止对方发送超大的长度前缀。
+func readFrame(conn net.Conn, max uint32) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > max {
+		return nil, fmt.Errorf("握手帧过长: %d 字节", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// handshakeFunc 在监听端对新连接执行握手，返回交给 Transport 的连接；
+// 返回错误时连接被关闭，连接和错误都为 nil 时表示连接已经被握手接管。
+type handshakeFunc func(conn net.Conn) (net.Conn, error)
+
+// handshakeListener 只把完成握手的连接交给 Transport。握手在单独的 goroutine 中进行，
+// 因此一个迟迟不发送数据的连接不会阻塞其他连接。
+type handshakeListener struct {
+	net.Listener
+	handshake handshakeFunc
+
+	conns     chan net.Conn
+	done      chan struct{}
+	failed    chan struct{}
+	err       error
+	closeOnce sync.Once
+}
+
+func newHandshakeListener(ln net.Listener, handshake handshakeFunc) *handshakeListener {
+	l := &handshakeListener{
+		Listener:  ln,
+		handshake: handshake,
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+		failed:    make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+func (l *handshakeListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.err = err
+			close(l.failed)
+			return
+		}
+		go l.serve(conn)
+	}
+}
+
+func (l *handshakeListener) serve(conn net.Conn) {
+	c, err := l.handshake(conn)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	if c == nil {
+		return
+	}
+	select {
+	case l.conns <- c:
+	case <-l.done:
+		_ = c.Close()
+	}
+}
+
+// Accept 返回下一个完成握手的连接。
+func (l *handshakeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.failed:
+		return nil, l.err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *handshakeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
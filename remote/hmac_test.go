@@ -0,0 +1,66 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageMAC(t *testing.T) {
+	key := []byte("secret")
+	target := actor.NewPID("node-b", "foo")
+	sender := actor.NewPID("node-a", "bar")
+	mac := messageMAC(key, "remote.TestMessage", target, sender, []byte("data"))
+	assert.Equal(t, mac, messageMAC(key, "remote.TestMessage", target, sender, []byte("data")))
+	assert.NotEqual(t, mac, messageMAC([]byte("other"), "remote.TestMessage", target, sender, []byte("data")))
+	assert.NotEqual(t, mac, messageMAC(key, "remote.TestMessage", target, nil, []byte("data")))
+	// 字段的边界不能移动。
+	assert.NotEqual(t,
+		messageMAC(key, "a", actor.NewPID("bc", "d"), nil, nil),
+		messageMAC(key, "ab", actor.NewPID("c", "d"), nil, nil))
+}
+
+func TestHMAC(t *testing.T) {
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithHMAC([]byte("secret")))
+	require.NoError(t, err)
+	got := make(chan string, 1)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo")
+	rejected := make(chan actor.RemoteIntegrityErrorEvent, 2)
+	sub := b.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteIntegrityErrorEvent); ok {
+			rejected <- ev
+		}
+	}, "events")
+	b.Subscribe(sub)
+
+	for _, config := range []Config{NewConfig(), NewConfig().WithHMAC([]byte("wrong"))} {
+		a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+		require.NoError(t, err)
+		a.Send(pidb, testMsg("forged"))
+		select {
+		case ev := <-rejected:
+			assert.Equal(t, pidb.ID, ev.Target.ID)
+			assert.Equal(t, "remote.TestMessage", ev.TypeName)
+		case <-time.After(2 * time.Second):
+			t.Fatal("没有收到 RemoteIntegrityErrorEvent")
+		}
+	}
+
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithHMAC([]byte("secret")))
+	require.NoError(t, err)
+	a.Send(pidb, testMsg("signed"))
+	select {
+	case s := <-got:
+		assert.Equal(t, "signed", s)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到签名的消息")
+	}
+	assert.Empty(t, got)
+}
@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"net"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// WithKeepalive 启用远程层的心跳：流写入器每隔 interval 发送一次心跳，对方超过 timeout
+// 没有回复时关闭连接并广播 actor.RemoteUnreachableEvent；监听端超过 timeout 没有收到
+// 任何数据（包括心跳）时关闭连接。这样半开的 TCP 连接能被很快发现，而不必等待 10 分钟的空闲超时。
+// timeout <= 0 时使用 3 倍的 interval。集群中的所有节点应该使用相同的设置，
+// 否则没有启用心跳的节点的空闲连接会被对方关闭。
+func (c Config) WithKeepalive(interval, timeout time.Duration) Config {
+	if timeout <= 0 {
+		timeout = 3 * interval
+	}
+	c.KeepaliveInterval = interval
+	c.KeepaliveTimeout = timeout
+	return c
+}
+
+// streamPing 要求流写入器发送一次心跳。
+type streamPing struct{}
+
+// startKeepalive 定期向流写入器的收件箱发送 streamPing，直到流被关闭。
+func (s *streamWriter) startKeepalive() {
+	if s.keepaliveInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.keepaliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.inbox.Send(actor.Envelope{Msg: &streamPing{}})
+			case <-s.stream.Closed():
+				return
+			}
+		}
+	}()
+}
+
+// ping 发送一次心跳。对方超过 keepaliveTimeout 没有回复时关闭流，
+// 流写入器随后关闭并广播 RemoteUnreachableEvent。
+func (s *streamWriter) ping() {
+	if ks, ok := s.stream.(KeepaliveStream); ok {
+		if since := time.Since(ks.LastReceived()); since > s.keepaliveTimeout {
+			s.engine.Logger().Warn("对端心跳超时，关闭连接", "remote", s.writeToAddr, "since", since)
+			_ = s.stream.Close()
+			return
+		}
+	}
+	if err := s.stream.Send(&Envelope{}); err != nil {
+		s.engine.Logger().Error("发送心跳失败", "err", err, "remote", s.writeToAddr)
+	}
+}
+
+// keepaliveListener 关闭超过 timeout 没有收到任何数据的连接。
+type keepaliveListener struct {
+	net.Listener
+	timeout time.Duration
+}
+
+func (l *keepaliveListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &keepaliveConn{Conn: conn, timeout: l.timeout}, nil
+}
+
+// keepaliveConn 在每次读取之前推后读取的截止时间。
+type keepaliveConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *keepaliveConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
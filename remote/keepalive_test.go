@@ -0,0 +1,104 @@
+package remote
+
+import (
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepalive(t *testing.T) {
+	for name, config := range map[string]Config{
+		"drpc": NewConfig(),
+		"grpc": NewConfig().WithTransport(NewGRPCTransport()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := config.WithKeepalive(20*time.Millisecond, 100*time.Millisecond)
+			a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+			b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+
+			unreachable := make(chan struct{}, 1)
+			sub := b.SpawnFunc(func(c *actor.Context) {
+				if _, ok := c.Message().(actor.RemoteUnreachableEvent); ok {
+					unreachable <- struct{}{}
+				}
+			}, "events")
+			b.Subscribe(sub)
+
+			wg := &sync.WaitGroup{}
+			wg.Add(2)
+			pida := a.SpawnFunc(func(c *actor.Context) {
+				if _, ok := c.Message().(*TestMessage); ok {
+					wg.Done()
+				}
+			}, "actor on a")
+			b.Send(pida, testMsg("foo"))
+			// 空闲时间超过超时时间，心跳应该让两端都保持连接。
+			time.Sleep(300 * time.Millisecond)
+			b.Send(pida, testMsg("bar"))
+			wg.Wait()
+
+			select {
+			case <-unreachable:
+				t.Fatal("心跳正常时不应该断开连接")
+			default:
+			}
+		})
+	}
+}
+
+func TestKeepaliveDetectsDeadPeer(t *testing.T) {
+	// 只读取数据、从不回复的对端。
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithKeepalive(20*time.Millisecond, 100*time.Millisecond))
+	require.NoError(t, err)
+	unreachable := make(chan actor.RemoteUnreachableEvent, 1)
+	sub := b.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteUnreachableEvent); ok {
+			unreachable <- ev
+		}
+	}, "events")
+	b.Subscribe(sub)
+
+	b.Send(actor.NewPID(ln.Addr().String(), "foo"), testMsg("foo"))
+	select {
+	case ev := <-unreachable:
+		assert.Equal(t, ln.Addr().String(), ev.ListenAddr)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有发现失效的对端")
+	}
+}
+
+func TestKeepaliveClosesIdleInbound(t *testing.T) {
+	addr := getRandomLocalhostAddr()
+	_, _, err := makeRemoteEngineWithConfig(addr, NewConfig().WithKeepalive(20*time.Millisecond, 100*time.Millisecond))
+	require.NoError(t, err)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	start := time.Now()
+	_, err = conn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,131 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+)
+
+// WithListenAddrs 使远程模块除了 New 的 addr 之外还监听 addrs，例如同时监听内网和公网网卡，
+// 或者同时监听 TCP 和 "unix://" 套接字，跨越网络边界的集群因此不需要额外的代理。
+// 所有监听地址共享同样的 TLS、认证和握手配置；节点只公布一个地址（WithAdvertisedAddr 或 addr）。
+func (c Config) WithListenAddrs(addrs ...string) Config {
+	c.ListenAddrs = append([]string(nil), addrs...)
+	return c
+}
+
+// listen 在 addr 上监听，并按配置包装 TLS、心跳和握手。
+func (r *Remote) listen(addr string) (net.Listener, error) {
+	var (
+		ln  net.Listener
+		err error
+	)
+	network, address := splitNetworkAddr(addr)
+	switch nt, ok := r.transport.(NetworkTransport); {
+	case ok:
+		ln, err = nt.Listen(addr, r.config.TLSConfig)
+	case r.config.TLSConfig == nil:
+		ln, err = net.Listen(network, address)
+	default:
+		r.logger().Debug("远程使用 TLS 进行监听", "addr", addr)
+		ln, err = tls.Listen(network, address, r.config.TLSConfig)
+		if err == nil {
+			ln = &peerListener{Listener: ln, engine: r.engine, timeout: r.config.tlsHandshakeTimeout()}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.config.KeepaliveInterval > 0 {
+		ln = &keepaliveListener{Listener: ln, timeout: r.config.KeepaliveTimeout}
+	}
+	return newHandshakeListener(ln, r.acceptHandshake), nil
+}
+
+// listenExtra 在 ListenAddrs 的每个地址上监听，失败时关闭已经打开的监听器。
+func (r *Remote) listenExtra() ([]net.Listener, error) {
+	lns := make([]net.Listener, 0, len(r.config.ListenAddrs))
+	for _, addr := range r.config.ListenAddrs {
+		ln, err := r.listen(addr)
+		if err != nil {
+			for _, ln := range lns {
+				_ = ln.Close()
+			}
+			return nil, err
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+// serve 接受 ln 上完成握手的连接，直到 ctx 被取消。每个连接由单独的 Transport.Serve 处理，
+// 流读取器因此知道 Envelope 来自哪个连接，可以按连接的对端限流。
+func (r *Remote) serve(ctx context.Context, ln net.Listener, reader *streamReader) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	stop := context.AfterFunc(ctx, func() { _ = ln.Close() })
+	defer stop()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.serveConn(ctx, conn, reader)
+		}()
+	}
+}
+
+// serveConn 在一个连接上运行 Transport，连接关闭或 ctx 被取消后返回。
+func (r *Remote) serveConn(ctx context.Context, conn net.Conn, reader *streamReader) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	limiter := r.acquireLimiter(conn)
+	defer r.releaseLimiter(limiter)
+	ln := &connListener{
+		conns: make(chan net.Conn, 1),
+		done:  ctx.Done(),
+		addr:  conn.LocalAddr(),
+	}
+	ln.conns <- &servedConn{Conn: conn, closed: cancel}
+	if err := r.transport.Serve(ctx, ln, reader.handler(limiter)); err != nil && ctx.Err() == nil {
+		r.logger().Debug("远程连接服务", "err", err, "remote", conn.RemoteAddr())
+	}
+}
+
+// connListener 是只返回一个连接的 net.Listener，之后的 Accept 阻塞到连接关闭。
+type connListener struct {
+	conns chan net.Conn
+	done  <-chan struct{}
+	addr  net.Addr
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *connListener) Close() error { return nil }
+
+func (l *connListener) Addr() net.Addr { return l.addr }
+
+// servedConn 在 Transport 关闭连接时通知 serveConn。
+type servedConn struct {
+	net.Conn
+	closed context.CancelFunc
+}
+
+func (c *servedConn) Close() error {
+	c.closed()
+	return c.Conn.Close()
+}
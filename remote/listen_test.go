@@ -0,0 +1,45 @@
+package remote
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAddrs(t *testing.T) {
+	sock := unixScheme + filepath.Join(t.TempDir(), "b.sock")
+	extra := getRandomLocalhostAddr()
+	addr := getRandomLocalhostAddr()
+	b, rb, err := makeRemoteEngineWithConfig(addr, NewConfig().WithListenAddrs(extra, sock))
+	require.NoError(t, err)
+	defer rb.Stop()
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			c.Respond(&TestMessage{Data: append(msg.Data, "bar"...)})
+		}
+	}, "echo")
+	// 节点只公布一个地址。
+	assert.Equal(t, addr, pid.Address)
+
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	for _, listenAddr := range []string{addr, extra, sock} {
+		target := actor.NewPID(listenAddr, pid.ID)
+		resp, err := a.Request(target, &TestMessage{Data: []byte("foo")}, time.Second).Result()
+		require.NoError(t, err, listenAddr)
+		assert.Equal(t, "foobar", string(resp.(*TestMessage).Data))
+	}
+}
+
+func TestListenAddrsFailure(t *testing.T) {
+	addr := getRandomLocalhostAddr()
+	_, _, err := makeRemoteEngineWithConfig(addr, NewConfig().WithListenAddrs("256.0.0.1:1"))
+	require.Error(t, err)
+	// 失败时主地址的监听器被关闭，可以再次使用。
+	_, _, err = makeRemoteEngine(addr)
+	require.NoError(t, err)
+}
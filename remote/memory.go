@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// MemoryNetwork 是进程内的内存网络。同一进程中运行多个引擎时（例如按租户隔离），
+// 它们可以通过 MemoryNetwork 互相发送消息，而不必经过 TCP 和序列化。
+//
+//	network := remote.NewMemoryNetwork()
+//	a, _ := actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("tenant-a")))
+//	b, _ := actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("tenant-b")))
+//
+// 消息以引用的方式传递给目标引擎，发送之后不应再修改它们。
+type MemoryNetwork struct {
+	mu      sync.RWMutex
+	engines map[string]*actor.Engine
+}
+
+// NewMemoryNetwork 创建一个新的内存网络。
+func NewMemoryNetwork() *MemoryNetwork {
+	return &MemoryNetwork{engines: make(map[string]*actor.Engine)}
+}
+
+// Remote 返回一个绑定到给定地址的 actor.Remoter，地址在网络中必须唯一。
+func (n *MemoryNetwork) Remote(addr string) *MemoryRemote {
+	return &MemoryRemote{addr: addr, network: n}
+}
+
+// join 将引擎注册到给定地址。
+func (n *MemoryNetwork) join(addr string, e *actor.Engine) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.engines[addr]; ok {
+		return fmt.Errorf("地址 %s 已被占用", addr)
+	}
+	n.engines[addr] = e
+	return nil
+}
+
+// leave 将给定地址从网络中移除。
+func (n *MemoryNetwork) leave(addr string) {
+	n.mu.Lock()
+	delete(n.engines, addr)
+	n.mu.Unlock()
+}
+
+// engine 返回绑定到给定地址的引擎。
+func (n *MemoryNetwork) engine(addr string) *actor.Engine {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.engines[addr]
+}
+
+// MemoryRemote 是通过 MemoryNetwork 通信的 actor.Remoter。
+type MemoryRemote struct {
+	addr    string
+	network *MemoryNetwork
+	engine  *actor.Engine
+}
+
+// Address 返回远程的地址。
+func (r *MemoryRemote) Address() string {
+	return r.addr
+}
+
+// Start 将引擎加入内存网络。
+func (r *MemoryRemote) Start(e *actor.Engine) error {
+	if err := r.network.join(r.addr, e); err != nil {
+		return err
+	}
+	r.engine = e
+	return nil
+}
+
+// Stop 将引擎从内存网络中移除，之后发送到该地址的消息将不可达。
+func (r *MemoryRemote) Stop() *sync.WaitGroup {
+	r.network.leave(r.addr)
+	return &sync.WaitGroup{}
+}
+
+// Send 将消息直接投递给目标地址上的引擎。目标地址不在网络中时，
+// 与 TCP 远程一样在本地引擎上广播 RemoteUnreachableEvent。
+func (r *MemoryRemote) Send(pid *actor.PID, msg any, sender *actor.PID) {
+	target := r.network.engine(pid.Address)
+	if target == nil {
+		r.engine.BroadcastEvent(actor.RemoteUnreachableEvent{ListenAddr: pid.Address})
+		return
+	}
+	target.SendLocal(pid, msg, sender)
+}
+
+// SendRequest 将请求直接投递给目标地址上的引擎，参见 Remote.SendRequest。
+func (r *MemoryRemote) SendRequest(pid *actor.PID, msg any, sender *actor.PID, requestID uint64) {
+	target := r.network.engine(pid.Address)
+	if target == nil {
+		r.engine.BroadcastEvent(actor.RemoteUnreachableEvent{ListenAddr: pid.Address})
+		return
+	}
+	target.SendLocalRequest(pid, msg, sender, requestID)
+}
+
+// SendResponse 将响应直接交给请求方引擎上等待的 Response，参见 Remote.SendResponse。
+func (r *MemoryRemote) SendResponse(pid *actor.PID, msg any, requestID uint64) {
+	target := r.network.engine(pid.Address)
+	if target == nil {
+		r.engine.BroadcastEvent(actor.RemoteUnreachableEvent{ListenAddr: pid.Address})
+		return
+	}
+	target.SendLocalResponse(pid, msg, requestID)
+}
@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memPing struct{ n int }
+
+func TestMemoryNetworkRequest(t *testing.T) {
+	network := NewMemoryNetwork()
+	a, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("a")))
+	require.NoError(t, err)
+	b, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("b")))
+	require.NoError(t, err)
+
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(memPing); ok {
+			c.Respond(memPing{n: msg.n + 1})
+		}
+	}, "echo")
+	assert.Equal(t, "b", pid.Address)
+
+	// 未注册到类型注册表的消息同样可以在内存网络中传递。
+	resp, err := a.Request(pid, memPing{n: 1}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, memPing{n: 2}, resp)
+
+	_, err = actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("a")))
+	assert.Error(t, err)
+}
+
+func TestMemoryNetworkWatchAndUnreachable(t *testing.T) {
+	network := NewMemoryNetwork()
+	a, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(network.Remote("a")))
+	require.NoError(t, err)
+	rb := network.Remote("b")
+	b, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(rb))
+	require.NoError(t, err)
+
+	target := b.SpawnFunc(func(*actor.Context) {}, "target")
+	terminated := make(chan *actor.PID, 1)
+	a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.Started:
+			c.Watch(target)
+		case *actor.Terminated:
+			terminated <- msg.PID
+		}
+	}, "watcher")
+	// 内存网络同步投递消息，Watch 已经在目标的收件箱中，先于 poisonPill 被处理。
+	<-b.Poison(target).Done()
+	select {
+	case pid := <-terminated:
+		assert.Equal(t, target, pid)
+	case <-time.After(time.Second):
+		t.Fatal("expected Terminated")
+	}
+
+	unreachable := make(chan string, 1)
+	unsubscribe := a.SubscribeFunc(func(event any) {
+		if evt, ok := event.(actor.RemoteUnreachableEvent); ok {
+			unreachable <- evt.ListenAddr
+		}
+	})
+	defer unsubscribe()
+	rb.Stop().Wait()
+	a.Send(actor.NewPID("b", "foo"), memPing{})
+	assert.Equal(t, "b", <-unreachable)
+}
+
+func TestTypeRegistryIsolation(t *testing.T) {
+	types := NewTypeRegistry()
+	s := VTProtoSerializer{Types: types}
+	data, err := s.Serialize(&actor.PID{Address: "a", ID: "b"})
+	require.NoError(t, err)
+	_, err = s.Deserialize(data, s.TypeName(&actor.PID{}))
+	assert.Error(t, err)
+
+	types.Register(&actor.PID{})
+	v1, err := s.Deserialize(data, s.TypeName(&actor.PID{}))
+	require.NoError(t, err)
+	v2, err := s.Deserialize(data, s.TypeName(&actor.PID{}))
+	require.NoError(t, err)
+	assert.True(t, v1.(*actor.PID).Equals(v2.(*actor.PID)))
+	assert.NotSame(t, v1, v2)
+}
@@ -0,0 +1,176 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// CertificateSource 在每次 TLS 握手时提供本节点的证书，使证书可以在运行时更换。
+type CertificateSource interface {
+	GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+// WithMutualTLS 配置双向 TLS：监听端要求并验证对方的客户端证书，拨号端出示自己的证书，
+// 双方都使用 roots 验证对方。certs 在每次握手时提供证书（例如 CertReloader），
+// 因此证书轮换不需要重启 Remote。对方通过验证后在事件流上广播
+// actor.RemotePeerAuthenticatedEvent。
+func (c Config) WithMutualTLS(certs CertificateSource, roots *x509.CertPool) Config {
+	c.TLSConfig = &tls.Config{
+		GetCertificate:       certs.GetCertificate,
+		GetClientCertificate: certs.GetClientCertificate,
+		ClientCAs:            roots,
+		RootCAs:              roots,
+		ClientAuth:           tls.RequireAndVerifyClientCert,
+		MinVersion:           tls.VersionTLS12,
+	}
+	return c
+}
+
+// CertReloader 从文件加载证书，并在文件被修改后的下一次握手时重新加载。
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewCertReloader 加载 certFile 和 keyFile 中的证书和私钥。
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 立即重新加载证书。加载失败时继续使用原来的证书。
+func (r *CertReloader) Reload() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return nil
+}
+
+// latestModTime 返回证书和私钥文件中较晚的修改时间。
+func (r *CertReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// certificate 返回当前的证书，文件被修改过时先重新加载。
+// 重新加载失败（例如文件只写入了一半）时返回原来的证书。
+func (r *CertReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	cert, modTime := r.cert, r.modTime
+	r.mu.Unlock()
+	if latest, err := r.latestModTime(); err == nil && latest.After(modTime) {
+		if err := r.Reload(); err == nil {
+			r.mu.Lock()
+			cert = r.cert
+			r.mu.Unlock()
+		}
+	}
+	if cert == nil {
+		return nil, errors.New("没有可用的证书")
+	}
+	return cert, nil
+}
+
+// GetCertificate 实现 CertificateSource，用于 tls.Config.GetCertificate。
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+// GetClientCertificate 实现 CertificateSource，用于 tls.Config.GetClientCertificate。
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate()
+}
+
+// peerIdentity 返回证书的身份：通用名称，没有时为第一个 URI 或 DNS 名称。
+func peerIdentity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	}
+	return ""
+}
+
+// peerListener 在 TLS 握手完成后广播对方的身份。
+type peerListener struct {
+	net.Listener
+	engine *actor.Engine
+	// timeout 是 TLS 握手的超时时间。
+	timeout time.Duration
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		return &peerConn{Conn: tlsConn, engine: l.engine, timeout: l.timeout}, nil
+	}
+	return conn, nil
+}
+
+// peerConn 在第一次读取时完成握手，这样握手不会阻塞 Accept。
+type peerConn struct {
+	*tls.Conn
+	engine  *actor.Engine
+	timeout time.Duration
+	once    sync.Once
+}
+
+func (c *peerConn) Read(b []byte) (int, error) {
+	c.once.Do(func() {
+		// 使用 context 而不是 deadline 限制握手，不影响外层（心跳）设置的读取 deadline。
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		defer cancel()
+		if err := c.Conn.HandshakeContext(ctx); err != nil {
+			return
+		}
+		state := c.Conn.ConnectionState()
+		if len(state.PeerCertificates) == 0 {
+			return
+		}
+		c.engine.BroadcastEvent(actor.RemotePeerAuthenticatedEvent{
+			RemoteAddr: c.Conn.RemoteAddr().String(),
+			Identity:   peerIdentity(state.PeerCertificates[0]),
+		})
+	})
+	return c.Conn.Read(b)
+}
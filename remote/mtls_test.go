@@ -0,0 +1,160 @@
+package remote
+
+import (
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCertFiles(t *testing.T, dir string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string) (string, string) {
+	t.Helper()
+	certPEM, keyPEM, err := generateCertPEM(ca, caKey, commonName)
+	require.NoError(t, err)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
+
+func leafCommonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestSend_MutualTLS(t *testing.T) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	ra, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "localhost"))
+	require.NoError(t, err)
+	rb, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "localhost"))
+	require.NoError(t, err)
+
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMutualTLS(ra, roots))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMutualTLS(rb, roots))
+	require.NoError(t, err)
+
+	authenticated := make(chan actor.RemotePeerAuthenticatedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemotePeerAuthenticatedEvent); ok {
+			select {
+			case authenticated <- ev:
+			default:
+			}
+		}
+	}, "peer_events")
+	a.Subscribe(sub)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			assert.Equal(t, []byte("foo"), msg.Data)
+			wg.Done()
+		}
+	}, "actor on a")
+	b.Send(pida, &TestMessage{Data: []byte("foo")})
+	wg.Wait()
+
+	select {
+	case ev := <-authenticated:
+		assert.Equal(t, "localhost", ev.Identity)
+		assert.NotEmpty(t, ev.RemoteAddr)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemotePeerAuthenticatedEvent")
+	}
+}
+
+func TestMutualTLSRejectsClientWithoutCertificate(t *testing.T) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	ra, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "localhost"))
+	require.NoError(t, err)
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMutualTLS(ra, roots))
+	require.NoError(t, err)
+	// b 只有服务端证书，拨号时不出示客户端证书。
+	rb, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "localhost"))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithTLS(&tls.Config{
+		GetCertificate: rb.GetCertificate,
+		RootCAs:        roots,
+	}))
+	require.NoError(t, err)
+
+	received := make(chan struct{}, 1)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			received <- struct{}{}
+		}
+	}, "actor on a")
+	b.Send(pida, &TestMessage{Data: []byte("foo")})
+
+	select {
+	case <-received:
+		t.Fatal("没有客户端证书的节点不应该能发送消息")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile := writeCertFiles(t, dir, ca, caKey, "first")
+
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", leafCommonName(t, cert))
+
+	// 重写文件并推后修改时间，下一次握手应使用新证书。
+	writeCertFiles(t, dir, ca, caKey, "second")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	cert, err = r.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", leafCommonName(t, cert))
+
+	// 文件损坏时继续使用原来的证书。
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	evenLater := later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, evenLater, evenLater))
+	cert, err = r.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", leafCommonName(t, cert))
+	assert.Error(t, r.Reload())
+}
+
+func TestPeerIdentity(t *testing.T) {
+	u, err := url.Parse("spiffe://cluster/node-a")
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", peerIdentity(&x509.Certificate{
+		Subject:  pkix.Name{CommonName: "node-a"},
+		DNSNames: []string{"a.local"},
+	}))
+	assert.Equal(t, "spiffe://cluster/node-a", peerIdentity(&x509.Certificate{URIs: []*url.URL{u}, DNSNames: []string{"a.local"}}))
+	assert.Equal(t, "a.local", peerIdentity(&x509.Certificate{DNSNames: []string{"a.local"}}))
+	assert.Equal(t, "", peerIdentity(&x509.Certificate{}))
+}
@@ -0,0 +1,290 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// protocolVersion 是本节点的远程协议版本，线路格式发生不兼容的变化时递增。
+	protocolVersion uint32 = 1
+	// minProtocolVersion 是本节点仍然兼容的最低协议版本。
+	minProtocolVersion uint32 = 1
+	// maxHandshakeSize 是协商帧的最大长度。
+	maxHandshakeSize = 64 * 1024
+)
+
+// negotiationMagic 标记协商握手的开始，监听端据此区分协商握手和不协商的节点直接发送的 Transport 数据。
+// 开头的 0xff 让旧版本节点的 drpc 无法解析出 varint 而立即关闭连接，gRPC 则会因为不是 HTTP/2 前言而关闭连接。
+const negotiationMagic = "\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xffACTR"
+
+// supportedCompressions 按偏好顺序列出本节点支持的压缩格式。序列化格式来自 WithSerializers 配置的序列化链。
+var supportedCompressions = []string{"none"}
+
+var (
+	// ErrIncompatiblePeer 表示远程节点的协议版本或能力与本节点不兼容。
+	ErrIncompatiblePeer = errors.New("远程节点不兼容")
+
+	// errLegacyPeer 表示对方在协商握手时关闭了连接，它是不支持协商的旧版本节点。
+	errLegacyPeer = errors.New("远程节点不支持协商")
+)
+
+// WithNegotiation 让流写入器在连接建立后（认证握手之后）与对方交换协议版本和能力：
+// 支持的序列化格式、压缩格式和单条消息的最大字节数。版本或能力不兼容时关闭连接，
+// 并在两端广播 actor.RemoteIncompatibleEvent，而不是在反序列化时报出难以理解的错误；
+// 发送的消息超过对方的 MaxMessageSize 时在本地丢弃，并广播 actor.RemoteMessageRejectedEvent。
+// 监听端总是响应协商握手，同时接受不协商的连接，因此可以逐个节点启用；
+// 对方是不支持协商的旧版本节点时，流写入器回退到不协商的连接。
+func (c Config) WithNegotiation(enabled bool) Config {
+	c.Negotiation = enabled
+	return c
+}
+
+// capabilities 是协商的结果。
+type capabilities struct {
+	version uint32
+	// serializers 是双方共同支持的序列化格式，按拨号端的偏好顺序排列。
+	serializers []string
+	compression string
+	// maxMessageSize 是对方接收的单条消息的最大字节数，0 表示不限制。
+	maxMessageSize int
+}
+
+// localHandshake 返回拨号端提供的协议版本和能力，序列化格式是序列化链中的格式。
+func (r *Remote) localHandshake() *Handshake {
+	return &Handshake{
+		Version:        protocolVersion,
+		Serializers:    serializerNames(r.config.codecs()),
+		Compressions:   supportedCompressions,
+		MaxMessageSize: uint64(r.config.MaxMessageSize),
+	}
+}
+
+// acceptingHandshake 返回监听端提供的协议版本和能力。监听端总是能够反序列化 "proto"，
+// 因此除了序列化链中的格式之外也接受 "proto"。
+func (r *Remote) acceptingHandshake() *Handshake {
+	local := r.localHandshake()
+	if !slices.Contains(local.Serializers, protoCodecName) {
+		local.Serializers = append(local.Serializers, protoCodecName)
+	}
+	return local
+}
+
+// serializerNames 返回序列化链中格式的名称。
+func serializerNames(codecs []Codec) []string {
+	names := make([]string, len(codecs))
+	for i, codec := range codecs {
+		names[i] = codec.Name()
+	}
+	return names
+}
+
+// agree 检查对方的协议版本，选出对方提供的、同时被 local 包含的序列化格式，
+// 以及对方提供的第一个本节点支持的压缩格式。
+func agree(peer, local *Handshake) (capabilities, error) {
+	if peer.Version < minProtocolVersion {
+		return capabilities{}, fmt.Errorf("%w: 协议版本 %d 过旧，最低支持 %d", ErrIncompatiblePeer, peer.Version, minProtocolVersion)
+	}
+	caps := capabilities{
+		version:        min(peer.Version, protocolVersion),
+		maxMessageSize: int(peer.MaxMessageSize),
+	}
+	for _, name := range peer.Serializers {
+		if slices.Contains(local.Serializers, name) && !slices.Contains(caps.serializers, name) {
+			caps.serializers = append(caps.serializers, name)
+		}
+	}
+	if len(caps.serializers) == 0 {
+		return capabilities{}, fmt.Errorf("%w: 没有共同的序列化格式 %v", ErrIncompatiblePeer, peer.Serializers)
+	}
+	var ok bool
+	if caps.compression, ok = choose(peer.Compressions, supportedCompressions); !ok {
+		return capabilities{}, fmt.Errorf("%w: 没有共同的压缩格式 %v", ErrIncompatiblePeer, peer.Compressions)
+	}
+	return caps, nil
+}
+
+// choose 返回 offered 中第一个被 supported 包含的格式。
+func choose(offered, supported []string) (string, bool) {
+	for _, name := range offered {
+		if slices.Contains(supported, name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// negotiate 在拨号端执行协商握手：发送 negotiationMagic 和本节点的能力，
+// 监听端回复选中的格式或拒绝的原因。对方在回复之前关闭连接时返回 errLegacyPeer。
+func negotiate(conn net.Conn, local *Handshake) (capabilities, error) {
+	payload, err := local.MarshalVT()
+	if err != nil {
+		return capabilities{}, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return capabilities{}, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := io.WriteString(conn, negotiationMagic); err != nil {
+		return capabilities{}, err
+	}
+	if err := writeFrame(conn, payload); err != nil {
+		return capabilities{}, err
+	}
+	reply, err := readFrame(conn, maxHandshakeSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+			return capabilities{}, errLegacyPeer
+		}
+		return capabilities{}, err
+	}
+	peer := &Handshake{}
+	if err := peer.UnmarshalVT(reply); err != nil {
+		return capabilities{}, err
+	}
+	if peer.Error != "" {
+		return capabilities{}, fmt.Errorf("%w: %s", ErrIncompatiblePeer, peer.Error)
+	}
+	return agree(peer, local)
+}
+
+// readPreamble 读取连接开头与 negotiationMagic 等长的字节，用于区分协商握手、中继注册和 Transport 数据。
+func readPreamble(conn net.Conn) ([]byte, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	magic := make([]byte, len(negotiationMagic))
+	if _, err := io.ReadFull(conn, magic); err != nil {
+		return nil, err
+	}
+	return magic, nil
+}
+
+// acceptNegotiation 在监听端读取 negotiationMagic 之后执行协商握手。
+func acceptNegotiation(conn net.Conn, local *Handshake) (net.Conn, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	payload, err := readFrame(conn, maxHandshakeSize)
+	if err != nil {
+		return nil, err
+	}
+	peer := &Handshake{}
+	if err := peer.UnmarshalVT(payload); err != nil {
+		return nil, err
+	}
+	caps, err := agree(peer, local)
+	reply := &Handshake{Version: protocolVersion}
+	if err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.Serializers = caps.serializers
+		reply.Compressions = []string{caps.compression}
+		reply.MaxMessageSize = local.MaxMessageSize
+	}
+	b, merr := reply.MarshalVT()
+	if merr != nil {
+		return nil, merr
+	}
+	if werr := writeFrame(conn, b); werr != nil {
+		return nil, werr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// acceptHandshake 是监听端的 handshakeFunc，依次执行节点过滤、Noise 和认证握手，然后根据连接开头的标记
+// 执行协商握手、中继注册或接收数据流。没有标记时对方没有启用协商，已经读取的字节在 Transport 读取时原样返回。
+func (r *Remote) acceptHandshake(conn net.Conn) (net.Conn, error) {
+	if !r.admitPeer(conn) {
+		return nil, errPeerBlocked
+	}
+	if r.config.NoiseKey != nil {
+		c, err := noiseHandshake(conn, r.config.NoiseKey, false)
+		if err != nil {
+			r.engine.BroadcastEvent(actor.RemoteAuthFailedEvent{
+				RemoteAddr: conn.RemoteAddr().String(),
+				Reason:     err.Error(),
+			})
+			return nil, err
+		}
+		conn = c
+	}
+	if r.config.Auth != nil {
+		if err := verifyPeer(conn, r.config.Auth); err != nil {
+			r.engine.BroadcastEvent(actor.RemoteAuthFailedEvent{
+				RemoteAddr: conn.RemoteAddr().String(),
+				Reason:     err.Error(),
+			})
+			return nil, err
+		}
+	}
+	magic, err := readPreamble(conn)
+	if err != nil {
+		return nil, err
+	}
+	switch string(magic) {
+	case negotiationMagic:
+		c, err := acceptNegotiation(conn, r.acceptingHandshake())
+		if errors.Is(err, ErrIncompatiblePeer) {
+			r.engine.BroadcastEvent(actor.RemoteIncompatibleEvent{
+				RemoteAddr: conn.RemoteAddr().String(),
+				Reason:     err.Error(),
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		return r.trackInbound(c), nil
+	case relayMagic:
+		return nil, r.acceptRelay(conn)
+	case transferMagic:
+		// 跟踪传输连接，BlockPeer 同时断开进行中的传输。
+		return nil, r.acceptTransfer(r.trackInbound(conn))
+	default:
+		return r.trackInbound(&prefixConn{Conn: conn, prefix: magic}), nil
+	}
+}
+
+// rejectOversized 在消息超过对方接收的上限时广播 RemoteMessageRejectedEvent 并返回 true。
+func (s *streamWriter) rejectOversized(stream *streamDeliver, tname string, size int) bool {
+	if max := s.caps.maxMessageSize; max <= 0 || size <= max {
+		return false
+	}
+	s.engine.BroadcastEvent(actor.RemoteMessageRejectedEvent{
+		Sender:   stream.sender,
+		Target:   stream.target,
+		TypeName: tname,
+		Size:     size,
+	})
+	return true
+}
+
+// prefixConn 在读取底层连接之前先返回 prefix 中的字节。
+type prefixConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixConn) Read(b []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(b, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
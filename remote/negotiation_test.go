@@ -0,0 +1,160 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiation(t *testing.T) {
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithNegotiation(true))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMaxMessageSize(64))
+	require.NoError(t, err)
+
+	rejected := make(chan actor.RemoteMessageRejectedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteMessageRejectedEvent); ok {
+			rejected <- ev
+		}
+	}, "events")
+	a.Subscribe(sub)
+
+	got := make(chan string, 2)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo")
+
+	// 超过 b 的 MaxMessageSize 的消息在 a 上被丢弃，其余消息照常投递。
+	a.Send(pidb, testMsg("small"))
+	a.Send(pidb, &TestMessage{Data: bytes.Repeat([]byte("x"), 128)})
+	a.Send(pidb, testMsg("after"))
+
+	select {
+	case ev := <-rejected:
+		assert.Equal(t, pidb.ID, ev.Target.ID)
+		assert.Equal(t, "remote.TestMessage", ev.TypeName)
+		assert.Greater(t, ev.Size, 64)
+	case <-time.After(2 * time.Second):
+		t.Fatal("发送端没有广播 RemoteMessageRejectedEvent")
+	}
+	for _, want := range []string{"small", "after"} {
+		select {
+		case s := <-got:
+			assert.Equal(t, want, s)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
+
+func TestNegotiationIncompatible(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(server, make([]byte, len(negotiationMagic)))
+		require.NoError(t, err)
+		_, err = acceptNegotiation(server, &Handshake{Version: protocolVersion})
+		errc <- err
+	}()
+	_, err := negotiate(client, &Handshake{
+		Version:      protocolVersion,
+		Serializers:  []string{"json"},
+		Compressions: supportedCompressions,
+	})
+	assert.ErrorIs(t, err, ErrIncompatiblePeer)
+	assert.ErrorIs(t, <-errc, ErrIncompatiblePeer)
+
+	local := &Handshake{Version: protocolVersion, Serializers: []string{"vtproto", "proto"}, Compressions: supportedCompressions}
+	_, err = agree(&Handshake{Version: 0, Serializers: []string{"proto"}, Compressions: supportedCompressions}, local)
+	assert.ErrorIs(t, err, ErrIncompatiblePeer)
+	caps, err := agree(&Handshake{
+		Version:        protocolVersion + 1,
+		Serializers:    []string{"json", "proto", "vtproto"},
+		Compressions:   []string{"zstd", "none"},
+		MaxMessageSize: 1024,
+	}, local)
+	require.NoError(t, err)
+	assert.Equal(t, capabilities{version: protocolVersion, serializers: []string{"proto", "vtproto"}, compression: "none", maxMessageSize: 1024}, caps)
+}
+
+func TestNegotiationSerializers(t *testing.T) {
+	a, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().
+		WithNegotiation(true).
+		WithSerializers(VTProtoSerializer{}, ProtoSerializer{}, JSONSerializer{}))
+	require.NoError(t, err)
+	// b 只配置了默认的 ProtoSerializer。
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vtproto", "proto", "json"}, ra.localHandshake().Serializers)
+
+	failed := make(chan actor.RemoteDeserializationErrorEvent, 1)
+	sub := b.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteDeserializationErrorEvent); ok {
+			failed <- ev
+		}
+	}, "events")
+	b.Subscribe(sub)
+	got := make(chan any, 3)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case *TestMessage, *jsonOrder:
+			got <- msg
+		}
+	}, "foo")
+
+	// a 的序列化链被限制为 b 支持的 "proto"：protobuf 消息不再使用 vtproto，
+	// 只有 JSON 能序列化的消息在 a 上被丢弃。
+	a.Send(pidb, testMsg("first"))
+	a.Send(pidb, &jsonOrder{ID: "1"})
+	a.Send(pidb, testMsg("last"))
+	for _, want := range []string{"first", "last"} {
+		select {
+		case msg := <-got:
+			assert.Equal(t, testMsg(want).Data, msg.(*TestMessage).Data)
+		case ev := <-failed:
+			t.Fatalf("b 无法反序列化 %s: %v", ev.TypeName, ev.Err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
+
+func TestNegotiationLegacyPeer(t *testing.T) {
+	// 不支持协商的旧版本节点直接在监听的连接上运行 Transport。
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan *Envelope, 1)
+	go NewDRPCTransport(0).Serve(ctx, ln, func(env *Envelope) error {
+		if !isHeartbeat(env) {
+			received <- env.CloneVT()
+		}
+		return nil
+	})
+
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithNegotiation(true))
+	require.NoError(t, err)
+	a.Send(actor.NewPID(ln.Addr().String(), "foo"), testMsg("legacy"))
+
+	select {
+	case env := <-received:
+		require.Len(t, env.Messages, 1)
+		assert.Equal(t, "remote.TestMessage", env.TypeNames[0])
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有回退到不协商的连接")
+	}
+}
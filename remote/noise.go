@@ -0,0 +1,341 @@
+package remote
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// NoiseKeySize 是 WithNoise 使用的预共享密钥的长度。
+const NoiseKeySize = 32
+
+const (
+	// noiseProtocolName 是使用的 Noise 协议：NNpsk0 握手模式，双方只凭预共享密钥互相认证，
+	// 临时密钥交换提供前向保密。
+	noiseProtocolName = "Noise_NNpsk0_25519_AESGCM_SHA256"
+	noisePrologue     = "actor-remote"
+	noiseMaxFrameSize = 65535 // Noise 规定的单条消息的最大长度
+	noiseTagSize      = 16
+	noiseMaxPlaintext = noiseMaxFrameSize - noiseTagSize
+)
+
+// errNoiseHandshake 表示对方没有使用相同的预共享密钥。
+var errNoiseHandshake = errors.New("Noise 握手失败，预共享密钥不一致")
+
+// WithNoise 使用基于 Noise 框架（Noise_NNpsk0_25519_AESGCM_SHA256）的加密代替 TLS，
+// 适合没有 CA 的家庭实验室和边缘部署：集群中的节点共享一个 NoiseKeySize 字节的密钥，
+// 持有相同密钥的节点互相认证，连接使用每次握手生成的临时密钥加密。
+// 密钥可以用 NewNoiseKey 生成。不能与 WithTLS 同时使用。
+func (c Config) WithNoise(key []byte) Config {
+	c.NoiseKey = key
+	return c
+}
+
+// NewNoiseKey 生成一个随机的 Noise 预共享密钥。
+func NewNoiseKey() ([]byte, error) {
+	key := make([]byte, NoiseKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// noiseCipher 是 Noise 的 CipherState，每加密或解密一条消息 nonce 加一。
+type noiseCipher struct {
+	aead  cipher.AEAD
+	nonce uint64
+}
+
+func newNoiseCipher(key []byte) (*noiseCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &noiseCipher{aead: aead}, nil
+}
+
+// next 返回 AESGCM 的 nonce：4 个零字节之后是大端序的 64 位计数。
+func (c *noiseCipher) next() []byte {
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[4:], c.nonce)
+	c.nonce++
+	return nonce[:]
+}
+
+func (c *noiseCipher) encrypt(dst, ad, plaintext []byte) []byte {
+	return c.aead.Seal(dst, c.next(), plaintext, ad)
+}
+
+func (c *noiseCipher) decrypt(dst, ad, ciphertext []byte) ([]byte, error) {
+	return c.aead.Open(dst, c.next(), ciphertext, ad)
+}
+
+// noiseState 是 Noise 握手期间的 SymmetricState。
+type noiseState struct {
+	ck, h []byte
+	k     *noiseCipher
+}
+
+func newNoiseState() *noiseState {
+	h := make([]byte, sha256.Size)
+	if len(noiseProtocolName) <= sha256.Size {
+		copy(h, noiseProtocolName)
+	} else {
+		sum := sha256.Sum256([]byte(noiseProtocolName))
+		h = sum[:]
+	}
+	return &noiseState{ck: h, h: h}
+}
+
+func (s *noiseState) mixHash(data []byte) {
+	hash := sha256.New()
+	hash.Write(s.h)
+	hash.Write(data)
+	s.h = hash.Sum(nil)
+}
+
+func (s *noiseState) mixKey(ikm []byte) error {
+	out := noiseHKDF(s.ck, ikm, 2)
+	s.ck = out[0]
+	k, err := newNoiseCipher(out[1])
+	s.k = k
+	return err
+}
+
+func (s *noiseState) mixKeyAndHash(ikm []byte) error {
+	out := noiseHKDF(s.ck, ikm, 3)
+	s.ck = out[0]
+	s.mixHash(out[1])
+	k, err := newNoiseCipher(out[2])
+	s.k = k
+	return err
+}
+
+func (s *noiseState) encryptAndHash(plaintext []byte) []byte {
+	ciphertext := s.k.encrypt(nil, s.h, plaintext)
+	s.mixHash(ciphertext)
+	return ciphertext
+}
+
+func (s *noiseState) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := s.k.decrypt(nil, s.h, ciphertext)
+	if err != nil {
+		return nil, errNoiseHandshake
+	}
+	s.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+// split 返回发起方发送和接收使用的 CipherState。
+func (s *noiseState) split() (*noiseCipher, *noiseCipher, error) {
+	out := noiseHKDF(s.ck, nil, 2)
+	c1, err := newNoiseCipher(out[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	c2, err := newNoiseCipher(out[1])
+	return c1, c2, err
+}
+
+// noiseHKDF 是 Noise 规范中的 HKDF，返回 n 个输出。
+func noiseHKDF(ck, ikm []byte, n int) [][]byte {
+	mac := hmac.New(sha256.New, ck)
+	mac.Write(ikm)
+	tempKey := mac.Sum(nil)
+	out := make([][]byte, n)
+	var prev []byte
+	for i := range out {
+		mac := hmac.New(sha256.New, tempKey)
+		mac.Write(prev)
+		mac.Write([]byte{byte(i + 1)})
+		prev = mac.Sum(nil)
+		out[i] = prev
+	}
+	return out
+}
+
+// noiseHandshake 在 conn 上执行 NNpsk0 握手，返回加密的连接：
+//
+//	-> psk, e
+//	<- e, ee
+//
+// 两条握手消息都带有一个空的加密负载，双方据此确认对方持有相同的预共享密钥。
+func noiseHandshake(conn net.Conn, psk []byte, initiator bool) (net.Conn, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	s := newNoiseState()
+	s.mixHash([]byte(noisePrologue))
+	if err := s.mixKeyAndHash(psk); err != nil {
+		return nil, err
+	}
+	e, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	// writeEphemeral 和 readEphemeral 处理 e，PSK 模式下临时公钥还要参与 MixKey。
+	writeEphemeral := func() ([]byte, error) {
+		pub := e.PublicKey().Bytes()
+		s.mixHash(pub)
+		return pub, s.mixKey(pub)
+	}
+	readEphemeral := func(msg []byte) (*ecdh.PublicKey, error) {
+		if len(msg) != 32+noiseTagSize {
+			return nil, errNoiseHandshake
+		}
+		re, err := ecdh.X25519().NewPublicKey(msg[:32])
+		if err != nil {
+			return nil, err
+		}
+		s.mixHash(msg[:32])
+		return re, s.mixKey(msg[:32])
+	}
+	mixDH := func(re *ecdh.PublicKey) error {
+		secret, err := e.ECDH(re)
+		if err != nil {
+			return err
+		}
+		return s.mixKey(secret)
+	}
+
+	if initiator {
+		msg, err := writeEphemeral()
+		if err != nil {
+			return nil, err
+		}
+		if err := writeNoiseFrame(conn, append(msg, s.encryptAndHash(nil)...)); err != nil {
+			return nil, err
+		}
+		reply, err := readNoiseFrame(conn)
+		if err != nil {
+			return nil, err
+		}
+		re, err := readEphemeral(reply)
+		if err != nil {
+			return nil, err
+		}
+		if err := mixDH(re); err != nil {
+			return nil, err
+		}
+		if _, err := s.decryptAndHash(reply[32:]); err != nil {
+			return nil, err
+		}
+		send, recv, err := s.split()
+		if err != nil {
+			return nil, err
+		}
+		return &noiseConn{Conn: conn, send: send, recv: recv}, nil
+	}
+
+	msg, err := readNoiseFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	re, err := readEphemeral(msg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.decryptAndHash(msg[32:]); err != nil {
+		return nil, err
+	}
+	reply, err := writeEphemeral()
+	if err != nil {
+		return nil, err
+	}
+	if err := mixDH(re); err != nil {
+		return nil, err
+	}
+	if err := writeNoiseFrame(conn, append(reply, s.encryptAndHash(nil)...)); err != nil {
+		return nil, err
+	}
+	recv, send, err := s.split()
+	if err != nil {
+		return nil, err
+	}
+	return &noiseConn{Conn: conn, send: send, recv: recv}, nil
+}
+
+// writeNoiseFrame 写出一个以 2 字节长度为前缀的 Noise 消息。
+func writeNoiseFrame(w io.Writer, msg []byte) error {
+	frame := binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(msg)), uint16(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+// readNoiseFrame 读取一个以 2 字节长度为前缀的 Noise 消息。
+func readNoiseFrame(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// noiseConn 是 Noise 握手之后加密的连接。写入的数据被切分为不超过 noiseMaxPlaintext 的消息，
+// 每条消息单独加密。
+type noiseConn struct {
+	net.Conn
+
+	rmu     sync.Mutex
+	recv    *noiseCipher
+	pending []byte // 已经解密但还没有被读取的数据
+
+	wmu  sync.Mutex
+	send *noiseCipher
+}
+
+func (c *noiseConn) Read(b []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+	if len(c.pending) == 0 {
+		msg, err := readNoiseFrame(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		plaintext, err := c.recv.decrypt(msg[:0], nil, msg)
+		if err != nil {
+			return 0, err
+		}
+		c.pending = plaintext
+	}
+	n := copy(b, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+func (c *noiseConn) Write(b []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	written := 0
+	for len(b) > 0 {
+		chunk := b[:min(len(b), noiseMaxPlaintext)]
+		frame := make([]byte, 2, 2+len(chunk)+noiseTagSize)
+		frame = c.send.encrypt(frame, nil, chunk)
+		binary.BigEndian.PutUint16(frame, uint16(len(frame)-2))
+		if _, err := c.Conn.Write(frame); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		b = b[len(chunk):]
+	}
+	return written, nil
+}
@@ -0,0 +1,145 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoise(t *testing.T) {
+	key, err := NewNoiseKey()
+	require.NoError(t, err)
+	for name, config := range map[string]Config{
+		"drpc": NewConfig(),
+		"grpc": NewConfig().WithTransport(NewGRPCTransport()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := config.WithNoise(key)
+			a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+			b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+
+			got := make(chan string, 1)
+			pidb := b.SpawnFunc(func(c *actor.Context) {
+				if msg, ok := c.Message().(*TestMessage); ok {
+					got <- string(msg.Data)
+				}
+			}, "foo")
+			a.Send(pidb, testMsg("secret"))
+			select {
+			case s := <-got:
+				assert.Equal(t, "secret", s)
+			case <-time.After(2 * time.Second):
+				t.Fatal("没有收到消息")
+			}
+		})
+	}
+}
+
+func TestNoiseWrongKey(t *testing.T) {
+	keyA, err := NewNoiseKey()
+	require.NoError(t, err)
+	keyB, err := NewNoiseKey()
+	require.NoError(t, err)
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithNoise(keyA))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithNoise(keyB))
+	require.NoError(t, err)
+
+	failed := make(chan actor.RemoteAuthFailedEvent, 1)
+	subb := b.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteAuthFailedEvent); ok {
+			failed <- ev
+		}
+	}, "events")
+	b.Subscribe(subb)
+	unreachable := make(chan struct{}, 1)
+	suba := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(actor.RemoteUnreachableEvent); ok {
+			unreachable <- struct{}{}
+		}
+	}, "events")
+	a.Subscribe(suba)
+
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			t.Error("密钥不一致时不应该收到消息")
+		}
+	}, "foo")
+	a.Send(pidb, testMsg("secret"))
+
+	select {
+	case ev := <-failed:
+		assert.Equal(t, errNoiseHandshake.Error(), ev.Reason)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到 RemoteAuthFailedEvent")
+	}
+	select {
+	case <-unreachable:
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到 RemoteUnreachableEvent")
+	}
+}
+
+func TestNoiseConn(t *testing.T) {
+	key, err := NewNoiseKey()
+	require.NoError(t, err)
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// 记录线路上的数据，确认明文没有出现在其中。
+	wire := &bytes.Buffer{}
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := noiseHandshake(server, key, false)
+		require.NoError(t, err)
+		accepted <- conn
+	}()
+	conn, err := noiseHandshake(&recordConn{Conn: client, w: wire}, key, true)
+	require.NoError(t, err)
+	peer := <-accepted
+
+	// 超过单条 Noise 消息上限的数据被切分为多条消息。
+	data := bytes.Repeat([]byte("plaintext"), 20000)
+	go func() {
+		_, err := conn.Write(data)
+		assert.NoError(t, err)
+	}()
+	buf := make([]byte, len(data))
+	_, err = io.ReadFull(peer, buf)
+	require.NoError(t, err)
+	assert.Equal(t, data, buf)
+	assert.Greater(t, wire.Len(), len(data))
+	assert.False(t, bytes.Contains(wire.Bytes(), []byte("plaintextplaintext")))
+}
+
+func TestNoiseConfig(t *testing.T) {
+	_, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(New(getRandomLocalhostAddr(), NewConfig().WithNoise([]byte("short")))))
+	assert.Error(t, err)
+
+	key, err := NewNoiseKey()
+	require.NoError(t, err)
+	config := NewConfig().WithNoise(key).WithTLS(&tls.Config{})
+	_, err = actor.NewEngine(actor.NewEngineConfig().WithRemote(New(getRandomLocalhostAddr(), config)))
+	assert.Error(t, err)
+}
+
+// recordConn 把写入的数据复制到 w。
+type recordConn struct {
+	net.Conn
+	w *bytes.Buffer
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	c.w.Write(b)
+	return c.Conn.Write(b)
+}
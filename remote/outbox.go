@@ -0,0 +1,144 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// WithOutbox 为每个远程地址启用发件箱：在连接建立之前（包括节点不可达期间），
+// 发往该地址的消息最多缓存 size 条，连接建立后按顺序发送；超过 size 时最早的消息进入死信，
+// 缓存超过 maxAge 的消息也进入死信（maxAge <= 0 表示不限制）。
+// 节点不可达期间，流路由器按照 WithReconnectBackoff 的间隔重新连接，而不是为每条新消息创建流写入器。
+// size <= 0 时不启用，这也是默认值。
+func (c Config) WithOutbox(size int, maxAge time.Duration) Config {
+	c.OutboxSize = size
+	c.OutboxMaxAge = maxAge
+	return c
+}
+
+// outboxRedial 要求流路由器重新连接 address，以发送发件箱中的消息。
+type outboxRedial struct {
+	address string
+}
+
+type outboxEntry struct {
+	msg *streamDeliver
+	at  time.Time
+}
+
+// outbox 缓存发往一个尚未连接的地址的消息。
+type outbox struct {
+	entries []outboxEntry
+	// redialing 表示已经安排了一次 outboxRedial。
+	redialing bool
+	// attempts 是连接建立之前已经安排的重新连接次数，决定下一次重新连接的间隔。
+	attempts int
+}
+
+// outboxEnabled 报告是否启用了发件箱。
+func (s *streamRouter) outboxEnabled() bool {
+	return s.remote.config.OutboxSize > 0
+}
+
+// buffer 把消息放入 address 的发件箱，发件箱已满时最早的消息进入死信。
+func (s *streamRouter) buffer(ob *outbox, msg *streamDeliver) {
+	if len(ob.entries) >= s.remote.config.OutboxSize {
+		s.deadLetter(ob.entries[0].msg)
+		ob.entries[0] = outboxEntry{}
+		ob.entries = ob.entries[1:]
+	}
+	ob.entries = append(ob.entries, outboxEntry{msg: msg, at: time.Now()})
+}
+
+// expireOutbox 把发件箱中超过 OutboxMaxAge 的消息移入死信。
+func (s *streamRouter) expireOutbox(ob *outbox) {
+	maxAge := s.remote.config.OutboxMaxAge
+	if maxAge <= 0 {
+		return
+	}
+	n := 0
+	for _, entry := range ob.entries {
+		if time.Since(entry.at) > maxAge {
+			s.deadLetter(entry.msg)
+			continue
+		}
+		ob.entries[n] = entry
+		n++
+	}
+	clear(ob.entries[n:])
+	ob.entries = ob.entries[:n]
+}
+
+// handleConnected 在到 address 的连接建立后发送发件箱中的消息，
+// 如果该地址之前不可达，广播 RemoteReconnectedEvent。
+func (s *streamRouter) handleConnected(address string) {
+	replayed := 0
+	if ob, ok := s.outboxes[address]; ok {
+		swpid, ok := s.streams[address]
+		if !ok {
+			return
+		}
+		s.expireOutbox(ob)
+		for _, entry := range ob.entries {
+			s.send(swpid, entry.msg)
+		}
+		replayed = len(ob.entries)
+		delete(s.outboxes, address)
+	}
+	if s.down[address] {
+		delete(s.down, address)
+		s.engine.BroadcastEvent(actor.RemoteReconnectedEvent{
+			ListenAddr: address,
+			Replayed:   replayed,
+		})
+	}
+}
+
+// handleDisconnected 记录 address 不可达。发件箱中有消息时安排重新连接。
+func (s *streamRouter) handleDisconnected(address string) {
+	s.down[address] = true
+	if !s.outboxEnabled() {
+		return
+	}
+	ob, ok := s.outboxes[address]
+	if !ok {
+		ob = &outbox{}
+		s.outboxes[address] = ob
+	}
+	s.scheduleRedial(address, ob)
+}
+
+// scheduleRedial 按照重新连接的退避间隔发送 outboxRedial，发件箱为空时什么也不做。
+func (s *streamRouter) scheduleRedial(address string, ob *outbox) {
+	if ob.redialing || len(ob.entries) == 0 {
+		return
+	}
+	ob.redialing = true
+	delay := s.remote.config.reconnectDelay(ob.attempts)
+	ob.attempts++
+	time.AfterFunc(delay, func() {
+		s.engine.Send(s.pid, &outboxRedial{address: address})
+	})
+}
+
+// handleRedial 为发件箱中还有消息的地址创建新的流写入器。
+func (s *streamRouter) handleRedial(address string) {
+	ob, ok := s.outboxes[address]
+	if !ok {
+		return
+	}
+	ob.redialing = false
+	s.expireOutbox(ob)
+	if len(ob.entries) == 0 {
+		return
+	}
+	if _, ok := s.streams[address]; ok {
+		return
+	}
+	if !s.allow(address) {
+		s.scheduleRedial(address, ob)
+		return
+	}
+	s.spawnWriter(address)
+}
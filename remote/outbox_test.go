@@ -0,0 +1,99 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxReplay(t *testing.T) {
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithOutbox(16, 0))
+	require.NoError(t, err)
+
+	unreachable := make(chan struct{}, 1)
+	reconnected := make(chan actor.RemoteReconnectedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.RemoteUnreachableEvent:
+			select {
+			case unreachable <- struct{}{}:
+			default:
+			}
+		case actor.RemoteReconnectedEvent:
+			reconnected <- msg
+		}
+	}, "events")
+	a.Subscribe(sub)
+
+	bAddr := getRandomLocalhostAddr()
+	target := actor.NewPID(bAddr, "foo/1")
+	for _, s := range []string{"a", "b", "c"} {
+		a.Send(target, testMsg(s))
+	}
+	select {
+	case <-unreachable:
+	case <-time.After(10 * time.Second):
+		t.Fatal("没有收到 RemoteUnreachableEvent")
+	}
+
+	// 对方恢复后，发件箱中的消息按顺序补发。
+	b, _, err := makeRemoteEngine(bAddr)
+	require.NoError(t, err)
+	got := make(chan string, 3)
+	b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo", actor.WithID("1"))
+
+	select {
+	case ev := <-reconnected:
+		assert.Equal(t, bAddr, ev.ListenAddr)
+		assert.Equal(t, 3, ev.Replayed)
+	case <-time.After(10 * time.Second):
+		t.Fatal("没有收到 RemoteReconnectedEvent")
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		select {
+		case s := <-got:
+			assert.Equal(t, want, s)
+		case <-time.After(time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
+
+func TestOutboxBounds(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	deadLetters := make(chan string, 4)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.DeadLetterEvent); ok {
+			deadLetters <- string(ev.Message.(*TestMessage).Data)
+		}
+	}, "events")
+	e.Subscribe(sub)
+
+	s := &streamRouter{engine: e, remote: &Remote{config: NewConfig().WithOutbox(2, 50*time.Millisecond)}}
+	target := actor.NewPID("127.0.0.1:4000", "foo")
+	ob := &outbox{}
+	for _, data := range []string{"a", "b", "c"} {
+		s.buffer(ob, newStreamDeliver(target, testMsg(data), nil))
+	}
+	// 发件箱已满时最早的消息进入死信。
+	require.Len(t, ob.entries, 2)
+	assert.Equal(t, "b", string(ob.entries[0].msg.msg.(*TestMessage).Data))
+	assert.Equal(t, "a", <-deadLetters)
+
+	// 超过 maxAge 的消息进入死信。
+	time.Sleep(100 * time.Millisecond)
+	s.buffer(ob, newStreamDeliver(target, testMsg("d"), nil))
+	s.expireOutbox(ob)
+	require.Len(t, ob.entries, 1)
+	assert.Equal(t, "d", string(ob.entries[0].msg.msg.(*TestMessage).Data))
+	assert.Equal(t, "b", <-deadLetters)
+	assert.Equal(t, "c", <-deadLetters)
+}
@@ -0,0 +1,117 @@
+package remote
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// errPeerBlocked 表示连接被 Block 或 PeerFilter 拒绝。
+var errPeerBlocked = errors.New("远程节点被屏蔽")
+
+// PeerFilter 决定是否接受远程节点的连接。addr 是对方的网络地址（"ip:port"），
+// identity 是对方 TLS 客户端证书的身份（与 RemotePeerAuthenticatedEvent 相同），没有证书时为空。
+type PeerFilter func(addr, identity string) bool
+
+// WithPeerFilter 设置在接受连接时调用的过滤器，返回 false 的连接在 Noise、认证和协商握手之前被关闭，
+// 并在事件流上广播 actor.RemotePeerBlockedEvent。运行时可以用 Remote.Block 额外屏蔽节点。
+func (c Config) WithPeerFilter(filter PeerFilter) Config {
+	c.PeerFilter = filter
+	return c
+}
+
+// Block 拒绝来自 addr 的连接，并关闭已经建立的连接。addr 是 IP 地址（屏蔽该主机的所有连接）
+// 或 "ip:port"（只屏蔽这个地址）。
+func (r *Remote) Block(addr string) {
+	r.blocked.Store(addr, struct{}{})
+	r.inbound.Range(func(key, _ any) bool {
+		if conn := key.(*inboundConn); r.isBlocked(conn.RemoteAddr().String()) {
+			_ = conn.Close()
+		}
+		return true
+	})
+}
+
+// Unblock 撤销 Block(addr)。
+func (r *Remote) Unblock(addr string) {
+	r.blocked.Delete(addr)
+}
+
+// isBlocked 报告 addr 或它的主机是否被 Block 屏蔽。
+func (r *Remote) isBlocked(addr string) bool {
+	if _, ok := r.blocked.Load(addr); ok {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	_, ok := r.blocked.Load(host)
+	return ok
+}
+
+// admitPeer 在握手之前检查连接是否被屏蔽或被 PeerFilter 拒绝，拒绝时广播 RemotePeerBlockedEvent。
+func (r *Remote) admitPeer(conn net.Conn) bool {
+	addr := conn.RemoteAddr().String()
+	if r.isBlocked(addr) {
+		r.engine.BroadcastEvent(actor.RemotePeerBlockedEvent{RemoteAddr: addr})
+		return false
+	}
+	if r.config.PeerFilter == nil {
+		return true
+	}
+	identity, err := tlsIdentity(conn)
+	if err != nil {
+		return false
+	}
+	if !r.config.PeerFilter(addr, identity) {
+		r.engine.BroadcastEvent(actor.RemotePeerBlockedEvent{RemoteAddr: addr, Identity: identity})
+		return false
+	}
+	return true
+}
+
+// tlsIdentity 完成 TLS 握手并返回对方证书的身份，不是 TLS 连接或对方没有证书时返回空字符串。
+func tlsIdentity(conn net.Conn) (string, error) {
+	if kc, ok := conn.(*keepaliveConn); ok {
+		conn = kc.Conn
+	}
+	pc, ok := conn.(*peerConn)
+	if !ok {
+		return "", nil
+	}
+	if err := pc.SetDeadline(time.Now().Add(pc.timeout)); err != nil {
+		return "", err
+	}
+	if err := pc.Conn.Handshake(); err != nil {
+		return "", err
+	}
+	if err := pc.SetDeadline(time.Time{}); err != nil {
+		return "", err
+	}
+	if certs := pc.ConnectionState().PeerCertificates; len(certs) > 0 {
+		return peerIdentity(certs[0]), nil
+	}
+	return "", nil
+}
+
+// inboundConn 是交给 Transport 的入站连接，Block 借此关闭来自被屏蔽节点的连接。
+type inboundConn struct {
+	net.Conn
+	remote    *Remote
+	closeOnce sync.Once
+}
+
+func (r *Remote) trackInbound(conn net.Conn) net.Conn {
+	c := &inboundConn{Conn: conn, remote: r}
+	r.inbound.Store(c, struct{}{})
+	return c
+}
+
+func (c *inboundConn) Close() error {
+	c.closeOnce.Do(func() { c.remote.inbound.Delete(c) })
+	return c.Conn.Close()
+}
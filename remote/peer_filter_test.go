@@ -0,0 +1,133 @@
+package remote
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribeBlocked 订阅 e 上的 RemotePeerBlockedEvent。
+func subscribeBlocked(e *actor.Engine) chan actor.RemotePeerBlockedEvent {
+	blocked := make(chan actor.RemotePeerBlockedEvent, 16)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemotePeerBlockedEvent); ok {
+			select {
+			case blocked <- ev:
+			default:
+			}
+		}
+	}, "blocked_events")
+	e.Subscribe(sub)
+	return blocked
+}
+
+func TestPeerFilter(t *testing.T) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	ra, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "intruder"))
+	require.NoError(t, err)
+	rb, err := NewCertReloader(writeCertFiles(t, t.TempDir(), ca, caKey, "localhost"))
+	require.NoError(t, err)
+
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMutualTLS(ra, roots))
+	require.NoError(t, err)
+	filter := func(addr, identity string) bool { return identity != "intruder" }
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithMutualTLS(rb, roots).WithPeerFilter(filter))
+	require.NoError(t, err)
+	blocked := subscribeBlocked(b)
+
+	got := make(chan struct{}, 1)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			got <- struct{}{}
+		}
+	}, "foo")
+	a.Send(pidb, testMsg("foo"))
+
+	select {
+	case ev := <-blocked:
+		assert.Equal(t, "intruder", ev.Identity)
+		assert.NotEmpty(t, ev.RemoteAddr)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有广播 RemotePeerBlockedEvent")
+	}
+	select {
+	case <-got:
+		t.Fatal("被拒绝的节点的消息不应该送达")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBlock(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	blocked := subscribeBlocked(b)
+
+	got := make(chan string, 16)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo")
+	receive := func() string {
+		select {
+		case s := <-got:
+			return s
+		case <-time.After(2 * time.Second):
+			return ""
+		}
+	}
+	a.Send(pidb, testMsg("before"))
+	require.Equal(t, "before", receive())
+
+	// 屏蔽主机会关闭已经建立的连接，之后的连接在握手之前被拒绝。
+	rb.Block("127.0.0.1")
+	rb.Block("::1")
+	assert.Eventually(t, func() bool {
+		n := 0
+		rb.inbound.Range(func(_, _ any) bool { n++; return true })
+		return n == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	a.Send(pidb, testMsg("blocked"))
+	select {
+	case <-blocked:
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有广播 RemotePeerBlockedEvent")
+	}
+
+	rb.Unblock("127.0.0.1")
+	rb.Unblock("::1")
+	assert.Eventually(t, func() bool {
+		a.Send(pidb, testMsg("after"))
+		for {
+			select {
+			case s := <-got:
+				if s == "after" {
+					return true
+				}
+			case <-time.After(100 * time.Millisecond):
+				return false
+			}
+		}
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestIsBlocked(t *testing.T) {
+	r := New(getRandomLocalhostAddr(), NewConfig())
+	r.Block("10.0.0.1")
+	r.Block("10.0.0.2:4000")
+	assert.True(t, r.isBlocked("10.0.0.1:1234"))
+	assert.True(t, r.isBlocked("10.0.0.2:4000"))
+	assert.False(t, r.isBlocked("10.0.0.2:4001"))
+	r.Unblock("10.0.0.1")
+	assert.False(t, r.isBlocked("10.0.0.1:1234"))
+}
@@ -0,0 +1,72 @@
+package remote
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// Prioritized 由需要优先发送的消息实现，例如集群的成员协议消息。RemotePriority 返回 true 的消息
+// 绕过流写入器收件箱中排队的普通消息，在下一次写出时发送，大量的应用消息因此不会推迟它们。
+// 启用顺序保证时，发往同一目标的优先消息仍然在该目标之前的消息之后投递。
+type Prioritized interface {
+	RemotePriority() bool
+}
+
+// isPriority 报告 msg 是否是优先消息。
+func isPriority(msg any) bool {
+	msg, _ = unwrapTrace(msg)
+	p, ok := msg.(Prioritized)
+	return ok && p.RemotePriority()
+}
+
+// streamPriority 在优先通道中有消息时唤醒空闲的流写入器。
+type streamPriority struct{}
+
+// priorityLane 是流写入器的优先通道。流路由器把优先消息放入通道，流写入器在处理收件箱中的
+// 每条消息之前取出它们。
+type priorityLane struct {
+	mu      sync.Mutex
+	msgs    []*streamDeliver
+	pending atomic.Bool
+}
+
+func (l *priorityLane) push(msg *streamDeliver) {
+	l.mu.Lock()
+	l.msgs = append(l.msgs, msg)
+	l.pending.Store(true)
+	l.mu.Unlock()
+}
+
+func (l *priorityLane) take() []*streamDeliver {
+	if !l.pending.Load() {
+		return nil
+	}
+	l.mu.Lock()
+	msgs := l.msgs
+	l.msgs = nil
+	l.pending.Store(false)
+	l.mu.Unlock()
+	return msgs
+}
+
+// addPriority 把优先通道中的消息加入当前批次，返回是否有这样的消息。
+func (s *streamWriter) addPriority() bool {
+	msgs := s.priority.take()
+	for _, msg := range msgs {
+		s.add(msg)
+	}
+	return len(msgs) > 0
+}
+
+// sendPriority 把优先消息交给 swpid 的流写入器的优先通道，并唤醒流写入器。
+func (s *streamRouter) sendPriority(swpid *actor.PID, msg *streamDeliver) bool {
+	w, ok := s.writers[msg.target.Address]
+	if !ok || w.pid != swpid {
+		return false
+	}
+	w.priority.push(msg)
+	s.engine.Send(swpid, streamPriority{})
+	return true
+}
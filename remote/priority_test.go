@@ -0,0 +1,55 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+)
+
+// seqStream 记录每个写出的 Envelope 中消息的序列号。
+type seqStream struct {
+	recordingStream
+	mu   sync.Mutex
+	seqs [][]uint64
+}
+
+func (s *seqStream) Send(env *Envelope) error {
+	seqs := make([]uint64, len(env.Messages))
+	for i, m := range env.Messages {
+		seqs[i] = m.Seq
+	}
+	s.mu.Lock()
+	s.seqs = append(s.seqs, seqs)
+	s.mu.Unlock()
+	return nil
+}
+
+type priorityMsg struct{ *TestMessage }
+
+func (priorityMsg) RemotePriority() bool { return true }
+
+func TestIsPriority(t *testing.T) {
+	assert.True(t, isPriority(priorityMsg{}))
+	assert.False(t, isPriority(testMsg("foo")))
+}
+
+func TestPriorityLaneAheadOfBacklog(t *testing.T) {
+	w, _ := newBatchingWriter(t, 2, 0)
+	stream := &seqStream{}
+	w.stream = stream
+
+	msgs := deliveries(4)
+	for i := range msgs {
+		msgs[i].Msg.(*streamDeliver).seq = uint64(i + 1)
+	}
+	prio := newStreamDeliver(actor.NewPID("peer", "cluster"), testMsg("members"), nil)
+	prio.seq = 99
+	w.priority.push(prio)
+
+	w.Invoke(msgs)
+	// 优先消息加入第一个批次，排在收件箱中的消息之前。
+	assert.Equal(t, [][]uint64{{99, 1}, {2, 3}, {4}}, stream.seqs)
+	assert.Nil(t, w.priority.take())
+}
@@ -2,23 +2,47 @@ package remote
 
 import (
 	"fmt"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 )
 
-// registry 是类型注册表。
-var registry = map[string]VTUnmarshaler{}
+// TypeRegistry 是 VTProtoSerializer 反序列化时使用的类型注册表。
+// 同一进程中的多个引擎可以各自使用独立的注册表，互不冲突。
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[string]VTUnmarshaler
+}
 
-// RegisterType 注册一个类型到注册表。
-func RegisterType(v VTUnmarshaler) {
+// NewTypeRegistry 创建一个新的空类型注册表。
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[string]VTUnmarshaler)}
+}
+
+// Register 注册一个类型到注册表。
+func (r *TypeRegistry) Register(v VTUnmarshaler) {
 	tname := string(proto.MessageName(v))
-	registry[tname] = v
+	r.mu.Lock()
+	r.types[tname] = v
+	r.mu.Unlock()
 }
 
-// registryGetType 从注册表获取类型。
-func registryGetType(t string) (VTUnmarshaler, error) {
-	if m, ok := registry[t]; ok {
-		return m, nil
+// New 返回给定类型名称的一个新实例。每次调用都返回新的实例，
+// 可以被多个流读取器并发使用。
+func (r *TypeRegistry) New(t string) (VTUnmarshaler, error) {
+	r.mu.RLock()
+	m, ok := r.types[t]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("给定类型 (%s) 未注册。你是否忘记使用 remote.RegisterType(&instance{}) 注册你的类型？", t)
 	}
-	return nil, fmt.Errorf("给定类型 (%s) 未注册。你是否忘记使用 remote.RegisterType(&instance{}) 注册你的类型？", t)
+	return m.ProtoReflect().New().Interface().(VTUnmarshaler), nil
+}
+
+// registry 是默认的类型注册表。
+var registry = NewTypeRegistry()
+
+// RegisterType 注册一个类型到默认的类型注册表。
+func RegisterType(v VTUnmarshaler) {
+	registry.Register(v)
 }
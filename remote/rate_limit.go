@@ -0,0 +1,124 @@
+package remote
+
+import (
+	"net"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/TAnNbR/Distributed-framework/internal/ratelimit"
+)
+
+// InboundRateLimitPolicy 决定某个远程节点发来的消息超过速率限制时如何处理。
+type InboundRateLimitPolicy int
+
+const (
+	// InboundRateLimitDelay 暂停读取该节点的连接，直到速率允许。对方的发送随之放慢，
+	// 其他节点的连接不受影响。
+	InboundRateLimitDelay InboundRateLimitPolicy = iota
+	// InboundRateLimitDrop 丢弃超过速率的消息。
+	InboundRateLimitDrop
+)
+
+// WithInboundRateLimit 限制每个远程节点每 per 时间内最多投递 n 条消息（令牌桶，允许 n 条的突发），
+// 超过限制时按照 policy 推迟或丢弃消息，并在事件流上广播 actor.RemoteThrottledEvent，
+// 防止单个节点的消息洪流压垮本地引擎。节点按入站连接的对端主机区分，同一主机的所有连接共享限制，
+// 限流器在该主机的最后一个连接关闭后删除。
+func (c Config) WithInboundRateLimit(n int, per time.Duration, policy InboundRateLimitPolicy) Config {
+	c.InboundRateLimit = n
+	c.InboundRatePer = per
+	c.InboundRatePolicy = policy
+	return c
+}
+
+// inboundLimiter 是一个远程主机的入站限流器。同一主机的所有入站连接（包括数据流传输）共享它，
+// 对方不能通过新建连接或伪造消息的发送者绕过限制。
+type inboundLimiter struct {
+	host   string
+	bucket *ratelimit.TokenBucket
+	// conns 是使用限流器的连接数，参见 releaseLimiter。
+	conns int
+}
+
+// reserve 尝试取得一个令牌，参见 ratelimit.TokenBucket.Reserve。
+func (l *inboundLimiter) reserve(wait bool) time.Duration {
+	return l.bucket.Reserve(time.Now(), wait)
+}
+
+// acquireLimiter 返回 conn 的对端主机的限流器，未启用限流时返回 nil。连接关闭后调用 releaseLimiter。
+func (r *Remote) acquireLimiter(conn net.Conn) *inboundLimiter {
+	if r.config.InboundRateLimit <= 0 || r.config.InboundRatePer <= 0 {
+		return nil
+	}
+	host := connHost(conn)
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	l, ok := r.limiters[host]
+	if !ok {
+		l = &inboundLimiter{
+			host:   host,
+			bucket: ratelimit.New(r.config.InboundRateLimit, r.config.InboundRatePer, time.Now()),
+		}
+		r.limiters[host] = l
+	}
+	l.conns++
+	return l
+}
+
+// releaseLimiter 释放 acquireLimiter 返回的限流器。主机的最后一个连接关闭 InboundRatePer 时间后，
+// 令牌桶已经重新装满，删除它与保留它等价。
+func (r *Remote) releaseLimiter(l *inboundLimiter) {
+	if l == nil {
+		return
+	}
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	if l.conns--; l.conns > 0 {
+		return
+	}
+	time.AfterFunc(r.config.InboundRatePer, func() {
+		r.limitersMu.Lock()
+		defer r.limitersMu.Unlock()
+		if l.conns == 0 && r.limiters[l.host] == l {
+			delete(r.limiters, l.host)
+		}
+	})
+}
+
+// connHost 返回连接的对端主机（不含端口）。
+func connHost(conn net.Conn) string {
+	addr := conn.RemoteAddr()
+	if addr == nil {
+		return unknownPeer
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// throttle 对一条消息应用速率限制，返回 false 时消息应当被丢弃。推迟的时间累加到 delay。
+func (r *streamReader) throttle(limiter *inboundLimiter, delay *time.Duration) bool {
+	wait := limiter.reserve(r.remote.config.InboundRatePolicy == InboundRateLimitDelay)
+	if wait == 0 {
+		return true
+	}
+	if r.remote.config.InboundRatePolicy == InboundRateLimitDrop {
+		return false
+	}
+	*delay += wait
+	time.Sleep(wait)
+	return true
+}
+
+// reportThrottled 在一个 Envelope 中有消息被推迟或丢弃时广播 RemoteThrottledEvent。
+func (r *streamReader) reportThrottled(peer string, dropped int, delay time.Duration) {
+	if dropped == 0 && delay == 0 {
+		return
+	}
+	r.remote.engine.BroadcastEvent(actor.RemoteThrottledEvent{
+		Peer:    peer,
+		Dropped: dropped,
+		Delay:   delay,
+	})
+}
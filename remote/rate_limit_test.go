@@ -0,0 +1,117 @@
+package remote
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribeThrottled 订阅 e 上的 RemoteThrottledEvent。
+func subscribeThrottled(e *actor.Engine) chan actor.RemoteThrottledEvent {
+	events := make(chan actor.RemoteThrottledEvent, 64)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteThrottledEvent); ok {
+			events <- ev
+		}
+	}, "throttled_events")
+	e.Subscribe(sub)
+	return events
+}
+
+func TestInboundRateLimitDrop(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithInboundRateLimit(5, time.Hour, InboundRateLimitDrop))
+	require.NoError(t, err)
+	events := subscribeThrottled(b)
+
+	got := make(chan struct{}, 20)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			got <- struct{}{}
+		}
+	}, "foo")
+	sender := a.SpawnFunc(func(c *actor.Context) {}, "sender")
+	for i := 0; i < 10; i++ {
+		a.SendWithSender(pidb, testMsg("flood"), sender)
+	}
+
+	dropped := 0
+	deadline := time.After(2 * time.Second)
+	for dropped < 5 {
+		select {
+		case ev := <-events:
+			assert.Contains(t, []string{"127.0.0.1", "::1"}, ev.Peer)
+			dropped += ev.Dropped
+		case <-deadline:
+			t.Fatalf("只丢弃了 %d 条消息", dropped)
+		}
+	}
+	assert.Equal(t, 5, dropped)
+	assert.Eventually(t, func() bool { return len(got) == 5 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, got, 5)
+}
+
+func TestInboundRateLimitDelay(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithInboundRateLimit(10, 100*time.Millisecond, InboundRateLimitDelay))
+	require.NoError(t, err)
+	events := subscribeThrottled(b)
+
+	got := make(chan struct{}, 30)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			got <- struct{}{}
+		}
+	}, "foo")
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		a.Send(pidb, testMsg("flood"))
+	}
+	for i := 0; i < 30; i++ {
+		select {
+		case <-got:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("只收到 %d 条消息", i)
+		}
+	}
+	// 前 10 条消息是突发，其余 20 条至少需要 200ms。
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	select {
+	case ev := <-events:
+		assert.Zero(t, ev.Dropped)
+		assert.Greater(t, ev.Delay, time.Duration(0))
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteThrottledEvent")
+	}
+}
+
+func TestInboundLimiterPerHost(t *testing.T) {
+	r := New("", NewConfig().WithInboundRateLimit(1, 20*time.Millisecond, InboundRateLimitDrop))
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	// 同一主机的连接共享限流器，新建连接不会得到新的令牌。
+	l1 := r.acquireLimiter(c1)
+	l2 := r.acquireLimiter(c2)
+	require.Same(t, l1, l2)
+	assert.Zero(t, l1.reserve(false))
+	assert.Positive(t, l2.reserve(false))
+
+	r.releaseLimiter(l1)
+	r.releaseLimiter(l2)
+	assert.Eventually(t, func() bool {
+		r.limitersMu.Lock()
+		defer r.limitersMu.Unlock()
+		return len(r.limiters) == 0
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package remote
+
+import "time"
+
+const (
+	// defaultReconnectMin 和 defaultReconnectMax 是重新连接不可达节点的默认最短和最长间隔。
+	defaultReconnectMin = time.Second
+	defaultReconnectMax = 30 * time.Second
+)
+
+// WithReconnectBackoff 设置流路由器重新连接不可达节点的间隔：第一次等待 min，之后每次失败加倍，
+// 最多等待 max。只在启用 WithOutbox 时生效，重新连接期间发往该节点的消息保存在发件箱中，
+// 连接建立后按顺序补发。默认 min 为 1 秒，max 为 30 秒。
+func (c Config) WithReconnectBackoff(min, max time.Duration) Config {
+	c.ReconnectMin = min
+	c.ReconnectMax = max
+	return c
+}
+
+// reconnectDelay 返回第 attempt 次（从 0 开始）重新连接之前的等待时间。
+func (c Config) reconnectDelay(attempt int) time.Duration {
+	lo, hi := c.ReconnectMin, c.ReconnectMax
+	if lo <= 0 {
+		lo = defaultReconnectMin
+	}
+	if hi <= 0 {
+		hi = defaultReconnectMax
+	}
+	d := lo
+	for i := 0; i < attempt && d < hi; i++ {
+		d *= 2
+	}
+	return min(d, hi)
+}
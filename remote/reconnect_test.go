@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	c := NewConfig()
+	assert.Equal(t, time.Second, c.reconnectDelay(0))
+	assert.Equal(t, 4*time.Second, c.reconnectDelay(2))
+	assert.Equal(t, 30*time.Second, c.reconnectDelay(100))
+
+	c = c.WithReconnectBackoff(100*time.Millisecond, 300*time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, c.reconnectDelay(0))
+	assert.Equal(t, 200*time.Millisecond, c.reconnectDelay(1))
+	assert.Equal(t, 300*time.Millisecond, c.reconnectDelay(2))
+}
+
+func TestReconnectHoldsMessages(t *testing.T) {
+	config := NewConfig().WithOutbox(16, 0).WithReconnectBackoff(50*time.Millisecond, 200*time.Millisecond)
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+	require.NoError(t, err)
+
+	unreachable := make(chan struct{}, 1)
+	reconnected := make(chan actor.RemoteReconnectedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.RemoteUnreachableEvent:
+			select {
+			case unreachable <- struct{}{}:
+			default:
+			}
+		case actor.RemoteReconnectedEvent:
+			reconnected <- msg
+		}
+	}, "events")
+	a.Subscribe(sub)
+
+	bAddr := getRandomLocalhostAddr()
+	target := actor.NewPID(bAddr, "foo/1")
+	a.Send(target, testMsg("a"))
+	select {
+	case <-unreachable:
+	case <-time.After(10 * time.Second):
+		t.Fatal("没有收到 RemoteUnreachableEvent")
+	}
+	// 不可达期间的消息留在发件箱中，由重新连接循环补发。
+	a.Send(target, testMsg("b"))
+	a.Send(target, testMsg("c"))
+
+	b, _, err := makeRemoteEngine(bAddr)
+	require.NoError(t, err)
+	got := make(chan string, 3)
+	b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo", actor.WithID("1"))
+
+	select {
+	case ev := <-reconnected:
+		assert.Equal(t, 3, ev.Replayed)
+	case <-time.After(15 * time.Second):
+		t.Fatal("没有收到 RemoteReconnectedEvent")
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		select {
+		case s := <-got:
+			assert.Equal(t, want, s)
+		case <-time.After(time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// relayMagic 标记中继注册，长度与 negotiationMagic 相同，监听端读取同样长度的前缀后区分两者。
+	relayMagic          = "\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xffRLAY"
+	relayRedialInterval = time.Second // 与中继的连接断开后重新注册的间隔
+	maxRelayIDSize      = 1024
+
+	relayAccepted byte = 1
+	relayRejected byte = 0
+)
+
+var (
+	// ErrRelayRejected 表示中继节点拒绝了注册，通常是因为它没有启用 WithRelayServer。
+	ErrRelayRejected = errors.New("中继节点拒绝了注册")
+
+	errNotRelay = errors.New("本节点不是中继节点")
+)
+
+// WithRelay 让无法被直接拨号的节点（例如位于家庭网络的 NAT 之后）经由中继节点接收消息。
+// 节点主动连接 relay 并注册，Remote.Address() 变为 "<addr>@<relay>"（addr 是公布的地址，
+// 在同一个中继上必须唯一）；其他节点发往这个地址的消息
+// 先发送到中继，再由中继通过节点建立的连接转发，对发送者是透明的。
+// relay 必须与中继节点的 Address() 完全相同，中继节点需要启用 WithRelayServer。
+func (c Config) WithRelay(relay string) Config {
+	c.Relay = relay
+	return c
+}
+
+// WithRelayServer 让本节点作为中继，接受 WithRelay 节点的注册并为它们转发消息。
+// 转发时不反序列化消息，因此中继不需要注册这些消息的类型。
+func (c Config) WithRelayServer(enabled bool) Config {
+	c.RelayServer = enabled
+	return c
+}
+
+// relayAddress 返回经由 relay 访问节点 id 的地址。
+func relayAddress(id, relay string) string {
+	return id + "@" + relay
+}
+
+// splitRelayAddr 拆分经由中继的地址，不是这种地址时 ok 为 false。
+func splitRelayAddr(addr string) (id, relay string, ok bool) {
+	i := strings.LastIndex(addr, "@")
+	if i < 0 {
+		return "", addr, false
+	}
+	return addr[:i], addr[i+1:], true
+}
+
+// dialAddr 返回连接 address 时实际拨号的地址：经由中继的地址拨号到中继。
+func dialAddr(address string) string {
+	_, relay, _ := splitRelayAddr(address)
+	return relay
+}
+
+// relayedMessage 是中继转发的消息，保持序列化后的形式。
+type relayedMessage struct {
+	typeName   string
+	serializer string
+	data       []byte
+	// mac 是发送者的签名，参见 WithHMAC。
+	mac []byte
+}
+
+// relays 报告本节点是否负责转发发往 address 的消息。
+func (r *Remote) relays(address string) bool {
+	if !r.config.RelayServer {
+		return false
+	}
+	_, relay, ok := splitRelayAddr(address)
+	return ok && relay == r.Address()
+}
+
+// acceptRelay 在中继节点上处理注册：记录节点建立的连接，之后发往该节点的流写入器使用这个连接。
+func (r *Remote) acceptRelay(conn net.Conn) error {
+	if !r.config.RelayServer {
+		_, _ = conn.Write([]byte{relayRejected})
+		return errNotRelay
+	}
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	id, err := readFrame(conn, maxRelayIDSize)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte{relayAccepted}); err != nil {
+		return err
+	}
+	// 连接交给流写入器之前清除截止时间，之后由流写入器设置。
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return err
+	}
+	address := relayAddress(string(id), r.Address())
+	if old, loaded := r.relayConns.Swap(address, conn); loaded {
+		_ = old.(net.Conn).Close()
+	}
+	r.logger().Debug("节点已注册到中继", "remote", address)
+	return nil
+}
+
+// takeRelayConn 取出节点注册时建立的连接，每个连接只能被一个流写入器使用。
+func (r *Remote) takeRelayConn(address string) net.Conn {
+	if conn, ok := r.relayConns.LoadAndDelete(address); ok {
+		return conn.(net.Conn)
+	}
+	return nil
+}
+
+// dialRelay 连接到中继并注册。连接建立之后双方的角色互换：中继在这个连接上打开流，本节点接收消息。
+func (r *Remote) dialRelay() (net.Conn, error) {
+	conn, err := r.dialPeer(r.config.Relay)
+	if err != nil {
+		return nil, err
+	}
+	if err := register(conn, r.advertisedAddr()); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialPeer 连接到 addr 并完成 Noise 和认证握手，用于流写入器之外的专用连接。
+func (r *Remote) dialPeer(addr string) (net.Conn, error) {
+	ctx, cancel := r.config.openContext()
+	defer cancel()
+	conn, err := dialConn(ctx, r.transport, addr, r.clientTLS(addr), r.config.tlsHandshakeTimeout())
+	if err != nil {
+		return nil, err
+	}
+	if r.config.NoiseKey != nil {
+		c, err := noiseHandshake(conn, r.config.NoiseKey, true)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		conn = c
+	}
+	if r.config.Auth != nil {
+		if err := authenticate(conn, r.config.Auth, addr); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// register 发送 relayMagic 和本节点的标识，并等待中继的结果。
+func register(conn net.Conn, id string) error {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := io.WriteString(conn, relayMagic); err != nil {
+		return err
+	}
+	if err := writeFrame(conn, []byte(id)); err != nil {
+		return err
+	}
+	var result [1]byte
+	if _, err := io.ReadFull(conn, result[:]); err != nil {
+		return err
+	}
+	if result[0] != relayAccepted {
+		return ErrRelayRejected
+	}
+	return nil
+}
+
+// runRelay 保持与中继的连接，连接断开后重新注册，直到 ctx 被取消。
+func (r *Remote) runRelay(ctx context.Context, ln *relayListener) {
+	for {
+		conn, err := r.dialRelay()
+		if err != nil {
+			r.logger().Error("注册到中继失败", "err", err, "relay", r.config.Relay)
+		} else {
+			c := &relayConn{Conn: conn, closed: make(chan struct{})}
+			select {
+			case ln.conns <- c:
+			case <-ctx.Done():
+				_ = conn.Close()
+				return
+			}
+			r.logger().Debug("已注册到中继", "relay", r.config.Relay)
+			select {
+			case <-c.closed:
+				r.logger().Debug("与中继的连接断开", "relay", r.config.Relay)
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-time.After(relayRedialInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// relayListener 把与中继的连接交给 Transport，本节点在这些连接上接收消息。
+type relayListener struct {
+	addr      net.Addr
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newRelayListener(addr net.Addr) *relayListener {
+	return &relayListener{
+		addr:  addr,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *relayListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *relayListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *relayListener) Addr() net.Addr { return l.addr }
+
+// relayConn 在被关闭时通知 runRelay 重新注册。
+type relayConn struct {
+	net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *relayConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
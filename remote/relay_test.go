@@ -0,0 +1,93 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelay(t *testing.T) {
+	relayAddr := getRandomLocalhostAddr()
+	relay, r, err := makeRemoteEngineWithConfig(relayAddr, NewConfig().WithRelayServer(true))
+	require.NoError(t, err)
+	// n 只能经由中继访问，它的监听地址不会被其他节点拨号。
+	n, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithRelay(relayAddr))
+	require.NoError(t, err)
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	id, host, ok := splitRelayAddr(n.Address())
+	require.True(t, ok)
+	assert.Equal(t, relayAddr, host)
+	require.Eventually(t, func() bool {
+		_, ok := r.relayConns.Load(relayAddress(id, relayAddr))
+		return ok
+	}, 2*time.Second, 10*time.Millisecond)
+
+	got := make(chan string, 2)
+	pidn := n.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+			// 回复直接发往发送者。
+			if c.Sender() != nil {
+				c.Respond(testMsg("pong"))
+			}
+		}
+	}, "foo")
+	assert.Equal(t, n.Address(), pidn.Address)
+
+	pong := make(chan struct{}, 1)
+	a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.Started:
+			c.Send(pidn, testMsg("from a"))
+		case *TestMessage:
+			assert.Equal(t, "pong", string(msg.Data))
+			pong <- struct{}{}
+		}
+	}, "bar")
+	relay.Send(pidn, testMsg("from relay"))
+
+	received := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case s := <-got:
+			received[s] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("经由中继的消息没有送达")
+		}
+	}
+	assert.Equal(t, map[string]bool{"from a": true, "from relay": true}, received)
+	select {
+	case <-pong:
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到回复")
+	}
+}
+
+func TestRelayRejected(t *testing.T) {
+	notRelay := getRandomLocalhostAddr()
+	_, _, err := makeRemoteEngine(notRelay)
+	require.NoError(t, err)
+
+	r := New(getRandomLocalhostAddr(), NewConfig().WithRelay(notRelay))
+	r.transport = r.config.transport()
+	_, err = r.dialRelay()
+	assert.ErrorIs(t, err, ErrRelayRejected)
+}
+
+func TestSplitRelayAddr(t *testing.T) {
+	id, relay, ok := splitRelayAddr(relayAddress("10.0.0.5:4000", "relay.example.com:4000"))
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.5:4000", id)
+	assert.Equal(t, "relay.example.com:4000", relay)
+
+	_, relay, ok = splitRelayAddr("127.0.0.1:4000")
+	assert.False(t, ok)
+	assert.Equal(t, "127.0.0.1:4000", relay)
+	assert.Equal(t, "127.0.0.1:4000", dialAddr("127.0.0.1:4000"))
+	assert.Equal(t, "relay:4000", dialAddr("node@relay:4000"))
+}
@@ -5,23 +5,90 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"math/rand"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/TAnNbR/Distributed-framework/actor"
-	"storj.io/drpc/drpcmanager"
-	"storj.io/drpc/drpcmux"
-	"storj.io/drpc/drpcserver"
-	"storj.io/drpc/drpcwire"
 )
 
 // Config 保存远程配置。
 type Config struct {
 	TLSConfig *tls.Config
-	BuffSize  int
+	// TLSResolver 按目标地址选择拨号使用的 TLS 配置，参见 WithTLSResolver。
+	TLSResolver TLSResolver
+	BuffSize    int
+	Transport   Transport
+	Auth        Authenticator
+	// MaxMessageSize 是接收的单条消息序列化后的最大字节数，0 表示不限制。
+	MaxMessageSize int
+	// CircuitBreakerFailures 和 CircuitBreakerCooldown 配置每个远程地址的熔断器，参见 WithCircuitBreaker。
+	CircuitBreakerFailures int
+	CircuitBreakerCooldown time.Duration
+	// DisableOrdering 关闭按发送顺序投递的保证，参见 WithOrdering。
+	DisableOrdering bool
+	// StatsInterval 是广播 actor.RemoteStatsEvent 的间隔，参见 WithStatsInterval。
+	StatsInterval time.Duration
+	// OutboxSize 和 OutboxMaxAge 配置每个远程地址的发件箱，参见 WithOutbox。
+	OutboxSize   int
+	OutboxMaxAge time.Duration
+	// ReconnectMin 和 ReconnectMax 是重新连接不可达节点的间隔，参见 WithReconnectBackoff。
+	ReconnectMin time.Duration
+	ReconnectMax time.Duration
+	// KeepaliveInterval 和 KeepaliveTimeout 配置远程层的心跳，参见 WithKeepalive。
+	KeepaliveInterval time.Duration
+	KeepaliveTimeout  time.Duration
+	// BackpressureThreshold 和 BackpressurePolicy 配置发送队列的背压，参见 WithBackpressure。
+	BackpressureThreshold int
+	BackpressurePolicy    BackpressurePolicy
+	// Negotiation 在连接建立时交换协议版本和能力，参见 WithNegotiation。
+	Negotiation bool
+	// NoiseKey 是 Noise 加密的预共享密钥，参见 WithNoise。
+	NoiseKey []byte
+	// AdvertisedAddr 是其他节点访问本节点使用的地址，参见 WithAdvertisedAddr。
+	AdvertisedAddr string
+	// Relay 是转发发往本节点的消息的中继地址，参见 WithRelay。
+	Relay string
+	// RelayServer 表示本节点作为中继，参见 WithRelayServer。
+	RelayServer bool
+	// PeerFilter 决定是否接受远程节点的连接，参见 WithPeerFilter。
+	PeerFilter PeerFilter
+	// TransferDir 是保存接收的数据流的目录，参见 WithTransferDir。
+	TransferDir string
+	// InboundRateLimit、InboundRatePer 和 InboundRatePolicy 限制每个远程节点的消息速率，
+	// 参见 WithInboundRateLimit。
+	InboundRateLimit  int
+	InboundRatePer    time.Duration
+	InboundRatePolicy InboundRateLimitPolicy
+	// StopTimeout 是 Stop 等待流写入器写出消息的最长时间，参见 WithStopTimeout。
+	StopTimeout time.Duration
+	// IdleTimeout、WriteTimeout 和 OpenTimeout 是连接的超时时间，参见 WithConnTimeouts。
+	IdleTimeout  time.Duration
+	WriteTimeout time.Duration
+	OpenTimeout  time.Duration
+	// SendTTL 是发往远程节点的消息的有效期，参见 WithSendTTL。
+	SendTTL time.Duration
+	// Serializers 是按顺序尝试的序列化链，参见 WithSerializers。
+	Serializers []Codec
+	// HMACKey 是消息签名使用的密钥，参见 WithHMAC。
+	HMACKey []byte
+	// ListenAddrs 是除 New 的 addr 之外的监听地址，参见 WithListenAddrs。
+	ListenAddrs []string
+	// BatchSize 和 BatchLinger 控制流写入器的批处理，参见 WithBatching。
+	BatchSize   int
+	BatchLinger time.Duration
+	// Admin 表示是否创建 remote/admin actor，参见 WithAdmin。
+	Admin bool
+	// TLSSessionCacheSize 和 TLSHandshakeTimeout 配置 TLS 会话缓存和握手超时，参见 WithTLSSessions。
+	TLSSessionCacheSize int
+	TLSHandshakeTimeout time.Duration
 }
 
+// defaultStopTimeout 是 Stop 等待流写入器写出消息的默认时间。
+const defaultStopTimeout = time.Second * 5
+
 // NewConfig 返回一个新的默认远程配置。
 func NewConfig() Config {
 	return Config{}
@@ -33,6 +100,14 @@ func (c Config) WithTLS(tlsconf *tls.Config) Config {
 	return c
 }
 
+// WithAdvertisedAddr 设置其他节点访问本节点使用的地址，引擎用它作为本地 PID 的地址，
+// 而 New 的 addr 只用于监听。在 Docker/Kubernetes 的 NAT 之后，可以监听 "0.0.0.0:4000"，
+// 同时公布外部可达的 "node-1.example.com:4000"。未设置时公布监听地址。
+func (c Config) WithAdvertisedAddr(addr string) Config {
+	c.AdvertisedAddr = addr
+	return c
+}
+
 // WithBufferSize 设置流读取器的缓冲区大小。
 // 如果未提供，默认缓冲区大小由 drpc 包定义为 4MB。
 func (c Config) WithBufferSize(size int) Config {
@@ -40,6 +115,35 @@ func (c Config) WithBufferSize(size int) Config {
 	return c
 }
 
+// WithMaxMessageSize 设置接收的单条消息序列化后的最大字节数。超过的消息被丢弃，
+// 并在事件流上广播 actor.RemoteMessageRejectedEvent，同一批次中的其他消息照常投递。
+func (c Config) WithMaxMessageSize(size int) Config {
+	c.MaxMessageSize = size
+	return c
+}
+
+// WithStopTimeout 设置 Stop 等待流写入器写出队列中消息的最长时间，超过后未写出的消息被丢弃。
+// 未设置时为 5 秒。
+func (c Config) WithStopTimeout(d time.Duration) Config {
+	c.StopTimeout = d
+	return c
+}
+
+// WithTransport 设置远程使用的 RPC 协议，例如 NewGRPCTransport()。
+// 集群中的所有节点必须使用相同的 Transport。未设置时使用 NewDRPCTransport(BuffSize)。
+func (c Config) WithTransport(t Transport) Config {
+	c.Transport = t
+	return c
+}
+
+// transport 返回配置的 Transport，未设置时返回默认的 drpc Transport。
+func (c Config) transport() Transport {
+	if c.Transport != nil {
+		return c.Transport
+	}
+	return drpcTransport{buffSize: c.BuffSize, inactivityTimeout: c.OpenTimeout}
+}
+
 // Remote 表示远程通信模块。
 type Remote struct {
 	addr            string
@@ -49,6 +153,26 @@ type Remote struct {
 	stopCh          chan struct{} // Stop 关闭此通道以通知远程停止监听。
 	stopWg          *sync.WaitGroup
 	state           atomic.Uint32
+	// epoch 在每次启动时随机生成，随 Envelope 发送，用于区分重启前后的序列号。
+	epoch     uint64
+	transport Transport
+	// stats 收集每个远程节点的统计，未启用时为 nil。
+	stats *remoteStats
+	// queues 是远程地址到流写入器队列的映射，仅在启用背压时使用。
+	queues sync.Map
+	// relayConns 是已注册节点的地址到它建立的连接的映射，仅在中继节点上使用。
+	relayConns sync.Map
+	// blocked 是被 Block 屏蔽的地址的集合。
+	blocked sync.Map
+	// inbound 是交给 Transport 的入站连接的集合。
+	inbound sync.Map
+	// limiters 是远程主机到它的入站限流器的映射，仅在启用 WithInboundRateLimit 时使用。
+	limitersMu sync.Mutex
+	limiters   map[string]*inboundLimiter
+	// adminPID 是 remote/admin 的 PID，未启用 WithAdmin 时为 nil。
+	adminPID *actor.PID
+	// tlsSessions 是拨号共享的 TLS 会话缓存，参见 WithTLSSessions。
+	tlsSessions tls.ClientSessionCache
 }
 
 const (
@@ -58,11 +182,15 @@ const (
 	stateStopped
 )
 
-// New 根据给定的 Config 创建一个新的 "Remote" 对象。
+// New 根据给定的 Config 创建一个新的 "Remote" 对象。addr 是 TCP 监听地址，
+// 或者以 "unix://" 开头的 Unix 域套接字路径（例如 "unix:///var/run/actor.sock"），
+// 同一主机上的进程（sidecar、测试工具）可以借此避免 TCP 回环的开销，并通过文件权限控制访问。
 func New(addr string, config Config) *Remote {
 	r := &Remote{
-		addr:   addr,
-		config: config,
+		addr:        addr,
+		config:      config,
+		tlsSessions: config.tlsSessionCache(),
+		limiters:    make(map[string]*inboundLimiter),
 	}
 	r.state.Store(stateInitialized)
 	return r
@@ -73,51 +201,79 @@ func (r *Remote) Start(e *actor.Engine) error {
 	if r.state.Load() != stateInitialized {
 		return fmt.Errorf("远程模块已启动")
 	}
+	if r.config.NoiseKey != nil {
+		if len(r.config.NoiseKey) != NoiseKeySize {
+			return fmt.Errorf("Noise 预共享密钥必须是 %d 字节", NoiseKeySize)
+		}
+		if r.config.TLSConfig != nil || r.config.TLSResolver != nil {
+			return fmt.Errorf("WithNoise 不能与 WithTLS 同时使用")
+		}
+	}
 	r.state.Store(stateRunning)
 	r.engine = e
+	r.epoch = rand.Uint64() | 1
 	var ln net.Listener
 	var err error
-	switch r.config.TLSConfig {
-	case nil:
-		ln, err = net.Listen("tcp", r.addr)
-	default:
-		slog.Debug("远程使用 TLS 进行监听")
-		ln, err = tls.Listen("tcp", r.addr, r.config.TLSConfig)
+	transport := r.config.transport()
+	r.transport = transport
+	if r.config.StatsInterval > 0 {
+		r.stats = newRemoteStats(e, r.config.StatsInterval)
 	}
+	ln, err = r.listen(r.addr)
 	if err != nil {
 		return fmt.Errorf("远程监听失败: %w", err)
 	}
-	slog.Debug("正在监听", "addr", r.addr)
-	mux := drpcmux.New()
-	err = DRPCRegisterRemote(mux, newStreamReader(r))
+	extra, err := r.listenExtra()
 	if err != nil {
-		return fmt.Errorf("注册远程失败: %w", err)
+		_ = ln.Close()
+		return fmt.Errorf("远程监听失败: %w", err)
 	}
-	s := drpcserver.NewWithOptions(mux, drpcserver.Options{
-		Manager: drpcmanager.Options{
-			Reader: drpcwire.ReaderOptions{
-				MaximumBufferSize: r.config.BuffSize,
-			},
-		},
-	})
+	r.logger().Debug("正在监听", "addr", r.addr, "listenAddrs", r.config.ListenAddrs, "advertisedAddr", r.Address())
+	reader := newStreamReader(r)
 
 	r.streamRouterPID = r.engine.Spawn(
-		newStreamRouter(r.engine, r.config.TLSConfig, r.config.BuffSize),
-		"router", actor.WithInboxSize(1024*1024))
-	slog.Debug("服务器已启动", "listenAddr", r.addr)
+		newStreamRouter(r),
+		"router", actor.WithInboxSize(1024*1024), actor.WithSystem(), actor.WithDedicatedGoroutine())
+	if r.config.Admin {
+		r.adminPID = r.engine.Spawn(newAdmin(r), "remote", actor.WithID("admin"), actor.WithSystem())
+	}
+	r.logger().Debug("服务器已启动", "listenAddr", r.addr)
 	r.stopWg = &sync.WaitGroup{}
 	r.stopWg.Add(1)
 	r.stopCh = make(chan struct{})
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
 		defer r.stopWg.Done()
-		err := s.Serve(ctx, ln)
+		err := r.serve(ctx, ln, reader)
 		if err != nil {
-			slog.Error("drpcserver", "err", err)
+			r.logger().Error("远程服务", "err", err)
 		} else {
-			slog.Debug("drpcserver 已停止")
+			r.logger().Debug("远程服务已停止")
 		}
 	}()
+	for _, ln := range extra {
+		r.stopWg.Add(1)
+		go func() {
+			defer r.stopWg.Done()
+			if err := r.serve(ctx, ln, reader); err != nil {
+				r.logger().Error("远程服务", "err", err, "addr", ln.Addr())
+			}
+		}()
+	}
+	if r.config.Relay != "" {
+		relayLn := newRelayListener(ln.Addr())
+		r.stopWg.Add(1)
+		go func() {
+			defer r.stopWg.Done()
+			if err := r.serve(ctx, relayLn, reader); err != nil {
+				r.logger().Error("中继服务", "err", err)
+			}
+		}()
+		go r.runRelay(ctx, relayLn)
+	}
+	if r.stats != nil {
+		go r.stats.run(ctx.Done())
+	}
 	// 等待 stopCh 被关闭
 	go func() {
 		<-r.stopCh
@@ -126,30 +282,114 @@ func (r *Remote) Start(e *actor.Engine) error {
 	return nil
 }
 
-// Stop 将停止远程监听。
+// Stop 将停止远程监听。所有流写入器先写出队列中的消息（最多等待 StopTimeout）并关闭连接，
+// 然后停止监听，返回的 WaitGroup 在这之后才完成。
 func (r *Remote) Stop() *sync.WaitGroup {
 	if r.state.Load() != stateRunning {
-		slog.Warn("远程已停止但调用了 stop", "state", r.state.Load())
+		r.logger().Warn("远程已停止但调用了 stop", "state", r.state.Load())
 		return &sync.WaitGroup{} // 返回空的 waitgroup 以便调用者仍然可以等待而不会 panic。
 	}
 	r.state.Store(stateStopped)
-	r.stopCh <- struct{}{}
+	r.stopWg.Add(1)
+	go func() {
+		defer r.stopWg.Done()
+		timeout := r.config.StopTimeout
+		if timeout <= 0 {
+			timeout = defaultStopTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := r.flush(ctx, true); err != nil {
+			r.logger().Warn("停止远程时没有写出所有消息", "err", err, "timeout", timeout)
+		}
+		r.stopCh <- struct{}{}
+	}()
 	return r.stopWg
 }
 
+// Drain 等待所有已经交给远程的消息被写入网络，或 ctx 被取消。
+// 它实现了 actor.RemoteDrainer，由 Engine.Shutdown 在停止远程之前调用。
+func (r *Remote) Drain(ctx context.Context) error {
+	if r.state.Load() != stateRunning {
+		return nil
+	}
+	return r.flush(ctx, false)
+}
+
+// flush 要求所有流写入器写出已经收到的消息，closeWriters 为 true 时流写入器随后关闭连接。
+func (r *Remote) flush(ctx context.Context, closeWriters bool) error {
+	flush := &streamFlush{
+		routed: make(chan struct{}),
+		wg:     &sync.WaitGroup{},
+		close:  closeWriters,
+	}
+	r.engine.Send(r.streamRouterPID, flush)
+	done := make(chan struct{})
+	go func() {
+		<-flush.routed
+		flush.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// logger 返回绑定引擎的日志记录器，远程尚未启动时使用 slog.Default()。
+func (r *Remote) logger() *slog.Logger {
+	if r.engine == nil {
+		return slog.Default()
+	}
+	return r.engine.Logger()
+}
+
 // Send 通过网络将给定的消息发送到具有给定 pid 的进程。
 // 可选地，可以给出"发送者 PID"以通知接收进程谁发送了消息。
 // 即使远程已停止，发送仍然有效。但是，接收将不起作用。
 func (r *Remote) Send(pid *actor.PID, msg any, sender *actor.PID) {
-	r.engine.Send(r.streamRouterPID, &streamDeliver{
-		target: pid,
-		sender: sender,
-		msg:    msg,
-	})
+	r.send(pid, msg, sender, 0, 0)
+}
+
+// SendRequest 发送 actor.Engine.Request 发起的请求，消息携带请求 ID，sender 是等待响应的 Response。
+// 接收端的 actor 响应时，响应通过 SendResponse 携带同一个 ID 返回。
+func (r *Remote) SendRequest(pid *actor.PID, msg any, sender *actor.PID, requestID uint64) {
+	r.send(pid, msg, sender, requestID, 0)
 }
 
-// Address 返回远程的监听地址。
+// SendResponse 发送对请求 ID 为 requestID 的远程请求的响应，pid 是请求方的 Response。
+// 请求方按 ID 直接把响应交给 Response；响应不分配序列号，因为每个 Response 只接受一条消息。
+func (r *Remote) SendResponse(pid *actor.PID, msg any, requestID uint64) {
+	r.send(pid, msg, nil, 0, requestID)
+}
+
+func (r *Remote) send(pid *actor.PID, msg any, sender *actor.PID, requestID, responseID uint64) {
+	if r.config.BackpressureThreshold > 0 && !r.admit(pid, msg, sender) {
+		return
+	}
+	d := newStreamDeliver(pid, msg, sender)
+	d.requestID = requestID
+	d.responseID = responseID
+	d.deadline = r.sendDeadline()
+	r.engine.Send(r.streamRouterPID, d)
+}
+
+// Address 返回其他节点访问本节点使用的地址：启用 WithRelay 时是经由中继的地址，
+// 否则是 WithAdvertisedAddr 设置的地址，都未设置时是监听地址。
 func (r *Remote) Address() string {
+	if r.config.Relay != "" {
+		return relayAddress(r.advertisedAddr(), r.config.Relay)
+	}
+	return r.advertisedAddr()
+}
+
+// advertisedAddr 返回公布的地址，未设置时返回监听地址。
+func (r *Remote) advertisedAddr() string {
+	if r.config.AdvertisedAddr != "" {
+		return r.config.AdvertisedAddr
+	}
 	return r.addr
 }
 
@@ -1,6 +1,7 @@
 package remote
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net"
@@ -97,6 +98,30 @@ func TestSend(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestAdvertisedAddr(t *testing.T) {
+	// 监听所有网卡，对外公布回环地址。
+	port := getRandomLocalhostAddr()[len("localhost"):]
+	a, _, err := makeRemoteEngineWithConfig("0.0.0.0"+port, NewConfig().WithAdvertisedAddr("127.0.0.1"+port))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1"+port, a.Address())
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	got := make(chan struct{})
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			close(got)
+		}
+	}, "foo")
+	assert.Equal(t, "127.0.0.1"+port, pid.Address)
+	b.Send(pid, testMsg("foo"))
+	select {
+	case <-got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("发往公布地址的消息没有送达")
+	}
+}
+
 func TestWithSender(t *testing.T) {
 	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
 	defer ra.Stop()
@@ -153,6 +178,25 @@ func TestRequestResponse(t *testing.T) {
 	assert.Equal(t, resp.(*TestMessage).Data, []byte("foo"))
 }
 
+func TestRequestRespondErr(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer ra.Stop()
+	defer rb.Stop()
+
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			c.RespondErr(fmt.Errorf("not found"))
+		}
+	}, "lookup")
+	_, err = a.Request(pid, &TestMessage{Data: []byte("key")}, time.Second).Result()
+	var respErr *actor.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, "not found", respErr.Message)
+}
+
 func TestEventStream(t *testing.T) {
 	// Events should work over the wire from the get go.
 	// Which is just insane, huh?
@@ -246,9 +290,144 @@ func TestStreamWriterRemoteUnreachableEvent(t *testing.T) {
 	wg.Wait()
 }
 
+func TestWatchRemote(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer ra.Stop()
+	b, rb, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	watched := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			c.Engine().Poison(c.PID())
+		}
+	}, "watched")
+
+	a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.Started:
+			c.Watch(watched)
+			c.Send(watched, &TestMessage{Data: []byte("stop")})
+		case *actor.Terminated:
+			assert.True(t, watched.Equals(msg.PID))
+			wg.Done()
+		}
+	}, "watcher")
+	wg.Wait()
+}
+
+func TestWatchRemoteUnreachable(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer ra.Stop()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	target := actor.NewPID(getRandomLocalhostAddr(), "foo/bar")
+	a.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case actor.Started:
+			c.Watch(target)
+		case *actor.Terminated:
+			assert.True(t, target.Equals(msg.PID))
+			wg.Done()
+		}
+	}, "watcher")
+	wg.Wait()
+}
+
+func TestEngineShutdownFlushesRemote(t *testing.T) {
+	const msgs = 100
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			mu.Lock()
+			received++
+			mu.Unlock()
+		}
+	}, "receiver")
+	for i := 0; i < msgs; i++ {
+		a.Send(pid, &TestMessage{Data: []byte("foo")})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, a.Shutdown(ctx))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received == msgs
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStopDrainsStreamWriters(t *testing.T) {
+	const msgs = 100
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	unreachable := make(chan struct{}, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(actor.RemoteUnreachableEvent); ok {
+			select {
+			case unreachable <- struct{}{}:
+			default:
+			}
+		}
+	}, "events")
+	a.Subscribe(sub)
+
+	var (
+		mu       sync.Mutex
+		received int
+	)
+	pid := b.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			mu.Lock()
+			received++
+			mu.Unlock()
+		}
+	}, "receiver")
+	for i := 0; i < msgs; i++ {
+		a.Send(pid, &TestMessage{Data: []byte("foo")})
+	}
+
+	ra.Stop().Wait()
+	// Stop 返回时所有消息已经写出，流写入器已经关闭。
+	assert.Nil(t, a.Registry.GetPID("stream", rb.Address()))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received == msgs
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-unreachable:
+		t.Fatal("停止远程时不应该广播 RemoteUnreachableEvent")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func makeRemoteEngine(listenAddr string) (*actor.Engine, *Remote, error) {
+	return makeRemoteEngineWithConfig(listenAddr, NewConfig())
+}
+
+func makeRemoteEngineWithConfig(listenAddr string, config Config) (*actor.Engine, *Remote, error) {
 	var e *actor.Engine
-	r := New(listenAddr, NewConfig())
+	r := New(listenAddr, config)
 	var err error
 	e, err = actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
 	if err != nil {
@@ -269,3 +448,29 @@ func tcpPing(addr string) error {
 	defer conn.Close()
 	return nil
 }
+
+func BenchmarkSendRemote(b *testing.B) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(b, err)
+	e, re, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(b, err)
+	b.Cleanup(func() {
+		ra.Stop().Wait()
+		re.Stop().Wait()
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(b.N)
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			wg.Done()
+		}
+	}, "bench")
+	msg := &TestMessage{Data: []byte("foo")}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Send(pid, msg)
+	}
+	wg.Wait()
+}
@@ -68,10 +68,44 @@ func TestSend_TLS(t *testing.T) {
 }
 
 func generateTLSConfig() (*sharedConfig, error) {
+	caCert, caPrivKey, err := generateCA()
+	if err != nil {
+		return nil, err
+	}
+	// Create the CertPool and add the CA certificate
+	caCertPool := x509.NewCertPool()
+	caCertPool.AddCert(caCert)
+
+	peer1Pair, err := generateCert(caCert, caPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("generateCert(peer1): %w", err)
+	}
+	peer1TlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{*peer1Pair},
+		ClientCAs:    caCertPool,
+		RootCAs:      caCertPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	peer2Pair, err := generateCert(caCert, caPrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("generateCert(peer2): %w", err)
+	}
+	peer2TlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{*peer2Pair},
+		ClientCAs:    caCertPool,
+		RootCAs:      caCertPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+
+	return &sharedConfig{peer1Config: peer1TlsConfig, peer2Config: peer2TlsConfig}, nil
+}
+
+// generateCA creates a self-signed CA certificate and its private key.
+func generateCA() (*x509.Certificate, *ecdsa.PrivateKey, error) {
 	// Create a new ECDSA private key for CA
 	caPrivKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return nil, fmt.Errorf("ecdsa.GenerateKey: %w", err)
+		return nil, nil, fmt.Errorf("ecdsa.GenerateKey: %w", err)
 	}
 
 	// Create a CA certificate
@@ -90,53 +124,41 @@ func generateTLSConfig() (*sharedConfig, error) {
 
 	caCertBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, &caPrivKey.PublicKey, caPrivKey)
 	if err != nil {
-		return nil, fmt.Errorf("x509.CreateCertificate: %w", err)
+		return nil, nil, fmt.Errorf("x509.CreateCertificate: %w", err)
 	}
 
 	// Parse the CA certificate for inclusion in tls.Config
 	caCert, err := x509.ParseCertificate(caCertBytes)
 	if err != nil {
-		return nil, fmt.Errorf("x509.ParseCertificate: %w", err)
+		return nil, nil, fmt.Errorf("x509.ParseCertificate: %w", err)
 	}
-	// Create the CertPool and add the CA certificate
-	caCertPool := x509.NewCertPool()
-	caCertPool.AddCert(caCert)
+	return caCert, caPrivKey, nil
+}
 
-	peer1Pair, err := generateCert(caCert, caPrivKey)
+// generateCert takes a CA, makes a new private key and certificate, and returns a tls.Certificate
+func generateCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*tls.Certificate, error) {
+	certPEM, keyPEM, err := generateCertPEM(ca, caKey, "localhost")
 	if err != nil {
-		return nil, fmt.Errorf("generateCert(peer1): %w", err)
-	}
-	peer1TlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{*peer1Pair},
-		ClientCAs:    caCertPool,
-		RootCAs:      caCertPool,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
+		return nil, err
 	}
-	peer2Pair, err := generateCert(caCert, caPrivKey)
+	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
 	if err != nil {
-		return nil, fmt.Errorf("generateCert(peer2): %w", err)
-	}
-	peer2TlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{*peer2Pair},
-		ClientCAs:    caCertPool,
-		RootCAs:      caCertPool,
-		ClientAuth:   tls.RequireAndVerifyClientCert,
+		return nil, fmt.Errorf("tls.X509KeyPair: %w", err)
 	}
-
-	return &sharedConfig{peer1Config: peer1TlsConfig, peer2Config: peer2TlsConfig}, nil
+	return &tlsCert, nil
 }
 
-// generateCert takes a CA, makes a new private key and certificate, and returns a tls.Certificate
-func generateCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*tls.Certificate, error) {
-	// Create a new ECDSA private key for peer1
+// generateCertPEM makes a new private key and a certificate with the given common name signed by the CA,
+// and returns them PEM encoded.
+func generateCertPEM(ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string) ([]byte, []byte, error) {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
-		return nil, fmt.Errorf("ecdsa.GenerateKey: %w", err)
+		return nil, nil, fmt.Errorf("ecdsa.GenerateKey: %w", err)
 	}
 	certificate := &x509.Certificate{
 		SerialNumber: big.NewInt(2),
 		Subject: pkix.Name{
-			CommonName: "localhost",
+			CommonName: commonName,
 		},
 		DNSNames:    []string{"localhost"},
 		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
@@ -147,7 +169,7 @@ func generateCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*tls.Certifica
 	}
 	certBytes, err := x509.CreateCertificate(rand.Reader, certificate, ca, &key.PublicKey, caKey)
 	if err != nil {
-		return nil, fmt.Errorf("x509.CreateCertificate: %w", err)
+		return nil, nil, fmt.Errorf("x509.CreateCertificate: %w", err)
 	}
 	certPEM := pem.EncodeToMemory(&pem.Block{
 		Type:  "CERTIFICATE",
@@ -156,17 +178,13 @@ func generateCert(ca *x509.Certificate, caKey *ecdsa.PrivateKey) (*tls.Certifica
 
 	keyBytes, err := x509.MarshalECPrivateKey(key)
 	if err != nil {
-		return nil, fmt.Errorf("x509.MarshalECPrivateKey: %w", err)
+		return nil, nil, fmt.Errorf("x509.MarshalECPrivateKey: %w", err)
 	}
 	keyPEM := pem.EncodeToMemory(&pem.Block{
 		Type:  "EC PRIVATE KEY",
 		Bytes: keyBytes,
 	})
-	tlsCert, err := tls.X509KeyPair(certPEM, keyPEM)
-	if err != nil {
-		return nil, fmt.Errorf("tls.X509KeyPair: %w", err)
-	}
-	return &tlsCert, nil
+	return certPEM, keyPEM, nil
 }
 
 func makeRemoteEngineTls(listenAddr string, config *tls.Config) (*actor.Engine, *Remote, error) {
@@ -0,0 +1,252 @@
+package remote
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// reorderGapTimeout 是接收端等待缺失序列号的最长时间。超时后认为缺失的消息已经丢失
+	// （例如流写入器重启时丢弃了收件箱中的消息），继续投递后面的消息。
+	reorderGapTimeout = time.Millisecond * 100
+	// maxReorderPending 是每个消息流最多缓存的乱序消息数，超过时立即跳过缺口。
+	maxReorderPending = 1024
+	// reorderIdleTimeout 是接收端保留空闲序列状态的时间。
+	reorderIdleTimeout = time.Second * 30
+	// sequenceIdleTimeout 是发送端保留空闲序列号的时间。它比 reorderIdleTimeout 长，
+	// 保证发送端从 1 重新编号时接收端已经忘记旧的状态。
+	sequenceIdleTimeout = reorderIdleTimeout * 2
+)
+
+// WithOrdering 设置是否保证同一发送者到同一目标的消息按发送顺序投递，默认启用。
+// 启用时每条消息携带序列号，接收端缓存乱序到达的消息（例如流写入器重连前后的消息），
+// 缺失的消息在 100ms 后被视为丢失。禁用可以节省序列号的开销以提高吞吐量。
+func (c Config) WithOrdering(enabled bool) Config {
+	c.DisableOrdering = !enabled
+	return c
+}
+
+type sequence struct {
+	last uint64
+	used time.Time
+}
+
+// sequencer 为发送的消息分配序列号。序列号按目标编号，因此同一个节点上所有发送者发往
+// 同一目标的消息都按发送顺序投递（这也包含了同一发送者的顺序）。
+// 它由流路由器持有，因此在流写入器重启后序列号仍然连续。
+type sequencer struct {
+	seqs      map[uint64]*sequence
+	lastSweep time.Time
+}
+
+func newSequencer() *sequencer {
+	return &sequencer{
+		seqs:      make(map[uint64]*sequence),
+		lastSweep: time.Now(),
+	}
+}
+
+// next 返回发往 target 的下一条消息的序列号。
+func (s *sequencer) next(target *actor.PID) uint64 {
+	now := time.Now()
+	if now.Sub(s.lastSweep) > sequenceIdleTimeout {
+		for key, seq := range s.seqs {
+			if now.Sub(seq.used) > sequenceIdleTimeout {
+				delete(s.seqs, key)
+			}
+		}
+		s.lastSweep = now
+	}
+	key := target.LookupKey()
+	seq, ok := s.seqs[key]
+	if !ok {
+		seq = &sequence{}
+		s.seqs[key] = seq
+	}
+	seq.last++
+	seq.used = now
+	return seq.last
+}
+
+// reorderKey 标识接收端的一个有序消息流。
+type reorderKey struct {
+	epoch  uint64
+	target uint64
+}
+
+// reorderedMsg 是等待按序列号投递给本地 actor 的消息。
+type reorderedMsg struct {
+	target *actor.PID
+	sender *actor.PID
+	msg    any
+	// requestID 不为 0 时消息是远程请求，参见 actor.Engine.SendLocalRequest。
+	requestID uint64
+}
+
+// send 把消息投递给本地的目标，msg 为 nil 时什么也不做。
+func (m reorderedMsg) send(e *actor.Engine) {
+	switch {
+	case m.msg == nil:
+	case m.requestID != 0:
+		e.SendLocalRequest(m.target, m.msg, m.sender, m.requestID)
+	default:
+		e.SendLocal(m.target, m.msg, m.sender)
+	}
+}
+
+type reorderState struct {
+	next    uint64
+	pending map[uint64]reorderedMsg
+	used    time.Time
+	timer   *time.Timer
+	// timerGen 在每次启动或停止定时器时递增。
+	timerGen uint64
+	// ready 是已经可以投递、等待在锁外投递的消息。dispatching 表示有 goroutine 正在投递 ready。
+	ready       []reorderedMsg
+	dispatching bool
+}
+
+// reorderBuffer 按序列号投递消息。多个连接的 streamReader 并发调用 deliver，
+// 消息在 mu 之外投递给本地 actor。
+type reorderBuffer struct {
+	engine *actor.Engine
+
+	mu        sync.Mutex
+	states    map[reorderKey]*reorderState
+	lastSweep time.Time
+}
+
+func newReorderBuffer(e *actor.Engine) *reorderBuffer {
+	return &reorderBuffer{
+		engine:    e,
+		states:    make(map[reorderKey]*reorderState),
+		lastSweep: time.Now(),
+	}
+}
+
+// deliver 投递序列号为 seq 的消息，或者在前面的消息到达之前缓存它。
+// m.msg 为 nil 表示这条消息被丢弃，只推进序列号。
+func (b *reorderBuffer) deliver(epoch, seq uint64, m reorderedMsg) {
+	b.mu.Lock()
+
+	now := time.Now()
+	b.sweep(now)
+	key := reorderKey{epoch: epoch, target: m.target.LookupKey()}
+	st, ok := b.states[key]
+	if !ok {
+		// 发送端的序列号从 1 开始，新的消息流也从 1 开始等待，先到达的后续消息被缓存。
+		// 接收端已经忘记的消息流（空闲超过 reorderIdleTimeout）在等待缺口超时后继续投递。
+		st = &reorderState{next: 1, pending: make(map[uint64]reorderedMsg)}
+		b.states[key] = st
+	}
+	st.used = now
+
+	switch {
+	case seq < st.next:
+		// 缺口已经被跳过之后才到达的消息，直接投递。
+		st.ready = append(st.ready, m)
+	case seq == st.next:
+		st.ready = append(st.ready, m)
+		st.next++
+		b.flush(st)
+	default:
+		st.pending[seq] = m
+		if len(st.pending) > maxReorderPending {
+			b.skipGap(st)
+		}
+		b.armTimer(key, st)
+	}
+	b.dispatch(st)
+}
+
+// dispatch 在 mu 之外按顺序投递 st.ready 中的消息。同一个消息流同时只有一个 goroutine 投递，
+// 其他 goroutine 就绪的消息由它继续投递。调用时持有 mu，返回时 mu 已被释放。
+func (b *reorderBuffer) dispatch(st *reorderState) {
+	if st.dispatching {
+		b.mu.Unlock()
+		return
+	}
+	st.dispatching = true
+	for len(st.ready) > 0 {
+		ready := st.ready
+		st.ready = nil
+		b.mu.Unlock()
+		for _, m := range ready {
+			m.send(b.engine)
+		}
+		b.mu.Lock()
+	}
+	st.dispatching = false
+	b.mu.Unlock()
+}
+
+// armTimer 在有缓存的消息时启动等待缺失消息的定时器。
+func (b *reorderBuffer) armTimer(key reorderKey, st *reorderState) {
+	if st.timer != nil || len(st.pending) == 0 {
+		return
+	}
+	st.timerGen++
+	gen := st.timerGen
+	st.timer = time.AfterFunc(reorderGapTimeout, func() { b.expire(key, gen) })
+}
+
+// expire 在等待缺失消息超时后跳过缺口。gen 用于忽略已经被停止、但已触发的旧定时器。
+func (b *reorderBuffer) expire(key reorderKey, gen uint64) {
+	b.mu.Lock()
+	st, ok := b.states[key]
+	if !ok || st.timerGen != gen {
+		b.mu.Unlock()
+		return
+	}
+	st.timer = nil
+	b.skipGap(st)
+	b.armTimer(key, st)
+	b.dispatch(st)
+}
+
+// skipGap 跳到最小的已缓存序列号并继续投递。
+func (b *reorderBuffer) skipGap(st *reorderState) {
+	var lowest uint64
+	for seq := range st.pending {
+		if lowest == 0 || seq < lowest {
+			lowest = seq
+		}
+	}
+	if lowest > st.next {
+		st.next = lowest
+	}
+	b.flush(st)
+}
+
+// flush 把从 st.next 开始连续的已缓存消息移到 st.ready。
+func (b *reorderBuffer) flush(st *reorderState) {
+	for {
+		m, ok := st.pending[st.next]
+		if !ok {
+			break
+		}
+		delete(st.pending, st.next)
+		st.ready = append(st.ready, m)
+		st.next++
+	}
+	if len(st.pending) == 0 && st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+		st.timerGen++
+	}
+}
+
+// sweep 删除空闲的序列状态。
+func (b *reorderBuffer) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < reorderIdleTimeout {
+		return
+	}
+	for key, st := range b.states {
+		if len(st.pending) == 0 && !st.dispatching && now.Sub(st.used) > reorderIdleTimeout {
+			delete(b.states, key)
+		}
+	}
+	b.lastSweep = now
+}
@@ -0,0 +1,173 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderCollector 记录收到的 TestMessage 的内容。
+type orderCollector struct {
+	mu   sync.Mutex
+	got  []string
+	recv chan struct{}
+}
+
+func newOrderCollector(t *testing.T) (*actor.Engine, *actor.PID, *orderCollector) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	c := &orderCollector{recv: make(chan struct{}, 128)}
+	pid := e.SpawnFunc(func(ctx *actor.Context) {
+		if msg, ok := ctx.Message().(*TestMessage); ok {
+			c.mu.Lock()
+			c.got = append(c.got, string(msg.Data))
+			c.mu.Unlock()
+			c.recv <- struct{}{}
+		}
+	}, "collector")
+	return e, pid, c
+}
+
+func (c *orderCollector) wait(t *testing.T, n int) []string {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.recv:
+		case <-time.After(time.Second):
+			t.Fatalf("只收到 %d 条消息，期望 %d 条", i, n)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.got...)
+}
+
+func testMsg(s string) *TestMessage { return &TestMessage{Data: []byte(s)} }
+
+func TestReorderBuffer(t *testing.T) {
+	e, pid, c := newOrderCollector(t)
+	b := newReorderBuffer(e)
+
+	// 新的消息流从 1 开始，先于第一条消息到达的消息被缓存。
+	b.deliver(1, 2, reorderedMsg{target: pid, msg: testMsg("b")})
+	b.deliver(1, 1, reorderedMsg{target: pid, msg: testMsg("a")})
+	b.deliver(1, 4, reorderedMsg{target: pid, msg: testMsg("d")})
+	b.deliver(1, 3, reorderedMsg{target: pid, msg: testMsg("c")})
+	start := time.Now()
+	assert.Equal(t, []string{"a", "b", "c", "d"}, c.wait(t, 4))
+	assert.Less(t, time.Since(start), reorderGapTimeout)
+
+	// 被丢弃的消息只推进序列号。
+	b.deliver(1, 6, reorderedMsg{target: pid, msg: testMsg("f")})
+	b.deliver(1, 5, reorderedMsg{target: pid})
+	assert.Equal(t, []string{"a", "b", "c", "d", "f"}, c.wait(t, 1))
+
+	// 不同的 epoch 是独立的消息流。
+	b.deliver(2, 1, reorderedMsg{target: pid, msg: testMsg("x")})
+	assert.Equal(t, "x", c.wait(t, 1)[5])
+}
+
+func TestReorderBufferSkipsGap(t *testing.T) {
+	e, pid, c := newOrderCollector(t)
+	b := newReorderBuffer(e)
+
+	b.deliver(1, 1, reorderedMsg{target: pid, msg: testMsg("a")})
+	b.deliver(1, 3, reorderedMsg{target: pid, msg: testMsg("c")})
+	start := time.Now()
+	assert.Equal(t, []string{"a", "c"}, c.wait(t, 2))
+	assert.GreaterOrEqual(t, time.Since(start), reorderGapTimeout/2)
+
+	// 缺口被跳过之后才到达的消息直接投递。
+	b.deliver(1, 2, reorderedMsg{target: pid, msg: testMsg("b")})
+	b.deliver(1, 4, reorderedMsg{target: pid, msg: testMsg("d")})
+	assert.Equal(t, []string{"a", "c", "b", "d"}, c.wait(t, 2))
+}
+
+func TestReorderBufferUnknownStream(t *testing.T) {
+	e, pid, c := newOrderCollector(t)
+	b := newReorderBuffer(e)
+
+	// 接收端已经忘记的消息流不是从 1 开始，等待缺口超时后继续投递。
+	b.deliver(1, 10, reorderedMsg{target: pid, msg: testMsg("j")})
+	b.deliver(1, 11, reorderedMsg{target: pid, msg: testMsg("k")})
+	assert.Equal(t, []string{"j", "k"}, c.wait(t, 2))
+}
+
+// reentrantProc 在收到消息时向 reorderBuffer 投递另一个消息流的消息。
+type reentrantProc struct {
+	pid    *actor.PID
+	buffer *reorderBuffer
+	next   *actor.PID
+}
+
+func (p *reentrantProc) Start()                  {}
+func (p *reentrantProc) PID() *actor.PID         { return p.pid }
+func (p *reentrantProc) Invoke([]actor.Envelope) {}
+func (p *reentrantProc) Shutdown()               {}
+
+func (p *reentrantProc) Send(_ *actor.PID, msg any, _ *actor.PID) {
+	p.buffer.deliver(1, 1, reorderedMsg{target: p.next, msg: msg})
+}
+
+func TestReorderBufferDeliversOutsideLock(t *testing.T) {
+	e, pid, c := newOrderCollector(t)
+	b := newReorderBuffer(e)
+	proc := &reentrantProc{pid: actor.NewPID(e.Address(), "reentrant"), buffer: b, next: pid}
+	e.SpawnProc(proc)
+
+	done := make(chan struct{})
+	go func() {
+		b.deliver(1, 1, reorderedMsg{target: proc.pid, msg: testMsg("a")})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("投递消息时持有 reorderBuffer 的锁")
+	}
+	assert.Equal(t, []string{"a"}, c.wait(t, 1))
+}
+
+func TestSequencer(t *testing.T) {
+	s := newSequencer()
+	a := actor.NewPID("127.0.0.1:4000", "a")
+	b := actor.NewPID("127.0.0.1:4000", "b")
+	assert.Equal(t, uint64(1), s.next(a))
+	assert.Equal(t, uint64(2), s.next(a))
+	assert.Equal(t, uint64(1), s.next(b))
+	assert.Equal(t, uint64(3), s.next(a))
+}
+
+func TestSend_Ordering(t *testing.T) {
+	for name, enabled := range map[string]bool{"enabled": true, "disabled": false} {
+		t.Run(name, func(t *testing.T) {
+			config := NewConfig().WithOrdering(enabled)
+			a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+			b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+			require.NoError(t, err)
+
+			const n = 100
+			var got []byte
+			wg := &sync.WaitGroup{}
+			wg.Add(n)
+			pida := a.SpawnFunc(func(c *actor.Context) {
+				if msg, ok := c.Message().(*TestMessage); ok {
+					got = append(got, msg.Data[0])
+					wg.Done()
+				}
+			}, "actor on a")
+			for i := 0; i < n; i++ {
+				b.Send(pida, &TestMessage{Data: []byte{byte(i)}})
+			}
+			wg.Wait()
+			for i := 0; i < n; i++ {
+				assert.Equal(t, byte(i), got[i])
+			}
+		})
+	}
+}
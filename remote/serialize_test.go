@@ -3,7 +3,10 @@ package remote
 import (
 	"testing"
 
+	"github.com/TAnNbR/Distributed-framework/actor"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestProtoSerializer(t *testing.T) {
@@ -14,6 +17,11 @@ func TestProtoSerializer(t *testing.T) {
 	sermsg, err := ProtoSerializer{}.Deserialize(b, ProtoSerializer{}.TypeName(msg))
 	assert.Nil(t, err)
 	assert.Equal(t, msg.Data, sermsg.(*TestMessage).Data)
+
+	// vtproto 路径的编码与 proto.Marshal 相同。
+	want, err := proto.Marshal(msg)
+	assert.Nil(t, err)
+	assert.Equal(t, want, b)
 }
 
 // chmarkSerialize-12    	 8748982	       137.9 ns/op	     144 B/op	       2 allocs/op
@@ -30,3 +38,84 @@ func TestProtoSerializer(t *testing.T) {
 // 		serialize(pid, sender, payload)
 // 	}
 // }
+
+// BenchmarkProtoSerializer 比较 proto 的反射路径和 ProtoSerializer 自动选择的 vtproto 路径。
+func BenchmarkProtoSerializer(b *testing.B) {
+	msg := &Envelope{
+		TypeNames: []string{"remote.TestMessage"},
+		Targets:   []*actor.PID{actor.NewPID("127.0.0.1:4000", "foo")},
+		Senders:   []*actor.PID{actor.NewPID("127.0.0.1:8000", "bar")},
+	}
+	for i := 0; i < 16; i++ {
+		msg.Messages = append(msg.Messages, &Message{Data: []byte("some number of bytes in here would be nice"), Seq: uint64(i + 1)})
+	}
+	tname := ProtoSerializer{}.TypeName(msg)
+
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := proto.Marshal(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := proto.Unmarshal(data, &Envelope{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("vtproto", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, err := ProtoSerializer{}.Serialize(msg)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := (ProtoSerializer{}).Deserialize(data, tname); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestMarshalVTBatch(t *testing.T) {
+	var b writeBatch
+	var all [][]byte
+	// 超过初始容量，之前的数据不受 buf 重新分配的影响。
+	for i := 0; i < 200; i++ {
+		data, err := b.marshalVT(&TestMessage{Data: []byte("some number of bytes in here would be nice")})
+		require.NoError(t, err)
+		all = append(all, data)
+	}
+	want, err := proto.Marshal(&TestMessage{Data: []byte("some number of bytes in here would be nice")})
+	require.NoError(t, err)
+	for _, data := range all {
+		assert.Equal(t, want, data)
+	}
+}
+
+// BenchmarkStreamWriterEncode 比较为每条消息分配内存的 Serialize 和流写入器共享批次缓冲区的序列化。
+func BenchmarkStreamWriterEncode(b *testing.B) {
+	msg := &TestMessage{Data: []byte("some number of bytes in here would be nice")}
+
+	b.Run("Serialize", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := (ProtoSerializer{}).Serialize(msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		w := &streamWriter{codecs: []Codec{ProtoSerializer{}}}
+		w.batch.reset()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, _, err := w.encode(msg); err != nil {
+				b.Fatal(err)
+			}
+			if i%1024 == 1023 {
+				w.batch.reset()
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// protoCodecName 是 ProtoSerializer 的名称，也是 Envelope 没有记录序列化格式时使用的格式。
+const protoCodecName = "proto"
+
+// Codec 是可以放入序列化链的序列化格式，参见 WithSerializers。
+type Codec interface {
+	Serializer
+	Deserializer
+	// Name 是记录在 Envelope 中的格式名称，两端使用同一名称识别格式。
+	Name() string
+	// Accepts 报告 msg 能否使用这个格式序列化。
+	Accepts(msg any) bool
+}
+
+// WithSerializers 设置按顺序尝试的序列化链，例如 VTProtoSerializer → ProtoSerializer → JSONSerializer：
+// 每条消息使用第一个接受它的格式序列化，格式的名称随消息记录在 Envelope 中，接收端据此选择反序列化的格式。
+// 这样不同格式的消息可以混合发送，不必把所有消息转换为同一种格式。接收端必须配置发送端使用的所有格式，
+// 未配置时只使用 ProtoSerializer。启用 WithNegotiation 时，双方在握手中交换各自的序列化链，
+// 发送端只使用对方也配置了的格式。
+func (c Config) WithSerializers(codecs ...Codec) Config {
+	c.Serializers = append([]Codec(nil), codecs...)
+	return c
+}
+
+// codecs 返回配置的序列化链。
+func (c Config) codecs() []Codec {
+	if len(c.Serializers) == 0 {
+		return []Codec{ProtoSerializer{}}
+	}
+	return c.Serializers
+}
+
+// codecsByName 返回接收端按名称查找格式的表，总是包含 "proto" 以接收没有记录格式的 Envelope。
+func (c Config) codecsByName() map[string]Codec {
+	m := map[string]Codec{protoCodecName: ProtoSerializer{}}
+	for _, codec := range c.codecs() {
+		m[codec.Name()] = codec
+	}
+	return m
+}
+
+// Name 实现 Codec。
+func (ProtoSerializer) Name() string { return protoCodecName }
+
+// Accepts 实现 Codec。
+func (ProtoSerializer) Accepts(msg any) bool {
+	_, ok := msg.(proto.Message)
+	return ok
+}
+
+// Name 实现 Codec。
+func (VTProtoSerializer) Name() string { return "vtproto" }
+
+// Accepts 实现 Codec。接收端必须用 RegisterType 注册消息的类型。
+func (VTProtoSerializer) Accepts(msg any) bool {
+	_, ok := msg.(VTMarshaler)
+	return ok
+}
+
+// jsonTypes 是 JSONSerializer 的类型名称到类型的映射。
+var jsonTypes sync.Map
+
+// RegisterJSONType 注册一个可以由 JSONSerializer 发送和接收的类型。v 是指针时接收端得到指针，
+// 否则得到值。两端都要注册同一类型。
+func RegisterJSONType(v any) {
+	t := reflect.TypeOf(v)
+	jsonTypes.Store(jsonTypeName(t), t)
+}
+
+// jsonTypeName 返回类型的完整名称，例如 "*github.com/acme/app.Order"。
+func jsonTypeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + jsonTypeName(t.Elem())
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// JSONSerializer 使用 encoding/json 序列化通过 RegisterJSONType 注册的类型，
+// 用于没有 protobuf 定义的消息。
+type JSONSerializer struct{}
+
+// Name 实现 Codec。
+func (JSONSerializer) Name() string { return "json" }
+
+// Accepts 实现 Codec，只接受注册过的类型。
+func (JSONSerializer) Accepts(msg any) bool {
+	_, ok := jsonTypes.Load(jsonTypeName(reflect.TypeOf(msg)))
+	return ok
+}
+
+// TypeName 返回消息的类型名称。
+func (JSONSerializer) TypeName(msg any) string {
+	return jsonTypeName(reflect.TypeOf(msg))
+}
+
+// Serialize 序列化消息。
+func (JSONSerializer) Serialize(msg any) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+// Deserialize 反序列化消息。
+func (JSONSerializer) Deserialize(data []byte, tname string) (any, error) {
+	v, ok := jsonTypes.Load(tname)
+	if !ok {
+		return nil, fmt.Errorf("给定类型 (%s) 未注册。你是否忘记使用 remote.RegisterJSONType 注册你的类型？", tname)
+	}
+	t := v.(reflect.Type)
+	if t.Kind() == reflect.Pointer {
+		pv := reflect.New(t.Elem())
+		if err := json.Unmarshal(data, pv.Interface()); err != nil {
+			return nil, err
+		}
+		return pv.Interface(), nil
+	}
+	pv := reflect.New(t)
+	if err := json.Unmarshal(data, pv.Interface()); err != nil {
+		return nil, err
+	}
+	return pv.Elem().Interface(), nil
+}
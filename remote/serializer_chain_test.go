@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonOrder struct {
+	ID    string
+	Items []string
+}
+
+func init() {
+	RegisterJSONType(&jsonOrder{})
+}
+
+func TestSerializerChainEncode(t *testing.T) {
+	w := &streamWriter{codecs: []Codec{VTProtoSerializer{}, ProtoSerializer{}, JSONSerializer{}}}
+	codec, tname, _, err := w.encode(testMsg("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "vtproto", codec)
+	assert.Equal(t, "remote.TestMessage", tname)
+
+	codec, tname, data, err := w.encode(&jsonOrder{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "json", codec)
+	assert.Equal(t, "*github.com/TAnNbR/Distributed-framework/remote.jsonOrder", tname)
+	v, err := JSONSerializer{}.Deserialize(data, tname)
+	require.NoError(t, err)
+	assert.Equal(t, &jsonOrder{ID: "1"}, v)
+
+	_, _, _, err = w.encode("not registered")
+	assert.Error(t, err)
+}
+
+func TestSerializerChain(t *testing.T) {
+	config := NewConfig().WithSerializers(VTProtoSerializer{}, ProtoSerializer{}, JSONSerializer{})
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), config)
+	require.NoError(t, err)
+
+	got := make(chan any, 2)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case *TestMessage, *jsonOrder:
+			got <- msg
+		}
+	}, "foo")
+	a.Send(pidb, testMsg("proto"))
+	a.Send(pidb, &jsonOrder{ID: "1", Items: []string{"x"}})
+	// 消息按发送顺序投递。
+	recv := func() any {
+		select {
+		case msg := <-got:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("没有收到消息")
+			return nil
+		}
+	}
+	assert.Equal(t, "proto", string(recv().(*TestMessage).Data))
+	assert.Equal(t, &jsonOrder{ID: "1", Items: []string{"x"}}, recv())
+}
+
+func TestSerializerChainUnknownFormat(t *testing.T) {
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithSerializers(JSONSerializer{}))
+	require.NoError(t, err)
+	// 接收端只使用默认的 ProtoSerializer。
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	errs := make(chan actor.RemoteDeserializationErrorEvent, 1)
+	sub := b.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteDeserializationErrorEvent); ok {
+			errs <- ev
+		}
+	}, "events")
+	b.Subscribe(sub)
+
+	a.Send(actor.NewPID(b.Address(), "foo"), &jsonOrder{ID: "1"})
+	select {
+	case ev := <-errs:
+		assert.ErrorContains(t, ev.Err, "json")
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到 RemoteDeserializationErrorEvent")
+	}
+}
@@ -0,0 +1,171 @@
+package remote
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// unknownPeer 是无法确定发送者地址的入站消息使用的 Peer。
+const unknownPeer = "unknown"
+
+// WithStatsInterval 使远程模块每隔 interval 为每个远程节点在事件流上广播一次
+// actor.RemoteStatsEvent（收发字节数、消息速率、序列化错误和发送队列长度）。
+// interval <= 0 时不统计，这也是默认值。
+func (c Config) WithStatsInterval(interval time.Duration) Config {
+	c.StatsInterval = interval
+	return c
+}
+
+// peerStats 是一个远程节点在当前统计间隔内的计数。
+type peerStats struct {
+	bytesIn         atomic.Uint64
+	bytesOut        atomic.Uint64
+	msgsIn          atomic.Uint64
+	msgsOut         atomic.Uint64
+	serializeErrors atomic.Uint64
+}
+
+// addIn 记录收到的 Envelope，p 为 nil 时什么也不做。
+func (p *peerStats) addIn(bytes, msgs int) {
+	if p == nil {
+		return
+	}
+	p.bytesIn.Add(uint64(bytes))
+	p.msgsIn.Add(uint64(msgs))
+}
+
+// addOut 记录发送的 Envelope，p 为 nil 时什么也不做。
+func (p *peerStats) addOut(bytes, msgs int) {
+	if p == nil {
+		return
+	}
+	p.bytesOut.Add(uint64(bytes))
+	p.msgsOut.Add(uint64(msgs))
+}
+
+// addSerializeError 记录一次序列化失败，p 为 nil 时什么也不做。
+func (p *peerStats) addSerializeError() {
+	if p == nil {
+		return
+	}
+	p.serializeErrors.Add(1)
+}
+
+// remoteStats 收集每个远程节点的统计并定期广播。
+type remoteStats struct {
+	engine   *actor.Engine
+	interval time.Duration
+
+	mu     sync.Mutex
+	peers  map[string]*peerStats
+	queues map[string]*actor.Inbox
+	last   time.Time
+}
+
+func newRemoteStats(e *actor.Engine, interval time.Duration) *remoteStats {
+	return &remoteStats{
+		engine:   e,
+		interval: interval,
+		peers:    make(map[string]*peerStats),
+		queues:   make(map[string]*actor.Inbox),
+		last:     time.Now(),
+	}
+}
+
+// peer 返回 addr 的计数，s 为 nil（未启用统计）时返回 nil。
+func (s *remoteStats) peer(addr string) *peerStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.peers[addr]
+	if !ok {
+		p = &peerStats{}
+		s.peers[addr] = p
+	}
+	return p
+}
+
+// attach 记录 addr 的流写入器收件箱，用于报告发送队列长度。
+func (s *remoteStats) attach(addr string, inbox *actor.Inbox) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.queues[addr] = inbox
+	s.mu.Unlock()
+}
+
+// detach 在流写入器关闭时删除它的收件箱。
+func (s *remoteStats) detach(addr string, inbox *actor.Inbox) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.queues[addr] == inbox {
+		delete(s.queues, addr)
+	}
+	s.mu.Unlock()
+}
+
+// run 每隔 interval 广播一次统计，直到 done 被关闭。
+func (s *remoteStats) run(done <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.report()
+		case <-done:
+			return
+		}
+	}
+}
+
+// report 为每个远程节点广播本次间隔内的统计并清零计数。
+// 没有流量、也没有流写入器的节点不再报告。
+func (s *remoteStats) report() {
+	s.mu.Lock()
+	now := time.Now()
+	interval := now.Sub(s.last)
+	s.last = now
+	events := make([]actor.RemoteStatsEvent, 0, len(s.peers))
+	for addr, p := range s.peers {
+		ev := actor.RemoteStatsEvent{
+			Peer:            addr,
+			Interval:        interval,
+			BytesIn:         p.bytesIn.Swap(0),
+			BytesOut:        p.bytesOut.Swap(0),
+			MessagesIn:      p.msgsIn.Swap(0),
+			MessagesOut:     p.msgsOut.Swap(0),
+			SerializeErrors: p.serializeErrors.Swap(0),
+		}
+		inbox, attached := s.queues[addr]
+		if attached {
+			ev.QueueDepth = inbox.Len()
+		}
+		if !attached && ev.MessagesIn == 0 && ev.MessagesOut == 0 && ev.SerializeErrors == 0 {
+			delete(s.peers, addr)
+			continue
+		}
+		events = append(events, ev)
+	}
+	s.mu.Unlock()
+	for _, ev := range events {
+		s.engine.BroadcastEvent(ev)
+	}
+}
+
+// inboundPeer 返回 Envelope 的发送节点的地址，没有发送者时返回 unknownPeer。
+func inboundPeer(envelope *Envelope) string {
+	for _, sender := range envelope.Senders {
+		if sender != nil && sender.Address != "" {
+			return sender.Address
+		}
+	}
+	return unknownPeer
+}
@@ -0,0 +1,115 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// subscribeStats 订阅 e 的事件流中 peer 的 RemoteStatsEvent。
+func subscribeStats(e *actor.Engine, peer string) <-chan actor.RemoteStatsEvent {
+	events := make(chan actor.RemoteStatsEvent, 64)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteStatsEvent); ok && ev.Peer == peer {
+			events <- ev
+		}
+	}, "stats")
+	e.Subscribe(sub)
+	return events
+}
+
+// sumStats 累加收到的统计，直到 done 返回 true。
+func sumStats(t *testing.T, events <-chan actor.RemoteStatsEvent, done func(total actor.RemoteStatsEvent) bool) actor.RemoteStatsEvent {
+	t.Helper()
+	var total actor.RemoteStatsEvent
+	timeout := time.After(3 * time.Second)
+	for !done(total) {
+		select {
+		case ev := <-events:
+			assert.Greater(t, ev.Interval, time.Duration(0))
+			total.BytesIn += ev.BytesIn
+			total.BytesOut += ev.BytesOut
+			total.MessagesIn += ev.MessagesIn
+			total.MessagesOut += ev.MessagesOut
+			total.SerializeErrors += ev.SerializeErrors
+		case <-timeout:
+			t.Fatalf("统计不完整: %+v", total)
+		}
+	}
+	return total
+}
+
+func TestRemoteStats(t *testing.T) {
+	const msgs = 10
+	config := NewConfig().WithStatsInterval(50 * time.Millisecond)
+	aAddr, bAddr := getRandomLocalhostAddr(), getRandomLocalhostAddr()
+	a, _, err := makeRemoteEngineWithConfig(aAddr, config)
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(bAddr, config)
+	require.NoError(t, err)
+	inbound := subscribeStats(a, bAddr)
+	outbound := subscribeStats(b, aAddr)
+
+	pida := a.SpawnFunc(func(c *actor.Context) {}, "actor on a")
+	pidb := b.SpawnFunc(func(c *actor.Context) {}, "actor on b")
+	for i := 0; i < msgs; i++ {
+		b.SendWithSender(pida, &TestMessage{Data: []byte("foo")}, pidb)
+	}
+
+	out := sumStats(t, outbound, func(total actor.RemoteStatsEvent) bool {
+		return total.MessagesOut >= msgs
+	})
+	assert.Equal(t, uint64(msgs), out.MessagesOut)
+	assert.Greater(t, out.BytesOut, uint64(0))
+
+	in := sumStats(t, inbound, func(total actor.RemoteStatsEvent) bool {
+		return total.MessagesIn >= msgs
+	})
+	assert.Equal(t, uint64(msgs), in.MessagesIn)
+	assert.Greater(t, in.BytesIn, uint64(0))
+}
+
+func TestRemoteStatsReport(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	events := make(chan actor.RemoteStatsEvent, 4)
+	sub := e.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteStatsEvent); ok {
+			events <- ev
+		}
+	}, "stats")
+	e.Subscribe(sub)
+
+	s := newRemoteStats(e, time.Second)
+	inbox := actor.NewInbox(8)
+	s.attach("peer", inbox)
+	p := s.peer("peer")
+	p.addOut(100, 2)
+	p.addSerializeError()
+	time.Sleep(10 * time.Millisecond)
+	s.report()
+
+	select {
+	case ev := <-events:
+		assert.Equal(t, "peer", ev.Peer)
+		assert.Equal(t, uint64(100), ev.BytesOut)
+		assert.Equal(t, uint64(2), ev.MessagesOut)
+		assert.Equal(t, uint64(1), ev.SerializeErrors)
+		assert.Greater(t, ev.MessagesOutPerSec(), 0.0)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteStatsEvent")
+	}
+
+	// 流写入器关闭、也没有流量的节点不再报告。
+	s.detach("peer", inbox)
+	s.report()
+	assert.Empty(t, s.peers)
+
+	// 未启用统计时所有操作都是空操作。
+	var disabled *remoteStats
+	disabled.peer("peer").addIn(1, 1)
+	disabled.attach("peer", inbox)
+}
@@ -1,59 +1,185 @@
 package remote
 
 import (
-	"context"
-	"errors"
-	"log/slog"
+	"fmt"
+	"time"
 
 	"github.com/TAnNbR/Distributed-framework/actor"
 )
 
-// streamReader 是流读取器，负责接收远程消息。
+// streamReader 是流读取器，负责把远程消息投递给本地 actor。
 type streamReader struct {
-	DRPCRemoteUnimplementedServer
-
-	remote       *Remote
-	deserializer Deserializer
+	remote *Remote
+	// codecs 是格式名称到反序列化格式的映射，参见 WithSerializers。
+	codecs map[string]Codec
+	// reorder 按序列号投递消息，禁用顺序保证时为 nil。
+	reorder *reorderBuffer
 }
 
 // newStreamReader 创建一个新的流读取器。
 func newStreamReader(r *Remote) *streamReader {
-	return &streamReader{
-		remote:       r,
-		deserializer: ProtoSerializer{},
+	reader := &streamReader{
+		remote: r,
+		codecs: r.config.codecsByName(),
+	}
+	if !r.config.DisableOrdering {
+		reader.reorder = newReorderBuffer(r.engine)
 	}
+	return reader
 }
 
-// Receive 接收并处理远程消息。
-func (r *streamReader) Receive(stream DRPCRemote_ReceiveStream) error {
-	defer slog.Debug("流读取器已终止")
+// handler 返回一个入站连接的 EnvelopeHandler，limiter 是该连接的入站限流器，未启用限流时为 nil。
+func (r *streamReader) handler(limiter *inboundLimiter) EnvelopeHandler {
+	return func(envelope *Envelope) error {
+		return r.handle(envelope, limiter)
+	}
+}
 
-	for {
-		envelope, err := stream.Recv()
+// handle 反序列化 Envelope 中的消息并投递给本地的目标。
+// 无法反序列化的消息被丢弃并广播 RemoteDeserializationErrorEvent，不会断开连接。
+func (r *streamReader) handle(envelope *Envelope, limiter *inboundLimiter) error {
+	if r.remote.stats != nil {
+		r.remote.stats.peer(inboundPeer(envelope)).addIn(envelope.SizeVT(), len(envelope.Messages))
+	}
+	var (
+		dropped int
+		delay   time.Duration
+	)
+	if limiter != nil {
+		defer func() { r.reportThrottled(limiter.host, dropped, delay) }()
+	}
+	for _, msg := range envelope.Messages {
+		tname, target, sender, err := resolve(envelope, msg)
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				break
+			// 索引来自网络，越界的消息按无法反序列化丢弃，不影响同一批次的其他消息。
+			r.remote.engine.BroadcastEvent(actor.RemoteDeserializationErrorEvent{
+				Sender:   sender,
+				Target:   target,
+				TypeName: tname,
+				Err:      err,
+			})
+			if target != nil {
+				r.skip(envelope.Epoch, msg.Seq, target)
 			}
-			slog.Error("流读取器接收", "err", err)
-			return err
+			continue
 		}
+		if limiter != nil && !r.throttle(limiter, &delay) {
+			dropped++
+			r.skip(envelope.Epoch, msg.Seq, target)
+			continue
+		}
+		if max := r.remote.config.MaxMessageSize; max > 0 && len(msg.Data) > max {
+			r.reject(msg, tname, target, sender)
+			// 被丢弃的消息也要推进序列号，避免后面的消息等待它。
+			r.skip(envelope.Epoch, msg.Seq, target)
+			continue
+		}
+		if r.remote.relays(target.Address) {
+			r.forward(envelope, msg, tname, target, sender)
+			continue
+		}
+		if !r.verify(msg, tname, target, sender) {
+			r.skip(envelope.Epoch, msg.Seq, target)
+			continue
+		}
+		payload, err := r.deserialize(envelope, msg, tname)
+		if err != nil {
+			// 丢弃这条消息而不是断开连接，同一批次和之后的消息照常投递。
+			r.remote.engine.BroadcastEvent(actor.RemoteDeserializationErrorEvent{
+				Sender:   sender,
+				Target:   target,
+				TypeName: tname,
+				Err:      err,
+			})
+			r.skip(envelope.Epoch, msg.Seq, target)
+			continue
+		}
+		payload = withTrace(payload, msg.Trace)
+		if msg.ResponseID != 0 {
+			// 响应不带序列号，直接交给等待的 Response。
+			r.remote.engine.SendLocalResponse(target, payload, msg.ResponseID)
+			continue
+		}
+		r.deliver(envelope.Epoch, msg.Seq, reorderedMsg{target: target, sender: sender, msg: payload, requestID: msg.RequestID})
+	}
+	return nil
+}
 
-		for _, msg := range envelope.Messages {
-			tname := envelope.TypeNames[msg.TypeNameIndex]
-			payload, err := r.deserializer.Deserialize(msg.Data, tname)
-
-			if err != nil {
-				slog.Error("流读取器反序列化", "err", err)
-				return err
-			}
-			target := envelope.Targets[msg.TargetIndex]
-			var sender *actor.PID
-			if len(envelope.Senders) > 0 {
-				sender = envelope.Senders[msg.SenderIndex]
-			}
-			r.remote.engine.SendLocal(target, payload, sender)
+// resolve 返回消息的类型名称、目标和发送者。索引来自网络，越界或目标为空时返回错误，
+// 已经解析的字段照常返回，用于报告。
+func resolve(envelope *Envelope, msg *Message) (tname string, target, sender *actor.PID, err error) {
+	target, ok := at(envelope.Targets, msg.TargetIndex)
+	if !ok || target == nil {
+		return "", nil, nil, fmt.Errorf("目标索引 %d 无效（共 %d 个目标）", msg.TargetIndex, len(envelope.Targets))
+	}
+	tname, ok = at(envelope.TypeNames, msg.TypeNameIndex)
+	if !ok {
+		return "", target, nil, fmt.Errorf("类型索引 %d 越界（共 %d 个类型）", msg.TypeNameIndex, len(envelope.TypeNames))
+	}
+	if len(envelope.Senders) > 0 {
+		if sender, ok = at(envelope.Senders, msg.SenderIndex); !ok {
+			return tname, target, nil, fmt.Errorf("发送者索引 %d 越界（共 %d 个发送者）", msg.SenderIndex, len(envelope.Senders))
 		}
 	}
+	return tname, target, sender, nil
+}
 
-	return nil
+// at 返回 s[i]，i 越界时返回 false。
+func at[T any](s []T, i int32) (T, bool) {
+	if i < 0 || int(i) >= len(s) {
+		var zero T
+		return zero, false
+	}
+	return s[i], true
+}
+
+// deliver 把消息投递给本地的目标。消息带有序列号时按序列号的顺序投递；m.msg 为 nil 时只推进序列号。
+func (r *streamReader) deliver(epoch, seq uint64, m reorderedMsg) {
+	if r.reorder != nil && seq != 0 {
+		r.reorder.deliver(epoch, seq, m)
+		return
+	}
+	m.send(r.remote.engine)
+}
+
+// skip 推进被丢弃的消息的序列号，避免后面的消息等待它。
+func (r *streamReader) skip(epoch, seq uint64, target *actor.PID) {
+	r.deliver(epoch, seq, reorderedMsg{target: target})
+}
+
+// deserialize 使用消息记录的格式反序列化消息。
+func (r *streamReader) deserialize(envelope *Envelope, msg *Message, tname string) (any, error) {
+	name := messageSerializer(envelope, msg)
+	codec, ok := r.codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的序列化格式 %q", name)
+	}
+	return codec.Deserialize(msg.Data, tname)
+}
+
+// messageSerializer 返回消息使用的序列化格式的名称，Envelope 没有记录格式时返回 "proto"。
+func messageSerializer(envelope *Envelope, msg *Message) string {
+	if len(envelope.Serializers) == 0 {
+		return protoCodecName
+	}
+	if i := int(msg.SerializerIndex); i >= 0 && i < len(envelope.Serializers) {
+		return envelope.Serializers[i]
+	}
+	return ""
+}
+
+// forward 把发往已注册节点的消息交给中继到该节点的流写入器，不反序列化消息。
+func (r *streamReader) forward(envelope *Envelope, msg *Message, tname string, target, sender *actor.PID) {
+	relayed := &relayedMessage{typeName: tname, serializer: messageSerializer(envelope, msg), data: msg.Data, mac: msg.Mac}
+	r.remote.send(target, withTrace(relayed, msg.Trace), sender, msg.RequestID, msg.ResponseID)
+}
+
+// reject 丢弃超过 MaxMessageSize 的消息并广播 RemoteMessageRejectedEvent。
+func (r *streamReader) reject(msg *Message, tname string, target, sender *actor.PID) {
+	r.remote.engine.BroadcastEvent(actor.RemoteMessageRejectedEvent{
+		Sender:   sender,
+		Target:   target,
+		TypeName: tname,
+		Size:     len(msg.Data),
+	})
 }
@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxMessageSize(t *testing.T) {
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithMaxMessageSize(1024))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	rejected := make(chan actor.RemoteMessageRejectedEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteMessageRejectedEvent); ok {
+			rejected <- ev
+		}
+	}, "rejected_events")
+	a.Subscribe(sub)
+
+	var (
+		mu       sync.Mutex
+		received [][]byte
+		wg       sync.WaitGroup
+	)
+	wg.Add(2)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			mu.Lock()
+			received = append(received, msg.Data)
+			mu.Unlock()
+			wg.Done()
+		}
+	}, "actor on a")
+	pidb := b.SpawnFunc(func(c *actor.Context) {}, "actor on b")
+
+	b.SendWithSender(pida, &TestMessage{Data: []byte("small")}, pidb)
+	b.SendWithSender(pida, &TestMessage{Data: bytes.Repeat([]byte("x"), 4096)}, pidb)
+	b.SendWithSender(pida, &TestMessage{Data: []byte("after")}, pidb)
+
+	select {
+	case ev := <-rejected:
+		assert.Equal(t, "remote.TestMessage", ev.TypeName)
+		assert.True(t, ev.Size > 4096)
+		assert.True(t, ev.Sender.Equals(pidb))
+		assert.True(t, ev.Target.Equals(pida))
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteMessageRejectedEvent")
+	}
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, [][]byte{[]byte("small"), []byte("after")}, received)
+}
+
+func TestUnknownTypeDoesNotKillStream(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	failed := make(chan actor.RemoteDeserializationErrorEvent, 1)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteDeserializationErrorEvent); ok {
+			failed <- ev
+		}
+	}, "deserialization_events")
+	a.Subscribe(sub)
+
+	received := make(chan string, 2)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			received <- string(msg.Data)
+		}
+	}, "actor on a")
+	sender := actor.NewPID("127.0.0.1:4000", "sender")
+
+	data := func(s string) []byte {
+		b, err := testMsg(s).MarshalVT()
+		require.NoError(t, err)
+		return b
+	}
+	envelope := &Envelope{
+		TypeNames: []string{"remote.TestMessage", "remote.Unknown"},
+		Targets:   []*actor.PID{pida},
+		Senders:   []*actor.PID{sender},
+		Messages: []*Message{
+			{Data: data("before"), TypeNameIndex: 0},
+			{Data: []byte("???"), TypeNameIndex: 1},
+			{Data: data("after"), TypeNameIndex: 0},
+		},
+	}
+	require.NoError(t, newStreamReader(ra).handle(envelope, nil))
+
+	select {
+	case ev := <-failed:
+		assert.Equal(t, "remote.Unknown", ev.TypeName)
+		assert.True(t, ev.Sender.Equals(sender))
+		assert.True(t, ev.Target.Equals(pida))
+		assert.Error(t, ev.Err)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteDeserializationErrorEvent")
+	}
+	for _, want := range []string{"before", "after"} {
+		select {
+		case s := <-received:
+			assert.Equal(t, want, s)
+		case <-time.After(time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
+
+func TestStreamReaderInvalidIndex(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	failed := make(chan actor.RemoteDeserializationErrorEvent, 4)
+	sub := a.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteDeserializationErrorEvent); ok {
+			failed <- ev
+		}
+	}, "deserialization_events")
+	a.Subscribe(sub)
+
+	received := make(chan string, 1)
+	pida := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			received <- string(msg.Data)
+		}
+	}, "actor on a")
+	data, err := testMsg("valid").MarshalVT()
+	require.NoError(t, err)
+
+	// 越界的索引只丢弃对应的消息，不会使流读取器 panic。
+	envelope := &Envelope{
+		TypeNames: []string{"remote.TestMessage"},
+		Targets:   []*actor.PID{pida},
+		Senders:   []*actor.PID{actor.NewPID("127.0.0.1:4000", "sender")},
+		Messages: []*Message{
+			{Data: data, TargetIndex: 3},
+			{Data: data, TypeNameIndex: -1},
+			{Data: data, SenderIndex: 7},
+			{Data: data},
+		},
+	}
+	require.NoError(t, newStreamReader(ra).handle(envelope, nil))
+
+	for i := 0; i < 3; i++ {
+		select {
+		case ev := <-failed:
+			assert.Error(t, ev.Err)
+		case <-time.After(time.Second):
+			t.Fatal("没有收到 RemoteDeserializationErrorEvent")
+		}
+	}
+	select {
+	case s := <-received:
+		assert.Equal(t, "valid", s)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到有效的消息")
+	}
+}
+
+func TestStreamReaderResponseID(t *testing.T) {
+	a, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	resp := a.Request(actor.NewPID(getRandomLocalhostAddr(), "foo"), &TestMessage{}, time.Second)
+	id, err := strconv.ParseUint(strings.TrimPrefix(resp.PID().ID, "response/"), 10, 64)
+	require.NoError(t, err)
+	data, err := testMsg("pong").MarshalVT()
+	require.NoError(t, err)
+
+	// 响应按 responseID 交给等待的 Response，不经过 PID 路由和序列号。
+	envelope := &Envelope{
+		TypeNames: []string{"remote.TestMessage"},
+		Targets:   []*actor.PID{resp.PID()},
+		Messages:  []*Message{{Data: data, ResponseID: id}},
+	}
+	require.NoError(t, newStreamReader(ra).handle(envelope, nil))
+	v, err := resp.Result()
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(v.(*TestMessage).Data))
+}
@@ -1,8 +1,8 @@
 package remote
 
 import (
-	"crypto/tls"
-	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/TAnNbR/Distributed-framework/actor"
 )
@@ -12,26 +12,89 @@ type streamDeliver struct {
 	sender *actor.PID
 	target *actor.PID
 	msg    any
+	// seq 是流路由器分配的序列号，0 表示不保证顺序。
+	seq uint64
+	// deadline 是消息的过期时间，零值表示不会过期，参见 WithSendTTL。
+	deadline time.Time
+	// requestID 和 responseID 标识远程请求和它的响应，参见 Remote.SendRequest。
+	requestID  uint64
+	responseID uint64
+}
+
+// streamDeliverPool 复用 streamDeliver。Remote.Send 从池中取出，
+// 流写入器在序列化消息之后将其放回；未到达流写入器的 streamDeliver 交给 GC 回收。
+var streamDeliverPool = sync.Pool{New: func() any { return new(streamDeliver) }}
+
+// newStreamDeliver 从池中取出一个 streamDeliver 并填充字段。
+func newStreamDeliver(target *actor.PID, msg any, sender *actor.PID) *streamDeliver {
+	d := streamDeliverPool.Get().(*streamDeliver)
+	d.target = target
+	d.sender = sender
+	d.msg = msg
+	return d
+}
+
+// release 清空字段并将 streamDeliver 放回池中，之后不能再使用它。
+func (d *streamDeliver) release() {
+	*d = streamDeliver{}
+	streamDeliverPool.Put(d)
+}
+
+// streamFlush 要求所有流写入器把在它之前收到的消息写入网络。
+// routed 在路由器把 streamFlush 转发给所有流写入器后关闭，
+// 每个流写入器在写出消息后调用 wg.Done。close 为 true 时流写入器随后关闭连接。
+type streamFlush struct {
+	routed chan struct{}
+	wg     *sync.WaitGroup
+	close  bool
+}
+
+// streamClosed 通知流路由器 address 的旧流写入器已经从注册表移除。
+type streamClosed struct {
+	address string
 }
 
 // streamRouter 是流路由器，负责管理到不同远程地址的流写入器。
 type streamRouter struct {
 	engine *actor.Engine
+	remote *Remote
 	// streams 是远程地址到流写入器 pid 的映射。
-	streams   map[string]*actor.PID
+	streams map[string]*actor.PID
+	// writers 和 connected 记录 streams 中每个流写入器及其连接是否已经建立，供 remote/admin 查询。
+	writers   map[string]*streamWriter
+	connected map[string]bool
 	pid       *actor.PID
-	tlsConfig *tls.Config
-	buffSize  int
+	// breakers 是远程地址到熔断器的映射，仅在启用熔断时使用。
+	breakers map[string]*circuitBreaker
+	// seqs 为发送的消息分配序列号，禁用顺序保证时为 nil。
+	seqs *sequencer
+	// outboxes 是尚未连接的远程地址的发件箱，仅在启用发件箱时使用。
+	outboxes map[string]*outbox
+	// down 记录不可达的远程地址，用于在重新连接时广播 RemoteReconnectedEvent。
+	down map[string]bool
+	// closing 是正在关闭流写入器的远程地址到关闭期间收到的消息的映射。旧的流写入器从注册表移除之前
+	// 不能创建同名的新流写入器，这些消息在收到 streamClosed 之后发送。
+	closing map[string][]*streamDeliver
 }
 
 // newStreamRouter 创建一个新的流路由器。
-func newStreamRouter(e *actor.Engine, tlsConfig *tls.Config, buffSize int) actor.Producer {
+func newStreamRouter(r *Remote) actor.Producer {
 	return func() actor.Receiver {
+		var seqs *sequencer
+		if !r.config.DisableOrdering {
+			seqs = newSequencer()
+		}
 		return &streamRouter{
 			streams:   make(map[string]*actor.PID),
-			engine:    e,
-			tlsConfig: tlsConfig,
-			buffSize:  buffSize,
+			writers:   make(map[string]*streamWriter),
+			connected: make(map[string]bool),
+			engine:    r.engine,
+			remote:    r,
+			breakers:  make(map[string]*circuitBreaker),
+			seqs:      seqs,
+			outboxes:  make(map[string]*outbox),
+			down:      make(map[string]bool),
+			closing:   make(map[string][]*streamDeliver),
 		}
 	}
 }
@@ -43,21 +106,65 @@ func (s *streamRouter) Receive(ctx *actor.Context) {
 		s.pid = ctx.PID()
 	case *streamDeliver:
 		s.deliverStream(msg)
+	case *streamFlush:
+		for _, pid := range s.streams {
+			msg.wg.Add(1)
+			s.engine.Send(pid, msg)
+		}
+		if msg.close {
+			// 关闭的流写入器不会广播 RemoteUnreachableEvent，之后的消息使用新的流写入器。
+			clear(s.streams)
+			clear(s.writers)
+			clear(s.connected)
+		}
+		close(msg.routed)
+	case *streamConnected:
+		s.connected[msg.address] = true
+		s.recordSuccess(msg.address)
+		s.handleConnected(msg.address)
+	case *streamClosed:
+		s.handleClosed(msg.address)
+	case *outboxRedial:
+		s.handleRedial(msg.address)
+	case peerQuery:
+		ctx.Respond(s.peers())
+	case peerDisconnect:
+		ctx.Respond(s.disconnect(msg.address))
 	case actor.RemoteUnreachableEvent:
 		s.handleTerminateStream(msg)
+		s.recordFailure(msg.ListenAddr)
+		s.handleDisconnected(msg.ListenAddr)
+		// 正在关闭的流写入器先断开时不会处理关闭请求，它已经从注册表移除。
+		s.handleClosed(msg.ListenAddr)
 	}
 }
 
 // handleTerminateStream 处理流终止事件。
 func (s *streamRouter) handleTerminateStream(msg actor.RemoteUnreachableEvent) {
 	streamWriterPID := s.streams[msg.ListenAddr]
-	delete(s.streams, msg.ListenAddr)
-	slog.Debug("流已终止",
+	s.removeStream(msg.ListenAddr)
+	s.engine.Logger().Debug("流已终止",
 		"remote", msg.ListenAddr,
 		"pid", streamWriterPID,
 	)
 }
 
+// spawnWriter 为 address 创建流写入器并返回它的 PID。
+func (s *streamRouter) spawnWriter(address string) *actor.PID {
+	w := newStreamWriter(s.remote, s.pid, address)
+	pid := s.engine.SpawnProc(w)
+	s.streams[address] = pid
+	s.writers[address] = w.(*streamWriter)
+	return pid
+}
+
+// removeStream 忘记 address 的流写入器，之后发往 address 的消息使用新的流写入器。
+func (s *streamRouter) removeStream(address string) {
+	delete(s.streams, address)
+	delete(s.writers, address)
+	delete(s.connected, address)
+}
+
 // deliverStream 将消息传递到对应的流写入器。
 func (s *streamRouter) deliverStream(msg *streamDeliver) {
 	var (
@@ -66,11 +173,62 @@ func (s *streamRouter) deliverStream(msg *streamDeliver) {
 		address = msg.target.Address
 	)
 
+	if pending, closing := s.closing[address]; closing {
+		s.closing[address] = append(pending, msg)
+		return
+	}
 	swpid, ok = s.streams[address]
+	if !ok && s.down[address] && s.outboxEnabled() {
+		// 节点不可达期间由重新连接循环建立连接，消息先留在发件箱中。
+		if ob, ok := s.outboxes[address]; ok {
+			s.buffer(ob, msg)
+			s.scheduleRedial(address, ob)
+			return
+		}
+	}
 	if !ok {
-		swpid = s.engine.SpawnProc(newStreamWriter(s.engine, s.pid, address, s.tlsConfig, s.buffSize))
-		s.streams[address] = swpid
+		if !s.allow(address) {
+			s.deadLetter(msg)
+			return
+		}
+		swpid = s.spawnWriter(address)
+		// 流写入器确认连接之前的消息先放入发件箱，连接失败时不会丢失。
+		if _, ok := s.outboxes[address]; !ok && s.outboxEnabled() {
+			s.outboxes[address] = &outbox{}
+		}
 	}
+	if ob, ok := s.outboxes[address]; ok {
+		s.buffer(ob, msg)
+		return
+	}
+	s.send(swpid, msg)
+}
 
+// send 为响应以外的消息分配序列号并交给流写入器。
+func (s *streamRouter) send(swpid *actor.PID, msg *streamDeliver) {
+	if s.seqs != nil && msg.responseID == 0 {
+		msg.seq = s.seqs.next(msg.target)
+	}
+	if isPriority(msg.msg) && s.sendPriority(swpid, msg) {
+		return
+	}
 	s.engine.Send(swpid, msg)
 }
+
+// handleClosed 结束 address 的关闭状态，并发送关闭期间收到的消息。
+func (s *streamRouter) handleClosed(address string) {
+	pending, ok := s.closing[address]
+	if !ok {
+		return
+	}
+	delete(s.closing, address)
+	for _, msg := range pending {
+		s.deliverStream(msg)
+	}
+}
+
+// deadLetter 把无法发送的消息交给死信处理。
+func (s *streamRouter) deadLetter(msg *streamDeliver) {
+	s.engine.DeadLetter(msg.target, msg.msg, msg.sender)
+	msg.release()
+}
@@ -4,48 +4,181 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
-	"log/slog"
 	"net"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/TAnNbR/Distributed-framework/actor"
-	"storj.io/drpc/drpcconn"
-	"storj.io/drpc/drpcmanager"
-	"storj.io/drpc/drpcwire"
 )
 
 const (
-	connIdleTimeout       = time.Minute * 10 // 连接空闲超时时间
-	streamWriterBatchSize = 1024             // 流写入器批处理大小
+	streamWriterBatchSize = 1024 // 流写入器批处理大小
 )
 
 // streamWriter 是流写入器，负责向远程发送消息。
 type streamWriter struct {
+	remote      *Remote
 	writeToAddr string
 	rawconn     net.Conn
-	conn        *drpcconn.Conn
-	stream      DRPCRemote_ReceiveStream
+	stream      Stream
 	engine      *actor.Engine
 	routerPID   *actor.PID
 	pid         *actor.PID
 	inbox       actor.Inboxer
-	serializer  Serializer
+	codecs      []Codec
 	tlsConfig   *tls.Config
-	buffSize    int
+	transport   Transport
+	auth        Authenticator
+	noiseKey    []byte
+	hmacKey     []byte
+	epoch       uint64
+	stats       *remoteStats
+	peer        *peerStats
+	// keepaliveInterval 和 keepaliveTimeout 配置心跳，interval 为 0 时不发送心跳。
+	keepaliveInterval time.Duration
+	keepaliveTimeout  time.Duration
+	// idleTimeout 和 writeTimeout 是连接的超时时间，参见 WithConnTimeouts。
+	idleTimeout  time.Duration
+	writeTimeout time.Duration
+	// negotiation 表示连接建立时执行协商握手，caps 是协商的结果，未协商时为零值。
+	negotiation bool
+	caps        capabilities
+	// queue 用于背压，未启用时为 nil。
+	queue *writerQueue
+	// closed 保证流写入器只关闭一次。
+	closed atomic.Bool
+	// batch 是在多次 Invoke 之间复用的写出缓冲区。
+	batch writeBatch
+	// batchSize 和 batchLinger 是批处理策略，参见 WithBatching。lingering 表示已安排写出未满的批次。
+	batchSize   int
+	batchLinger time.Duration
+	lingering   bool
+	// priority 是优先消息的通道，参见 Prioritized。
+	priority priorityLane
+}
+
+// writeBatch 保存构建一个网络 Envelope 所需的查找表和切片。
+// 流写入器串行地处理消息，stream.Send 返回时 Envelope 已经被编码，
+// 因此这些缓冲区可以在下一批消息中复用。
+type writeBatch struct {
+	typeLookup   map[string]int32
+	typeNames    []string
+	codecLookup  map[string]int32
+	codecNames   []string
+	senderLookup map[uint64]int32
+	senders      []*actor.PID
+	targetLookup map[uint64]int32
+	targets      []*actor.PID
+	messages     []*Message
+	env          Envelope
+	// buf 保存本批消息用 vtproto 序列化后的数据，写出后复用。
+	buf []byte
+}
+
+// reset 清空上一批消息的内容，保留已分配的内存。
+func (b *writeBatch) reset() {
+	if b.typeLookup == nil {
+		b.typeLookup = make(map[string]int32)
+		b.codecLookup = make(map[string]int32)
+		b.senderLookup = make(map[uint64]int32)
+		b.targetLookup = make(map[uint64]int32)
+	}
+	clear(b.typeLookup)
+	clear(b.codecLookup)
+	clear(b.senderLookup)
+	clear(b.targetLookup)
+	clear(b.senders)
+	clear(b.targets)
+	for _, m := range b.messages {
+		m.Data = nil
+		m.Trace = nil
+		m.Mac = nil
+	}
+	b.typeNames = b.typeNames[:0]
+	b.codecNames = b.codecNames[:0]
+	b.buf = b.buf[:0]
+	b.senders = b.senders[:0]
+	b.targets = b.targets[:0]
+	b.messages = b.messages[:0]
+}
+
+// vtSizedMarshaler 由 vtproto 生成的消息实现，可以序列化到调用者提供的缓冲区。
+type vtSizedMarshaler interface {
+	SizeVT() int
+	MarshalToSizedBufferVT([]byte) (int, error)
+}
+
+// isVTCodec 报告 codec 的编码是否与 vtproto 相同。
+func isVTCodec(codec Codec) bool {
+	switch codec.(type) {
+	case ProtoSerializer, VTProtoSerializer:
+		return true
+	}
+	return false
+}
+
+// marshalVT 把消息序列化到 buf 中，同一批消息共享 buf，不必为每条消息分配内存。
+// buf 容量不足时分配新的 buf，之前的消息仍然引用旧的 buf。
+func (b *writeBatch) marshalVT(m vtSizedMarshaler) ([]byte, error) {
+	size := m.SizeVT()
+	if cap(b.buf)-len(b.buf) < size {
+		b.buf = make([]byte, 0, max(2*cap(b.buf), size, 4096))
+	}
+	off := len(b.buf)
+	b.buf = b.buf[:off+size]
+	data := b.buf[off : off+size : off+size]
+	n, err := m.MarshalToSizedBufferVT(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[size-n:], nil
+}
+
+// message 返回一个可用的 Message，优先复用之前分配的对象。
+func (b *writeBatch) message() *Message {
+	n := len(b.messages)
+	if n < cap(b.messages) {
+		b.messages = b.messages[:n+1]
+		if m := b.messages[n]; m != nil {
+			return m
+		}
+	} else {
+		b.messages = append(b.messages, nil)
+	}
+	m := &Message{}
+	b.messages[n] = m
+	return m
 }
 
 // newStreamWriter 创建一个新的流写入器。
-func newStreamWriter(e *actor.Engine, rpid *actor.PID, address string, tlsConfig *tls.Config, buffSize int) actor.Processer {
+func newStreamWriter(r *Remote, rpid *actor.PID, address string) actor.Processer {
 	return &streamWriter{
+		remote:      r,
 		writeToAddr: address,
-		engine:      e,
+		engine:      r.engine,
 		routerPID:   rpid,
 		inbox:       actor.NewInbox(streamWriterBatchSize),
-		pid:         actor.NewPID(e.Address(), "stream"+"/"+address),
-		serializer:  ProtoSerializer{},
-		tlsConfig:   tlsConfig,
-		buffSize:    buffSize,
+		pid:         actor.NewPID(r.engine.Address(), "stream"+"/"+address),
+		codecs:      r.config.codecs(),
+		tlsConfig:   r.clientTLS(dialAddr(address)),
+		transport:   r.transport,
+		auth:        r.config.Auth,
+		noiseKey:    r.config.NoiseKey,
+		hmacKey:     r.config.HMACKey,
+		epoch:       r.epoch,
+		stats:       r.stats,
+		peer:        r.stats.peer(address),
+
+		negotiation:       r.config.Negotiation,
+		keepaliveInterval: r.config.KeepaliveInterval,
+		keepaliveTimeout:  r.config.KeepaliveTimeout,
+		idleTimeout:       r.config.idleTimeout(),
+		writeTimeout:      r.config.WriteTimeout,
+		batchSize:         r.config.BatchSize,
+		batchLinger:       r.config.BatchLinger,
 	}
 }
 
@@ -57,98 +190,160 @@ func (s *streamWriter) Send(_ *actor.PID, msg any, sender *actor.PID) {
 	s.inbox.Send(actor.Envelope{Msg: msg, Sender: sender})
 }
 
-// Invoke 批量处理消息并发送到远程。
+// Invoke 批量处理消息并发送到远程。批次达到 batchSize 条时立即写出，
+// 未满的批次在启用 linger 时留到窗口结束或下一次刷新请求时写出。
+// 优先通道中的消息在处理每条消息之前加入批次，并且不等待 linger。
 func (s *streamWriter) Invoke(msgs []actor.Envelope) {
-	var (
-		typeLookup   = make(map[string]int32)
-		typeNames    = make([]string, 0)
-		senderLookup = make(map[uint64]int32)
-		senders      = make([]*actor.PID, 0)
-		targetLookup = make(map[uint64]int32)
-		targets      = make([]*actor.PID, 0)
-		messages     = make([]*Message, len(msgs))
-	)
+	b := &s.batch
+	if b.typeLookup == nil {
+		b.reset()
+	}
+	ping := false
+	flushNow := false
+	defer s.relieve()
 
 	for i := 0; i < len(msgs); i++ {
-		var (
-			stream   = msgs[i].Msg.(*streamDeliver)
-			typeID   int32
-			senderID int32
-			targetID int32
-		)
-		typeID, typeNames = lookupTypeName(typeLookup, s.serializer.TypeName(stream.msg), typeNames)
-		senderID, senders = lookupPIDs(senderLookup, stream.sender, senders)
-		targetID, targets = lookupPIDs(targetLookup, stream.target, targets)
-
-		b, err := s.serializer.Serialize(stream.msg)
-		if err != nil {
-			slog.Error("序列化", "err", err)
-			continue
+		if s.addPriority() {
+			flushNow = true
 		}
-
-		messages[i] = &Message{
-			Data:          b,
-			TypeNameIndex: typeID,
-			SenderIndex:   senderID,
-			TargetIndex:   targetID,
+		switch msg := msgs[i].Msg.(type) {
+		case *streamFlush:
+			// 刷新请求在本批消息写出之后完成。
+			defer msg.wg.Done()
+			if msg.close {
+				defer s.shutdown(false)
+			}
+			flushNow = true
+		case *streamPing:
+			ping = true
+		case streamLinger:
+			s.lingering = false
+			flushNow = true
+		case *streamDeliver:
+			s.add(msg)
 		}
 	}
+	if s.addPriority() {
+		flushNow = true
+	}
+	if ping {
+		defer s.ping()
+	}
+	if len(b.messages) == 0 || (!flushNow && s.linger()) {
+		return
+	}
+	s.send()
+}
 
-	env := &Envelope{
-		Senders:   senders,
-		Targets:   targets,
-		TypeNames: typeNames,
-		Messages:  messages,
+// add 序列化一条消息并加入当前批次，批次已满时写出。
+func (s *streamWriter) add(stream *streamDeliver) {
+	b := &s.batch
+	if s.expired(stream) {
+		// 接收端在等待超时后跳过这条消息的序列号。
+		stream.release()
+		return
+	}
+	msg, trace := unwrapTrace(stream.msg)
+	codec, tname, data, err := s.encode(msg)
+	if err == nil && s.rejectOversized(stream, tname, len(data)) {
+		// 接收端在等待超时后跳过这条消息的序列号。
+		stream.release()
+		return
+	}
+	if err != nil {
+		stream.release()
+		s.engine.Logger().Error("序列化", "err", err)
+		s.peer.addSerializeError()
+		return
 	}
+	// 被拒绝或序列化失败的消息不在批次的查找表中留下条目。
+	var typeID, senderID, targetID int32
+	typeID, b.typeNames = lookupTypeName(b.typeLookup, tname, b.typeNames)
+	senderID, b.senders = lookupPIDs(b.senderLookup, stream.sender, b.senders)
+	targetID, b.targets = lookupPIDs(b.targetLookup, stream.target, b.targets)
+	seq, requestID, responseID := stream.seq, stream.requestID, stream.responseID
+	mac := s.sign(msg, tname, stream.target, stream.sender, data)
+	stream.release()
 
+	var codecID int32
+	codecID, b.codecNames = lookupTypeName(b.codecLookup, codec, b.codecNames)
+
+	m := b.message()
+	m.Data = data
+	m.SerializerIndex = codecID
+	m.TypeNameIndex = typeID
+	m.SenderIndex = senderID
+	m.TargetIndex = targetID
+	m.Seq = seq
+	m.Trace = trace
+	m.Mac = mac
+	m.RequestID = requestID
+	m.ResponseID = responseID
+	if s.full() {
+		s.send()
+	}
+}
+
+// send 把当前批次作为一个 Envelope 写出并清空批次。
+func (s *streamWriter) send() {
+	b := &s.batch
+	defer b.reset()
+
+	env := &b.env
+	env.Epoch = s.epoch
+	env.Senders = b.senders
+	env.Targets = b.targets
+	env.TypeNames = b.typeNames
+	env.Serializers = b.codecNames
+	env.Messages = b.messages
+
+	if s.writeTimeout > 0 {
+		if err := s.rawconn.SetWriteDeadline(time.Now().Add(s.writeTimeout)); err != nil {
+			s.engine.Logger().Error("设置写超时失败", "err", err)
+		}
+	}
 	if err := s.stream.Send(env); err != nil {
 		if errors.Is(err, io.EOF) {
-			_ = s.conn.Close()
+			_ = s.stream.Close()
 			return
 		}
-		slog.Error("流写入器发送消息失败",
+		s.engine.Logger().Error("流写入器发送消息失败",
 			"err", err,
 		)
+	} else if s.peer != nil {
+		s.peer.addOut(env.SizeVT(), len(env.Messages))
 	}
 	// 刷新连接超时时间。
-	err := s.rawconn.SetDeadline(time.Now().Add(connIdleTimeout))
+	err := s.rawconn.SetDeadline(time.Now().Add(s.idleTimeout))
 	if err != nil {
-		slog.Error("设置上下文超时失败", "err", err)
+		s.engine.Logger().Error("设置上下文超时失败", "err", err)
 	}
 }
 
-// init 初始化流写入器，建立到远程的连接。
-func (s *streamWriter) init() {
-	var (
-		rawconn    net.Conn
-		err        error
-		delay      time.Duration = time.Millisecond * 500
-		maxRetries               = 3
-	)
-	for i := 0; i < maxRetries; i++ {
-		// 这里我们尝试连接到远程地址。
-		switch s.tlsConfig {
-		case nil:
-			rawconn, err = net.Dial("tcp", s.writeToAddr)
-			if err != nil {
-				d := time.Duration(delay * time.Duration(i*2))
-				slog.Error("net.Dial", "err", err, "remote", s.writeToAddr, "retry", i, "max", maxRetries, "delay", d)
-				time.Sleep(d)
-				continue
-			}
-		default:
-			slog.Debug("远程使用 TLS 进行写入")
-			rawconn, err = tls.Dial("tcp", s.writeToAddr, s.tlsConfig)
-			if err != nil {
-				d := time.Duration(delay * time.Duration(i*2))
-				slog.Error("tls.Dial", "err", err, "remote", s.writeToAddr, "retry", i, "max", maxRetries, "delay", d)
-				time.Sleep(d)
-				continue
-			}
+// encode 使用序列化链中第一个接受消息的格式序列化消息，返回格式名称、类型名称和序列化后的数据，
+// 中继转发的消息原样返回。
+func (s *streamWriter) encode(msg any) (string, string, []byte, error) {
+	if m, ok := msg.(*relayedMessage); ok {
+		return m.serializer, m.typeName, m.data, nil
+	}
+	for _, codec := range s.codecs {
+		if !codec.Accepts(msg) {
+			continue
 		}
-		break
+		if m, ok := msg.(vtSizedMarshaler); ok && isVTCodec(codec) {
+			data, err := s.batch.marshalVT(m)
+			return codec.Name(), codec.TypeName(msg), data, err
+		}
+		data, err := codec.Serialize(msg)
+		return codec.Name(), codec.TypeName(msg), data, err
 	}
-	// 重试 N 次后仍无法连接到远程。因此，关闭流写入器
+	return "", "", nil, fmt.Errorf("没有序列化格式接受消息类型 %T", msg)
+}
+
+// init 初始化流写入器，建立到远程的连接。
+func (s *streamWriter) init() {
+	rawconn := s.connect(s.negotiation)
+	// 重试 N 次后仍无法连接到远程，或者握手失败。因此，关闭流写入器
 	// 并通知 RemoteUnreachableEvent。
 	if rawconn == nil {
 		s.Shutdown()
@@ -156,59 +351,175 @@ func (s *streamWriter) init() {
 	}
 
 	s.rawconn = rawconn
-	err = rawconn.SetDeadline(time.Now().Add(connIdleTimeout))
+	// 流打开之后 context 仍然属于流，因此打开流的超时通过连接的 deadline 实现。
+	timeout := s.idleTimeout
+	if open := s.remote.config.OpenTimeout; open > 0 {
+		timeout = open
+	}
+	err := rawconn.SetDeadline(time.Now().Add(timeout))
 	if err != nil {
-		slog.Error("设置原始连接超时失败", "err", err)
+		s.engine.Logger().Error("设置原始连接超时失败", "err", err)
 		return
 	}
 
-	conn := drpcconn.NewWithOptions(rawconn, drpcconn.Options{
-		Manager: drpcmanager.Options{
-			Reader: drpcwire.ReaderOptions{
-				MaximumBufferSize: s.buffSize,
-			},
-		},
-	})
-	client := NewDRPCRemoteClient(conn)
-
-	stream, err := client.Receive(context.Background())
+	stream, err := s.transport.Open(context.Background(), rawconn)
+	if err == nil {
+		err = rawconn.SetDeadline(time.Now().Add(s.idleTimeout))
+	}
 	if err != nil {
-		slog.Error("接收", "err", err, "remote", s.writeToAddr)
+		s.engine.Logger().Error("打开流失败", "err", err, "remote", s.writeToAddr)
+		_ = rawconn.Close()
 		s.Shutdown()
 		return
 	}
 
 	s.stream = stream
-	s.conn = conn
+	s.engine.Send(s.routerPID, &streamConnected{address: s.writeToAddr})
+	s.startKeepalive()
 
-	slog.Debug("已连接",
+	s.engine.Logger().Debug("已连接",
 		"remote", s.writeToAddr,
 	)
 
 	go func() {
-		<-s.conn.Closed()
-		slog.Debug("连接丢失",
+		<-s.stream.Closed()
+		s.engine.Logger().Debug("连接丢失",
 			"remote", s.writeToAddr,
 		)
 		s.Shutdown()
 	}()
 }
 
+// connect 建立到远程的连接并完成 Noise、认证和协商握手，失败时返回 nil。
+// 本节点是目标地址的中继时，使用目标注册时建立的连接。
+func (s *streamWriter) connect(negotiation bool) net.Conn {
+	if s.remote.relays(s.writeToAddr) {
+		rawconn := s.remote.takeRelayConn(s.writeToAddr)
+		if rawconn == nil {
+			s.engine.Logger().Error("节点没有注册到中继", "remote", s.writeToAddr)
+		}
+		return rawconn
+	}
+	rawconn := s.dial()
+	if rawconn == nil {
+		return nil
+	}
+	if s.noiseKey != nil {
+		conn, err := noiseHandshake(rawconn, s.noiseKey, true)
+		if err != nil {
+			s.engine.Logger().Error("Noise 握手失败", "err", err, "remote", s.writeToAddr)
+			_ = rawconn.Close()
+			return nil
+		}
+		rawconn = conn
+	}
+	if s.auth != nil {
+		if err := authenticate(rawconn, s.auth, s.writeToAddr); err != nil {
+			s.engine.Logger().Error("认证握手失败", "err", err, "remote", s.writeToAddr)
+			_ = rawconn.Close()
+			return nil
+		}
+	}
+	if !negotiation {
+		return rawconn
+	}
+	caps, err := negotiate(rawconn, s.remote.localHandshake())
+	switch {
+	case errors.Is(err, errLegacyPeer):
+		// 对方是不支持协商的旧版本节点，重新建立不协商的连接。
+		s.engine.Logger().Warn("远程节点不支持协商，使用不协商的连接", "remote", s.writeToAddr)
+		_ = rawconn.Close()
+		return s.connect(false)
+	case err != nil:
+		s.engine.Logger().Error("协商握手失败", "err", err, "remote", s.writeToAddr)
+		if errors.Is(err, ErrIncompatiblePeer) {
+			s.engine.BroadcastEvent(actor.RemoteIncompatibleEvent{RemoteAddr: s.writeToAddr, Reason: err.Error()})
+		}
+		_ = rawconn.Close()
+		return nil
+	}
+	s.caps = caps
+	// 只使用对方能够反序列化的格式。
+	s.codecs = slices.DeleteFunc(slices.Clone(s.remote.config.codecs()), func(codec Codec) bool {
+		return !slices.Contains(caps.serializers, codec.Name())
+	})
+	s.engine.Logger().Debug("协商完成",
+		"remote", s.writeToAddr,
+		"version", caps.version,
+		"serializers", caps.serializers,
+		"compression", caps.compression,
+		"maxMessageSize", caps.maxMessageSize,
+	)
+	return rawconn
+}
+
+// dial 连接到远程地址，失败时重试，重试 N 次后仍然失败时返回 nil。
+func (s *streamWriter) dial() net.Conn {
+	var (
+		delay      time.Duration = time.Millisecond * 500
+		maxRetries               = 3
+		addr                     = dialAddr(s.writeToAddr)
+	)
+	for i := 0; i < maxRetries; i++ {
+		// 这里我们尝试连接到远程地址。
+		ctx, cancel := s.remote.config.openContext()
+		rawconn, err := dialConn(ctx, s.transport, addr, s.tlsConfig, s.remote.config.tlsHandshakeTimeout())
+		cancel()
+		if err == nil {
+			if tlsConn, ok := rawconn.(*tls.Conn); ok && tlsConn.ConnectionState().DidResume {
+				s.engine.Logger().Debug("恢复 TLS 会话", "remote", addr)
+			}
+			return rawconn
+		}
+		d := time.Duration(delay * time.Duration(i*2))
+		s.engine.Logger().Error("dial", "err", err, "remote", addr, "retry", i, "max", maxRetries, "delay", d)
+		time.Sleep(d)
+	}
+	return nil
+}
+
+// dialConn 建立到 addr 的连接：Transport 实现了 NetworkTransport 时由它拨号，
+// 否则使用 TCP（或 Unix 域套接字），配置了 tlsConfig 时使用 TLS，握手在 handshakeTimeout 内完成。
+func dialConn(ctx context.Context, transport Transport, addr string, tlsConfig *tls.Config, handshakeTimeout time.Duration) (net.Conn, error) {
+	if nt, ok := transport.(NetworkTransport); ok {
+		return nt.Dial(ctx, addr, tlsConfig)
+	}
+	if tlsConfig != nil && handshakeTimeout > 0 {
+		return dialTLS(ctx, addr, tlsConfig, handshakeTimeout)
+	}
+	return dialTCP(ctx, addr, tlsConfig)
+}
+
 // Shutdown 关闭流写入器。
 // TODO: 有没有办法让流路由器监听事件流而不是自己发送事件？
 func (s *streamWriter) Shutdown() {
-	evt := actor.RemoteUnreachableEvent{ListenAddr: s.writeToAddr}
-	s.engine.Send(s.routerPID, evt)
-	s.engine.BroadcastEvent(evt)
+	s.shutdown(true)
+}
+
+// shutdown 关闭流写入器，只有第一次调用生效。unreachable 为 false 时（远程停止）
+// 不通知流路由器，也不广播 RemoteUnreachableEvent。
+func (s *streamWriter) shutdown(unreachable bool) {
+	if !s.closed.CompareAndSwap(false, true) {
+		return
+	}
+	if unreachable {
+		evt := actor.RemoteUnreachableEvent{ListenAddr: s.writeToAddr}
+		s.engine.Send(s.routerPID, evt)
+		s.engine.BroadcastEvent(evt)
+	}
 	if s.stream != nil {
 		s.stream.Close()
 	}
 	s.inbox.Stop()
+	s.stats.detach(s.writeToAddr, s.inbox.(*actor.Inbox))
+	s.remote.unregisterQueue(s.writeToAddr, s.queue)
 	s.engine.Registry.Remove(s.PID())
 }
 
 // Start 启动流写入器。
 func (s *streamWriter) Start() {
+	s.stats.attach(s.writeToAddr, s.inbox.(*actor.Inbox))
+	s.queue = s.remote.registerQueue(s.writeToAddr, s.inbox.(*actor.Inbox))
 	s.inbox.Start(s)
 	s.init()
 }
@@ -0,0 +1,36 @@
+package remote
+
+import (
+	"context"
+	"time"
+)
+
+// defaultIdleTimeout 是连接的默认空闲超时时间。
+const defaultIdleTimeout = time.Minute * 10
+
+// WithConnTimeouts 设置出站连接的超时时间，对延迟敏感的部署可以借此更快地发现失效的连接：
+//   - idle 是连接上没有写出消息的最长时间，超过后连接被关闭，默认 10 分钟；
+//   - write 是写出一个 Envelope 的最长时间，超过后连接被关闭，0 表示只受 idle 限制；
+//   - open 是每次拨号和打开流的最长时间，同时也是接收端等待对方打开流的最长时间，0 表示不限制。
+func (c Config) WithConnTimeouts(idle, write, open time.Duration) Config {
+	c.IdleTimeout = idle
+	c.WriteTimeout = write
+	c.OpenTimeout = open
+	return c
+}
+
+// idleTimeout 返回连接的空闲超时时间。
+func (c Config) idleTimeout() time.Duration {
+	if c.IdleTimeout > 0 {
+		return c.IdleTimeout
+	}
+	return defaultIdleTimeout
+}
+
+// openContext 返回拨号使用的 context，未设置 OpenTimeout 时不会超时。
+func (c Config) openContext() (context.Context, context.CancelFunc) {
+	if c.OpenTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), c.OpenTimeout)
+}
@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connStream 把 Envelope 直接写入连接。
+type connStream struct {
+	recordingStream
+	conn net.Conn
+}
+
+func (s *connStream) Send(env *Envelope) error {
+	data, err := env.MarshalVT()
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(data)
+	return err
+}
+
+func TestIdleTimeout(t *testing.T) {
+	assert.Equal(t, defaultIdleTimeout, NewConfig().idleTimeout())
+	assert.Equal(t, time.Second, NewConfig().WithConnTimeouts(time.Second, 0, 0).idleTimeout())
+}
+
+func TestWriteTimeout(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	// 对端从不读取，写入一直阻塞到写超时。
+	conn, peer := net.Pipe()
+	defer conn.Close()
+	defer peer.Close()
+	w := &streamWriter{
+		engine:       e,
+		codecs:       []Codec{ProtoSerializer{}},
+		rawconn:      conn,
+		stream:       &connStream{conn: conn},
+		idleTimeout:  time.Minute,
+		writeTimeout: 50 * time.Millisecond,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.Invoke(deliveries(1))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("写超时没有生效")
+	}
+}
+
+func TestOpenTimeout(t *testing.T) {
+	config := NewConfig().WithConnTimeouts(0, 0, 50*time.Millisecond)
+	blocking := NewNetworkTransport(NewDRPCTransport(0), nil,
+		func(ctx context.Context, _ string, _ *tls.Config) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	ctx, cancel := config.openContext()
+	defer cancel()
+	start := time.Now()
+	_, err := dialConn(ctx, blocking, "127.0.0.1:1", nil, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 50*time.Millisecond, config.transport().(drpcTransport).inactivityTimeout)
+}
@@ -0,0 +1,25 @@
+package remote
+
+import "crypto/tls"
+
+// TLSResolver 返回连接 addr 时使用的 TLS 配置，返回 nil 时使用 WithTLS 设置的配置（可能是不加密的连接）。
+type TLSResolver func(addr string) *tls.Config
+
+// WithTLSResolver 按目标地址选择拨号使用的 TLS 配置（客户端证书、ServerName、根证书池），
+// 适用于跨越多个组织的集群，一个全局的 tls.Config 无法满足所有节点。监听仍然使用 WithTLS 的配置。
+// addr 是实际拨号的地址，经由中继的地址对应中继的地址。返回的配置没有设置 ServerName 时，
+// 默认的 TCP 拨号使用 addr 中的主机名作为 SNI。
+func (c Config) WithTLSResolver(resolver TLSResolver) Config {
+	c.TLSResolver = resolver
+	return c
+}
+
+// clientTLS 返回拨号 addr 使用的 TLS 配置。
+func (r *Remote) clientTLS(addr string) *tls.Config {
+	if r.config.TLSResolver != nil {
+		if config := r.config.TLSResolver(addr); config != nil {
+			return r.withSessionCache(config)
+		}
+	}
+	return r.withSessionCache(r.config.TLSConfig)
+}
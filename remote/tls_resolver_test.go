@@ -0,0 +1,58 @@
+package remote
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSResolver(t *testing.T) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	cert, err := generateCert(ca, caKey)
+	require.NoError(t, err)
+
+	// b 使用 TLS，c 不加密；a 只在连接 b 时使用 TLS。
+	bAddr := getRandomLocalhostAddr()
+	b, _, err := makeRemoteEngineTls(bAddr, &tls.Config{Certificates: []tls.Certificate{*cert}})
+	require.NoError(t, err)
+	c, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	resolved := make(chan string, 4)
+	a, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithTLSResolver(func(addr string) *tls.Config {
+		resolved <- addr
+		if addr == bAddr {
+			return &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		}
+		return nil
+	}))
+	require.NoError(t, err)
+
+	got := make(chan string, 2)
+	receiver := func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}
+	a.Send(b.SpawnFunc(receiver, "foo"), testMsg("tls"))
+	a.Send(c.SpawnFunc(receiver, "foo"), testMsg("plain"))
+
+	received := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case s := <-got:
+			received[s] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("没有收到消息")
+		}
+	}
+	assert.Equal(t, map[string]bool{"tls": true, "plain": true}, received)
+	assert.Len(t, resolved, 2)
+}
@@ -0,0 +1,77 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// defaultTLSSessionCacheSize 是默认的 TLS 会话缓存大小。
+const defaultTLSSessionCacheSize = 64
+
+// WithTLSSessions 配置 TLS 会话缓存和握手超时。拨号使用的 TLS 配置没有设置 ClientSessionCache 时，
+// 远程模块使用一个容纳 cacheSize 个会话的共享缓存，流写入器重新连接同一个节点时恢复之前的会话，
+// 省去完整握手的往返和证书验证（监听端需要启用会话票据，这是 crypto/tls 的默认行为）。
+// cacheSize 为 0 时使用默认大小 64，小于 0 时不缓存会话。
+// handshakeTimeout 限制拨号和接受连接时 TLS 握手的时间，0 表示 5 秒。
+func (c Config) WithTLSSessions(cacheSize int, handshakeTimeout time.Duration) Config {
+	c.TLSSessionCacheSize = cacheSize
+	c.TLSHandshakeTimeout = handshakeTimeout
+	return c
+}
+
+// tlsSessionCache 返回拨号共享的 TLS 会话缓存，不缓存会话时返回 nil。
+func (c Config) tlsSessionCache() tls.ClientSessionCache {
+	switch {
+	case c.TLSSessionCacheSize < 0:
+		return nil
+	case c.TLSSessionCacheSize == 0:
+		return tls.NewLRUClientSessionCache(defaultTLSSessionCacheSize)
+	}
+	return tls.NewLRUClientSessionCache(c.TLSSessionCacheSize)
+}
+
+// tlsHandshakeTimeout 返回 TLS 握手的超时时间。
+func (c Config) tlsHandshakeTimeout() time.Duration {
+	if c.TLSHandshakeTimeout > 0 {
+		return c.TLSHandshakeTimeout
+	}
+	return handshakeTimeout
+}
+
+// withSessionCache 返回使用共享会话缓存的 config 副本，config 已经设置了缓存时原样返回。
+func (r *Remote) withSessionCache(config *tls.Config) *tls.Config {
+	if config == nil || r.tlsSessions == nil || config.ClientSessionCache != nil {
+		return config
+	}
+	config = config.Clone()
+	config.ClientSessionCache = r.tlsSessions
+	return config
+}
+
+// dialTLS 建立到 addr 的 TCP 连接并在 timeout 内完成 TLS 握手，ctx 同时限制拨号和握手。
+func dialTLS(ctx context.Context, addr string, config *tls.Config, timeout time.Duration) (net.Conn, error) {
+	conn, err := dialTCP(ctx, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.ServerName == "" {
+		// 与 tls.Dialer 相同，使用地址中的主机名作为 SNI。
+		_, address := splitNetworkAddr(addr)
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			host = address
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
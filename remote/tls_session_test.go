@@ -0,0 +1,93 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoTLS 在本地端口上监听 TLS，把收到的数据原样写回，返回监听地址。
+func echoTLS(t *testing.T) (string, *x509.CertPool) {
+	ca, caKey, err := generateCA()
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	cert, err := generateCert(ca, caKey)
+	require.NoError(t, err)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{*cert}})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String(), roots
+}
+
+// resumed 建立一个 TLS 连接并交换一次数据（TLS 1.3 的会话票据在握手之后到达），返回是否恢复了会话。
+func resumed(t *testing.T, r *Remote, addr string) bool {
+	conn, err := dialConn(context.Background(), nil, addr, r.clientTLS(addr), r.config.tlsHandshakeTimeout())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte{1})
+	require.NoError(t, err)
+	_, err = io.ReadFull(conn, make([]byte, 1))
+	require.NoError(t, err)
+	return conn.(*tls.Conn).ConnectionState().DidResume
+}
+
+func TestTLSSessionResumption(t *testing.T) {
+	addr, roots := echoTLS(t)
+	r := New("127.0.0.1:0", NewConfig().WithTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"}))
+
+	assert.False(t, resumed(t, r, addr))
+	assert.True(t, resumed(t, r, addr))
+	assert.Nil(t, r.config.TLSConfig.ClientSessionCache, "不修改调用者的配置")
+}
+
+func TestTLSSessionCacheDisabled(t *testing.T) {
+	addr, roots := echoTLS(t)
+	r := New("127.0.0.1:0", NewConfig().
+		WithTLS(&tls.Config{RootCAs: roots, ServerName: "localhost"}).
+		WithTLSSessions(-1, 0))
+
+	assert.False(t, resumed(t, r, addr))
+	assert.False(t, resumed(t, r, addr))
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	// 接受连接但从不回应握手。
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	config := NewConfig().WithTLSSessions(0, 50*time.Millisecond)
+	start := time.Now()
+	_, err = dialConn(context.Background(), nil, ln.Addr().String(), &tls.Config{ServerName: "localhost"}, config.tlsHandshakeTimeout())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
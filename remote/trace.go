@@ -0,0 +1,31 @@
+package remote
+
+import "github.com/TAnNbR/Distributed-framework/actor"
+
+// unwrapTrace 取出 actor.Traced 中的消息和追踪上下文，其他消息的追踪上下文为 nil。
+func unwrapTrace(msg any) (any, *TraceContext) {
+	t, ok := msg.(*actor.Traced)
+	if !ok {
+		return msg, nil
+	}
+	return t.Message, &TraceContext{
+		TraceID: t.Trace.TraceID,
+		SpanID:  t.Trace.SpanID,
+		Baggage: t.Trace.Baggage,
+	}
+}
+
+// withTrace 把从网络收到的追踪上下文附加到消息上，tc 为 nil 时原样返回消息。
+func withTrace(msg any, tc *TraceContext) any {
+	if tc == nil {
+		return msg
+	}
+	return &actor.Traced{
+		Message: msg,
+		Trace: actor.TraceContext{
+			TraceID: tc.TraceID,
+			SpanID:  tc.SpanID,
+			Baggage: tc.Baggage,
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracePropagation(t *testing.T) {
+	a, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+
+	type received struct {
+		data  string
+		trace *actor.TraceContext
+	}
+	got := make(chan received, 2)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- received{data: string(msg.Data), trace: c.TraceContext()}
+		}
+	}, "foo")
+
+	tc := actor.TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Baggage: map[string]string{"tenant": "acme"},
+	}
+	a.Send(pidb, &actor.Traced{Message: testMsg("traced"), Trace: tc})
+	a.Send(pidb, testMsg("plain"))
+
+	for _, want := range []string{"traced", "plain"} {
+		select {
+		case r := <-got:
+			assert.Equal(t, want, r.data)
+			if want == "traced" {
+				require.NotNil(t, r.trace)
+				assert.Equal(t, tc, *r.trace)
+			} else {
+				assert.Nil(t, r.trace)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("没有收到 %s", want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package remote
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+const (
+	// transferMagic 标记数据流传输连接，长度与 negotiationMagic 相同。
+	transferMagic            = "\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xffXFER"
+	transferChunkSize        = 32 * 1024
+	transferIdleTimeout      = time.Second * 30 // 传输过程中等待对方读写的最长时间
+	transferProgressInterval = 1 << 20          // 每接收这么多字节向目标投递一次 TransferProgress
+	maxTransferHeaderSize    = 4096
+)
+
+var (
+	// ErrTransferRejected 表示对方拒绝或没有完整接收数据流，通常是因为它没有启用 WithTransferDir。
+	ErrTransferRejected = errors.New("远程节点拒绝了数据流传输")
+
+	errTransferDisabled  = errors.New("本节点没有启用数据流传输")
+	errTransferIntegrity = errors.New("数据流签名不匹配")
+	errTransferThrottled = errors.New("超过速率限制")
+)
+
+// WithTransferDir 允许其他节点通过 Remote.Transfer 向本节点传输数据流，接收的数据写入 dir 中的临时文件。
+// 未设置时拒绝所有传输。
+func (c Config) WithTransferDir(dir string) Config {
+	c.TransferDir = dir
+	return c
+}
+
+// TransferProgress 在接收数据流的过程中投递给目标 actor，Bytes 是目前已接收的字节数。
+type TransferProgress struct {
+	Name  string
+	Bytes int64
+}
+
+// TransferCompleted 在数据流接收结束后投递给目标 actor。成功时 Path 是保存数据的文件，
+// 由目标负责移动或删除；失败时 Err 不为 nil，已接收的数据被删除。
+type TransferCompleted struct {
+	Name string
+	Path string
+	Size int64
+	Err  error
+}
+
+// Transfer 通过专用连接把 src 中的数据传输到 target 所在的节点，而不是作为消息放入 Envelope，
+// 适合文件、快照等大块数据，也不会阻塞同一节点之间的其他消息。target 在传输过程中收到 TransferProgress，
+// 结束后收到 TransferCompleted。Transfer 阻塞到对方确认收到全部数据或 ctx 被取消，返回发送的字节数。
+// 对方需要启用 WithTransferDir；不支持经由中继的地址。
+//
+// 传输连接与消息连接经过相同的 TLS、Noise、认证握手和节点过滤。启用 WithHMAC 时，
+// 传输头和全部数据都被签名；WithInboundRateLimit 对每次传输计为一条消息，与同一主机的其他连接共享限制。
+func (r *Remote) Transfer(ctx context.Context, target *actor.PID, name string, src io.Reader) (int64, error) {
+	if r.state.Load() != stateRunning {
+		return 0, errors.New("远程模块没有运行")
+	}
+	if _, _, ok := splitRelayAddr(target.Address); ok {
+		return 0, errors.New("不支持经由中继传输数据流")
+	}
+	conn, err := r.dialPeer(target.Address)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	header := transferHeader{target: target, name: name}
+	n, err := sendTransfer(conn, header, r.config.HMACKey, src)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return n, ctxErr
+	}
+	return n, err
+}
+
+// transferHeader 是数据流传输开头的目标和名称。
+type transferHeader struct {
+	target *actor.PID
+	name   string
+}
+
+// newTransferMAC 返回签名数据流的 HMAC，它先覆盖传输头，之后依次写入数据。未启用 WithHMAC 时返回 nil。
+func newTransferMAC(key []byte, header transferHeader) hash.Hash {
+	if key == nil {
+		return nil
+	}
+	mac := hmac.New(sha256.New, key)
+	writeMACField(mac, []byte(transferMagic))
+	writeMACField(mac, []byte(header.target.GetAddress()))
+	writeMACField(mac, []byte(header.target.GetID()))
+	writeMACField(mac, []byte(header.name))
+	return mac
+}
+
+// sumMAC 返回目前写入 mac 的数据的签名，mac 为 nil 时返回 nil。
+func sumMAC(mac hash.Hash) []byte {
+	if mac == nil {
+		return nil
+	}
+	return mac.Sum(nil)
+}
+
+// sendTransfer 发送 transferMagic、传输头和传输头的签名，对方接受后逐帧发送数据，
+// 以空帧和全部数据的签名结束。对方在接受时和收到全部数据后各回复一帧，空帧表示成功，否则是错误信息。
+func sendTransfer(conn net.Conn, header transferHeader, key []byte, src io.Reader) (int64, error) {
+	pid, err := header.target.MarshalVT()
+	if err != nil {
+		return 0, err
+	}
+	mac := newTransferMAC(key, header)
+	if err := conn.SetDeadline(time.Now().Add(transferIdleTimeout)); err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(conn, transferMagic); err != nil {
+		return 0, err
+	}
+	for _, frame := range [][]byte{pid, []byte(header.name), sumMAC(mac)} {
+		if err := writeFrame(conn, frame); err != nil {
+			return 0, err
+		}
+	}
+	if err := readTransferReply(conn); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	buf := make([]byte, transferChunkSize)
+	for {
+		k, err := src.Read(buf)
+		if k > 0 {
+			if err := conn.SetDeadline(time.Now().Add(transferIdleTimeout)); err != nil {
+				return n, err
+			}
+			if err := writeFrame(conn, buf[:k]); err != nil {
+				return n, err
+			}
+			if mac != nil {
+				mac.Write(buf[:k])
+			}
+			n += int64(k)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	if err := writeFrame(conn, nil); err != nil {
+		return n, err
+	}
+	if err := writeFrame(conn, sumMAC(mac)); err != nil {
+		return n, err
+	}
+	return n, readTransferReply(conn)
+}
+
+func readTransferReply(conn net.Conn) error {
+	reply, err := readFrame(conn, maxTransferHeaderSize)
+	if err != nil {
+		return err
+	}
+	if len(reply) > 0 {
+		return fmt.Errorf("%w: %s", ErrTransferRejected, reply)
+	}
+	return nil
+}
+
+// acceptTransfer 在接收端把数据流写入 TransferDir 中的临时文件，并向目标投递进度和结果。
+// 它在节点过滤、Noise 和认证握手之后由 acceptHandshake 调用。
+func (r *Remote) acceptTransfer(conn net.Conn) error {
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return err
+	}
+	var frames [3][]byte
+	for i := range frames {
+		frame, err := readFrame(conn, maxTransferHeaderSize)
+		if err != nil {
+			return err
+		}
+		frames[i] = frame
+	}
+	target := &actor.PID{}
+	if err := target.UnmarshalVT(frames[0]); err != nil {
+		return err
+	}
+	header := transferHeader{target: target, name: string(frames[1])}
+	mac := newTransferMAC(r.config.HMACKey, header)
+	limiter := r.acquireLimiter(conn)
+	defer r.releaseLimiter(limiter)
+	if err := r.admitTransfer(header, limiter, mac, frames[2]); err != nil {
+		_ = writeFrame(conn, []byte(err.Error()))
+		return err
+	}
+	name := frames[1]
+	f, err := os.CreateTemp(r.config.TransferDir, "transfer-*")
+	if err != nil {
+		_ = writeFrame(conn, []byte(err.Error()))
+		return err
+	}
+	if err := writeFrame(conn, nil); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	size, err := r.receiveTransfer(conn, f, mac, target, string(name))
+	if err == nil {
+		err = r.verifyTransfer(conn, header, mac)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	completed := TransferCompleted{Name: string(name), Size: size}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		_ = writeFrame(conn, []byte(err.Error()))
+		completed.Err = err
+	} else {
+		completed.Path = f.Name()
+		_ = writeFrame(conn, nil)
+	}
+	r.engine.SendLocal(target, completed, nil)
+	return err
+}
+
+// admitTransfer 验证传输头的签名、应用对端主机的速率限制并检查本节点是否接收数据流。
+func (r *Remote) admitTransfer(header transferHeader, limiter *inboundLimiter, mac hash.Hash, sum []byte) error {
+	if mac != nil && !hmac.Equal(sum, sumMAC(mac)) {
+		r.engine.BroadcastEvent(actor.RemoteIntegrityErrorEvent{Target: header.target, TypeName: header.name})
+		return errTransferIntegrity
+	}
+	if limiter != nil {
+		wait := limiter.reserve(r.config.InboundRatePolicy == InboundRateLimitDelay)
+		switch {
+		case wait == 0:
+		case r.config.InboundRatePolicy == InboundRateLimitDrop:
+			r.engine.BroadcastEvent(actor.RemoteThrottledEvent{Peer: limiter.host, Dropped: 1})
+			return errTransferThrottled
+		default:
+			time.Sleep(wait)
+			r.engine.BroadcastEvent(actor.RemoteThrottledEvent{Peer: limiter.host, Delay: wait})
+		}
+	}
+	if r.config.TransferDir == "" {
+		return errTransferDisabled
+	}
+	return nil
+}
+
+// verifyTransfer 读取数据之后的签名帧，与接收的数据的签名比较。
+func (r *Remote) verifyTransfer(conn net.Conn, header transferHeader, mac hash.Hash) error {
+	sum, err := readFrame(conn, maxTransferHeaderSize)
+	if err != nil {
+		return err
+	}
+	if mac != nil && !hmac.Equal(sum, sumMAC(mac)) {
+		r.engine.BroadcastEvent(actor.RemoteIntegrityErrorEvent{Target: header.target, TypeName: header.name})
+		return errTransferIntegrity
+	}
+	return nil
+}
+
+// receiveTransfer 把数据帧写入 w，直到收到空帧。mac 不为 nil 时数据同时写入 mac。
+func (r *Remote) receiveTransfer(conn net.Conn, w io.Writer, mac hash.Hash, target *actor.PID, name string) (int64, error) {
+	var n int64
+	for {
+		if err := conn.SetDeadline(time.Now().Add(transferIdleTimeout)); err != nil {
+			return n, err
+		}
+		chunk, err := readFrame(conn, transferChunkSize)
+		if err != nil {
+			return n, err
+		}
+		if len(chunk) == 0 {
+			return n, nil
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return n, err
+		}
+		if mac != nil {
+			mac.Write(chunk)
+		}
+		prev := n
+		n += int64(len(chunk))
+		if n/transferProgressInterval > prev/transferProgressInterval {
+			r.engine.SendLocal(target, TransferProgress{Name: name, Bytes: n}, nil)
+		}
+	}
+}
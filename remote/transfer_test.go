@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfer(t *testing.T) {
+	dir := t.TempDir()
+	_, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithTransferDir(dir))
+	require.NoError(t, err)
+
+	progress := make(chan TransferProgress, 16)
+	completed := make(chan TransferCompleted, 1)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		switch msg := c.Message().(type) {
+		case TransferProgress:
+			progress <- msg
+		case TransferCompleted:
+			completed <- msg
+		}
+	}, "receiver")
+
+	data := make([]byte, 3*transferProgressInterval+123)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	n, err := ra.Transfer(ctx, pidb, "snapshot", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+
+	select {
+	case msg := <-completed:
+		require.NoError(t, msg.Err)
+		assert.Equal(t, "snapshot", msg.Name)
+		assert.Equal(t, int64(len(data)), msg.Size)
+		assert.Equal(t, dir, filepath.Dir(msg.Path))
+		got, err := os.ReadFile(msg.Path)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到 TransferCompleted")
+	}
+	require.Len(t, progress, 3)
+	for i := 1; i <= 3; i++ {
+		p := <-progress
+		assert.Equal(t, "snapshot", p.Name)
+		assert.GreaterOrEqual(t, p.Bytes, int64(i*transferProgressInterval))
+	}
+}
+
+func TestTransferRejected(t *testing.T) {
+	_, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	pidb := b.SpawnFunc(func(c *actor.Context) {}, "receiver")
+
+	_, err = ra.Transfer(context.Background(), pidb, "snapshot", bytes.NewReader([]byte("foo")))
+	assert.ErrorIs(t, err, ErrTransferRejected)
+}
+
+func TestTransferHMAC(t *testing.T) {
+	dir := t.TempDir()
+	_, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithHMAC([]byte("secret")))
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTransferDir(dir).WithHMAC([]byte("secret")))
+	require.NoError(t, err)
+	_, rc, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(), NewConfig().WithHMAC([]byte("other")))
+	require.NoError(t, err)
+
+	completed := make(chan TransferCompleted, 1)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(TransferCompleted); ok {
+			completed <- msg
+		}
+	}, "receiver")
+
+	data := []byte("snapshot data")
+	n, err := ra.Transfer(context.Background(), pidb, "snapshot", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+	msg := <-completed
+	require.NoError(t, msg.Err)
+
+	// 密钥不同的节点在传输头就被拒绝。
+	_, err = rc.Transfer(context.Background(), pidb, "snapshot", bytes.NewReader(data))
+	assert.ErrorIs(t, err, ErrTransferRejected)
+}
+
+func TestTransferRateLimit(t *testing.T) {
+	_, ra, err := makeRemoteEngine(getRandomLocalhostAddr())
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTransferDir(t.TempDir()).WithInboundRateLimit(1, time.Hour, InboundRateLimitDrop))
+	require.NoError(t, err)
+	pidb := b.SpawnFunc(func(c *actor.Context) {}, "receiver")
+
+	_, err = ra.Transfer(context.Background(), pidb, "first", bytes.NewReader([]byte("foo")))
+	require.NoError(t, err)
+	_, err = ra.Transfer(context.Background(), pidb, "second", bytes.NewReader([]byte("foo")))
+	assert.ErrorIs(t, err, ErrTransferRejected)
+}
@@ -0,0 +1,226 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"storj.io/drpc/drpcconn"
+	"storj.io/drpc/drpcmanager"
+	"storj.io/drpc/drpcmux"
+	"storj.io/drpc/drpcserver"
+	"storj.io/drpc/drpcwire"
+)
+
+// Transport 抽象了远程模块使用的 RPC 协议。Remote 负责监听和拨号（包括 TLS），
+// Transport 在建立好的连接之上收发 Envelope。默认使用 drpc，参见 Config.WithTransport。
+type Transport interface {
+	// Serve 在 ln 接受的连接上接收 Envelope 并交给 handler，直到 ctx 被取消。
+	Serve(ctx context.Context, ln net.Listener, handler EnvelopeHandler) error
+	// Open 在已经建立的连接 conn 上打开一个发送 Envelope 的流。
+	Open(ctx context.Context, conn net.Conn) (Stream, error)
+}
+
+// NetworkTransport 由自己负责建立连接的 Transport 实现（例如基于 UDP 的 QUIC）。
+// 未实现时 Remote 使用 TCP，并按照 Config.WithTLS 使用 TLS。
+type NetworkTransport interface {
+	Transport
+	// Listen 在 addr 上监听其他节点的连接。
+	Listen(addr string, tlsConfig *tls.Config) (net.Listener, error)
+	// Dial 建立到 addr 的连接。
+	Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error)
+}
+
+// ListenFunc 在 addr 上监听其他节点的连接，参见 NewNetworkTransport。
+type ListenFunc func(addr string, tlsConfig *tls.Config) (net.Listener, error)
+
+// DialFunc 建立到 addr 的连接，参见 NewNetworkTransport。
+type DialFunc func(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error)
+
+// NewNetworkTransport 使用 listen 和 dial 建立连接，在连接之上仍由 t 收发 Envelope。
+// 这样不必实现整个 Transport 就可以替换网络层，例如经由 SSH 隧道连接其他节点：
+//
+//	remote.NewNetworkTransport(remote.NewDRPCTransport(0), nil,
+//		func(ctx context.Context, addr string, _ *tls.Config) (net.Conn, error) {
+//			return sshClient.DialContext(ctx, "tcp", addr)
+//		})
+//
+// listen 或 dial 为 nil 时使用 TCP（或 "unix://" 地址的 Unix 域套接字），tlsConfig 不为 nil 时使用 TLS。
+// 返回的连接仍然经过 Remote 的 Noise、认证和协商握手。
+func NewNetworkTransport(t Transport, listen ListenFunc, dial DialFunc) NetworkTransport {
+	if listen == nil {
+		listen = listenTCP
+	}
+	if dial == nil {
+		dial = dialTCP
+	}
+	return networkTransport{Transport: t, listen: listen, dial: dial}
+}
+
+type networkTransport struct {
+	Transport
+	listen ListenFunc
+	dial   DialFunc
+}
+
+func (t networkTransport) Listen(addr string, tlsConfig *tls.Config) (net.Listener, error) {
+	return t.listen(addr, tlsConfig)
+}
+
+func (t networkTransport) Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	return t.dial(ctx, addr, tlsConfig)
+}
+
+// listenTCP 是默认的 ListenFunc。
+func listenTCP(addr string, tlsConfig *tls.Config) (net.Listener, error) {
+	network, address := splitNetworkAddr(addr)
+	if tlsConfig == nil {
+		return net.Listen(network, address)
+	}
+	return tls.Listen(network, address, tlsConfig)
+}
+
+// dialTCP 是默认的 DialFunc。
+func dialTCP(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	network, address := splitNetworkAddr(addr)
+	if tlsConfig == nil {
+		var d net.Dialer
+		return d.DialContext(ctx, network, address)
+	}
+	d := tls.Dialer{Config: tlsConfig}
+	return d.DialContext(ctx, network, address)
+}
+
+// KeepaliveStream 由能够接收对方回复的 Stream 实现。没有消息的 Envelope 是心跳，
+// Transport 的接收端不把它交给 EnvelopeHandler，而是在同一个流上回复一个空 Envelope；
+// 流写入器根据 LastReceived 检测失效的对端，参见 Config.WithKeepalive。
+type KeepaliveStream interface {
+	Stream
+	// LastReceived 返回最后一次从对方收到 Envelope 的时间。
+	LastReceived() time.Time
+}
+
+// isHeartbeat 报告 Envelope 是否是心跳。
+func isHeartbeat(env *Envelope) bool {
+	return len(env.Messages) == 0
+}
+
+// EnvelopeHandler 处理从远程节点收到的 Envelope。返回错误时关闭对应的流。
+type EnvelopeHandler func(*Envelope) error
+
+// Stream 是向远程节点发送 Envelope 的流。
+type Stream interface {
+	Send(*Envelope) error
+	Close() error
+	// Closed 返回一个在底层连接断开时关闭的 channel。
+	Closed() <-chan struct{}
+}
+
+// drpcTransport 是基于 drpc 的默认 Transport。
+type drpcTransport struct {
+	buffSize int
+	// inactivityTimeout 是接收端等待对方打开流的最长时间，0 表示不限制。
+	inactivityTimeout time.Duration
+}
+
+// NewDRPCTransport 返回基于 drpc 的 Transport。buffSize 是流读取器的缓冲区大小，
+// 为 0 时使用 drpc 的默认值（4MB）。
+func NewDRPCTransport(buffSize int) Transport {
+	return drpcTransport{buffSize: buffSize}
+}
+
+func (t drpcTransport) managerOptions() drpcmanager.Options {
+	return drpcmanager.Options{
+		Reader: drpcwire.ReaderOptions{
+			MaximumBufferSize: t.buffSize,
+		},
+		InactivityTimeout: t.inactivityTimeout,
+	}
+}
+
+// Serve 实现 Transport。
+func (t drpcTransport) Serve(ctx context.Context, ln net.Listener, handler EnvelopeHandler) error {
+	mux := drpcmux.New()
+	if err := DRPCRegisterRemote(mux, drpcReceiver{handler: handler}); err != nil {
+		return err
+	}
+	s := drpcserver.NewWithOptions(mux, drpcserver.Options{
+		Manager: t.managerOptions(),
+	})
+	return s.Serve(ctx, ln)
+}
+
+// Open 实现 Transport。
+func (t drpcTransport) Open(ctx context.Context, conn net.Conn) (Stream, error) {
+	dconn := drpcconn.NewWithOptions(conn, drpcconn.Options{
+		Manager: t.managerOptions(),
+	})
+	stream, err := NewDRPCRemoteClient(dconn).Receive(ctx)
+	if err != nil {
+		_ = dconn.Close()
+		return nil, err
+	}
+	s := &drpcStream{conn: dconn, stream: stream}
+	s.lastRecv.Store(time.Now().UnixNano())
+	// 接收端只回复心跳，Recv 在流结束时返回错误。
+	go func() {
+		for {
+			if _, err := stream.Recv(); err != nil {
+				return
+			}
+			s.lastRecv.Store(time.Now().UnixNano())
+		}
+	}()
+	return s, nil
+}
+
+// drpcReceiver 实现 drpc 生成的 Remote 服务，把收到的 Envelope 交给 handler。
+type drpcReceiver struct {
+	DRPCRemoteUnimplementedServer
+
+	handler EnvelopeHandler
+}
+
+// Receive 接收远程节点通过流发送的 Envelope。
+func (r drpcReceiver) Receive(stream DRPCRemote_ReceiveStream) error {
+	for {
+		envelope, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+		if isHeartbeat(envelope) {
+			if err := stream.Send(&Envelope{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.handler(envelope); err != nil {
+			return err
+		}
+	}
+}
+
+// drpcStream 是 drpc 连接上的发送流。
+type drpcStream struct {
+	conn     *drpcconn.Conn
+	stream   DRPCRemote_ReceiveClient
+	lastRecv atomic.Int64
+}
+
+func (s *drpcStream) Send(env *Envelope) error { return s.stream.Send(env) }
+
+// Close 关闭流和底层连接。
+func (s *drpcStream) Close() error {
+	_ = s.stream.Close()
+	return s.conn.Close()
+}
+
+func (s *drpcStream) Closed() <-chan struct{} { return s.conn.Closed() }
+
+func (s *drpcStream) LastReceived() time.Time { return time.Unix(0, s.lastRecv.Load()) }
@@ -0,0 +1,62 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeGRPCEngine(t *testing.T, listenAddr string) (*actor.Engine, *Remote) {
+	e, r, err := makeRemoteEngineWithConfig(listenAddr, NewConfig().WithTransport(NewGRPCTransport()))
+	require.NoError(t, err)
+	return e, r
+}
+
+func TestGRPCTransportSend(t *testing.T) {
+	const msgs = 10
+	aAddr := getRandomLocalhostAddr()
+	a, ra := makeGRPCEngine(t, aAddr)
+	bAddr := getRandomLocalhostAddr()
+	b, rb := makeGRPCEngine(t, bAddr)
+
+	senderPID := actor.NewPID(bAddr, "sender")
+	wg := &sync.WaitGroup{}
+	wg.Add(msgs)
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			assert.Equal(t, []byte("foo"), msg.Data)
+			assert.True(t, senderPID.Equals(c.Sender()))
+			wg.Done()
+		}
+	}, "dfoo")
+
+	for i := 0; i < msgs; i++ {
+		b.SendWithSender(pid, &TestMessage{Data: []byte("foo")}, senderPID)
+	}
+	wg.Wait()
+	ra.Stop().Wait()
+	rb.Stop().Wait()
+	assert.Error(t, tcpPing(aAddr))
+	assert.Error(t, tcpPing(bAddr))
+}
+
+func TestGRPCTransportRequestResponse(t *testing.T) {
+	a, ra := makeGRPCEngine(t, getRandomLocalhostAddr())
+	defer ra.Stop()
+	b, rb := makeGRPCEngine(t, getRandomLocalhostAddr())
+	defer rb.Stop()
+
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			c.Respond(&TestMessage{Data: append(msg.Data, "bar"...)})
+		}
+	}, "echo")
+
+	resp, err := b.Request(pid, &TestMessage{Data: []byte("foo")}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foobar"), resp.(*TestMessage).Data)
+}
@@ -0,0 +1,155 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// quicALPN 是 QUIC 连接使用的应用层协议名称。
+const quicALPN = "actor-remote"
+
+// quicTransport 在 QUIC 流之上使用 drpc 收发 Envelope。
+type quicTransport struct {
+	drpcTransport
+	config *quic.Config
+	// sessions 缓存 TLS 会话以便重新连接时使用 0-RTT。
+	sessions tls.ClientSessionCache
+}
+
+// NewQUICTransport 返回基于 QUIC 的 Transport，适用于丢包较多或延迟较高的链路：
+// 避免 TCP 的队头阻塞，支持 NAT 重绑定后的连接迁移，并在重新连接时使用 0-RTT。
+// QUIC 总是加密的，必须通过 Config.WithTLS 提供 TLS 配置，并且集群中的所有节点都要使用 QUIC。
+// config 为 nil 时使用默认配置，并开启保活以免空闲的连接被关闭。
+func NewQUICTransport(config *quic.Config) Transport {
+	if config == nil {
+		config = &quic.Config{KeepAlivePeriod: 10 * time.Second}
+	}
+	config = config.Clone()
+	config.Allow0RTT = true
+	return &quicTransport{
+		config:   config,
+		sessions: tls.NewLRUClientSessionCache(64),
+	}
+}
+
+// tlsConfig 返回带有 ALPN 和会话缓存的 TLS 配置副本。
+func (t *quicTransport) tlsConfig(tlsConfig *tls.Config) (*tls.Config, error) {
+	if tlsConfig == nil {
+		return nil, errors.New("QUIC 需要 TLS 配置")
+	}
+	tlsConfig = tlsConfig.Clone()
+	if len(tlsConfig.NextProtos) == 0 {
+		tlsConfig.NextProtos = []string{quicALPN}
+	}
+	if tlsConfig.ClientSessionCache == nil {
+		tlsConfig.ClientSessionCache = t.sessions
+	}
+	return tlsConfig, nil
+}
+
+// Listen 实现 NetworkTransport。每个 QUIC 连接上的第一个流作为一个 net.Conn 交给 Serve。
+func (t *quicTransport) Listen(addr string, tlsConfig *tls.Config) (net.Listener, error) {
+	tlsConfig, err := t.tlsConfig(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	ln, err := quic.ListenAddrEarly(addr, tlsConfig, t.config)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &quicListener{
+		ln:     ln,
+		conns:  make(chan net.Conn),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	go l.accept()
+	return l, nil
+}
+
+// Dial 实现 NetworkTransport。
+func (t *quicTransport) Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	tlsConfig, err := t.tlsConfig(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := quic.DialAddrEarly(ctx, addr, tlsConfig, t.config)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+	return &quicConn{Stream: stream, conn: conn}, nil
+}
+
+// quicListener 把 QUIC 连接适配为 net.Listener。
+type quicListener struct {
+	ln     *quic.EarlyListener
+	conns  chan net.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// accept 接受 QUIC 连接，并在独立的 goroutine 中等待对方打开流，
+// 避免一个迟迟不打开流的连接阻塞其他连接。
+func (l *quicListener) accept() {
+	for {
+		conn, err := l.ln.Accept(l.ctx)
+		if err != nil {
+			l.cancel()
+			return
+		}
+		go func() {
+			stream, err := conn.AcceptStream(l.ctx)
+			if err != nil {
+				_ = conn.CloseWithError(0, "")
+				return
+			}
+			select {
+			case l.conns <- &quicConn{Stream: stream, conn: conn}:
+			case <-l.ctx.Done():
+				_ = conn.CloseWithError(0, "")
+			}
+		}()
+	}
+}
+
+func (l *quicListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.ctx.Done():
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *quicListener) Close() error {
+	l.cancel()
+	return l.ln.Close()
+}
+
+func (l *quicListener) Addr() net.Addr { return l.ln.Addr() }
+
+// quicConn 把 QUIC 流适配为 net.Conn。
+type quicConn struct {
+	quic.Stream
+	conn quic.Connection
+}
+
+// Close 关闭流和它所在的 QUIC 连接。
+func (c *quicConn) Close() error {
+	_ = c.Stream.Close()
+	return c.conn.CloseWithError(0, "")
+}
+
+func (c *quicConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *quicConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
@@ -0,0 +1,51 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQUICTransport(t *testing.T) {
+	tlsConfig, err := generateTLSConfig()
+	require.NoError(t, err)
+	a, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTLS(tlsConfig.peer1Config).WithTransport(NewQUICTransport(nil)))
+	require.NoError(t, err)
+	defer ra.Stop()
+	b, rb, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTLS(tlsConfig.peer2Config).WithTransport(NewQUICTransport(nil)))
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	const msgs = 10
+	wg := &sync.WaitGroup{}
+	wg.Add(msgs)
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			if string(msg.Data) == "ping" {
+				c.Respond(&TestMessage{Data: []byte("pong")})
+				return
+			}
+			wg.Done()
+		}
+	}, "quic")
+	for i := 0; i < msgs; i++ {
+		b.Send(pid, &TestMessage{Data: []byte("foo")})
+	}
+	wg.Wait()
+
+	resp, err := b.Request(pid, &TestMessage{Data: []byte("ping")}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pong"), resp.(*TestMessage).Data)
+}
+
+func TestQUICTransportRequiresTLS(t *testing.T) {
+	r := New(getRandomLocalhostAddr(), NewConfig().WithTransport(NewQUICTransport(nil)))
+	_, err := actor.NewEngine(actor.NewEngineConfig().WithRemote(r))
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tunnels 模拟隧道：节点使用逻辑地址，实际监听和拨号使用随机的本地端口。
+type tunnels struct {
+	mu    sync.Mutex
+	addrs map[string]string
+}
+
+func (t *tunnels) listen(addr string, _ *tls.Config) (net.Listener, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.addrs[addr] = ln.Addr().String()
+	t.mu.Unlock()
+	return ln, nil
+}
+
+func (t *tunnels) dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	t.mu.Lock()
+	real := t.addrs[addr]
+	t.mu.Unlock()
+	return dialTCP(ctx, real, tlsConfig)
+}
+
+func TestNetworkTransport(t *testing.T) {
+	tun := &tunnels{addrs: make(map[string]string)}
+	config := NewConfig().WithTransport(NewNetworkTransport(NewDRPCTransport(0), tun.listen, tun.dial))
+	a, _, err := makeRemoteEngineWithConfig("node-a", config)
+	require.NoError(t, err)
+	b, _, err := makeRemoteEngineWithConfig("node-b", config)
+	require.NoError(t, err)
+
+	got := make(chan string, 1)
+	pidb := b.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			got <- string(msg.Data)
+		}
+	}, "foo")
+	assert.Equal(t, "node-b", pidb.Address)
+	a.Send(pidb, testMsg("through the tunnel"))
+
+	select {
+	case s := <-got:
+		assert.Equal(t, "through the tunnel", s)
+	case <-time.After(2 * time.Second):
+		t.Fatal("没有收到消息")
+	}
+}
@@ -0,0 +1,144 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// defaultWebSocketPath 是 WebSocket Transport 默认的 HTTP 路径。
+const defaultWebSocketPath = "/actor"
+
+// websocketTransport 在 WebSocket 连接之上使用 drpc 收发 Envelope。
+type websocketTransport struct {
+	drpcTransport
+	path string
+}
+
+// NewWebSocketTransport 返回基于 WebSocket 的 Transport，使无法使用原始 TCP 的轻量级客户端
+// （监控面板、actor 核心的 WASM 构建）也能与完整节点交换消息。节点在 path 上接受 WebSocket
+// 连接（为空时使用 "/actor"），连接上以二进制帧承载与 TCP 相同的 drpc 流。
+// 配置了 Config.WithTLS 时使用 wss。
+func NewWebSocketTransport(path string) Transport {
+	if path == "" {
+		path = defaultWebSocketPath
+	}
+	return &websocketTransport{path: path}
+}
+
+// Listen 实现 NetworkTransport。
+func (t *websocketTransport) Listen(addr string, tlsConfig *tls.Config) (net.Listener, error) {
+	var (
+		ln  net.Listener
+		err error
+	)
+	if tlsConfig != nil {
+		ln, err = tls.Listen("tcp", addr, tlsConfig)
+	} else {
+		ln, err = net.Listen("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	l := &websocketListener{
+		addr:   ln.Addr(),
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(t.path, websocket.Server{Handler: l.handle})
+	l.server = &http.Server{Handler: mux}
+	go l.server.Serve(ln)
+	return l, nil
+}
+
+// Dial 实现 NetworkTransport。
+func (t *websocketTransport) Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	scheme, origin := "ws://", "http://"
+	if tlsConfig != nil {
+		scheme, origin = "wss://", "https://"
+	}
+	config, err := websocket.NewConfig(scheme+addr+t.path, origin+addr)
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = tlsConfig
+	ws, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
+
+// websocketListener 把接受的 WebSocket 连接适配为 net.Listener。
+type websocketListener struct {
+	addr      net.Addr
+	server    *http.Server
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// handle 把 WebSocket 连接交给 Accept，并在连接关闭之前保持处理函数运行。
+func (l *websocketListener) handle(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	conn := &websocketConn{Conn: ws, done: make(chan struct{})}
+	// 服务端 websocket.Conn 的 RemoteAddr 是对方的 Origin，这里使用 TCP 连接的地址。
+	if addr, err := net.ResolveTCPAddr("tcp", ws.Request().RemoteAddr); err == nil {
+		conn.remoteAddr = addr
+	}
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		return
+	}
+	select {
+	case <-conn.done:
+	case <-l.closed:
+	}
+}
+
+func (l *websocketListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *websocketListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return l.server.Close()
+}
+
+func (l *websocketListener) Addr() net.Addr { return l.addr }
+
+// websocketConn 在关闭时通知处理函数返回。
+type websocketConn struct {
+	*websocket.Conn
+	remoteAddr net.Addr
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+func (c *websocketConn) RemoteAddr() net.Addr {
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *websocketConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	return c.Conn.Close()
+}
@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketTransport(t *testing.T) {
+	a, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTransport(NewWebSocketTransport("")))
+	require.NoError(t, err)
+	defer ra.Stop()
+	b, rb, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTransport(NewWebSocketTransport("")))
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	const msgs = 10
+	wg := &sync.WaitGroup{}
+	wg.Add(msgs)
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			if string(msg.Data) == "ping" {
+				c.Respond(&TestMessage{Data: []byte("pong")})
+				return
+			}
+			wg.Done()
+		}
+	}, "ws")
+	for i := 0; i < msgs; i++ {
+		b.Send(pid, &TestMessage{Data: []byte("foo")})
+	}
+	wg.Wait()
+
+	resp, err := b.Request(pid, &TestMessage{Data: []byte("ping")}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pong"), resp.(*TestMessage).Data)
+}
+
+func TestWebSocketTransportTLS(t *testing.T) {
+	tlsConfig, err := generateTLSConfig()
+	require.NoError(t, err)
+	a, ra, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTLS(tlsConfig.peer1Config).WithTransport(NewWebSocketTransport("/ws")))
+	require.NoError(t, err)
+	defer ra.Stop()
+	b, rb, err := makeRemoteEngineWithConfig(getRandomLocalhostAddr(),
+		NewConfig().WithTLS(tlsConfig.peer2Config).WithTransport(NewWebSocketTransport("/ws")))
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	done := make(chan struct{})
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if _, ok := c.Message().(*TestMessage); ok {
+			close(done)
+		}
+	}, "wss")
+	b.Send(pid, &TestMessage{Data: []byte("foo")})
+	<-done
+}
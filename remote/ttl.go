@@ -0,0 +1,38 @@
+package remote
+
+import (
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// WithSendTTL 为发往远程节点的消息设置有效期：流写入器在 ttl 内没有写出的消息
+// （对方不可达、发送队列积压或在发件箱中等待重新连接）被丢弃，并在事件流上广播 actor.RemoteExpiredEvent。
+// 启用顺序保证时，接收端在等待超时后跳过被丢弃消息的序列号。ttl <= 0 时不限制，这也是默认值。
+func (c Config) WithSendTTL(ttl time.Duration) Config {
+	c.SendTTL = ttl
+	return c
+}
+
+// sendDeadline 返回现在发送的消息的过期时间，未启用 WithSendTTL 时返回零值。
+func (r *Remote) sendDeadline() time.Time {
+	if r.config.SendTTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(r.config.SendTTL)
+}
+
+// expired 报告消息是否已经过期，过期时广播 RemoteExpiredEvent。
+func (s *streamWriter) expired(stream *streamDeliver) bool {
+	if stream.deadline.IsZero() || time.Now().Before(stream.deadline) {
+		return false
+	}
+	msg, _ := unwrapTrace(stream.msg)
+	s.engine.BroadcastEvent(actor.RemoteExpiredEvent{
+		Target:   stream.target,
+		Message:  msg,
+		Sender:   stream.sender,
+		Deadline: stream.deadline,
+	})
+	return true
+}
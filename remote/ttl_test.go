@@ -0,0 +1,44 @@
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendDeadline(t *testing.T) {
+	assert.True(t, New("", NewConfig()).sendDeadline().IsZero())
+	deadline := New("", NewConfig().WithSendTTL(time.Minute)).sendDeadline()
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+}
+
+func TestSendTTLDropsExpired(t *testing.T) {
+	w, stream := newBatchingWriter(t, 0, 0)
+	expired := make(chan actor.RemoteExpiredEvent, 1)
+	sub := w.engine.SpawnFunc(func(c *actor.Context) {
+		if ev, ok := c.Message().(actor.RemoteExpiredEvent); ok {
+			expired <- ev
+		}
+	}, "events")
+	w.engine.Subscribe(sub)
+
+	msgs := deliveries(2)
+	stale := msgs[0].Msg.(*streamDeliver)
+	stale.deadline = time.Now().Add(-time.Second)
+	deadline := stale.deadline
+	msgs[1].Msg.(*streamDeliver).deadline = time.Now().Add(time.Minute)
+	w.Invoke(msgs)
+	assert.Equal(t, []int{1}, stream.sent())
+
+	select {
+	case ev := <-expired:
+		assert.Equal(t, "foo", ev.Target.ID)
+		assert.Equal(t, deadline, ev.Deadline)
+		require.IsType(t, &TestMessage{}, ev.Message)
+	case <-time.After(time.Second):
+		t.Fatal("没有收到 RemoteExpiredEvent")
+	}
+}
@@ -0,0 +1,15 @@
+package remote
+
+import "strings"
+
+// unixScheme 是 Unix 域套接字地址的前缀，例如 "unix:///var/run/actor.sock"。
+const unixScheme = "unix://"
+
+// splitNetworkAddr 把远程地址拆分为 net.Listen/net.Dial 使用的网络类型和地址。
+// 以 "unix://" 开头的地址使用 Unix 域套接字，其余地址使用 TCP。
+func splitNetworkAddr(addr string) (network, address string) {
+	if path, ok := strings.CutPrefix(addr, unixScheme); ok {
+		return "unix", path
+	}
+	return "tcp", addr
+}
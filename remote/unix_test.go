@@ -0,0 +1,48 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitNetworkAddr(t *testing.T) {
+	network, address := splitNetworkAddr("unix:///var/run/actor.sock")
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/var/run/actor.sock", address)
+
+	network, address = splitNetworkAddr("127.0.0.1:4000")
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:4000", address)
+}
+
+func TestUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	aSock := filepath.Join(dir, "a.sock")
+	a, ra, err := makeRemoteEngine(unixScheme + aSock)
+	require.NoError(t, err)
+	b, rb, err := makeRemoteEngine(unixScheme + filepath.Join(dir, "b.sock"))
+	require.NoError(t, err)
+	defer rb.Stop()
+
+	pid := a.SpawnFunc(func(c *actor.Context) {
+		if msg, ok := c.Message().(*TestMessage); ok {
+			c.Respond(&TestMessage{Data: append(msg.Data, "bar"...)})
+		}
+	}, "echo")
+	assert.Equal(t, unixScheme+aSock, pid.Address)
+
+	resp, err := b.Request(pid, &TestMessage{Data: []byte("foo")}, time.Second).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foobar"), resp.(*TestMessage).Data)
+
+	// 停止后套接字文件被删除。
+	ra.Stop().Wait()
+	_, err = os.Stat(aSock)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -34,6 +34,21 @@ func New[T any](size int64) *RingBuffer[T] {
 // Push 向缓冲区添加一个元素。如果缓冲区已满，会自动扩容。
 func (rb *RingBuffer[T]) Push(item T) {
 	rb.mu.Lock()
+	rb.push(item)
+	rb.mu.Unlock()
+}
+
+// PushN 在一次加锁中向缓冲区添加多个元素。
+func (rb *RingBuffer[T]) PushN(items ...T) {
+	rb.mu.Lock()
+	for _, item := range items {
+		rb.push(item)
+	}
+	rb.mu.Unlock()
+}
+
+// push 添加一个元素，调用者必须持有锁。
+func (rb *RingBuffer[T]) push(item T) {
 	rb.content.tail = (rb.content.tail + 1) % rb.content.mod
 	if rb.content.tail == rb.content.head {
 		size := rb.content.mod * 2
@@ -52,7 +67,6 @@ func (rb *RingBuffer[T]) Push(item T) {
 	}
 	atomic.AddInt64(&rb.len, 1)
 	rb.content.items[rb.content.tail] = item
-	rb.mu.Unlock()
 }
 
 // Len 返回缓冲区中元素的数量。
@@ -79,10 +93,16 @@ func (rb *RingBuffer[T]) Pop() (T, bool) {
 
 // PopN 从缓冲区取出最多 n 个元素。如果缓冲区为空，返回 nil 和 false。
 func (rb *RingBuffer[T]) PopN(n int64) ([]T, bool) {
+	return rb.PopNInto(nil, n)
+}
+
+// PopNInto 与 PopN 相同，但把元素写入 dst 的底层数组（容量不足时重新分配），
+// 调用者可以在多次调用之间复用同一个切片以避免分配。如果缓冲区为空，返回 dst[:0] 和 false。
+func (rb *RingBuffer[T]) PopNInto(dst []T, n int64) ([]T, bool) {
 	rb.mu.Lock()
 	if rb.len == 0 {
 		rb.mu.Unlock()
-		return nil, false
+		return dst[:0], false
 	}
 	content := rb.content
 
@@ -91,7 +111,12 @@ func (rb *RingBuffer[T]) PopN(n int64) ([]T, bool) {
 	}
 	atomic.AddInt64(&rb.len, -n)
 
-	items := make([]T, n)
+	items := dst[:0]
+	if int64(cap(items)) < n {
+		items = make([]T, n)
+	} else {
+		items = items[:n]
+	}
 	for i := int64(0); i < n; i++ {
 		pos := (content.head + 1 + i) % content.mod
 		items[i] = content.items[pos]
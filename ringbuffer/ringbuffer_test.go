@@ -93,3 +93,40 @@ func TestPopThreadSafety(t *testing.T) {
 		}
 	})
 }
+
+func TestPopNInto(t *testing.T) {
+	rb := New[Item](4)
+	buf := make([]Item, 0, 8)
+	if items, ok := rb.PopNInto(buf, 8); ok || len(items) != 0 {
+		t.Fatal("expected empty buffer")
+	}
+	for i := 0; i < 6; i++ {
+		rb.Push(Item{i})
+	}
+	items, ok := rb.PopNInto(buf, 8)
+	if !ok || len(items) != 6 {
+		t.Fatalf("expected 6 items, got %d", len(items))
+	}
+	if &items[0] != &buf[:1][0] {
+		t.Fatal("expected dst to be reused")
+	}
+	for i, item := range items {
+		if item.i != i {
+			t.Fatal("invalid item popped")
+		}
+	}
+}
+
+func TestPushN(t *testing.T) {
+	rb := New[Item](2)
+	rb.PushN(Item{0}, Item{1}, Item{2}, Item{3}, Item{4})
+	if rb.Len() != 5 {
+		t.Fatalf("expected 5 items, got %d", rb.Len())
+	}
+	items, _ := rb.PopN(5)
+	for i, item := range items {
+		if item.i != i {
+			t.Fatal("invalid item popped")
+		}
+	}
+}
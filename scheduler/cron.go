@@ -0,0 +1,143 @@
+// Package scheduler 提供基于 cron 表达式的定时消息调度。
+// 一次性的延迟发送请使用 actor.Engine 的 SendAfter 和 SendAt。
+package scheduler
+
+import (
+	"github.com/TAnNbR/Distributed-framework/actor"
+)
+
+// addJob 注册或替换一个任务。
+type addJob struct {
+	id       string
+	schedule Schedule
+	target   *actor.PID
+	msg      any
+}
+
+// removeJob 取消一个任务。
+type removeJob struct {
+	id string
+}
+
+// fireJob 是任务到期时发送给调度 actor 的私有消息。
+type fireJob struct {
+	id  string
+	seq uint64
+}
+
+// job 是调度 actor 内部维护的任务。
+type job struct {
+	addJob
+	seq   uint64
+	timer actor.SendTimer
+}
+
+// cronActor 是维护所有 cron 任务的 actor。
+type cronActor struct {
+	jobs map[string]*job
+	seq  uint64
+}
+
+func newCronActor() actor.Receiver {
+	return &cronActor{
+		jobs: make(map[string]*job),
+	}
+}
+
+func (c *cronActor) Receive(ctx *actor.Context) {
+	switch msg := ctx.Message().(type) {
+	case addJob:
+		c.remove(msg.id)
+		c.seq++
+		j := &job{addJob: msg, seq: c.seq}
+		c.jobs[msg.id] = j
+		c.arm(ctx, j)
+	case removeJob:
+		c.remove(msg.id)
+	case fireJob:
+		j, ok := c.jobs[msg.id]
+		// 任务已被取消或替换。
+		if !ok || j.seq != msg.seq {
+			return
+		}
+		ctx.Send(j.target, j.msg)
+		c.arm(ctx, j)
+	case actor.Stopped:
+		for id := range c.jobs {
+			c.remove(id)
+		}
+	}
+}
+
+// arm 为任务设置下一次触发的定时器。
+func (c *cronActor) arm(ctx *actor.Context, j *job) {
+	next := j.schedule.Next(ctx.Engine().Clock().Now())
+	if next.IsZero() {
+		ctx.Engine().Logger().Warn("cron 任务没有下一次触发时间", "id", j.id)
+		delete(c.jobs, j.id)
+		return
+	}
+	j.timer = ctx.SendAt(ctx.PID(), fireJob{id: j.id, seq: j.seq}, next)
+}
+
+func (c *cronActor) remove(id string) {
+	if j, ok := c.jobs[id]; ok {
+		j.timer.Stop()
+		delete(c.jobs, id)
+	}
+}
+
+// Cron 是 cron 调度器。它在引擎上创建一个调度 actor，
+// 按照 cron 表达式周期性地向目标 PID 发送消息。触发时间按引擎的 Clock 计算，
+// 测试中可以用 actor.ManualClock 推进。
+//
+//	c := scheduler.NewCron(engine)
+//	err := c.Schedule("rebalance", "*/5 * * * *", pid, Rebalance{})
+type Cron struct {
+	engine *actor.Engine
+	pid    *actor.PID
+}
+
+// NewCron 在给定的引擎上创建一个新的 cron 调度器。
+func NewCron(e *actor.Engine, opts ...actor.OptFunc) *Cron {
+	return &Cron{
+		engine: e,
+		pid:    e.Spawn(newCronActor, "cron", opts...),
+	}
+}
+
+// PID 返回调度 actor 的 PID。
+func (c *Cron) PID() *actor.PID {
+	return c.pid
+}
+
+// Schedule 注册一个 ID 为 id 的任务，按照给定的 cron 表达式向 target 发送 msg。
+// 如果已存在相同 ID 的任务，将被替换。表达式无效时返回错误。
+func (c *Cron) Schedule(id, spec string, target *actor.PID, msg any) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	c.ScheduleWith(id, schedule, target, msg)
+	return nil
+}
+
+// ScheduleWith 与 Schedule 相同，但接受一个自定义的 Schedule。
+func (c *Cron) ScheduleWith(id string, schedule Schedule, target *actor.PID, msg any) {
+	c.engine.Send(c.pid, addJob{
+		id:       id,
+		schedule: schedule,
+		target:   target,
+		msg:      msg,
+	})
+}
+
+// Cancel 取消给定 ID 的任务。
+func (c *Cron) Cancel(id string) {
+	c.engine.Send(c.pid, removeJob{id: id})
+}
+
+// Stop 停止调度器并取消所有任务。
+func (c *Cron) Stop() {
+	<-c.engine.Poison(c.pid).Done()
+}
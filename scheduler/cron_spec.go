@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 描述任务的触发时间。
+type Schedule interface {
+	// Next 返回严格晚于 t 的下一次触发时间。
+	Next(t time.Time) time.Time
+}
+
+// field 描述 cron 表达式中一个字段的取值范围。
+type field struct {
+	name     string
+	min, max uint
+}
+
+var (
+	secondField = field{"second", 0, 59}
+	minuteField = field{"minute", 0, 59}
+	hourField   = field{"hour", 0, 23}
+	domField    = field{"day of month", 1, 31}
+	monthField  = field{"month", 1, 12}
+	dowField    = field{"day of week", 0, 6}
+)
+
+// descriptors 是预定义的 cron 表达式。
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule 是解析后的 cron 表达式，每个字段用位掩码表示允许的取值。
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+}
+
+// everySchedule 以固定间隔触发，对应 "@every <duration>"。
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// Parse 解析 cron 表达式。支持标准的 5 字段格式（分 时 日 月 周），
+// 以及带秒的 6 字段格式（秒 分 时 日 月 周）。每个字段支持 "*"、"a"、"a-b"、
+// "*/n"、"a-b/n" 以及用逗号分隔的列表。同时支持 @hourly、@daily、@weekly、
+// @monthly、@yearly 等预定义表达式和 "@every <duration>"。
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("无效的 @every 间隔: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("@every 间隔必须大于 0: %s", d)
+		}
+		return everySchedule{interval: d}, nil
+	}
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron 表达式需要 5 或 6 个字段，得到 %d 个: %q", len(fields), spec)
+	}
+	var (
+		s   cronSchedule
+		err error
+	)
+	specs := []struct {
+		dst *uint64
+		f   field
+	}{
+		{&s.second, secondField},
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	}
+	for i, sp := range specs {
+		if *sp.dst, err = parseField(fields[i], sp.f); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseField 将单个字段解析为位掩码。
+func parseField(expr string, f field) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(expr, ",") {
+		m, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		mask |= m
+	}
+	return mask, nil
+}
+
+func parseRange(expr string, f field) (uint64, error) {
+	var (
+		lo, hi = f.min, f.max
+		step   = uint(1)
+		err    error
+	)
+	rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+	if hasStep {
+		if step, err = parseUint(stepExpr, f); err != nil {
+			return 0, err
+		}
+		if step == 0 {
+			return 0, fmt.Errorf("%s 字段的步长不能为 0: %q", f.name, expr)
+		}
+	}
+	if rangeExpr != "*" {
+		loExpr, hiExpr, isRange := strings.Cut(rangeExpr, "-")
+		if lo, err = parseUint(loExpr, f); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if isRange {
+			if hi, err = parseUint(hiExpr, f); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			hi = f.max
+		}
+	}
+	if lo < f.min || hi > f.max || lo > hi {
+		return 0, fmt.Errorf("%s 字段超出范围 [%d, %d]: %q", f.name, f.min, f.max, expr)
+	}
+	var mask uint64
+	for v := lo; v <= hi; v += step {
+		mask |= 1 << v
+	}
+	return mask, nil
+}
+
+func parseUint(s string, f field) (uint, error) {
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%s 字段无效: %q", f.name, s)
+	}
+	return uint(v), nil
+}
+
+// Next 返回严格晚于 t 的下一次触发时间。如果五年内都没有匹配的时间，返回零值。
+func (s cronSchedule) Next(t time.Time) time.Time {
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())).Truncate(time.Second)
+	limit := t.AddDate(5, 0, 0)
+	loc := t.Location()
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 判断日期是否匹配。与标准 cron 一致：当日和周都有限制时，满足其一即可。
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	var (
+		domMatch = s.dom&(1<<uint(t.Day())) != 0
+		dowMatch = s.dow&(1<<uint(t.Weekday())) != 0
+		domAll   = bits.OnesCount64(s.dom) == int(domField.max-domField.min+1)
+		dowAll   = bits.OnesCount64(s.dow) == int(dowField.max-dowField.min+1)
+	)
+	if domAll || dowAll {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TAnNbR/Distributed-framework/actor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNext(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC) // 周五
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 12 * * *", time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{"30 9 * * *", time.Date(2024, time.March, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2024, time.March, 15, 13, 0, 0, 0, time.UTC)},
+		{"0,30 * * * *", time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)},
+		{"45 7 * * * *", time.Date(2024, time.March, 15, 10, 7, 45, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, s.Next(base), tt.spec)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+		"@every -1s",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCronScheduleAndCancel(t *testing.T) {
+	e, err := actor.NewEngine(actor.NewEngineConfig())
+	require.NoError(t, err)
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		ticks int
+	)
+	wg.Add(3)
+	pid := e.SpawnFunc(func(c *actor.Context) {
+		if c.Message() == "tick" {
+			mu.Lock()
+			ticks++
+			if ticks <= 3 {
+				wg.Done()
+			}
+			mu.Unlock()
+		}
+	}, "target")
+
+	c := NewCron(e)
+	require.NoError(t, c.Schedule("tick", "@every 5ms", pid, "tick"))
+	assert.Error(t, c.Schedule("bad", "not a spec", pid, "tick"))
+	wg.Wait()
+
+	c.Cancel("tick")
+	// 等待取消生效后，不应再收到新的消息。
+	time.Sleep(10 * time.Millisecond)
+	mu.Lock()
+	n := ticks
+	mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, n, ticks)
+	mu.Unlock()
+	c.Stop()
+}
+
+func TestCronManualClock(t *testing.T) {
+	clock := actor.NewManualClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	e, err := actor.NewEngine(actor.NewEngineConfig().WithClock(clock))
+	require.NoError(t, err)
+	ticks := make(chan struct{}, 4)
+	pid := e.SpawnFunc(func(c *actor.Context) {
+		if c.Message() == "tick" {
+			ticks <- struct{}{}
+		}
+	}, "target")
+
+	c := NewCron(e)
+	defer c.Stop()
+	require.NoError(t, c.Schedule("tick", "*/5 * * * *", pid, "tick"))
+	// 下一次触发时间按引擎的时钟计算。
+	clock.BlockUntil(1)
+	clock.Advance(4 * time.Minute)
+	assert.Empty(t, ticks)
+	clock.Advance(time.Minute)
+	select {
+	case <-ticks:
+	case <-time.After(time.Second):
+		t.Fatal("cron 任务没有在时钟到期后触发")
+	}
+}